@@ -0,0 +1,69 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/bootstrap"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fuse"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// MountCmd represents the mount command
+var MountCmd = &cobra.Command{
+	Use:   "mount <mountpoint>",
+	Short: "Mount the storages as a local file system through FUSE",
+	Long: `Mount the storages as a local file system through FUSE,
+the command blocks until it is interrupted or the mountpoint is unmounted.
+It is built with the fuse tag and needs libfuse (macFUSE, WinFsp) at run time`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Init()
+		defer Release()
+		bootstrap.LoadStorages()
+		bootstrap.InitTaskManager()
+		defer fs.ArchiveContentUploadTaskManager.RemoveAll()
+
+		username, _ := cmd.Flags().GetString("user")
+		user, err := op.GetAdmin()
+		if username != "" {
+			user, err = op.GetUserByName(username)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user: %+v", err)
+		}
+		root, _ := cmd.Flags().GetString("root")
+		host, err := fuse.NewHost(user, root)
+		if err != nil {
+			return fmt.Errorf("failed to create fuse host: %+v", err)
+		}
+
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-quit
+			utils.Log.Println("Unmount...")
+			host.Unmount()
+		}()
+		options, _ := cmd.Flags().GetStringArray("option")
+		utils.Log.Infof("mount [%s] of user [%s] at %s", root, user.Username, args[0])
+		if !host.Mount(args[0], options) {
+			return fmt.Errorf("failed to mount at %s", args[0])
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(MountCmd)
+	MountCmd.Flags().String("root", "/", "path of the OpenList file system to mount")
+	MountCmd.Flags().String("user", "", "mount on behalf of this user, defaults to admin")
+	MountCmd.Flags().StringArrayP("option", "o", nil, "fuse mount options, e.g. -o allow_other")
+}
@@ -67,6 +67,14 @@ func (d *Alias) Init(ctx context.Context) error {
 	return nil
 }
 
+// LinkTarget reports the aliased path when the storage aliases exactly one.
+func (d *Alias) LinkTarget() (string, bool) {
+	if !d.autoFlatten || len(d.pathMap[d.oneKey]) != 1 {
+		return "", false
+	}
+	return utils.FixAndCleanPath(d.pathMap[d.oneKey][0]), true
+}
+
 func (d *Alias) Drop(ctx context.Context) error {
 	d.rootOrder = nil
 	d.pathMap = nil
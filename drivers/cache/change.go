@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	log "github.com/sirupsen/logrus"
+)
+
+// changeRetention bounds how long the change log of a storage is kept.
+const changeRetention = 30 * 24 * time.Hour
+
+// recordChanges 对比刷新前后的快照，写入变更日志，并把变更交给 op.List
+// 随本次列表调用的 objs 更新钩子。首次缓存的目录没有旧快照，作为基线不产生变更。
+func (d *Cache) recordChanges(ctx context.Context, dirPath string, old, cur []model.CachedObj) {
+	changes := diffCachedObjs(d.ID, dirPath, old, cur)
+	if len(changes) == 0 {
+		return
+	}
+	log.Debugf("cache: %s changed, %d entries", dirPath, len(changes))
+	if err := CreateCacheChanges(changes); err != nil {
+		log.Errorf("cache: record changes %s: %+v", dirPath, err)
+	}
+	if err := DeleteCacheChangesBefore(d.ID, time.Now().Add(-changeRetention)); err != nil {
+		log.Errorf("cache: prune changes: %+v", err)
+	}
+
+	byName := make(map[string]model.CachedObj, len(old)+len(cur))
+	for _, o := range old {
+		byName[o.Name] = o
+	}
+	for _, c := range cur {
+		byName[c.Name] = c
+	}
+	change := &op.ObjsChange{}
+	for _, c := range changes {
+		obj := fromCachedObj(byName[c.Name])
+		switch c.Type {
+		case model.CacheChangeAdded:
+			change.Added = append(change.Added, obj)
+		case model.CacheChangeRemoved:
+			change.Removed = append(change.Removed, obj)
+		default:
+			change.Modified = append(change.Modified, obj)
+		}
+	}
+	op.SetObjsChange(ctx, change)
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+func TestDiffCachedObjs(t *testing.T) {
+	at := time.Unix(100, 0)
+	old := []model.CachedObj{
+		{Name: "same", Path: "/d/same", Size: 1, Modified: at},
+		{Name: "resized", Path: "/d/resized", Size: 1, Modified: at},
+		{Name: "touched", Path: "/d/touched", Size: 1, Modified: at},
+		{Name: "rehashed", Path: "/d/rehashed", Size: 1, Modified: at, HashInfo: map[string]string{"md5": "aa"}},
+		{Name: "gone", Path: "/d/gone", Size: 3, Modified: at},
+	}
+	cur := []model.CachedObj{
+		{Name: "same", Path: "/d/same", Size: 1, Modified: at, HashInfo: map[string]string{"md5": "new"}},
+		{Name: "resized", Path: "/d/resized", Size: 2, Modified: at},
+		{Name: "touched", Path: "/d/touched", Size: 1, Modified: at.Add(time.Second)},
+		{Name: "rehashed", Path: "/d/rehashed", Size: 1, Modified: at, HashInfo: map[string]string{"md5": "bb"}},
+		{Name: "new", Path: "/d/new", Size: 4, Modified: at},
+	}
+
+	changes := diffCachedObjs(7, "/d", old, cur)
+	type summary struct{ name, typ, reasons string }
+	var got []summary
+	for _, c := range changes {
+		if c.StorageID != 7 || c.DirPath != "/d" {
+			t.Fatalf("change not attributed to the directory: %+v", c)
+		}
+		got = append(got, summary{c.Name, c.Type, c.Reasons})
+	}
+	want := []summary{
+		{"resized", model.CacheChangeModified, "size"},
+		{"touched", model.CacheChangeModified, "mtime"},
+		{"rehashed", model.CacheChangeModified, "hash"},
+		{"new", model.CacheChangeAdded, ""},
+		{"gone", model.CacheChangeRemoved, ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("changes = %+v, want %+v", got, want)
+	}
+	if gone := changes[len(changes)-1]; gone.OldSize != 3 || gone.Size != 0 {
+		t.Fatalf("removed change = %+v", gone)
+	}
+}
+
+func TestRefreshRecordsChangesAndCallsHook(t *testing.T) {
+	d := setup(t)
+	t.Cleanup(func() {
+		_ = DeleteCacheList(d.ID, "/")
+		_ = DeleteCacheChangesBefore(d.ID, time.Now().Add(time.Hour))
+	})
+	changed := make(chan *op.ObjsChange, 4)
+	t.Cleanup(op.RegisterObjsUpdateHook(func(ctx context.Context, parent string, objs []model.Obj) {
+		if change, ok := op.ObjsChangeFromContext(ctx); ok && parent == "/cache" {
+			changed <- change
+		}
+	}))
+
+	// the first listing is the baseline
+	if _, err := op.List(context.Background(), d, "/", model.ListArgs{Refresh: true}); err != nil {
+		t.Fatalf("list: %+v", err)
+	}
+	if changes, total, err := ListCacheChanges(d.ID, "", time.Time{}, 1, 10); err != nil || total != 0 {
+		t.Fatalf("baseline changes = %+v, %v", changes, err)
+	}
+
+	root := mustRootPath(d)
+	_ = os.Remove(filepath.Join(root, "a.txt"))
+	_ = os.WriteFile(filepath.Join(root, "c.txt"), []byte("new"), 0o644)
+	if _, err := op.List(context.Background(), d, "/", model.ListArgs{Refresh: true}); err != nil {
+		t.Fatalf("refresh: %+v", err)
+	}
+
+	changes, total, err := ListCacheChanges(d.ID, "/", time.Time{}, 1, 10)
+	if err != nil || total != 2 {
+		t.Fatalf("changes = %+v (total %d), %v", changes, total, err)
+	}
+	types := map[string]string{}
+	for _, c := range changes {
+		types[c.Name] = c.Type
+	}
+	if types["a.txt"] != model.CacheChangeRemoved || types["c.txt"] != model.CacheChangeAdded {
+		t.Fatalf("change types = %v", types)
+	}
+
+	select {
+	case change := <-changed:
+		if len(change.Added) != 1 || change.Added[0].GetName() != "c.txt" ||
+			len(change.Removed) != 1 || change.Removed[0].GetName() != "a.txt" || len(change.Modified) != 0 {
+			t.Fatalf("hook change = %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("objs update hook was not called with the change")
+	}
+	// op.List calls the hooks once per listing, the delta rides on that call
+	select {
+	case change := <-changed:
+		t.Fatalf("hook called twice, again with %+v", change)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -42,3 +42,33 @@ func UpsertCacheList(storageID uint, dirPath string, data []model.CachedObj) err
 func DeleteCacheList(storageID uint, dirPath string) error {
 	return db.GetDb().Where("storage_id = ? AND dir_path = ?", storageID, dirPath).Delete(&model.CacheList{}).Error
 }
+
+func CreateCacheChanges(changes []model.CacheChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return db.GetDb().CreateInBatches(changes, 100).Error
+}
+
+// ListCacheChanges returns the newest changes first, since and dirPath are
+// optional filters.
+func ListCacheChanges(storageID uint, dirPath string, since time.Time, pageIndex, pageSize int) ([]model.CacheChange, int64, error) {
+	query := db.GetDb().Model(&model.CacheChange{}).Where("storage_id = ?", storageID)
+	if dirPath != "" {
+		query = query.Where("dir_path = ?", dirPath)
+	}
+	if !since.IsZero() {
+		query = query.Where("created_at > ?", since)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var changes []model.CacheChange
+	err := query.Order("id desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&changes).Error
+	return changes, total, err
+}
+
+func DeleteCacheChangesBefore(storageID uint, before time.Time) error {
+	return db.GetDb().Where("storage_id = ? AND created_at < ?", storageID, before).Delete(&model.CacheChange{}).Error
+}
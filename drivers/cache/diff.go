@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+// diffCachedObjs compares two snapshots of the same directory by entry name.
+// An entry counts as modified when its type, size, modification time or any
+// hash both snapshots know differs.
+func diffCachedObjs(storageID uint, dirPath string, old, cur []model.CachedObj) []model.CacheChange {
+	oldByName := make(map[string]model.CachedObj, len(old))
+	for _, o := range old {
+		oldByName[o.Name] = o
+	}
+	var changes []model.CacheChange
+	for _, c := range cur {
+		o, ok := oldByName[c.Name]
+		if !ok {
+			changes = append(changes, newCacheChange(storageID, dirPath, model.CacheChangeAdded, c))
+			continue
+		}
+		delete(oldByName, c.Name)
+		if reasons := modifiedReasons(o, c); len(reasons) > 0 {
+			change := newCacheChange(storageID, dirPath, model.CacheChangeModified, c)
+			change.Reasons = strings.Join(reasons, ",")
+			change.OldSize = o.Size
+			change.OldModified = o.Modified
+			changes = append(changes, change)
+		}
+	}
+	// keep removals in the order of the old snapshot
+	for _, o := range old {
+		if _, ok := oldByName[o.Name]; ok {
+			change := newCacheChange(storageID, dirPath, model.CacheChangeRemoved, o)
+			change.Size, change.OldSize = 0, o.Size
+			change.Modified, change.OldModified = time.Time{}, o.Modified
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func modifiedReasons(old, cur model.CachedObj) []string {
+	var reasons []string
+	if old.IsFolder != cur.IsFolder {
+		reasons = append(reasons, "type")
+	}
+	if old.Size != cur.Size {
+		reasons = append(reasons, "size")
+	}
+	if !old.Modified.Equal(cur.Modified) {
+		reasons = append(reasons, "mtime")
+	}
+	for name, v := range cur.HashInfo {
+		if ov, ok := old.HashInfo[name]; ok && v != "" && ov != "" && !strings.EqualFold(ov, v) {
+			reasons = append(reasons, "hash")
+			break
+		}
+	}
+	return reasons
+}
+
+func newCacheChange(storageID uint, dirPath, typ string, c model.CachedObj) model.CacheChange {
+	return model.CacheChange{
+		StorageID: storageID,
+		DirPath:   dirPath,
+		Path:      c.Path,
+		Name:      c.Name,
+		IsFolder:  c.IsFolder,
+		Type:      typ,
+		Size:      c.Size,
+		Modified:  c.Modified,
+	}
+}
@@ -90,7 +90,8 @@ func (d *Cache) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([
 	// 定时扫描（ScheduleScan）按 TTL 门控回源：行新鲜时直接 serve 缓存，
 	// 过期或缺失才回源刷新；手动刷新（无 ScheduleScan）总是回源。
 	ttl := time.Duration(d.TTLHours) * time.Hour
-	if item, err := GetCacheList(d.ID, dirPath); err != nil {
+	item, err := GetCacheList(d.ID, dirPath)
+	if err != nil {
 		log.Errorf("cache: get list %s: %+v", dirPath, err)
 	} else if item != nil && (!args.Refresh || (args.ScheduleScan && time.Since(item.UpdatedAt) < ttl)) {
 		return fromCachedObjs(filterCachedObjs(item.Data, entries, whitelisted)), nil
@@ -105,6 +106,8 @@ func (d *Cache) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([
 	}
 	if err := UpsertCacheList(d.ID, dirPath, snaps); err != nil {
 		log.Errorf("cache: upsert %s: %+v", dirPath, err)
+	} else if item != nil {
+		d.recordChanges(ctx, dirPath, item.Data, snaps)
 	}
 	return fromCachedObjs(filterCachedObjs(snaps, entries, whitelisted)), nil
 }
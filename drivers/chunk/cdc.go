@@ -0,0 +1,73 @@
+package chunk
+
+import (
+	"bufio"
+	"io"
+	"math/bits"
+)
+
+// gearTable maps every byte to a random value of the rolling gear hash. It
+// decides where chunks are cut, so it must never change or the pool stops
+// deduplicating against chunks written before.
+var gearTable = func() (table [256]uint64) {
+	// splitmix64 with a fixed seed
+	seed := uint64(0x6f70656e6c697374)
+	for i := range table {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return
+}()
+
+// maxChunkSize bounds the chunks whatever the part size, as each is held in
+// memory while it is hashed and uploaded.
+const maxChunkSize = 64 << 20
+
+// chunker cuts a stream where the gear hash of the trailing bytes matches a
+// mask, so identical content yields identical chunks wherever it sits in a
+// file. Chunks average about avg bytes and stay within [avg/4, avg*4], avg
+// being lowered so that avg*4 stays within maxChunkSize.
+type chunker struct {
+	r        *bufio.Reader
+	min, max int
+	mask     uint64
+	buf      []byte
+}
+
+func newChunker(r io.Reader, avg int64) *chunker {
+	avg = min(max(avg, 64), maxChunkSize/4)
+	return &chunker{
+		r:    bufio.NewReaderSize(r, 1<<20),
+		min:  int(avg / 4),
+		max:  int(avg * 4),
+		mask: 1<<(bits.Len64(uint64(avg))-1) - 1,
+	}
+}
+
+// next returns the next chunk, which is only valid until the following
+// call, and io.EOF after the last one.
+func (c *chunker) next() ([]byte, error) {
+	c.buf = c.buf[:0]
+	var hash uint64
+	for len(c.buf) < c.max {
+		b, err := c.r.ReadByte()
+		if err == io.EOF {
+			if len(c.buf) == 0 {
+				return nil, io.EOF
+			}
+			return c.buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.buf = append(c.buf, b)
+		hash = hash<<1 + gearTable[b]
+		if len(c.buf) >= c.min && hash&c.mask == 0 {
+			break
+		}
+	}
+	return c.buf, nil
+}
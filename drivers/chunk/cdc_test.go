@@ -0,0 +1,74 @@
+package chunk
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func cutChunks(t *testing.T, data []byte, avg int64) [][]byte {
+	t.Helper()
+	c := newChunker(bytes.NewReader(data), avg)
+	var chunks [][]byte
+	for {
+		b, err := c.next()
+		if err == io.EOF {
+			return chunks
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, bytes.Clone(b))
+	}
+}
+
+func TestChunkerBounds(t *testing.T) {
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+	const avg = 8 << 10
+	chunks := cutChunks(t, data, avg)
+	if !bytes.Equal(bytes.Join(chunks, nil), data) {
+		t.Fatal("chunks do not add up to the input")
+	}
+	for i, c := range chunks {
+		if len(c) > avg*4 || (len(c) < avg/4 && i != len(chunks)-1) {
+			t.Errorf("chunk %d has %d bytes", i, len(c))
+		}
+	}
+	if n := len(chunks); n < len(data)/avg/4 || n > len(data)/avg*4 {
+		t.Errorf("unexpected chunk count %d", n)
+	}
+	if len(cutChunks(t, nil, avg)) != 0 {
+		t.Error("empty input should have no chunks")
+	}
+}
+
+func TestChunkerCapsSize(t *testing.T) {
+	c := newChunker(bytes.NewReader(nil), 1<<40)
+	if c.max > maxChunkSize || c.min > c.max {
+		t.Errorf("chunks of a huge part size within [%d, %d]", c.min, c.max)
+	}
+}
+
+func TestChunkerShiftedContent(t *testing.T) {
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(2)).Read(data)
+	const avg = 8 << 10
+	seen := make(map[string]bool)
+	for _, c := range cutChunks(t, data, avg) {
+		seen[string(c)] = true
+	}
+	// a header inserted at the front only changes the chunks around it
+	shifted := append([]byte("remuxed header"), data...)
+	chunks := cutChunks(t, shifted, avg)
+	shared := 0
+	for _, c := range chunks {
+		if seen[string(c)] {
+			shared++
+		}
+	}
+	if shared < len(chunks)-2 {
+		t.Errorf("only %d of %d chunks are shared after the shift", shared, len(chunks))
+	}
+}
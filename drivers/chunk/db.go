@@ -0,0 +1,104 @@
+package chunk
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddChunkRefs adds the Refs of each item to the reference count of its
+// chunk, creating the chunks not counted yet.
+func AddChunkRefs(storageID uint, refs []model.ChunkRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	return db.GetDb().Transaction(func(tx *gorm.DB) error {
+		for _, ref := range refs {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "storage_id"}, {Name: "hash"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"refs":       gorm.Expr("refs + ?", ref.Refs),
+					"updated_at": now,
+				}),
+			}).Create(&model.ChunkRef{
+				StorageID: storageID,
+				Hash:      ref.Hash,
+				Size:      ref.Size,
+				Refs:      ref.Refs,
+				UpdatedAt: now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseChunkRefs subtracts the Refs of each item from the reference count
+// of its chunk. It returns the chunks no longer referenced, whose counts are
+// dropped, chunks never counted are left alone.
+func ReleaseChunkRefs(storageID uint, refs []model.ChunkRef) ([]model.ChunkRef, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	var released []model.ChunkRef
+	err := db.GetDb().Transaction(func(tx *gorm.DB) error {
+		hashes := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			err := tx.Model(&model.ChunkRef{}).
+				Where("storage_id = ? AND hash = ?", storageID, ref.Hash).
+				Updates(map[string]interface{}{
+					"refs":       gorm.Expr("refs - ?", ref.Refs),
+					"updated_at": time.Now(),
+				}).Error
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, ref.Hash)
+		}
+		err := tx.Where("storage_id = ? AND hash IN ? AND refs <= 0", storageID, hashes).Find(&released).Error
+		if err != nil || len(released) == 0 {
+			return err
+		}
+		return tx.Where("storage_id = ? AND hash IN ? AND refs <= 0", storageID, hashes).Delete(&model.ChunkRef{}).Error
+	})
+	return released, err
+}
+
+// ResetChunkRefs replaces the reference counts of a storage with refs.
+func ResetChunkRefs(storageID uint, refs []model.ChunkRef) error {
+	now := time.Now()
+	for i := range refs {
+		refs[i].ID = 0
+		refs[i].StorageID = storageID
+		refs[i].UpdatedAt = now
+	}
+	return db.GetDb().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("storage_id = ?", storageID).Delete(&model.ChunkRef{}).Error; err != nil {
+			return err
+		}
+		if len(refs) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(refs, 100).Error
+	})
+}
+
+// GetChunkRefStats returns the number of pooled chunks of a storage and
+// their total size.
+func GetChunkRefStats(storageID uint) (int64, int64, error) {
+	var stats struct {
+		Count int64
+		Size  int64
+	}
+	err := db.GetDb().Model(&model.ChunkRef{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(size), 0) AS size").
+		Where("storage_id = ?", storageID).
+		Scan(&stats).Error
+	return stats.Count, stats.Size, err
+}
@@ -0,0 +1,80 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig("data")
+	db.Init(dB)
+}
+
+func TestChunkRefs(t *testing.T) {
+	a := dedupManifest{Chunks: []dedupChunk{{"a", 1}, {"b", 2}, {"a", 1}}}
+	b := dedupManifest{Chunks: []dedupChunk{{"b", 2}, {"c", 3}}}
+	if err := AddChunkRefs(1, a.refs()); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddChunkRefs(1, b.refs()); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddChunkRefs(2, b.refs()); err != nil {
+		t.Fatal(err)
+	}
+	if count, size, err := GetChunkRefStats(1); err != nil || count != 3 || size != 6 {
+		t.Errorf("expected 3 chunks of 6 bytes, got %d of %d (%v)", count, size, err)
+	}
+
+	released, err := ReleaseChunkRefs(1, a.refs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(released) != 1 || released[0].Hash != "a" {
+		t.Errorf("expected a released, got %+v", released)
+	}
+	released, err = ReleaseChunkRefs(1, b.refs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(released) != 2 {
+		t.Errorf("expected b and c released, got %+v", released)
+	}
+	// chunks never counted are not released
+	released, err = ReleaseChunkRefs(1, []model.ChunkRef{{Hash: "d", Refs: 1}})
+	if err != nil || len(released) != 0 {
+		t.Errorf("expected nothing released, got %+v (%v)", released, err)
+	}
+	if count, _, _ := GetChunkRefStats(2); count != 2 {
+		t.Errorf("storage 2 polluted, %d chunks left", count)
+	}
+
+	if err = ResetChunkRefs(2, []model.ChunkRef{{Hash: "e", Size: 5, Refs: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if count, size, _ := GetChunkRefStats(2); count != 1 || size != 5 {
+		t.Errorf("expected the reset chunk only, got %d of %d bytes", count, size)
+	}
+}
+
+func TestManifestName(t *testing.T) {
+	d := &Chunk{Addition: Addition{CustomExt: ".chk"}}
+	name := d.manifestName(12345)
+	if size, ok := d.parseManifestName(name); !ok || size != 12345 {
+		t.Errorf("failed to parse %s", name)
+	}
+	for _, name := range []string{"0.chk", "hash_md5_x.chk", "dedup_x.chk"} {
+		if _, ok := d.parseManifestName(name); ok {
+			t.Errorf("%s is not a manifest", name)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	stdpath "path"
+	"strconv"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// In dedup mode the chunk folder of a file holds a manifest instead of the
+// numbered parts, named after the file size so listings need not read it.
+// The chunks live in the pool as <pool>/<hash[:2]>/<hash>.
+const dedupManifestPrefix = "dedup_"
+
+type dedupChunk struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+type dedupManifest struct {
+	Size   int64        `json:"size"`
+	Chunks []dedupChunk `json:"chunks"`
+}
+
+// refs counts the chunks of the manifest, a chunk repeated within the file
+// is referenced once per occurrence.
+func (m *dedupManifest) refs() []model.ChunkRef {
+	var refs []model.ChunkRef
+	idx := make(map[string]int)
+	for _, c := range m.Chunks {
+		if i, ok := idx[c.Hash]; ok {
+			refs[i].Refs++
+			continue
+		}
+		idx[c.Hash] = len(refs)
+		refs = append(refs, model.ChunkRef{Hash: c.Hash, Size: c.Size, Refs: 1})
+	}
+	return refs
+}
+
+func (d *Chunk) manifestName(size int64) string {
+	return fmt.Sprintf("%s%d%s", dedupManifestPrefix, size, d.CustomExt)
+}
+
+// parseManifestName returns the file size carried by a manifest name.
+func (d *Chunk) parseManifestName(name string) (int64, bool) {
+	after, ok := strings.CutPrefix(strings.TrimSuffix(name, d.CustomExt), dedupManifestPrefix)
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(after, 10, 64)
+	return size, err == nil
+}
+
+func (d *Chunk) poolDir(root, hash string) string {
+	return stdpath.Join(root, d.DedupPool, hash[:2])
+}
+
+func (d *Chunk) poolPath(root, hash string) string {
+	return stdpath.Join(d.poolDir(root, hash), hash+d.CustomExt)
+}
+
+// readManifest reads the manifest at path of the remote storage.
+func readManifest(ctx context.Context, storage driver.Driver, path string) (*dedupManifest, error) {
+	l, obj, err := op.Link(ctx, storage, path, model.LinkArgs{})
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	size := l.ContentLength
+	if size <= 0 {
+		size = obj.GetSize()
+	}
+	rrf, err := stream.GetRangeReaderFromLink(size, l)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := rrf.RangeRead(ctx, http_range.Range{Length: -1})
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var m dedupManifest
+	if err = utils.Json.NewDecoder(rc).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// findManifest returns the manifest in the chunk folder dir and its name, or
+// nil if the folder holds none.
+func (d *Chunk) findManifest(ctx context.Context, storage driver.Driver, dir string, refresh bool) (*dedupManifest, string, error) {
+	objs, err := op.List(ctx, storage, dir, model.ListArgs{Refresh: refresh})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	for _, o := range objs {
+		if _, ok := d.parseManifestName(o.GetName()); ok && !o.IsDir() {
+			m, err := readManifest(ctx, storage, stdpath.Join(dir, o.GetName()))
+			return m, o.GetName(), err
+		}
+	}
+	return nil, "", nil
+}
+
+// walkManifests calls visit with every manifest under the remote dir, the
+// pool is skipped. onDir reports the dirs walked and still queued.
+func (d *Chunk) walkManifests(ctx context.Context, storage driver.Driver, root, dir string, refresh bool,
+	visit func(path string, m *dedupManifest), onDir func(done, queued int)) error {
+	pool := stdpath.Join(root, d.DedupPool)
+	queue := []string{dir}
+	for done := 0; len(queue) > 0; done++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		current := queue[0]
+		queue = queue[1:]
+		objs, err := op.List(ctx, storage, current, model.ListArgs{Refresh: refresh})
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", current, err)
+		}
+		for _, o := range objs {
+			if !o.IsDir() {
+				continue
+			}
+			path := stdpath.Join(current, o.GetName())
+			if !strings.HasPrefix(o.GetName(), d.ChunkPrefix) {
+				if path != pool {
+					queue = append(queue, path)
+				}
+				continue
+			}
+			m, name, err := d.findManifest(ctx, storage, path, refresh)
+			if err != nil {
+				return err
+			}
+			if m != nil {
+				visit(stdpath.Join(path, name), m)
+			}
+		}
+		if onDir != nil {
+			onDir(done+1, len(queue))
+		}
+	}
+	return nil
+}
+
+// putPoolChunk stores a chunk in the pool unless it is there already.
+func (d *Chunk) putPoolChunk(ctx context.Context, storage driver.Driver, root, hash string, data []byte, file model.FileStreamer) error {
+	if obj, err := op.Get(ctx, storage, d.poolPath(root, hash)); err == nil && obj.GetSize() == int64(len(data)) {
+		return nil
+	}
+	return op.Put(ctx, storage, d.poolDir(root, hash), &stream.FileStream{
+		Obj: &model.Object{
+			Name:     hash + d.CustomExt,
+			Size:     int64(len(data)),
+			Modified: file.ModTime(),
+		},
+		Mimetype: "application/octet-stream",
+		Reader:   bytes.NewReader(data),
+	}, nil, true)
+}
+
+// putDedup cuts file by content into the pool and writes its manifest, the
+// file it replaces releases its chunks afterwards.
+func (d *Chunk) putDedup(ctx context.Context, storage driver.Driver, root, dst string, file model.FileStreamer, r io.Reader) error {
+	old, err := d.writeDedup(ctx, storage, root, dst, file, r)
+	if err != nil || old == nil {
+		return err
+	}
+	d.poolMu.Lock()
+	defer d.poolMu.Unlock()
+	d.release(ctx, storage, root, old.refs())
+	return nil
+}
+
+// writeDedup holds the read lock of the pool, so no chunk it finds pooled is
+// removed before its refs are added. It returns the replaced manifest.
+func (d *Chunk) writeDedup(ctx context.Context, storage driver.Driver, root, dst string, file model.FileStreamer, r io.Reader) (*dedupManifest, error) {
+	d.poolMu.RLock()
+	defer d.poolMu.RUnlock()
+	old, oldName, err := d.findManifest(ctx, storage, dst, false)
+	if err != nil {
+		return nil, err
+	}
+	var m dedupManifest
+	c := newChunker(r, d.PartSize)
+	for {
+		b, err := c.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(b)
+		hash := hex.EncodeToString(sum[:])
+		// chunks pooled by a failed upload are left to the gc task
+		if err = d.putPoolChunk(ctx, storage, root, hash, b, file); err != nil {
+			return nil, err
+		}
+		m.Chunks = append(m.Chunks, dedupChunk{Hash: hash, Size: int64(len(b))})
+		m.Size += int64(len(b))
+	}
+	if file.GetSize() >= 0 && m.Size != file.GetSize() {
+		return nil, fmt.Errorf("read %d bytes, expected %d", m.Size, file.GetSize())
+	}
+	data, err := utils.Json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if d.StoreHash {
+		d.putHashes(ctx, storage, dst, file)
+	}
+	err = op.Put(ctx, storage, dst, &stream.FileStream{
+		Obj: &model.Object{
+			Name:     d.manifestName(m.Size),
+			Size:     int64(len(data)),
+			Modified: file.ModTime(),
+		},
+		Mimetype: "application/json",
+		Reader:   bytes.NewReader(data),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = AddChunkRefs(d.ID, m.refs()); err != nil {
+		return nil, err
+	}
+	if old != nil && oldName != d.manifestName(m.Size) {
+		if err = op.Remove(ctx, storage, stdpath.Join(dst, oldName)); err != nil {
+			log.Warnf("chunk: failed to remove replaced manifest %s: %+v", oldName, err)
+		}
+	}
+	return old, nil
+}
+
+// release drops refs and removes the chunks no longer referenced. The caller
+// holds the write lock of the pool.
+func (d *Chunk) release(ctx context.Context, storage driver.Driver, root string, refs []model.ChunkRef) {
+	released, err := ReleaseChunkRefs(d.ID, refs)
+	if err != nil {
+		log.Warnf("chunk: failed to release chunk refs: %+v", err)
+		return
+	}
+	for _, ref := range released {
+		if err = op.Remove(ctx, storage, d.poolPath(root, ref.Hash)); err != nil && !errs.IsObjectNotFound(err) {
+			log.Warnf("chunk: failed to remove pooled chunk %s: %+v", ref.Hash, err)
+		}
+	}
+}
+
+// collectRefs returns the refs of every manifest at or under the remote
+// path, which is either a dir or a chunk folder.
+func (d *Chunk) collectRefs(ctx context.Context, storage driver.Driver, root, path string, isChunk bool) ([]model.ChunkRef, error) {
+	var refs []model.ChunkRef
+	if isChunk {
+		m, _, err := d.findManifest(ctx, storage, path, false)
+		if err != nil || m == nil {
+			return nil, err
+		}
+		return m.refs(), nil
+	}
+	err := d.walkManifests(ctx, storage, root, path, false, func(_ string, m *dedupManifest) {
+		refs = append(refs, m.refs()...)
+	}, nil)
+	return refs, err
+}
+
+// removeDedup removes obj along with the refs of the manifests it holds.
+func (d *Chunk) removeDedup(ctx context.Context, storage driver.Driver, root string, obj model.Obj) error {
+	d.poolMu.Lock()
+	defer d.poolMu.Unlock()
+	path := stdpath.Join(root, obj.GetPath())
+	chunkObj, isChunk := obj.(*chunkObject)
+	refs, err := d.collectRefs(ctx, storage, root, path, isChunk && chunkObj.manifest != "")
+	if err != nil {
+		return err
+	}
+	if err = op.Remove(ctx, storage, path); err != nil {
+		return err
+	}
+	d.release(ctx, storage, root, refs)
+	return nil
+}
+
+// copyDedupRefs references the chunks of the manifests a copy of obj is
+// about to duplicate. A failed copy leaves them to the gc task.
+func (d *Chunk) copyDedupRefs(ctx context.Context, storage driver.Driver, root string, obj model.Obj) error {
+	d.poolMu.RLock()
+	defer d.poolMu.RUnlock()
+	chunkObj, isChunk := obj.(*chunkObject)
+	if !obj.IsDir() && !(isChunk && chunkObj.manifest != "") {
+		return nil
+	}
+	refs, err := d.collectRefs(ctx, storage, root, stdpath.Join(root, obj.GetPath()), isChunk)
+	if err != nil {
+		return err
+	}
+	return AddChunkRefs(d.ID, refs)
+}
+
+// dedupParts returns the chunk sizes of a dedup file and the remote path of
+// each chunk.
+func (d *Chunk) dedupParts(ctx context.Context, storage driver.Driver, root, dir string, file *chunkObject) ([]int64, func(int) string, error) {
+	m, err := readManifest(ctx, storage, stdpath.Join(dir, file.manifest))
+	if err != nil {
+		return nil, nil, err
+	}
+	if m.Size != file.GetSize() {
+		return nil, nil, fmt.Errorf("manifest size %d does not match %d", m.Size, file.GetSize())
+	}
+	sizes := make([]int64, len(m.Chunks))
+	for i, c := range m.Chunks {
+		sizes[i] = c.Size
+	}
+	return sizes, func(idx int) string {
+		return d.poolPath(root, m.Chunks[idx].Hash)
+	}, nil
+}
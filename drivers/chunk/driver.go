@@ -9,6 +9,7 @@ import (
 	stdpath "path"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -17,6 +18,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"github.com/OpenListTeam/OpenList/v4/pkg/errgroup"
 	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
@@ -27,6 +29,9 @@ import (
 type Chunk struct {
 	model.Storage
 	Addition
+	// poolMu is read locked while chunks get referenced and write locked
+	// while unreferenced ones are removed
+	poolMu sync.RWMutex
 }
 
 func (d *Chunk) Config() driver.Config {
@@ -44,6 +49,9 @@ func (d *Chunk) Init(ctx context.Context) error {
 	if len(d.ChunkPrefix) <= 0 {
 		return errors.New("chunk folder prefix must not be empty")
 	}
+	if d.Dedup && (d.DedupPool == "" || strings.Contains(d.DedupPool, "/") || strings.HasPrefix(d.DedupPool, d.ChunkPrefix)) {
+		return errors.New("dedup pool must be a folder name not starting with the chunk folder prefix")
+	}
 	d.RemotePath = utils.FixAndCleanPath(d.RemotePath)
 	return nil
 }
@@ -87,6 +95,8 @@ func (d *Chunk) Get(ctx context.Context, path string) (model.Obj, error) {
 	chunkSizes := []int64{-1}
 	h := make(map[*utils.HashType]string)
 	var first model.Obj
+	var manifest string
+	var manifestSize int64
 	for _, o := range chunkObjs {
 		if o.IsDir() {
 			continue
@@ -101,6 +111,10 @@ func (d *Chunk) Get(ctx context.Context, path string) (model.Obj, error) {
 			}
 			continue
 		}
+		if size, ok := d.parseManifestName(o.GetName()); ok {
+			manifest, manifestSize, first = o.GetName(), size, o
+			continue
+		}
 		idx, err := strconv.Atoi(strings.TrimSuffix(o.GetName(), d.CustomExt))
 		if err != nil {
 			continue
@@ -120,6 +134,9 @@ func (d *Chunk) Get(ctx context.Context, path string) (model.Obj, error) {
 			chunkSizes[idx] = o.GetSize()
 		}
 	}
+	if manifest != "" {
+		totalSize = manifestSize
+	}
 	reqDir, _ := stdpath.Split(path)
 	objRes := chunkObject{
 		Object: model.Object{
@@ -130,6 +147,7 @@ func (d *Chunk) Get(ctx context.Context, path string) (model.Obj, error) {
 			Ctime:    first.CreateTime(),
 		},
 		chunkSizes: chunkSizes,
+		manifest:   manifest,
 	}
 	if len(h) > 0 {
 		objRes.HashInfo = utils.NewHashInfoByMap(h)
@@ -152,12 +170,16 @@ func (d *Chunk) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([
 		return nil, err
 	}
 	result := make([]model.Obj, 0, len(remoteObjs))
+	isRoot := utils.PathEqual(dir.GetPath(), "/")
 	listG, listCtx := errgroup.NewGroupWithContext(ctx, d.NumListWorkers, retry.Attempts(3))
 	for _, obj := range remoteObjs {
 		if utils.IsCanceled(listCtx) {
 			break
 		}
 		rawName := obj.GetName()
+		if obj.IsDir() && isRoot && rawName == d.DedupPool {
+			continue
+		}
 		if obj.IsDir() {
 			if name, ok := strings.CutPrefix(rawName, d.ChunkPrefix); ok {
 				resultIdx := len(result)
@@ -172,12 +194,17 @@ func (d *Chunk) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([
 						return err
 					}
 					totalSize := int64(0)
+					manifestSize := int64(-1)
 					h := make(map[*utils.HashType]string)
 					first := obj
 					for _, o := range chunkObjs {
 						if o.IsDir() {
 							continue
 						}
+						if size, ok := d.parseManifestName(o.GetName()); ok {
+							manifestSize, first = size, o
+							continue
+						}
 						if after, ok := strings.CutPrefix(strings.TrimSuffix(o.GetName(), d.CustomExt), "hash_"); ok {
 							hn, value, ok := strings.Cut(after, "_")
 							if ok {
@@ -197,6 +224,9 @@ func (d *Chunk) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([
 						}
 						totalSize += o.GetSize()
 					}
+					if manifestSize >= 0 {
+						totalSize = manifestSize
+					}
 					objRes := model.Object{
 						Name:     name,
 						Size:     totalSize,
@@ -261,6 +291,7 @@ func (d *Chunk) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (
 		return nil, err
 	}
 	chunkFile, ok := file.(*chunkObject)
+	root := remoteActualPath
 	remoteActualPath = stdpath.Join(remoteActualPath, file.GetPath())
 	if !ok {
 		l, _, err := op.Link(ctx, remoteStorage, remoteActualPath, args)
@@ -271,19 +302,30 @@ func (d *Chunk) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (
 		resultLink.SyncClosers = utils.NewSyncClosers(l)
 		return &resultLink, nil
 	}
-	// 检查0号块不等于-1 以支持空文件
-	// 如果块数量大于1 最后一块不可能为0
-	// 只检查中间块是否有0
-	for i, l := 0, len(chunkFile.chunkSizes)-2; ; i++ {
-		if i == 0 {
-			if chunkFile.chunkSizes[i] == -1 {
+	chunkSizes := chunkFile.chunkSizes
+	partPath := func(idx int) string {
+		return stdpath.Join(remoteActualPath, d.getPartName(idx))
+	}
+	if chunkFile.manifest != "" {
+		chunkSizes, partPath, err = d.dedupParts(ctx, remoteStorage, root, remoteActualPath, chunkFile)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// 检查0号块不等于-1 以支持空文件
+		// 如果块数量大于1 最后一块不可能为0
+		// 只检查中间块是否有0
+		for i, l := 0, len(chunkSizes)-2; ; i++ {
+			if i == 0 {
+				if chunkSizes[i] == -1 {
+					return nil, fmt.Errorf("chunk part[%d] are missing", i)
+				}
+			} else if chunkSizes[i] == 0 {
 				return nil, fmt.Errorf("chunk part[%d] are missing", i)
 			}
-		} else if chunkFile.chunkSizes[i] == 0 {
-			return nil, fmt.Errorf("chunk part[%d] are missing", i)
-		}
-		if i >= l {
-			break
+			if i >= l {
+				break
+			}
 		}
 	}
 	fileSize := chunkFile.GetSize()
@@ -302,9 +344,9 @@ func (d *Chunk) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (
 			rc       io.ReadCloser
 			readFrom bool
 		)
-		for idx, chunkSize := range chunkFile.chunkSizes {
+		for idx, chunkSize := range chunkSizes {
 			if readFrom {
-				l, o, err := op.Link(ctx, remoteStorage, stdpath.Join(remoteActualPath, d.getPartName(idx)), args)
+				l, o, err := op.Link(ctx, remoteStorage, partPath(idx), args)
 				if err != nil {
 					_ = cs.Close()
 					return nil, err
@@ -345,7 +387,7 @@ func (d *Chunk) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (
 			} else if newStart := start - chunkSize; newStart >= 0 {
 				start = newStart
 			} else {
-				l, o, err := op.Link(ctx, remoteStorage, stdpath.Join(remoteActualPath, d.getPartName(idx)), args)
+				l, o, err := op.Link(ctx, remoteStorage, partPath(idx), args)
 				if err != nil {
 					_ = cs.Close()
 					return nil, err
@@ -407,7 +449,25 @@ func (d *Chunk) Rename(ctx context.Context, srcObj model.Obj, newName string) er
 	return fs.Rename(ctx, stdpath.Join(d.RemotePath, srcObj.GetPath()), newName)
 }
 
+// holdsDedup tells whether obj is a dedup manifest or, in dedup mode, a
+// folder that may hold some.
+func (d *Chunk) holdsDedup(obj model.Obj) bool {
+	chunkObj, ok := obj.(*chunkObject)
+	return (ok && chunkObj.manifest != "") || (d.Dedup && obj.IsDir())
+}
+
 func (d *Chunk) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	// a copied manifest references its chunks once more, even once dedup
+	// mode is turned off
+	if d.holdsDedup(srcObj) {
+		remoteStorage, remoteActualPath, err := op.GetStorageAndActualPath(d.RemotePath)
+		if err != nil {
+			return err
+		}
+		if err = d.copyDedupRefs(ctx, remoteStorage, remoteActualPath, srcObj); err != nil {
+			return err
+		}
+	}
 	dst := stdpath.Join(d.RemotePath, dstDir.GetPath())
 	src := stdpath.Join(d.RemotePath, srcObj.GetPath())
 	_, err := fs.Copy(ctx, src, dst)
@@ -415,6 +475,15 @@ func (d *Chunk) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
 }
 
 func (d *Chunk) Remove(ctx context.Context, obj model.Obj) error {
+	// the manifests in a removed folder are only looked for in dedup mode,
+	// a removed manifest always releases its chunks
+	if d.holdsDedup(obj) {
+		remoteStorage, remoteActualPath, err := op.GetStorageAndActualPath(d.RemotePath)
+		if err != nil {
+			return err
+		}
+		return d.removeDedup(ctx, remoteStorage, remoteActualPath, obj)
+	}
 	return fs.Remove(ctx, stdpath.Join(d.RemotePath, obj.GetPath()))
 }
 
@@ -431,18 +500,11 @@ func (d *Chunk) Put(ctx context.Context, dstDir model.Obj, file model.FileStream
 		UpdateProgress: up,
 	}
 	dst := stdpath.Join(remoteActualPath, dstDir.GetPath(), d.ChunkPrefix+file.GetName())
+	if d.Dedup {
+		return d.putDedup(ctx, remoteStorage, remoteActualPath, dst, file, upReader)
+	}
 	if d.StoreHash {
-		for ht, value := range file.GetHash().All() {
-			_ = op.Put(ctx, remoteStorage, dst, &stream.FileStream{
-				Obj: &model.Object{
-					Name:     fmt.Sprintf("hash_%s_%s%s", ht.Name, value, d.CustomExt),
-					Size:     1,
-					Modified: file.ModTime(),
-				},
-				Mimetype: "application/octet-stream",
-				Reader:   bytes.NewReader([]byte{0}), // 兼容不支持空文件的驱动
-			}, nil, true)
-		}
+		d.putHashes(ctx, remoteStorage, dst, file)
 	}
 	fullPartCount := int(file.GetSize() / d.PartSize)
 	tailSize := file.GetSize() % d.PartSize
@@ -482,6 +544,20 @@ func (d *Chunk) Put(ctx context.Context, dstDir model.Obj, file model.FileStream
 	return err
 }
 
+func (d *Chunk) putHashes(ctx context.Context, remoteStorage driver.Driver, dst string, file model.FileStreamer) {
+	for ht, value := range file.GetHash().All() {
+		_ = op.Put(ctx, remoteStorage, dst, &stream.FileStream{
+			Obj: &model.Object{
+				Name:     fmt.Sprintf("hash_%s_%s%s", ht.Name, value, d.CustomExt),
+				Size:     1,
+				Modified: file.ModTime(),
+			},
+			Mimetype: "application/octet-stream",
+			Reader:   bytes.NewReader([]byte{0}), // 兼容不支持空文件的驱动
+		}, nil, true)
+	}
+}
+
 func (d *Chunk) getPartName(part int) string {
 	return fmt.Sprintf("%d%s", part, d.CustomExt)
 }
@@ -500,4 +576,23 @@ func (d *Chunk) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
 	}, nil
 }
 
+// Other starts a gc task for admins on "gc" and answers "dedup_stats" with
+// the chunks pooled in dedup mode.
+func (d *Chunk) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	switch args.Method {
+	case "gc":
+		return task.AddByAdmin(ctx, func() (*GCTask, error) {
+			return d.gc(ctx), nil
+		})
+	case "dedup_stats":
+		count, size, err := GetChunkRefStats(d.ID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int64{"chunks": count, "size": size}, nil
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
 var _ driver.Driver = (*Chunk)(nil)
@@ -0,0 +1,152 @@
+package chunk
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/tache"
+	log "github.com/sirupsen/logrus"
+)
+
+type GCProgress struct {
+	DirsDone  int   `json:"dirs_done"`
+	Queued    int   `json:"queued"`
+	PoolDirs  int   `json:"pool_dirs"`
+	PoolDone  int   `json:"pool_done"`
+	Manifests int   `json:"manifests"`
+	Chunks    int   `json:"chunks"`
+	Removed   int   `json:"removed"`
+	Freed     int64 `json:"freed"`
+	Errors    int   `json:"errors"`
+}
+
+// Walked weighs the walk of the manifests and the sweep of the pool as a
+// half each.
+func (p GCProgress) Walked() (int, int) {
+	if p.PoolDirs == 0 {
+		return p.DirsDone, p.DirsDone + 2*p.Queued
+	}
+	return p.PoolDirs + p.PoolDone, p.PoolDirs - p.PoolDone
+}
+
+// GCTask counts the chunks every manifest references, removes the pooled
+// chunks none does and resets the reference counts to what it found. Writes
+// to the storage wait while it runs.
+type GCTask struct {
+	task.WalkTask[GCProgress]
+	storage *Chunk
+}
+
+func (t *GCTask) GetName() string {
+	return fmt.Sprintf("chunk gc [%s]", t.storage.MountPath)
+}
+
+func (t *GCTask) GetStatus() string {
+	p := t.Progress()
+	return fmt.Sprintf("%d manifests, %d of %d chunks removed, %d errors", p.Manifests, p.Removed, p.Chunks, p.Errors)
+}
+
+func (t *GCTask) Run() error {
+	return t.Walk(t.gc)
+}
+
+func (t *GCTask) gc(ctx context.Context) error {
+	d := t.storage
+	remoteStorage, root, err := op.GetStorageAndActualPath(d.RemotePath)
+	if err != nil {
+		return err
+	}
+	d.poolMu.Lock()
+	defer d.poolMu.Unlock()
+
+	// a manifest missed would get its chunks removed, so any failure while
+	// walking aborts before removing anything
+	refs := make(map[string]*model.ChunkRef)
+	err = d.walkManifests(ctx, remoteStorage, root, root, true, func(_ string, m *dedupManifest) {
+		for _, ref := range m.refs() {
+			if r, ok := refs[ref.Hash]; ok {
+				r.Refs += ref.Refs
+			} else {
+				refs[ref.Hash] = &ref
+			}
+		}
+		t.Update(func(p *GCProgress) { p.Manifests++ })
+	}, func(done, queued int) {
+		t.Update(func(p *GCProgress) {
+			p.DirsDone, p.Queued = done, queued
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	pool := stdpath.Join(root, d.DedupPool)
+	dirs, err := op.List(ctx, remoteStorage, pool, model.ListArgs{Refresh: true})
+	if err != nil && !errs.IsObjectNotFound(err) {
+		return fmt.Errorf("failed to list pool: %w", err)
+	}
+	t.Update(func(p *GCProgress) { p.PoolDirs = len(dirs) })
+	for i, dir := range dirs {
+		t.Update(func(p *GCProgress) { p.PoolDone = i })
+		if !dir.IsDir() {
+			continue
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		objs, err := op.List(ctx, remoteStorage, stdpath.Join(pool, dir.GetName()), model.ListArgs{Refresh: true})
+		if err != nil {
+			log.Warnf("chunk gc: failed to list %s: %+v", dir.GetName(), err)
+			t.Update(func(p *GCProgress) { p.Errors++ })
+			continue
+		}
+		for _, obj := range objs {
+			if obj.IsDir() {
+				continue
+			}
+			t.Update(func(p *GCProgress) { p.Chunks++ })
+			if _, ok := refs[strings.TrimSuffix(obj.GetName(), d.CustomExt)]; ok {
+				continue
+			}
+			err = op.Remove(ctx, remoteStorage, stdpath.Join(pool, dir.GetName(), obj.GetName()))
+			if err != nil {
+				log.Warnf("chunk gc: failed to remove %s: %+v", obj.GetName(), err)
+				t.Update(func(p *GCProgress) { p.Errors++ })
+				continue
+			}
+			t.Update(func(p *GCProgress) {
+				p.Removed++
+				p.Freed += obj.GetSize()
+			})
+		}
+	}
+	t.Update(func(p *GCProgress) { p.PoolDone = len(dirs) })
+
+	counted := make([]model.ChunkRef, 0, len(refs))
+	for _, ref := range refs {
+		counted = append(counted, *ref)
+	}
+	if err = ResetChunkRefs(d.ID, counted); err != nil {
+		return err
+	}
+	p := t.Progress()
+	log.Infof("chunk gc: completed %s, %d chunks removed, %d bytes freed", t.GetName(), p.Removed, p.Freed)
+	return nil
+}
+
+var GCTaskManager *tache.Manager[*GCTask]
+
+func (d *Chunk) gc(ctx context.Context) *GCTask {
+	t := &GCTask{
+		WalkTask: task.NewWalkTask[GCProgress](ctx),
+		storage:  d,
+	}
+	GCTaskManager.Add(t)
+	return t
+}
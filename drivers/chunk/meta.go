@@ -13,6 +13,8 @@ type Addition struct {
 	CustomExt          string `json:"custom_ext" type:"string"`
 	StoreHash          bool   `json:"store_hash" type:"bool" default:"true"`
 	NumListWorkers     int    `json:"num_list_workers" required:"true" type:"number" default:"5"`
+	Dedup              bool   `json:"dedup" default:"false" help:"cut files by content, part_size becoming the average chunk size, and store each distinct chunk once in a shared pool"`
+	DedupPool          string `json:"dedup_pool" type:"string" default:".openlist_dedup" help:"the folder of the chunk pool under remote_path"`
 
 	Thumbnail  bool `json:"thumbnail" required:"true" default:"false" help:"enable thumbnail which pre-generated under .thumbnails folder"`
 	ShowHidden bool `json:"show_hidden"  default:"true" required:"false" help:"show hidden directories and files"`
@@ -33,6 +35,7 @@ func init() {
 			Addition: Addition{
 				ChunkPrefix:    "[openlist_chunk]",
 				NumListWorkers: 5,
+				DedupPool:      ".openlist_dedup",
 			},
 		}
 	})
@@ -5,4 +5,6 @@ import "github.com/OpenListTeam/OpenList/v4/internal/model"
 type chunkObject struct {
 	model.Object
 	chunkSizes []int64
+	// manifest is the name of the dedup manifest, empty for numbered parts
+	manifest string
 }
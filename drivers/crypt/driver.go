@@ -17,6 +17,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
@@ -43,6 +44,12 @@ func (d *Crypt) GetAddition() driver.Additional {
 }
 
 func (d *Crypt) Init(ctx context.Context) error {
+	if strings.TrimSpace(d.RcloneConfig) != "" {
+		if err := d.importRcloneConfig(d.RcloneConfig); err != nil {
+			return fmt.Errorf("failed to import rclone config: %w", err)
+		}
+		d.RcloneConfig = ""
+	}
 	// obfuscate credentials if it's updated or just created
 	err := d.updateObfusParm(&d.Password)
 	if err != nil {
@@ -54,31 +61,41 @@ func (d *Crypt) Init(ctx context.Context) error {
 	}
 
 	isCryptExt := regexp.MustCompile(`^[.][A-Za-z0-9-_]{2,}$`).MatchString
-	if !isCryptExt(d.EncryptedSuffix) {
+	if !isCryptExt(d.EncryptedSuffix) && d.EncryptedSuffix != "none" {
 		return fmt.Errorf("EncryptedSuffix is Illegal")
 	}
 	d.FileNameEncoding = utils.GetNoneEmpty(d.FileNameEncoding, "base64")
 	d.EncryptedSuffix = utils.GetNoneEmpty(d.EncryptedSuffix, ".bin")
 	d.RemotePath = utils.FixAndCleanPath(d.RemotePath)
 
-	p, _ := strings.CutPrefix(d.Password, obfuscatedPrefix)
-	p2, _ := strings.CutPrefix(d.Salt, obfuscatedPrefix)
+	c, err := newCipher(&d.Addition)
+	if err != nil {
+		return err
+	}
+	d.cipher = c
+
+	return nil
+}
+
+// newCipher creates the cipher of a, whose password and salt are already
+// obfuscated.
+func newCipher(a *Addition) (*rcCrypt.Cipher, error) {
+	p, _ := strings.CutPrefix(a.Password, obfuscatedPrefix)
+	p2, _ := strings.CutPrefix(a.Salt, obfuscatedPrefix)
 	config := configmap.Simple{
 		"password":                  p,
 		"password2":                 p2,
-		"filename_encryption":       d.FileNameEnc,
-		"directory_name_encryption": d.DirNameEnc,
-		"filename_encoding":         d.FileNameEncoding,
-		"suffix":                    d.EncryptedSuffix,
+		"filename_encryption":       a.FileNameEnc,
+		"directory_name_encryption": a.DirNameEnc,
+		"filename_encoding":         a.FileNameEncoding,
+		"suffix":                    a.EncryptedSuffix,
 		"pass_bad_blocks":           "",
 	}
 	c, err := rcCrypt.NewCipher(config)
 	if err != nil {
-		return fmt.Errorf("failed to create Cipher: %w", err)
+		return nil, fmt.Errorf("failed to create Cipher: %w", err)
 	}
-	d.cipher = c
-
-	return nil
+	return c, nil
 }
 
 func (d *Crypt) updateObfusParm(str *string) error {
@@ -378,6 +395,33 @@ func (d *Crypt) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
 	}, nil
 }
 
+// Other renders the storage as an rclone crypt remote on "rclone_config" and
+// starts a key rotation task on "rotate", both for admins only since they
+// expose or change the key.
+func (d *Crypt) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	switch args.Method {
+	case "rclone_config":
+		if err := task.RequireAdmin(ctx); err != nil {
+			return nil, err
+		}
+		return d.rcloneConfig(), nil
+	case "rotate":
+		return task.AddByAdmin(ctx, func() (*RotateTask, error) {
+			data, err := utils.Json.Marshal(args.Data)
+			if err != nil {
+				return nil, err
+			}
+			var rotateArgs RotateArgs
+			if err = utils.Json.Unmarshal(data, &rotateArgs); err != nil {
+				return nil, err
+			}
+			return d.rotate(ctx, rotateArgs)
+		})
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
 func (d *Crypt) BatchMove(ctx context.Context, srcDir model.Obj, srcObjs []model.Obj, dstDir model.Obj, args model.BatchArgs) error {
 
 	srcRemoteStorage, srcRemotePath, err := op.GetStorageAndActualPath(srcDir.GetPath())
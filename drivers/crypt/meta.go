@@ -19,6 +19,8 @@ type Addition struct {
 
 	ShowHidden  bool `json:"show_hidden"  default:"true" required:"false" help:"show hidden directories and files"`
 	Concurrency int  `json:"concurrency" type:"number" required:"false" help:"download concurrency"`
+
+	RcloneConfig string `json:"rclone_config" type:"text" required:"false" help:"paste a crypt remote of rclone.conf to take its password and options, remote_path still has to point to the encrypted data"`
 }
 
 var config = driver.Config{
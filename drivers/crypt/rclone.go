@@ -0,0 +1,110 @@
+package crypt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// rcloneSection is a section of rclone.conf
+type rcloneSection struct {
+	name   string
+	values map[string]string
+}
+
+// parseRcloneConfig reads the sections of an rclone.conf, comments and blank
+// lines are skipped.
+func parseRcloneConfig(text string) ([]rcloneSection, error) {
+	var sections []rcloneSection
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections = append(sections, rcloneSection{
+				name:   strings.TrimSpace(line[1 : len(line)-1]),
+				values: make(map[string]string),
+			})
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || len(sections) == 0 {
+			return nil, fmt.Errorf("invalid line %d: %s", n, line)
+		}
+		sections[len(sections)-1].values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return sections, scanner.Err()
+}
+
+// importRcloneConfig takes the password and options of the first crypt
+// remote in text. The password rclone stores is already obscured.
+func (d *Crypt) importRcloneConfig(text string) error {
+	sections, err := parseRcloneConfig(text)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(sections, func(s rcloneSection) bool {
+		return s.values["type"] == "crypt"
+	})
+	if i < 0 {
+		return errors.New("no crypt remote found")
+	}
+	v := sections[i].values
+	if v["password"] == "" {
+		return fmt.Errorf("crypt remote %s has no password", sections[i].name)
+	}
+	get := func(key, def string, options ...string) (string, error) {
+		val := strings.ToLower(v[key])
+		if val == "" {
+			return def, nil
+		}
+		if len(options) > 0 && !slices.Contains(options, val) {
+			return "", fmt.Errorf("unsupported %s: %s", key, val)
+		}
+		return val, nil
+	}
+	a := d.Addition
+	// defaults of rclone differ from the ones of this driver
+	if a.FileNameEnc, err = get("filename_encryption", "standard", "off", "standard", "obfuscate"); err != nil {
+		return err
+	}
+	if a.DirNameEnc, err = get("directory_name_encryption", "true", "true", "false"); err != nil {
+		return err
+	}
+	if a.FileNameEncoding, err = get("filename_encoding", "base32", "base64", "base32", "base32768"); err != nil {
+		return err
+	}
+	a.EncryptedSuffix, _ = get("suffix", ".bin")
+	if a.EncryptedSuffix != "none" && !strings.HasPrefix(a.EncryptedSuffix, ".") {
+		a.EncryptedSuffix = "." + a.EncryptedSuffix
+	}
+	a.Password = obfuscatedPrefix + v["password"]
+	a.Salt = ""
+	if v["password2"] != "" {
+		a.Salt = obfuscatedPrefix + v["password2"]
+	}
+	d.Addition = a
+	return nil
+}
+
+// rcloneConfig renders the storage as a crypt remote of rclone.conf, whose
+// remote is left for the user to fill in.
+func (d *Crypt) rcloneConfig() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]\n", strings.Trim(d.MountPath, "/"))
+	fmt.Fprintf(&b, "type = crypt\n")
+	fmt.Fprintf(&b, "remote = \n")
+	fmt.Fprintf(&b, "filename_encryption = %s\n", d.FileNameEnc)
+	fmt.Fprintf(&b, "directory_name_encryption = %s\n", d.DirNameEnc)
+	fmt.Fprintf(&b, "filename_encoding = %s\n", d.FileNameEncoding)
+	fmt.Fprintf(&b, "suffix = %s\n", d.EncryptedSuffix)
+	fmt.Fprintf(&b, "password = %s\n", strings.TrimPrefix(d.Password, obfuscatedPrefix))
+	if salt := strings.TrimPrefix(d.Salt, obfuscatedPrefix); salt != "" {
+		fmt.Fprintf(&b, "password2 = %s\n", salt)
+	}
+	return b.String()
+}
@@ -0,0 +1,174 @@
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// importRclone inits a Crypt from a section of testdata/rclone.conf.
+func importRclone(t *testing.T, section string) *Crypt {
+	t.Helper()
+	text := string(readTestdata(t, "rclone.conf"))
+	i := strings.Index(text, "["+section+"]")
+	if i < 0 {
+		t.Fatalf("section %s not found", section)
+	}
+	d := &Crypt{}
+	d.RemotePath = "/remote"
+	d.RcloneConfig = text[i:]
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestImportRcloneConfig(t *testing.T) {
+	d := importRclone(t, "secret")
+	if d.RcloneConfig != "" {
+		t.Error("rclone config should be cleared once imported")
+	}
+	if d.FileNameEnc != "standard" || d.DirNameEnc != "true" || d.FileNameEncoding != "base32" || d.EncryptedSuffix != ".bin" {
+		t.Errorf("rclone defaults not applied: %+v", d.Addition)
+	}
+	if d.Password != obfuscatedPrefix+"YWFhYWFhYWFhYWFhYWFhYQ" {
+		t.Errorf("password should be kept obscured, got %s", d.Password)
+	}
+	// names rclone produces for the zero key
+	plain, encrypted := "1/12/123", "p0e52nreeaj0a5ea7s64m4j72s/l42g6771hnv3an9cgc8cr2n1ng/qgm4avr35m5loi1th53ato71v0"
+	if got := d.cipher.EncryptDirName(plain); got != encrypted {
+		t.Errorf("expected %s, got %s", encrypted, got)
+	}
+	if got, err := d.cipher.DecryptFileName(encrypted); err != nil || got != plain {
+		t.Errorf("expected %s, got %s (%v)", plain, got, err)
+	}
+
+	d = importRclone(t, "secret64")
+	if d.FileNameEncoding != "base64" || d.EncryptedSuffix != "none" {
+		t.Errorf("rclone options not imported: %+v", d.Addition)
+	}
+	if got := d.cipher.EncryptFileName("1"); got != "yBxRX25ypgUVyj8MSxJnFw" {
+		t.Errorf("expected yBxRX25ypgUVyj8MSxJnFw, got %s", got)
+	}
+}
+
+func TestImportRcloneConfigInvalid(t *testing.T) {
+	for _, text := range []string{
+		"[gdrive]\ntype = drive\n",
+		"[secret]\ntype = crypt\nremote = gdrive:secret\n",
+		"[secret]\ntype = crypt\nfilename_encoding = base16\npassword = YWFhYWFhYWFhYWFhYWFhYQ\n",
+		"type = crypt\n",
+	} {
+		d := &Crypt{}
+		if err := d.importRcloneConfig(text); err == nil {
+			t.Errorf("expected an error importing %q", text)
+		}
+	}
+}
+
+func TestDecryptRcloneData(t *testing.T) {
+	d := importRclone(t, "secret")
+	for name, plain := range map[string][]byte{
+		"file1.bin":  {1},
+		"file16.bin": {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+	} {
+		encrypted := readTestdata(t, name)
+		if size, err := d.cipher.DecryptedSize(int64(len(encrypted))); err != nil || size != int64(len(plain)) {
+			t.Errorf("%s: expected size %d, got %d (%v)", name, len(plain), size, err)
+		}
+		r, err := d.cipher.DecryptData(io.NopCloser(bytes.NewReader(encrypted)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("%s: expected %v, got %v (%v)", name, plain, got, err)
+		}
+	}
+}
+
+func TestRcloneRoundTrip(t *testing.T) {
+	d := &Crypt{}
+	d.RemotePath = "/remote"
+	d.MountPath = "/secret"
+	d.Password = "password"
+	d.Salt = "salt"
+	d.FileNameEnc = "standard"
+	d.DirNameEnc = "true"
+	d.FileNameEncoding = "base64"
+	d.EncryptedSuffix = ".bin"
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	exported := d.rcloneConfig()
+	if !strings.HasPrefix(exported, "[secret]\ntype = crypt\n") {
+		t.Errorf("unexpected rclone config:\n%s", exported)
+	}
+
+	imported := &Crypt{}
+	imported.RemotePath = "/remote"
+	imported.RcloneConfig = exported
+	if err := imported.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	name := d.cipher.EncryptFileName("a/b.txt")
+	if got := imported.cipher.EncryptFileName("a/b.txt"); got != name {
+		t.Errorf("expected %s, got %s", name, got)
+	}
+
+	plain := bytes.Repeat([]byte("openlist"), 10000)
+	r, err := d.cipher.EncryptData(bytes.NewReader(plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(encrypted)) != d.cipher.EncryptedSize(int64(len(plain))) {
+		t.Errorf("expected encrypted size %d, got %d", d.cipher.EncryptedSize(int64(len(plain))), len(encrypted))
+	}
+	dr, err := imported.cipher.DecryptData(io.NopCloser(bytes.NewReader(encrypted)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(dr)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("round trip mismatch (%v)", err)
+	}
+}
+
+func TestOtherRequiresAdmin(t *testing.T) {
+	d := importRclone(t, "secret")
+	user := context.WithValue(context.Background(), conf.UserKey, &model.User{Role: model.GENERAL})
+	for _, method := range []string{"rclone_config", "rotate"} {
+		if _, err := d.Other(user, model.OtherArgs{Method: method}); !errors.Is(err, errs.PermissionDenied) {
+			t.Errorf("%s by a user: expected permission denied, got %v", method, err)
+		}
+	}
+	admin := context.WithValue(context.Background(), conf.UserKey, &model.User{Role: model.ADMIN})
+	if _, err := d.Other(admin, model.OtherArgs{Method: "rclone_config"}); err != nil {
+		t.Errorf("rclone_config by the admin: %v", err)
+	}
+	args := map[string]interface{}{"path": "/sub", "remote_path": "/rotated", "password": "new", "switch": true}
+	if _, err := d.Other(admin, model.OtherArgs{Method: "rotate", Data: args}); err == nil {
+		t.Error("a rotation of a sub dir should not switch")
+	}
+}
@@ -0,0 +1,255 @@
+package crypt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdpath "path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/tache"
+	log "github.com/sirupsen/logrus"
+)
+
+// RotateArgs describe the layout to re-encrypt into, empty options keep the
+// current ones.
+type RotateArgs struct {
+	// Path is the dir of the storage to re-encrypt, the whole storage if
+	// empty. It lands at the same place of the new layout under RemotePath
+	Path             string `json:"path"`
+	RemotePath       string `json:"remote_path"`
+	Password         string `json:"password"`
+	Salt             string `json:"salt"`
+	FileNameEnc      string `json:"filename_encryption"`
+	DirNameEnc       string `json:"directory_name_encryption"`
+	FileNameEncoding string `json:"filename_encoding"`
+	EncryptedSuffix  string `json:"encrypted_suffix"`
+	// Switch points the storage to the new layout once every file is
+	// re-encrypted, the old data is kept either way. Only a rotation of the
+	// whole storage can switch
+	Switch bool `json:"switch"`
+}
+
+type RotateProgress struct {
+	DirsDone int    `json:"dirs_done"`
+	Queued   int    `json:"queued"`
+	Files    int    `json:"files"`
+	Bytes    int64  `json:"bytes"`
+	Errors   int    `json:"errors"`
+	Current  string `json:"current"`
+}
+
+func (p RotateProgress) Walked() (int, int) {
+	return p.DirsDone, p.Queued
+}
+
+// RotateTask streams every file of a crypt storage through decryption and
+// encryption with the new key into another remote path.
+type RotateTask struct {
+	task.WalkTask[RotateProgress]
+	src  *Crypt
+	dst  *Crypt
+	args RotateArgs
+}
+
+func (t *RotateTask) GetName() string {
+	return fmt.Sprintf("rotate key [%s](%s, %s -> %s)", t.src.MountPath, t.args.Path, t.src.RemotePath, t.dst.RemotePath)
+}
+
+func (t *RotateTask) GetStatus() string {
+	p := t.Progress()
+	if p.Current == "" {
+		return fmt.Sprintf("%d files re-encrypted, %d errors", p.Files, p.Errors)
+	}
+	return fmt.Sprintf("re-encrypting %s, %d files done, %d errors", p.Current, p.Files, p.Errors)
+}
+
+// rotateDir is a dir to re-encrypt, with its decrypted path and the remote
+// paths on both sides
+type rotateDir struct {
+	path string
+	src  string
+	dst  string
+}
+
+func (t *RotateTask) Run() error {
+	return t.Walk(t.rotate)
+}
+
+// root returns the dir to re-encrypt.
+func (t *RotateTask) root(ctx context.Context) (rotateDir, error) {
+	root := rotateDir{path: t.args.Path, src: t.src.RemotePath, dst: t.dst.RemotePath}
+	if utils.PathEqual(t.args.Path, "/") {
+		return root, nil
+	}
+	obj, err := t.src.Get(ctx, t.args.Path)
+	if err != nil {
+		return root, err
+	}
+	if !obj.IsDir() {
+		return root, fmt.Errorf("%s is not a dir", t.args.Path)
+	}
+	root.src = obj.GetPath()
+	root.dst = stdpath.Join(t.dst.RemotePath, t.dst.encryptPath(t.args.Path, true))
+	return root, nil
+}
+
+func (t *RotateTask) rotate(ctx context.Context) error {
+	root, err := t.root(ctx)
+	if err != nil {
+		return err
+	}
+	if err = fs.MakeDir(ctx, root.dst); err != nil {
+		return fmt.Errorf("failed to make dir %s: %w", root.dst, err)
+	}
+	queue := []rotateDir{root}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		dir := queue[0]
+		queue = queue[1:]
+		objs, err := t.src.List(ctx, &model.Object{Path: dir.src, IsFolder: true}, model.ListArgs{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Warnf("crypt rotate: failed to list %s: %+v", dir.path, err)
+			t.Update(func(p *RotateProgress) {
+				p.DirsDone++
+				p.Errors++
+			})
+			continue
+		}
+		for _, obj := range objs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			path := stdpath.Join(dir.path, obj.GetName())
+			if obj.IsDir() {
+				dst := stdpath.Join(dir.dst, t.dst.cipher.EncryptDirName(obj.GetName()))
+				if err = fs.MakeDir(ctx, dst); err != nil {
+					log.Warnf("crypt rotate: failed to make dir %s: %+v", path, err)
+					t.Update(func(p *RotateProgress) { p.Errors++ })
+					continue
+				}
+				queue = append(queue, rotateDir{path: path, src: obj.GetPath(), dst: dst})
+				continue
+			}
+			t.Update(func(p *RotateProgress) {
+				p.Current = path
+				p.Queued = len(queue)
+			})
+			if err = t.rotateFile(ctx, obj, dir.dst); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Warnf("crypt rotate: failed to re-encrypt %s: %+v", path, err)
+				t.Update(func(p *RotateProgress) { p.Errors++ })
+				continue
+			}
+			t.Update(func(p *RotateProgress) {
+				p.Files++
+				p.Bytes += obj.GetSize()
+			})
+		}
+		t.Update(func(p *RotateProgress) {
+			p.DirsDone++
+			p.Queued = len(queue)
+		})
+	}
+	t.Update(func(p *RotateProgress) { p.Current = "" })
+	p := t.Progress()
+	log.Infof("crypt rotate: completed %s, %d files, %d errors", t.GetName(), p.Files, p.Errors)
+	if p.Errors > 0 {
+		return fmt.Errorf("%d objects failed to re-encrypt, the storage is left unchanged", p.Errors)
+	}
+	if t.args.Switch {
+		return t.switchStorage(ctx)
+	}
+	return nil
+}
+
+// rotateFile decrypts obj and puts it encrypted with the new key into the
+// remote dir dst.
+func (t *RotateTask) rotateFile(ctx context.Context, obj model.Obj, dst string) error {
+	link, err := t.src.Link(ctx, obj, model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		_ = link.Close()
+		return err
+	}
+	defer ss.Close()
+	return t.dst.Put(ctx, &model.Object{Path: dst, IsFolder: true}, ss, func(float64) {})
+}
+
+// switchStorage saves the new layout as the addition of the storage, which
+// reinitializes it.
+func (t *RotateTask) switchStorage(ctx context.Context) error {
+	storage, err := op.GetStorageByMountPath(t.src.MountPath)
+	if err != nil {
+		return err
+	}
+	addition, err := utils.Json.Marshal(t.dst.Addition)
+	if err != nil {
+		return err
+	}
+	s := *storage.GetStorage()
+	s.Addition = string(addition)
+	return op.UpdateStorage(ctx, s)
+}
+
+var RotateTaskManager *tache.Manager[*RotateTask]
+
+// rotate adds a task re-encrypting the storage with args.
+func (d *Crypt) rotate(ctx context.Context, args RotateArgs) (*RotateTask, error) {
+	args.Path = utils.FixAndCleanPath(args.Path)
+	if args.Switch && args.Path != "/" {
+		return nil, errors.New("only a rotation of the whole storage can switch")
+	}
+	args.RemotePath = utils.FixAndCleanPath(args.RemotePath)
+	if args.RemotePath == "/" {
+		return nil, errors.New("remote_path is required")
+	}
+	if utils.IsSubPath(args.RemotePath, d.RemotePath) || utils.IsSubPath(d.RemotePath, args.RemotePath) {
+		return nil, fmt.Errorf("remote_path %s overlaps the current one", args.RemotePath)
+	}
+	if args.Password == "" {
+		return nil, errors.New("password is required")
+	}
+	// the listing must hold everything, hidden files and thumbnails alike
+	src := *d
+	src.ShowHidden = true
+	src.Thumbnail = false
+	dst := &Crypt{Storage: d.Storage, Addition: d.Addition}
+	dst.RemotePath = args.RemotePath
+	dst.Password = args.Password
+	dst.Salt = args.Salt
+	dst.FileNameEnc = utils.GetNoneEmpty(args.FileNameEnc, d.FileNameEnc)
+	dst.DirNameEnc = utils.GetNoneEmpty(args.DirNameEnc, d.DirNameEnc)
+	dst.FileNameEncoding = utils.GetNoneEmpty(args.FileNameEncoding, d.FileNameEncoding)
+	dst.EncryptedSuffix = utils.GetNoneEmpty(args.EncryptedSuffix, d.EncryptedSuffix)
+	dst.RcloneConfig = ""
+	if err := dst.Init(ctx); err != nil {
+		return nil, err
+	}
+	t := &RotateTask{
+		WalkTask: task.NewWalkTask[RotateProgress](ctx),
+		src:      &src,
+		dst:      dst,
+		args:     args,
+	}
+	RotateTaskManager.Add(t)
+	return t, nil
+}
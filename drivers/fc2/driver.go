@@ -14,10 +14,10 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
-	"github.com/OpenListTeam/OpenList/v4/internal/emby"
+	"github.com/OpenListTeam/OpenList/v4/internal/media_job"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/notifier"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
-	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/go-resty/resty/v2"
 )
@@ -28,7 +28,7 @@ type FC2 struct {
 	AccessToken string
 	ShareToken  string
 	DriveId     string
-	cron        *cron.Cron
+	jobs        *media_job.Group
 	client      *resty.Client
 }
 
@@ -50,17 +50,17 @@ func (d *FC2) Init(ctx context.Context) error {
 		duration = time.Minute * 60
 	}
 
-	d.cron = cron.NewCron(duration)
-	d.cron.Do(func() {
-		d.rematchMediaReleaseTime()
-		if err := d.scanMediaArtifacts(); err != nil {
-			utils.Log.Warnf("failed to synchronize FC2 artifacts: %s", err)
-		}
-		if err := d.syncConfiguredNFOs(); err != nil {
-			utils.Log.Warnf("failed to synchronize FC2 NFOs: %s", err)
-		}
-		d.scanMediaSampleImages()
-	})
+	jobs := []media_job.Job{
+		{Name: "release_time", Run: media_job.Func(d.rematchMediaReleaseTime)},
+		{Name: "artifact", Run: d.scanMediaArtifacts},
+		{Name: "nfo", Run: d.syncConfiguredNFOs},
+		{Name: "sample_image", Run: media_job.Func(d.scanMediaSampleImages)},
+	}
+	if d.CloudPlayPrewarm {
+		jobs = append(jobs, media_job.Job{Name: "cloud_play_prewarm", Run: media_job.Func(d.prewarmCloudPlay)})
+	}
+	d.jobs = media_job.NewGroup(d.ID, "fc2", duration, jobs...)
+	d.jobs.Start()
 
 	return nil
 }
@@ -72,9 +72,7 @@ func newFC2HTTPClient() *resty.Client {
 }
 
 func (d *FC2) Drop(ctx context.Context) error {
-	if d.cron != nil {
-		d.cron.Stop()
-	}
+	d.jobs.Stop()
 	return nil
 }
 
@@ -249,8 +247,9 @@ func (d *FC2) Put(ctx context.Context, dstDir model.Obj, stream model.FileStream
 		return nil, err
 	}
 	op.Cache.DeleteDirectory(d, "个人收藏")
+	notifier.Notify(notifier.MediaItemPath(d.MountPath, star.Path, star.Code))
 	if d.EmbyServers != "" {
-		emby.Refresh(d.EmbyServers)
+		notifier.NotifyURLs(d.EmbyServers)
 	}
 
 	dirWrapper, err := wrapAddedStar(star)
@@ -23,6 +23,7 @@ func (d *FC2) scanMediaArtifacts() error {
 		return fmt.Errorf("list FC2 works for artifact scan: %w", err)
 	}
 	utils.Log.Infof("found %d FC2 artifact works, ids: %v", len(works), filmWorkIDs(works))
+	d.jobs.Processed(len(works))
 	for index := range works {
 		work := works[index]
 		identity := virtual_file.MediaIdentity{
@@ -46,6 +47,7 @@ func (d *FC2) scanMediaArtifacts() error {
 		if cacheErr != nil {
 			status = model.DMMPosterStatusTransientError
 			utils.Log.Warnf("failed to cache FC2 poster for %s: %s", work.Code, cacheErr)
+			d.jobs.Failed(1)
 		}
 		if err := db.UpdateMediaWorkDMMPosterStatus(work.ID, status); err != nil {
 			return fmt.Errorf("update FC2 poster status for %s: %w", work.Code, err)
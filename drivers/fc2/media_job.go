@@ -24,10 +24,12 @@ func (d *FC2) rematchMediaReleaseTime() {
 		return
 	}
 	utils.Log.Infof("found %d FC2 release works, ids: %v", len(works), filmWorkIDs(works))
+	d.jobs.Processed(len(works))
 	for index := range works {
 		work := works[index]
 		info, fetchErr := d.getFc2DailyFilm(work.Code)
 		if fetchErr != nil {
+			d.jobs.Failed(1)
 			next := time.Now().Add(time.Duration(d.ReleaseScanTime) * time.Minute)
 			if next.Before(time.Now().Add(time.Minute)) {
 				next = time.Now().Add(time.Hour)
@@ -74,6 +76,7 @@ func (d *FC2) scanMediaSampleImages() {
 		return
 	}
 	utils.Log.Infof("found %d FC2 sample-image works, ids: %v", len(works), filmWorkIDs(works))
+	d.jobs.Processed(len(works))
 	remaining := maxSampleImageRequestsPerRun
 	for index := range works {
 		work := works[index]
@@ -90,6 +93,7 @@ func (d *FC2) scanMediaSampleImages() {
 			}
 		}
 		if magnetErr != nil {
+			d.jobs.Failed(1)
 			if err := db.UpdateMediaWorkSampleScan(work.ID, false); err != nil {
 				utils.Log.Warnf("failed to update FC2 sample retry for %s: %s", work.Code, err)
 			}
@@ -101,6 +105,7 @@ func (d *FC2) scanMediaSampleImages() {
 		remaining--
 		linkInfo, linkErr := d.getWhatLinkInfo(magnet.MagnetURI)
 		if linkErr != nil {
+			d.jobs.Failed(1)
 			if err := db.UpdateMediaWorkSampleScan(work.ID, false); err != nil {
 				utils.Log.Warnf("failed to update FC2 sample retry for %s: %s", work.Code, err)
 			}
@@ -148,7 +153,9 @@ func (d *FC2) scanMediaSampleImages() {
 			}
 			completedCount = sampleIndex
 		}
-		if !failed && completedCount >= len(screenshots) {
+		if failed {
+			d.jobs.Failed(1)
+		} else if completedCount >= len(screenshots) {
 			if err := db.UpdateMediaWorkSampleScan(work.ID, true); err != nil {
 				utils.Log.Warnf("failed to complete FC2 samples for %s: %s", work.Code, err)
 			}
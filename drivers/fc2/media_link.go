@@ -20,7 +20,7 @@ import (
 var getFC2SukeMeta = av.GetMetaFromSuke
 
 func (d *FC2) cloudPlayMedia(ctx context.Context, args model.LinkArgs, file model.FilmFileWithWork) (*model.Link, error) {
-	fileName, err := model.BuildMediaFileName(file.Work.Code, file.PartIndex, file.PartCount)
+	playbackFile, err := tool.CloudPlayFile(file)
 	if err != nil {
 		return nil, err
 	}
@@ -28,15 +28,19 @@ func (d *FC2) cloudPlayMedia(ctx context.Context, args model.LinkArgs, file mode
 	if err != nil {
 		return nil, err
 	}
-	return tool.CloudPlay(ctx, args, d.CloudPlayDriverType, d.CloudPlayDownloadPath, &model.EmbyFileObj{
-		ObjThumb: model.ObjThumb{Object: model.Object{
-			Name:     fileName,
-			Path:     file.Work.PrimaryDir,
-			IsFolder: false,
-		}},
-		WorkID:     file.WorkID,
-		FilmFileID: file.ID,
-	}, magnets)
+	return tool.CloudPlay(ctx, args, d.CloudPlayDriverType, d.CloudPlayDownloadPath, playbackFile, magnets)
+}
+
+// prewarmCloudPlay 为近期新增的作品提前离线下载磁力，播放时直接命中云盘缓存。
+func (d *FC2) prewarmCloudPlay() {
+	tool.CloudPlayPrewarm{
+		StorageID:  d.ID,
+		Source:     "fc2",
+		DriverType: d.CloudPlayDriverType,
+		DriverPath: d.CloudPlayDownloadPath,
+		Magnets:    d.playbackMagnets,
+		Jobs:       d.jobs,
+	}.Run()
 }
 
 func (d *FC2) mediaMagnets(_ context.Context, work model.FilmWork) ([]model.SourceMagnet, error) {
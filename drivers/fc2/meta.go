@@ -16,6 +16,7 @@ type Addition struct {
 	MockedByMatchUa       string `json:"mocked_by_match_ua"`
 	CloudPlayDriverType   string `json:"cloud_play_driver_type" required:"true" default:"PikPak" type:"select" options:"PikPak,115 Cloud"`
 	CloudPlayDownloadPath string `json:"cloud_play_download_path" required:"false" help:"If empty then use global setting."`
+	CloudPlayPrewarm      bool   `json:"cloud_play_prewarm" required:"false" help:"Download the magnets of recently added works in the background before they are played."`
 	ReleaseScanTime       uint64 `json:"ReleaseScanTime" required:"true" type:"number" `
 	ScanTimeLimit         uint64 `json:"ScanTimeLimit" required:"true" type:"number" `
 	BatchScanSize         int    `json:"batch_scan_size" required:"true" type:"number" default:"20"`
@@ -23,7 +24,7 @@ type Addition struct {
 	SyncNfo               bool   `json:"sync_nfo" required:"false" help:"Write only normalized NFO records whose metadata changed."`
 	ScraperApi            string `json:"scraper_api" required:"false"`
 	MissAvMaxPage         int    `json:"miss_av_max_page" required:"true" type:"number" `
-	EmbyServers           string `json:"emby_servers" required:"false" type:"text"`
+	EmbyServers           string `json:"emby_servers" required:"false" type:"text" help:"Deprecated, configure media notifiers instead. One refresh URL per line, posted as a webhook."`
 }
 
 var config = driver.Config{
@@ -248,8 +248,10 @@ func (d *FC2) getPageFilms(url string) ([]string, error) {
 
 	var ids []string
 
-	err := spider.Visit(d.SpiderServer, url, time.Duration(d.SpiderMaxWaitTime)*time.Second, func(wd selenium.WebDriver) {
-		elements, _ := wd.FindElements(selenium.ByCSSSelector, ".absolute.top-0.left-0.text-white.bg-gray-800.px-1")
+	const idSelector = ".absolute.top-0.left-0.text-white.bg-gray-800.px-1"
+	page := spider.Page{URL: url, Ready: spider.Selector(idSelector), Timeout: time.Duration(d.SpiderMaxWaitTime) * time.Second}
+	err := spider.Fetch(d.SpiderServer, page, func(wd selenium.WebDriver) error {
+		elements, _ := wd.FindElements(selenium.ByCSSSelector, idSelector)
 		for _, element := range elements {
 			text, err1 := element.Text()
 			if err1 != nil {
@@ -258,6 +260,7 @@ func (d *FC2) getPageFilms(url string) ([]string, error) {
 				ids = append(ids, fmt.Sprintf("FC2-PPV-%s", text))
 			}
 		}
+		return nil
 	})
 
 	return ids, err
@@ -13,9 +13,9 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
-	"github.com/OpenListTeam/OpenList/v4/internal/emby"
+	"github.com/OpenListTeam/OpenList/v4/internal/media_job"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
-	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/internal/notifier"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/emirpasic/gods/v2/maps/linkedhashmap"
 	"github.com/go-resty/resty/v2"
@@ -27,8 +27,8 @@ type Javdb struct {
 	AccessToken      string
 	ShareToken       string
 	DriveId          string
-	cron             *cron.Cron
-	matchTopFilmCorn *cron.Cron
+	jobs             *media_job.Group
+	topFilmJobs      *media_job.Group
 	client           *resty.Client
 	removeBackground func(string, string, string) error
 }
@@ -51,31 +51,31 @@ func (d *Javdb) Init(ctx context.Context) error {
 		duration = time.Minute * 60
 	}
 
-	d.cron = cron.NewCron(duration)
-	d.cron.Do(func() {
-		if err := d.filterFilms(); err != nil {
-			utils.Log.Warnf("failed to filter normalized JavDB works: %s", err)
-		}
-		d.scanTranslations()
-		d.scanMediaSynopsis()
-		d.scanMediaMetadataAndMagnets()
-		d.scanMediaSubtitles()
-		if err := d.syncConfiguredNFOs(); err != nil {
-			utils.Log.Warnf("failed to synchronize JavDB NFOs: %s", err)
-		}
-		d.scanMediaSampleImages()
-		d.scanMediaDMMPosters()
-	})
+	jobs := []media_job.Job{
+		{Name: "filter", Run: d.filterFilms},
+		{Name: "translation", Run: media_job.Func(d.scanTranslations)},
+		{Name: "synopsis", Run: media_job.Func(d.scanMediaSynopsis)},
+		{Name: "metadata", Run: media_job.Func(d.scanMediaMetadataAndMagnets)},
+		{Name: "subtitle", Run: media_job.Func(d.scanMediaSubtitles)},
+		{Name: "nfo", Run: d.syncConfiguredNFOs},
+		{Name: "sample_image", Run: media_job.Func(d.scanMediaSampleImages)},
+		{Name: "dmm_poster", Run: media_job.Func(d.scanMediaDMMPosters)},
+	}
+	if d.CloudPlayPrewarm {
+		jobs = append(jobs, media_job.Job{Name: "cloud_play_prewarm", Run: media_job.Func(d.prewarmCloudPlay)})
+	}
+	d.jobs = media_job.NewGroup(d.ID, DriverName, duration, jobs...)
+	d.jobs.Start()
 
 	matchTopFilmsTimer := time.Hour * time.Duration(d.MatchTopFilmsTimer)
 	if matchTopFilmsTimer <= 0 {
 		matchTopFilmsTimer = time.Hour * 24
 	}
 
-	d.matchTopFilmCorn = cron.NewCron(matchTopFilmsTimer)
-	d.matchTopFilmCorn.Do(func() {
-		d.fetchJavTopFilms()
-	})
+	d.topFilmJobs = media_job.NewGroup(d.ID, DriverName, matchTopFilmsTimer,
+		media_job.Job{Name: "top_films", Run: media_job.Func(d.fetchJavTopFilms)},
+	)
+	d.topFilmJobs.Start()
 
 	return nil
 }
@@ -88,12 +88,8 @@ func newSampleImageClient() *resty.Client {
 }
 
 func (d *Javdb) Drop(ctx context.Context) error {
-	if d.cron != nil {
-		d.cron.Stop()
-	}
-	if d.matchTopFilmCorn != nil {
-		d.matchTopFilmCorn.Stop()
-	}
+	d.jobs.Stop()
+	d.topFilmJobs.Stop()
 	return nil
 }
 
@@ -244,8 +240,9 @@ func (d *Javdb) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	if err != nil {
 		return nil, err
 	}
+	notifier.Notify(notifier.MediaItemPath(d.MountPath, star.Path, star.Code))
 	if d.EmbyServers != "" {
-		emby.Refresh(d.EmbyServers)
+		notifier.NotifyURLs(d.EmbyServers)
 	}
 
 	dirWrapper, err := wrapAddedStar(star)
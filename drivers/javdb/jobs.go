@@ -9,6 +9,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/drivers/virtual_file"
 	"github.com/OpenListTeam/OpenList/v4/internal/av"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/spider"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 )
 
@@ -28,6 +29,7 @@ func (d *Javdb) filterFilms() error {
 		return fmt.Errorf("query normalized JavDB filter works: %w", err)
 	}
 	utils.Log.Infof("found %d JavDB works matching filter prefixes %v, ids to delete: %v", len(works), prefixes, mediaWorkIDs(works))
+	d.jobs.Processed(len(works))
 	deleteErrors := make([]error, 0)
 	for _, work := range works {
 		if err := virtual_file.DeleteMediaWork(work.ID); err != nil {
@@ -52,6 +54,7 @@ func (d *Javdb) fetchJavTopFilms() {
 
 	addFilmFunc := func(codes, tags []string) error {
 		unMissedFilms := db.QueryUnMissedFilms(codes)
+		d.topFilmJobs.Processed(len(unMissedFilms))
 		for _, code := range unMissedFilms {
 			if strings.HasPrefix(code, "FC2-") {
 				continue
@@ -62,6 +65,7 @@ func (d *Javdb) fetchJavTopFilms() {
 					missedFilms = append(missedFilms, code)
 				} else {
 					utils.Log.Warnf("failed to add film for code: %s, error: %s", code, err.Error())
+					d.topFilmJobs.Failed(1)
 					return err
 				}
 			}
@@ -71,12 +75,24 @@ func (d *Javdb) fetchJavTopFilms() {
 
 	year := time.Now().Year()
 	for currentYear := d.MatchTopFilmsStarter; currentYear <= year; currentYear++ {
-		codes := av.QueryJavSql(d.SpiderServer, fmt.Sprintf("SELECT SUBSTR(name, 0, 40) FROM ranks WHERE note = 'JavDB %d TOP250'", currentYear), d.SpiderMaxWaitTime)
+		codes, err := av.QueryJavSql(d.SpiderServer, fmt.Sprintf("SELECT SUBSTR(name, 0, 40) FROM ranks WHERE note = 'JavDB %d TOP250'", currentYear), d.SpiderMaxWaitTime)
+		if err != nil {
+			if spider.Retryable(err) {
+				d.topFilmJobs.Failed(1)
+			}
+			continue
+		}
 		if err := addFilmFunc(codes, []string{fmt.Sprintf("JavDB-TOP250-%d", currentYear)}); err != nil {
 			return
 		}
 	}
 
-	codes := av.QueryJavSql(d.SpiderServer, "SELECT SUBSTR(name, 0, 40) FROM ranks WHERE note = 'JavDB TOP250'", d.SpiderMaxWaitTime)
+	codes, err := av.QueryJavSql(d.SpiderServer, "SELECT SUBSTR(name, 0, 40) FROM ranks WHERE note = 'JavDB TOP250'", d.SpiderMaxWaitTime)
+	if err != nil {
+		if spider.Retryable(err) {
+			d.topFilmJobs.Failed(1)
+		}
+		return
+	}
 	_ = addFilmFunc(codes, []string{"JavDB-TOP250"})
 }
@@ -34,6 +34,7 @@ func (d *Javdb) scanTranslations() {
 		return
 	}
 	utils.Log.Infof("found %d JavDB translation works, ids: %v", len(works), mediaWorkIDs(works))
+	d.jobs.Processed(len(works))
 	items := make([]open_ai.TranslateItem, len(works))
 	for index, work := range works {
 		items[index] = open_ai.TranslateItem{Origin: work.RawTitle}
@@ -57,6 +58,7 @@ func (d *Javdb) scanTranslations() {
 			translated = translations[index]
 		}
 		if translated == "" {
+			d.jobs.Failed(1)
 			next := time.Now().Add(6 * time.Hour)
 			if err := db.UpdateMediaWorkTranslationRetry(work.ID, next, "translation returned an empty result", currentTranslationVersion); err != nil {
 				utils.Log.Warnf("failed to update translation retry for %s: %s", work.Code, err)
@@ -89,6 +91,7 @@ func (d *Javdb) scanMediaSynopsis() {
 		return
 	}
 	utils.Log.Infof("found %d JavDB empty-synopsis works, ids: %v", len(works), mediaWorkIDs(works))
+	d.jobs.Processed(len(works))
 
 	var collected []mediaSynopsisCandidate
 	for index := range works {
@@ -105,6 +108,7 @@ func (d *Javdb) scanMediaSynopsis() {
 		}
 		synopsis, dmmErr := d.fetchDmmSynopsis(work.Code)
 		if dmmErr != nil {
+			d.jobs.Failed(1)
 			next := time.Now().Add(72 * time.Hour)
 			if err := db.UpdateMediaWorkSynopsisRetry(work.ID, next, dmmErr.Error()); err != nil {
 				utils.Log.Warnf("failed to update synopsis retry for %s: %s", work.Code, err)
@@ -171,6 +175,7 @@ func (d *Javdb) scanMediaMetadataAndMagnets() {
 		return
 	}
 	utils.Log.Infof("found %d JavDB pending metadata works, ids: %v", len(works), mediaWorkIDs(works))
+	d.jobs.Processed(len(works))
 	for index := range works {
 		work := works[index]
 		meta, fetchErr := getJavdbMeta(work.SourceURL)
@@ -178,6 +183,7 @@ func (d *Javdb) scanMediaMetadataAndMagnets() {
 			if fetchErr == nil {
 				fetchErr = errors.New("JavDB returned no magnets")
 			}
+			d.jobs.Failed(1)
 			next := time.Now().Add(6 * time.Hour)
 			if err := db.UpdateMediaWorkMagnetScan(work.ID, &next, fetchErr.Error()); err != nil {
 				utils.Log.Warnf("failed to update magnet retry for %s: %s", work.Code, err)
@@ -192,6 +198,7 @@ func (d *Javdb) scanMediaMetadataAndMagnets() {
 		}
 		magnets := sourceMagnetsFromMeta(meta)
 		if err := db.UpsertSourceMagnets(work.ID, magnets); err != nil {
+			d.jobs.Failed(1)
 			next := time.Now().Add(6 * time.Hour)
 			if updateErr := db.UpdateMediaWorkMagnetScan(work.ID, &next, err.Error()); updateErr != nil {
 				utils.Log.Warnf("failed to persist magnet error for %s: %s", work.Code, updateErr)
@@ -266,10 +273,12 @@ func (d *Javdb) scanMediaSubtitles() {
 		return
 	}
 	utils.Log.Infof("found %d JavDB subtitle works, ids: %v", len(works), mediaWorkIDs(works))
+	d.jobs.Processed(len(works))
 	for index := range works {
 		work := works[index]
 		subtitles, matchErr := matchMediaSubtitles(work.Code)
 		if matchErr != nil {
+			d.jobs.Failed(1)
 			next := time.Now().Add(24 * time.Hour)
 			if err := db.UpdateMediaWorkSubtitleScan(work.ID, &next, matchErr.Error()); err != nil {
 				utils.Log.Warnf("failed to update subtitle retry for %s: %s", work.Code, err)
@@ -290,6 +299,7 @@ func (d *Javdb) scanMediaSubtitles() {
 		identity := mediaIdentity(work)
 		files, listErr := db.ListFilmFiles(work.ID)
 		if listErr != nil {
+			d.jobs.Failed(1)
 			next := time.Now().Add(24 * time.Hour)
 			if err := db.UpdateMediaWorkSubtitleScan(work.ID, &next, listErr.Error()); err != nil {
 				utils.Log.Warnf("failed to persist subtitle list error for %s: %s", work.Code, err)
@@ -308,6 +318,7 @@ func (d *Javdb) scanMediaSubtitles() {
 			}
 		}
 		if failed {
+			d.jobs.Failed(1)
 			continue
 		}
 		if err := db.UpdateMediaWorkSubtitleScan(work.ID, nil, ""); err != nil {
@@ -326,6 +337,7 @@ func (d *Javdb) scanMediaDMMPosters() {
 		return
 	}
 	utils.Log.Infof("found %d JavDB DMM poster works, ids: %v", len(works), mediaWorkIDs(works))
+	d.jobs.Processed(len(works))
 	for index := range works {
 		d.scanMediaDMMPoster(context.Background(), works[index])
 	}
@@ -419,6 +431,7 @@ func (d *Javdb) scanMediaDMMPoster(ctx context.Context, work model.FilmWork) {
 func (d *Javdb) updateMediaDMMStatus(work model.FilmWork, status string, cause error) {
 	if cause != nil {
 		utils.Log.Warnf("DMM poster scan for %s failed: %s", work.Code, cause)
+		d.jobs.Failed(1)
 	}
 	if err := db.UpdateMediaWorkDMMPosterStatus(work.ID, status); err != nil {
 		utils.Log.Warnf("failed to update DMM status for %s: %s", work.Code, err)
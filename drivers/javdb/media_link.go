@@ -17,7 +17,7 @@ var (
 )
 
 func (d *Javdb) cloudPlayMedia(ctx context.Context, args model.LinkArgs, provider string, file model.FilmFileWithWork) (*model.Link, error) {
-	fileName, err := model.BuildMediaFileName(file.Work.Code, file.PartIndex, file.PartCount)
+	playbackFile, err := tool.CloudPlayFile(file)
 	if err != nil {
 		return nil, err
 	}
@@ -25,15 +25,19 @@ func (d *Javdb) cloudPlayMedia(ctx context.Context, args model.LinkArgs, provide
 	if err != nil {
 		return nil, err
 	}
-	return tool.CloudPlay(ctx, args, provider, d.CloudPlayDownloadPath, &model.EmbyFileObj{
-		ObjThumb: model.ObjThumb{Object: model.Object{
-			Name:     fileName,
-			Path:     file.Work.PrimaryDir,
-			IsFolder: false,
-		}},
-		WorkID:     file.WorkID,
-		FilmFileID: file.ID,
-	}, magnets)
+	return tool.CloudPlay(ctx, args, provider, d.CloudPlayDownloadPath, playbackFile, magnets)
+}
+
+// prewarmCloudPlay 为近期新增的作品提前离线下载磁力，播放时直接命中云盘缓存。
+func (d *Javdb) prewarmCloudPlay() {
+	tool.CloudPlayPrewarm{
+		StorageID:  d.ID,
+		Source:     DriverName,
+		DriverType: d.CloudPlayDriverType,
+		DriverPath: d.CloudPlayDownloadPath,
+		Magnets:    d.playbackMagnets,
+		Jobs:       d.jobs,
+	}.Run()
 }
 
 func (d *Javdb) mediaMagnets(_ context.Context, work model.FilmWork) ([]model.SourceMagnet, error) {
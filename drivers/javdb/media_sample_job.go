@@ -23,6 +23,7 @@ func (d *Javdb) scanMediaSampleImages() {
 		return
 	}
 	utils.Log.Infof("found %d JavDB sample-image works, ids: %v", len(works), mediaWorkIDs(works))
+	d.jobs.Processed(len(works))
 	remaining := maxSampleImageRequestsPerRun
 	for index := range works {
 		work := works[index]
@@ -32,7 +33,7 @@ func (d *Javdb) scanMediaSampleImages() {
 			}
 			remoteURL, pathErr := sampleImageURL(work.ImageURL, sampleIndex)
 			if pathErr != nil {
-				markMediaSampleRetry(work, pathErr)
+				d.markMediaSampleRetry(work, pathErr)
 				break
 			}
 			remaining--
@@ -44,13 +45,13 @@ func (d *Javdb) scanMediaSampleImages() {
 				if errors.As(cacheErr, &statusErr) && statusErr.StatusCode == http.StatusForbidden {
 					if sampleIndex > 1 {
 						if err := completeMediaSamples(work, sampleIndex-1); err != nil {
-							markMediaSampleRetry(work, err)
+							d.markMediaSampleRetry(work, err)
 						}
 					} else {
-						markMediaSampleRetry(work, cacheErr)
+						d.markMediaSampleRetry(work, cacheErr)
 					}
 				} else {
-					markMediaSampleRetry(work, cacheErr)
+					d.markMediaSampleRetry(work, cacheErr)
 				}
 				break
 			}
@@ -60,7 +61,7 @@ func (d *Javdb) scanMediaSampleImages() {
 			}
 			if sampleIndex == maxSampleImageCount {
 				if err := completeMediaSamples(work, sampleIndex); err != nil {
-					markMediaSampleRetry(work, err)
+					d.markMediaSampleRetry(work, err)
 				}
 				break
 			}
@@ -87,8 +88,9 @@ func completeMediaSamples(work model.FilmWork, count int) error {
 	return db.UpdateMediaWorkSampleProgress(work.ID, count, true)
 }
 
-func markMediaSampleRetry(work model.FilmWork, cause error) {
+func (d *Javdb) markMediaSampleRetry(work model.FilmWork, cause error) {
 	utils.Log.Warnf("failed to cache sample image for work %s: %s", work.Code, cause)
+	d.jobs.Failed(1)
 	if err := db.UpdateMediaWorkSampleScan(work.ID, false); err != nil {
 		utils.Log.Warnf("failed to update sample retry for %s: %s", work.Code, err)
 	}
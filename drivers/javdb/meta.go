@@ -21,12 +21,13 @@ type Addition struct {
 	CloudPlayDriverType   string `json:"cloud_play_driver_type" required:"true" default:"PikPak" type:"select" options:"PikPak,115 Cloud"`
 	FallbackPlay          bool   `json:"fallback_play" required:"false"`
 	CloudPlayDownloadPath string `json:"cloud_play_download_path" required:"false" help:"If empty then use global setting."`
+	CloudPlayPrewarm      bool   `json:"cloud_play_prewarm" required:"false" help:"Download the magnets of recently added works in the background before they are played."`
 	Filter                string `json:"filter" required:"false" help:"Multi values must separated by commas."`
 	SubtitleScanTime      uint64 `json:"subtitle_scan_time" required:"true" type:"number" `
 	SubtitlesScanLimit    int    `json:"subtitles_scan_limit" required:"true" type:"number" `
 	RefreshNfo            bool   `json:"refresh_nfo" required:"false" help:"Force rewriting every normalized NFO during scheduled maintenance."`
 	SyncNfo               bool   `json:"sync_nfo" required:"false" help:"Write only normalized NFO records whose metadata changed."`
-	EmbyServers           string `json:"emby_servers" required:"false" type:"text"`
+	EmbyServers           string `json:"emby_servers" required:"false" type:"text" help:"Deprecated, configure media notifiers instead. One refresh URL per line, posted as a webhook."`
 	MatchFilmTagLimit     int    `json:"match_film_tag_limit" required:"false" type:"number" `
 	MatchTopFilmsStarter  int    `json:"match_top_film_starter" required:"true" type:"number" `
 	MatchTopFilmsTimer    int    `json:"match_top_film_timer" required:"true" type:"number" `
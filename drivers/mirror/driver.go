@@ -0,0 +1,358 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/sign"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	log "github.com/sirupsen/logrus"
+)
+
+var errLinkTimeout = errors.New("link timeout")
+
+// Mirror serves one tree kept on several replicas. Reads go to the
+// healthiest replica and fail over to the next, writes land on one replica
+// and are copied to the others by copy tasks.
+type Mirror struct {
+	model.Storage
+	Addition
+	replicas []string
+	health   *health
+	cancel   context.CancelFunc
+}
+
+func (d *Mirror) Config() driver.Config {
+	return config
+}
+
+func (d *Mirror) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Mirror) Init(ctx context.Context) error {
+	d.replicas = nil
+	for _, r := range strings.Split(d.Replicas, "\n") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		r = utils.FixAndCleanPath(r)
+		if utils.IsSubPath(r, d.MountPath) || utils.IsSubPath(d.MountPath, r) {
+			return fmt.Errorf("replica %s overlaps the mirror itself", r)
+		}
+		d.replicas = append(d.replicas, r)
+	}
+	if len(d.replicas) < 2 {
+		return errors.New("at least two replicas are required")
+	}
+	d.health = newHealth(d.replicas)
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+	if d.VerifyInterval > 0 {
+		verifyCtx, cancel := context.WithCancel(context.Background())
+		d.cancel = cancel
+		go d.verifyLoop(verifyCtx)
+	}
+	return nil
+}
+
+func (d *Mirror) Drop(ctx context.Context) error {
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+	return nil
+}
+
+func (d *Mirror) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	var failed error
+	for _, i := range d.health.order(time.Now()) {
+		obj, err := fs.Get(ctx, stdpath.Join(d.replicas[i], path), &fs.GetArgs{NoLog: true})
+		if err != nil {
+			if !errs.IsObjectNotFound(err) {
+				d.health.fail(i, err, time.Now())
+				failed = errors.Join(failed, fmt.Errorf("%s: %w", d.replicas[i], err))
+			}
+			continue
+		}
+		return &model.Object{
+			Path:     path,
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		}, nil
+	}
+	return nil, notFoundUnless(failed)
+}
+
+func (d *Mirror) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var failed error
+	for _, i := range d.health.order(time.Now()) {
+		objs, err := fs.List(ctx, stdpath.Join(d.replicas[i], dir.GetPath()), &fs.ListArgs{
+			NoLog:   true,
+			Refresh: args.Refresh,
+		})
+		if err != nil {
+			if !errs.IsObjectNotFound(err) {
+				d.health.fail(i, err, time.Now())
+				failed = errors.Join(failed, fmt.Errorf("%s: %w", d.replicas[i], err))
+			}
+			continue
+		}
+		return utils.SliceConvert(objs, func(obj model.Obj) (model.Obj, error) {
+			return toMirrorObj(obj), nil
+		})
+	}
+	return nil, notFoundUnless(failed)
+}
+
+// notFoundUnless returns the joined errors of the replicas that failed, or
+// ObjectNotFound when every replica reported the object missing.
+func notFoundUnless(failed error) error {
+	if failed != nil {
+		return failed
+	}
+	return errs.ObjectNotFound
+}
+
+type linkResult struct {
+	link *model.Link
+	obj  model.Obj
+	err  error
+}
+
+func (d *Mirror) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	// proxy || ftp,s3
+	if common.GetApiUrl(ctx) == "" {
+		args.Redirect = false
+	}
+	var failed error
+	for _, i := range d.health.order(time.Now()) {
+		reqPath := stdpath.Join(d.replicas[i], file.GetPath())
+		start := time.Now()
+		r, err := d.linkWithTimeout(ctx, reqPath, args)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !errs.IsObjectNotFound(err) {
+				d.health.fail(i, err, time.Now())
+				failed = errors.Join(failed, fmt.Errorf("%s: %w", d.replicas[i], err))
+				log.Warnf("mirror: failed to link %s, trying next replica: %+v", reqPath, err)
+			}
+			continue
+		}
+		d.health.succeed(i, time.Since(start))
+		if r.link == nil {
+			return &model.Link{
+				URL: fmt.Sprintf("%s/p%s?sign=%s",
+					common.GetApiUrl(ctx),
+					utils.EncodePath(reqPath, true),
+					sign.Sign(reqPath)),
+			}, nil
+		}
+		resultLink := *r.link
+		resultLink.SyncClosers = utils.NewSyncClosers(r.link)
+		if !args.Redirect && resultLink.ContentLength == 0 {
+			resultLink.ContentLength = r.obj.GetSize()
+		}
+		return &resultLink, nil
+	}
+	return nil, notFoundUnless(failed)
+}
+
+// linkWithTimeout links reqPath, giving up after LinkTimeout. A link that
+// arrives too late is closed.
+func (d *Mirror) linkWithTimeout(ctx context.Context, reqPath string, args model.LinkArgs) (linkResult, error) {
+	ch := make(chan linkResult, 1)
+	go func() {
+		link, obj, err := d.link(ctx, reqPath, args)
+		ch <- linkResult{link: link, obj: obj, err: err}
+	}()
+	var timeout <-chan time.Time
+	if d.LinkTimeout > 0 {
+		timer := time.NewTimer(time.Duration(d.LinkTimeout) * time.Second)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case r := <-ch:
+		return r, r.err
+	case <-timeout:
+		go func() {
+			if r := <-ch; r.link != nil {
+				_ = r.link.Close()
+			}
+		}()
+		return linkResult{}, errLinkTimeout
+	case <-ctx.Done():
+		return linkResult{}, ctx.Err()
+	}
+}
+
+func (d *Mirror) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	var err error
+	for _, r := range d.replicas {
+		err = errors.Join(err, fs.MakeDir(ctx, stdpath.Join(r, parentDir.GetPath(), dirName)))
+	}
+	return err
+}
+
+func (d *Mirror) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	var err error
+	for _, r := range d.replicas {
+		_, e := fs.Move(ctx, stdpath.Join(r, srcObj.GetPath()), stdpath.Join(r, dstDir.GetPath()))
+		if !errs.IsObjectNotFound(e) {
+			err = errors.Join(err, e)
+		}
+	}
+	return err
+}
+
+func (d *Mirror) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	var err error
+	for _, r := range d.replicas {
+		e := fs.Rename(ctx, stdpath.Join(r, srcObj.GetPath()), newName)
+		if !errs.IsObjectNotFound(e) {
+			err = errors.Join(err, e)
+		}
+	}
+	return err
+}
+
+func (d *Mirror) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	var err error
+	for _, r := range d.replicas {
+		_, e := fs.Copy(ctx, stdpath.Join(r, srcObj.GetPath()), stdpath.Join(r, dstDir.GetPath()))
+		if !errs.IsObjectNotFound(e) {
+			err = errors.Join(err, e)
+		}
+	}
+	return err
+}
+
+func (d *Mirror) Remove(ctx context.Context, obj model.Obj) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	var err error
+	for _, r := range d.replicas {
+		e := fs.Remove(ctx, stdpath.Join(r, obj.GetPath()))
+		if !errs.IsObjectNotFound(e) {
+			err = errors.Join(err, e)
+		}
+	}
+	return err
+}
+
+func (d *Mirror) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	order := d.health.order(time.Now())
+	primary := d.replicas[order[0]]
+	storage, reqActualPath, err := op.GetStorageAndActualPath(stdpath.Join(primary, dstDir.GetPath()))
+	if err != nil {
+		return err
+	}
+	err = op.Put(ctx, storage, reqActualPath, &stream.FileStream{
+		Obj:      s,
+		Mimetype: s.GetMimetype(),
+		Reader:   s,
+	}, up)
+	if err != nil {
+		d.health.fail(order[0], err, time.Now())
+		return err
+	}
+	// the other replicas catch up through copy tasks, the ones failing to
+	// start are left to the verify task
+	src := stdpath.Join(primary, dstDir.GetPath(), s.GetName())
+	for _, i := range order[1:] {
+		dst := stdpath.Join(d.replicas[i], dstDir.GetPath())
+		if err = fs.MakeDir(ctx, dst); err == nil {
+			_, err = fs.Copy(ctx, src, dst)
+		}
+		if err != nil {
+			log.Warnf("mirror: failed to copy %s to %s: %+v", src, dst, err)
+		}
+	}
+	return nil
+}
+
+// GetDetails reports the smallest replica, the mirror cannot hold more.
+func (d *Mirror) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var ret *model.StorageDetails
+	for _, r := range d.replicas {
+		storage, _, err := op.GetStorageAndActualPath(r)
+		if err != nil {
+			continue
+		}
+		details, err := op.GetStorageDetails(ctx, storage)
+		if err != nil {
+			continue
+		}
+		if ret == nil {
+			ret = &model.StorageDetails{DiskUsage: details.DiskUsage}
+			continue
+		}
+		ret.TotalSpace = min(ret.TotalSpace, details.TotalSpace)
+		ret.FreeSpace = min(ret.FreeSpace, details.FreeSpace)
+	}
+	if ret == nil {
+		return nil, errs.NotImplement
+	}
+	return ret, nil
+}
+
+// Other answers "health" with the state of each replica and starts a
+// verify task for admins on "verify".
+func (d *Mirror) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	switch args.Method {
+	case "health":
+		return d.health.snapshot(), nil
+	case "verify":
+		return task.AddByAdmin(ctx, func() (*fs.VerifyTask, error) {
+			return d.verify(ctx), nil
+		})
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
+var _ driver.Driver = (*Mirror)(nil)
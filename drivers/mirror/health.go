@@ -0,0 +1,75 @@
+package mirror
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// healthCooldown is how long failures push a replica back, afterwards it
+// is tried in its configured order again.
+const healthCooldown = 5 * time.Minute
+
+type ReplicaHealth struct {
+	Replica   string    `json:"replica"`
+	Failures  int       `json:"failures"` // consecutive
+	LastError string    `json:"last_error"`
+	LastFail  time.Time `json:"last_fail"`
+	// Latency of the last link the replica answered, in milliseconds
+	Latency int64 `json:"latency"`
+}
+
+type health struct {
+	mu       sync.Mutex
+	replicas []ReplicaHealth
+}
+
+func newHealth(replicas []string) *health {
+	h := &health{replicas: make([]ReplicaHealth, len(replicas))}
+	for i, r := range replicas {
+		h.replicas[i].Replica = r
+	}
+	return h
+}
+
+// order returns the replica indexes, recently failing ones last.
+func (h *health) order(now time.Time) []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	penalty := func(i int) int {
+		r := h.replicas[i]
+		if now.Sub(r.LastFail) > healthCooldown {
+			return 0
+		}
+		return r.Failures
+	}
+	idx := make([]int, len(h.replicas))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return penalty(idx[a]) < penalty(idx[b])
+	})
+	return idx
+}
+
+func (h *health) succeed(i int, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replicas[i].Failures = 0
+	h.replicas[i].Latency = latency.Milliseconds()
+}
+
+func (h *health) fail(i int, err error, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replicas[i].Failures++
+	h.replicas[i].LastError = err.Error()
+	h.replicas[i].LastFail = now
+}
+
+func (h *health) snapshot() []ReplicaHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]ReplicaHealth(nil), h.replicas...)
+}
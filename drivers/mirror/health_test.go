@@ -0,0 +1,44 @@
+package mirror
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+)
+
+func TestHealthOrder(t *testing.T) {
+	h := newHealth([]string{"/115/lib", "/pikpak/lib", "/aliyun/lib"})
+	now := time.Now()
+	if got := h.order(now); !slices.Equal(got, []int{0, 1, 2}) {
+		t.Fatalf("healthy order = %v, want configured order", got)
+	}
+	h.fail(0, errors.New("rate limited"), now)
+	h.fail(0, errors.New("rate limited"), now)
+	h.fail(1, errors.New("timeout"), now)
+	if got := h.order(now); !slices.Equal(got, []int{2, 1, 0}) {
+		t.Errorf("order after failures = %v, want [2 1 0]", got)
+	}
+	h.succeed(1, 80*time.Millisecond)
+	if got := h.order(now); !slices.Equal(got, []int{1, 2, 0}) {
+		t.Errorf("order after recovery = %v, want [1 2 0]", got)
+	}
+	if got := h.order(now.Add(healthCooldown + time.Second)); !slices.Equal(got, []int{0, 1, 2}) {
+		t.Errorf("order after cooldown = %v, want configured order", got)
+	}
+	if s := h.snapshot(); s[0].Failures != 2 || s[0].LastError != "rate limited" || s[1].Latency != 80 {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+}
+
+func TestNotFoundUnless(t *testing.T) {
+	if err := notFoundUnless(nil); !errs.IsObjectNotFound(err) {
+		t.Errorf("all replicas missing: got %v, want object not found", err)
+	}
+	failed := errors.Join(errors.New("/115/lib: timeout"), errors.New("/pikpak/lib: 502 bad gateway"))
+	if err := notFoundUnless(failed); errs.IsObjectNotFound(err) || err != failed {
+		t.Errorf("replicas failed: got %v, want the joined replica errors", err)
+	}
+}
@@ -0,0 +1,34 @@
+package mirror
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	// one openlist path per line, all holding the same tree
+	Replicas         string `json:"replicas" required:"true" type:"text" help:"One path per line. Writes land on the healthiest replica first, then are copied to the others"`
+	LinkTimeout      int    `json:"link_timeout" type:"number" default:"10" help:"Seconds to wait for a replica's link before failing over, 0 to wait forever"`
+	VerifyInterval   int    `json:"verify_interval" type:"number" default:"0" help:"Hours between background verify tasks, 0 to disable"`
+	RepairDivergence bool   `json:"repair_divergence" type:"bool" default:"false" help:"Copy missing or differing objects from earlier replicas when verifying"`
+	Writable         bool   `json:"writable" type:"bool" default:"true"`
+}
+
+var config = driver.Config{
+	Name:             "Mirror",
+	LocalSort:        true,
+	NoCache:          true,
+	DefaultRoot:      "/",
+	ProxyRangeOption: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Mirror{
+			Addition: Addition{
+				LinkTimeout: 10,
+				Writable:    true,
+			},
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package mirror
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	log "github.com/sirupsen/logrus"
+)
+
+func (d *Mirror) verifyLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(d.VerifyInterval) * time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// scheduled runs belong to the admin, like the storage itself
+			admin, err := op.GetAdmin()
+			if err != nil {
+				log.Warnf("mirror: failed to get admin for scheduled verify: %+v", err)
+				continue
+			}
+			d.verify(context.WithValue(ctx, conf.UserKey, admin))
+		}
+	}
+}
+
+func (d *Mirror) verify(ctx context.Context) *fs.VerifyTask {
+	return fs.VerifyAsTask(ctx, fs.VerifyArgs{
+		Replicas: append([]string(nil), d.replicas...),
+		Repair:   d.RepairDivergence,
+		Refresh:  true,
+	})
+}
+
+func (d *Mirror) link(ctx context.Context, reqPath string, args model.LinkArgs) (*model.Link, model.Obj, error) {
+	storage, reqActualPath, err := op.GetStorageAndActualPath(reqPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !args.Redirect {
+		return op.Link(ctx, storage, reqActualPath, args)
+	}
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	if common.ShouldProxy(storage, stdpath.Base(reqPath)) {
+		return nil, obj, nil
+	}
+	return op.Link(ctx, storage, reqActualPath, args)
+}
+
+func toMirrorObj(obj model.Obj) model.Obj {
+	objRes := model.Object{
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+		HashInfo: obj.GetHash(),
+	}
+	if thumb, ok := model.GetThumb(obj); ok {
+		return &model.ObjThumb{
+			Object: objRes,
+			Thumbnail: model.Thumbnail{
+				Thumbnail: thumb,
+			},
+		}
+	}
+	return &objRes
+}
@@ -10,11 +10,17 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/drivers/virtual_file"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/spider"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	gocache "github.com/OpenListTeam/go-cache"
 )
 
-const unavailableActorRetryInterval = 5 * time.Minute
+const (
+	unavailableActorRetryInterval = 5 * time.Minute
+	// missingActorRetryInterval applies to actor pages the site reported
+	// as missing, those rarely come back
+	missingActorRetryInterval = 24 * time.Hour
+)
 
 var (
 	unavailableActorPages  = gocache.NewMemCache(gocache.WithShards[struct{}](8))
@@ -46,7 +52,11 @@ func (d *Pornhub) getFilms(dirName, pageKey string) ([]model.EmbyFileObj, error)
 
 		actorFilms, err := fetchPornhubActorFilms(d, pageKey)
 		if err != nil {
-			unavailableActorPages.Set(retryKey, struct{}{}, gocache.WithEx[struct{}](unavailableActorRetryInterval))
+			retryInterval := unavailableActorRetryInterval
+			if !spider.Retryable(err) {
+				retryInterval = missingActorRetryInterval
+			}
+			unavailableActorPages.Set(retryKey, struct{}{}, gocache.WithEx[struct{}](retryInterval))
 			return virtual_file.ListMediaFiles(d.ID, DriverName, dirName)
 		}
 		films = actorFilms
@@ -16,8 +16,8 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/media_job"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
-	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 )
 
@@ -27,8 +27,8 @@ type Pornhub struct {
 	AccessToken        string
 	ShareToken         string
 	DriveId            string
-	cron               *cron.Cron
-	fanartCron         *cron.Cron
+	jobs               *media_job.Group
+	fanartJobs         *media_job.Group
 	fanartCtx          context.Context
 	fanartCancel       context.CancelFunc
 	fanartMu           sync.Mutex
@@ -58,16 +58,12 @@ func (d *Pornhub) Init(ctx context.Context) error {
 		duration = time.Minute * 60
 	}
 
-	d.cron = cron.NewCron(duration)
-	d.cron.Do(func() {
-		d.reMatchTags()
-		if err := d.scanMediaArtifacts(); err != nil {
-			utils.Log.Warnf("failed to synchronize Pornhub artifacts: %s", err)
-		}
-		if err := d.syncConfiguredNFOs(); err != nil {
-			utils.Log.Warnf("failed to synchronize Pornhub NFOs: %s", err)
-		}
-	})
+	d.jobs = media_job.NewGroup(d.ID, DriverName, duration,
+		media_job.Job{Name: "tag", Run: media_job.Func(d.reMatchTags)},
+		media_job.Job{Name: "artifact", Run: d.scanMediaArtifacts},
+		media_job.Job{Name: "nfo", Run: d.syncConfiguredNFOs},
+	)
+	d.jobs.Start()
 
 	if d.FanartCount > 0 && d.FanartScanLimit > 0 {
 		fanartDuration := time.Minute * time.Duration(d.FanartScanTime)
@@ -81,8 +77,10 @@ func (d *Pornhub) Init(ctx context.Context) error {
 		d.fanartStopping = false
 		d.fanartCtx, d.fanartCancel = context.WithCancel(context.Background())
 		d.fanartMu.Unlock()
-		d.fanartCron = cron.NewCron(fanartDuration)
-		d.fanartCron.Do(d.runFanart)
+		d.fanartJobs = media_job.NewGroup(d.ID, DriverName, fanartDuration,
+			media_job.Job{Name: "fanart", Run: media_job.Func(d.runFanart)},
+		)
+		d.fanartJobs.Start()
 	}
 
 	return nil
@@ -96,13 +94,9 @@ func (d *Pornhub) Drop(ctx context.Context) error {
 	if fanartCancel != nil {
 		fanartCancel()
 	}
-	if d.fanartCron != nil {
-		d.fanartCron.Stop()
-	}
+	d.fanartJobs.Stop()
 	d.fanartWg.Wait()
-	if d.cron != nil {
-		d.cron.Stop()
-	}
+	d.jobs.Stop()
 	return nil
 }
 
@@ -29,6 +29,7 @@ func (d *Pornhub) scanFanart(ctx context.Context) {
 		return
 	}
 	utils.Log.Infof("found %d Pornhub fanart works, ids: %v", len(works), filmWorkIDs(works))
+	d.fanartJobs.Processed(len(works))
 
 	for workIndex := range works {
 		if err := ctx.Err(); err != nil {
@@ -197,6 +198,7 @@ func (d *Pornhub) advanceExistingFanart(work *model.FilmWork, count int, removeB
 
 func (d *Pornhub) updateSampleImageScanAt(ctx context.Context, work *model.FilmWork, scanErr error) {
 	utils.Log.Warnf("failed to scan fanart for work %s: %s", work.Code, scanErr.Error())
+	d.fanartJobs.Failed(1)
 	if errors.Is(scanErr, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
 		return
 	}
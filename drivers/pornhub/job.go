@@ -25,6 +25,7 @@ func (d *Pornhub) reMatchTags() {
 		return
 	}
 	utils.Log.Infof("found %d Pornhub tag works, ids: %v", len(films), filmWorkIDs(films))
+	d.jobs.Processed(len(films))
 
 	for _, film := range films {
 
@@ -54,6 +55,7 @@ func (d *Pornhub) reMatchTags() {
 
 		if err1 != nil {
 			utils.Log.Infof("failed to get film: %s tag info, error message: %s", film.Code, err1.Error())
+			d.jobs.Failed(1)
 			next := time.Now().Add(time.Hour)
 			if updateErr := db.UpdateMediaWorkTagRetry(film.ID, next, err1.Error()); updateErr != nil {
 				utils.Log.Warnf("failed to update tag retry for %s: %s", film.Code, updateErr)
@@ -65,6 +67,7 @@ func (d *Pornhub) reMatchTags() {
 		err1 = db.UpdateMediaWorkTags(film.ID, film.Tags, film.TagVersion+1)
 		if err1 != nil {
 			utils.Log.Infof("failed to update film: %s tag info, error: %s", film.Code, err1.Error())
+			d.jobs.Failed(1)
 			continue
 		}
 
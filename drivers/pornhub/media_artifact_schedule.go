@@ -24,6 +24,7 @@ func (d *Pornhub) scanMediaArtifacts() error {
 		return fmt.Errorf("list Pornhub works for artifact scan: %w", err)
 	}
 	utils.Log.Infof("found %d Pornhub artifact works, ids: %v", len(works), filmWorkIDs(works))
+	d.jobs.Processed(len(works))
 	for index := range works {
 		work := works[index]
 		identity := pornhubMediaIdentity(&work)
@@ -46,6 +47,7 @@ func (d *Pornhub) scanMediaArtifacts() error {
 		if cacheErr != nil {
 			status = classifyPosterError(cacheErr)
 			utils.Log.Warnf("failed to cache Pornhub poster for %s: %s", work.Code, cacheErr)
+			d.jobs.Failed(1)
 		}
 		if err := db.UpdateMediaWorkDMMPosterStatus(work.ID, status); err != nil {
 			return fmt.Errorf("update Pornhub poster status for %s: %w", work.Code, err)
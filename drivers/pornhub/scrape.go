@@ -15,27 +15,32 @@ var viewKeyCompile = regexp.MustCompile(`/view_video.php\?viewkey=([^&\s]+)`)
 
 func (d *Pornhub) getPlayListFilms(playlistID string) ([]PornFilm, error) {
 	var films []PornFilm
-	err := spider.Visit(d.SpiderServer, fmt.Sprintf("%s/playlist/%s", d.ServerUrl, playlistID), time.Duration(d.SpiderMaxWaitTime)*time.Second, func(wd selenium.WebDriver) {
+	page := spider.Page{URL: fmt.Sprintf("%s/playlist/%s", d.ServerUrl, playlistID), Timeout: d.spiderTimeout()}
+	err := spider.Fetch(d.SpiderServer, page, func(wd selenium.WebDriver) error {
 		source, _ := wd.PageSource()
 		compile := regexp.MustCompile("token=(.*)\"")
 		tokenStr := compile.FindString(source)
 		token := compile.ReplaceAllString(tokenStr, "$1")
 
-		page := 1
+		pageIndex := 1
 		previousLength := len(films)
-		for page == 1 || previousLength != len(films) {
+		for pageIndex == 1 || previousLength != len(films) {
 			previousLength = len(films)
-			pageURL := fmt.Sprintf("%s/playlist/viewChunked?id=%s&token=%s&page=%d", d.ServerUrl, playlistID, token, page)
+			pageURL := fmt.Sprintf("%s/playlist/viewChunked?id=%s&token=%s&page=%d", d.ServerUrl, playlistID, token, pageIndex)
 			if err := wd.Get(pageURL); err != nil {
 				utils.Log.Warnf("failed to get playlist, %s", err.Error())
-				return
+				return nil
+			}
+			if err := spider.WaitFor(wd, spider.DocumentReady, d.spiderTimeout()); err != nil {
+				utils.Log.Warnf("failed to get playlist, %s", err.Error())
+				return nil
 			}
 
-			time.Sleep(time.Duration(d.SpiderMaxWaitTime) * time.Second)
 			resolvedFilms, _ := resolveFilms(wd, PlayList)
 			films = append(films, resolvedFilms...)
-			page++
+			pageIndex++
 		}
+		return nil
 	})
 	if err != nil {
 		utils.Log.Warnf("failed to get pornhub films, %s", err.Error())
@@ -46,9 +51,14 @@ func (d *Pornhub) getPlayListFilms(playlistID string) ([]PornFilm, error) {
 
 func (d *Pornhub) getActorFilms(pageKey string) ([]PornFilm, error) {
 	var films []PornFilm
-	pageURL := fmt.Sprintf("%s%s?o=mr&page=1", d.ServerUrl, pageKey)
-	err := spider.Visit(d.SpiderServer, pageURL, time.Duration(d.SpiderMaxWaitTime)*time.Second, func(wd selenium.WebDriver) {
+	page := spider.Page{
+		URL:     fmt.Sprintf("%s%s?o=mr&page=1", d.ServerUrl, pageKey),
+		Ready:   spider.Selector(".videoUList"),
+		Timeout: d.spiderTimeout(),
+	}
+	err := spider.Fetch(d.SpiderServer, page, func(wd selenium.WebDriver) error {
 		films = d.resolveActorFilms(wd, pageKey)
+		return nil
 	})
 	if err != nil {
 		utils.Log.Warnf("failed to get pornhub films, %s", err.Error())
@@ -57,12 +67,13 @@ func (d *Pornhub) getActorFilms(pageKey string) ([]PornFilm, error) {
 	return films, nil
 }
 
-type filmElementFinder interface {
-	FindElement(by, value string) (selenium.WebElement, error)
+// spiderTimeout bounds how long a page may take to become ready.
+func (d *Pornhub) spiderTimeout() time.Duration {
+	return time.Duration(d.SpiderMaxWaitTime) * time.Second
 }
 
 type actorFilmPage interface {
-	filmElementFinder
+	spider.ElementFinder
 	Get(url string) error
 }
 
@@ -78,8 +89,11 @@ func (d *Pornhub) resolveActorFilms(wd actorFilmPage, pageKey string) []PornFilm
 			utils.Log.Warnf("failed to get actor films, %s", err.Error())
 			return films
 		}
+		if err := spider.WaitForElement(wd, ".videoUList", d.spiderTimeout()); err != nil {
+			utils.Log.Warnf("failed to get actor films, %s", err.Error())
+			return films
+		}
 
-		time.Sleep(time.Duration(d.SpiderMaxWaitTime) * time.Second)
 		pageFilms, pageAvailable := resolveFilms(wd, Model)
 		if !pageAvailable || len(pageFilms) == 0 {
 			return films
@@ -97,7 +111,7 @@ func hasNextActorFilmPage(wd actorFilmPage) bool {
 	return err != nil
 }
 
-func resolveFilms(wd filmElementFinder, actorType int) ([]PornFilm, bool) {
+func resolveFilms(wd spider.ElementFinder, actorType int) ([]PornFilm, bool) {
 	var films []PornFilm
 	var parentElement selenium.WebElement
 	var err error
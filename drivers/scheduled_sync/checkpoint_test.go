@@ -0,0 +1,188 @@
+package scheduled_sync
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func schedWithID(id uint, addition Addition) *ScheduledSync {
+	d := &ScheduledSync{}
+	d.SetStorage(model.Storage{ID: id, MountPath: "/sched"})
+	d.Addition = addition
+	return d
+}
+
+func listedPaths() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	var got []string
+	for _, c := range calls {
+		got = append(got, c.path)
+	}
+	return got
+}
+
+func TestScanResumesFromCheckpoint(t *testing.T) {
+	resetFake()
+	registerFake(t)
+	tree = map[string][]string{
+		"/":    {"a", "b"},
+		"/a":   nil,
+		"/b":   {"c"},
+		"/b/c": nil,
+	}
+	addition := Addition{RemotePath: "/fake", SyncCronExpr: "0 3 * * *"}
+	d := schedWithID(9001, addition)
+	report := &model.SyncReport{StorageID: d.ID, Status: model.SyncRunRunning, Listed: 2}
+	if err := SaveSyncReport(report); err != nil {
+		t.Fatalf("save report: %v", err)
+	}
+	// the process stopped after listing / and /a
+	if err := SaveSyncCheckpoint(&model.SyncCheckpoint{
+		StorageID: d.ID, RemotePath: addition.RemotePath, ReportID: report.ID,
+		Pending: model.StringArray{"/b"},
+	}); err != nil {
+		t.Fatalf("save checkpoint: %v", err)
+	}
+
+	d.scan()
+
+	if got := listedPaths(); !slices.Equal(got, []string{"/b", "/b/c"}) {
+		t.Fatalf("resumed walk = %v, want [/b /b/c]", got)
+	}
+	if checkpoint, err := GetSyncCheckpoint(d.ID); err != nil || checkpoint != nil {
+		t.Fatalf("checkpoint after completed walk = %+v, %v", checkpoint, err)
+	}
+	got, err := d.Other(context.Background(), model.OtherArgs{Method: "report"})
+	if err != nil {
+		t.Fatalf("other report: %v", err)
+	}
+	resumed := got.(model.SyncReport)
+	if resumed.ID != report.ID || resumed.Status != model.SyncRunCompleted || resumed.Resumes != 1 || resumed.Listed != 4 || resumed.EndAt == nil {
+		t.Fatalf("resumed report = %+v", resumed)
+	}
+}
+
+// 编辑存储时旧实例 Drop、新实例 Init：旧遍历须先停下并保存检查点，
+// 新实例续扫时只有一个遍历在走，每个目录只 List 一次。
+func TestDropStopsWalkBeforeResume(t *testing.T) {
+	resetFake()
+	registerFake(t)
+	tree = map[string][]string{"/": {"a", "b", "c", "d", "e", "f", "g", "h"}}
+	want := []string{"/"}
+	for _, name := range tree["/"] {
+		tree["/"+name] = nil
+		want = append(want, "/"+name)
+	}
+	addition := Addition{RemotePath: "/fake", SyncCronExpr: "0 3 * * *", ListRateLimit: 50}
+	report := &model.SyncReport{StorageID: 9004, Status: model.SyncRunRunning}
+	if err := SaveSyncReport(report); err != nil {
+		t.Fatalf("save report: %v", err)
+	}
+	if err := SaveSyncCheckpoint(&model.SyncCheckpoint{
+		StorageID: 9004, RemotePath: addition.RemotePath, ReportID: report.ID, Pending: model.StringArray{"/"},
+	}); err != nil {
+		t.Fatalf("save checkpoint: %v", err)
+	}
+
+	first := schedWithID(9004, addition)
+	if err := first.Init(context.Background()); err != nil {
+		t.Fatalf("first init: %+v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := first.Drop(context.Background()); err != nil {
+		t.Fatalf("drop: %+v", err)
+	}
+	stopped := len(listedPaths())
+	if stopped == 0 || stopped == len(want) {
+		t.Fatalf("first walk listed %d dirs before drop, want it stopped midway", stopped)
+	}
+	second := schedWithID(9004, addition)
+	if err := second.Init(context.Background()); err != nil {
+		t.Fatalf("second init: %+v", err)
+	}
+	t.Cleanup(func() { _ = second.Drop(context.Background()) })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		reports, err := ListSyncReports(9004, 1)
+		if err == nil && len(reports) == 1 && reports[0].Status == model.SyncRunCompleted {
+			if reports[0].Resumes != 2 {
+				t.Errorf("resumes = %d, want 2", reports[0].Resumes)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("walk not completed, reports = %+v, %v", reports, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	got := listedPaths()
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Fatalf("listed = %v, want every dir once: %v", got, want)
+	}
+}
+
+func TestScanRestartsWhenSettingsChanged(t *testing.T) {
+	resetFake()
+	registerFake(t)
+	tree = map[string][]string{"/": {"a"}, "/a": nil}
+	d := schedWithID(9002, Addition{RemotePath: "/fake", SyncCronExpr: "0 3 * * *"})
+	stale := &model.SyncReport{StorageID: d.ID, Status: model.SyncRunRunning}
+	if err := SaveSyncReport(stale); err != nil {
+		t.Fatalf("save report: %v", err)
+	}
+	if err := SaveSyncCheckpoint(&model.SyncCheckpoint{
+		StorageID: d.ID, RemotePath: "/fake", SyncPaths: "/old", ReportID: stale.ID,
+		Pending: model.StringArray{"/old/x"},
+	}); err != nil {
+		t.Fatalf("save checkpoint: %v", err)
+	}
+
+	d.scan()
+
+	if got := listedPaths(); !slices.Equal(got, []string{"/", "/a"}) {
+		t.Fatalf("walk after settings change = %v, want a full walk", got)
+	}
+	reports, err := ListSyncReports(d.ID, 10)
+	if err != nil || len(reports) != 2 {
+		t.Fatalf("reports = %+v, %v", reports, err)
+	}
+	if reports[0].Status != model.SyncRunCompleted || reports[0].Listed != 2 || reports[0].Resumes != 0 {
+		t.Fatalf("new report = %+v", reports[0])
+	}
+	if reports[1].ID != stale.ID || reports[1].Status != model.SyncRunFailed {
+		t.Fatalf("stale report = %+v", reports[1])
+	}
+}
+
+func TestScanReportCountsListErrors(t *testing.T) {
+	resetFake()
+	registerFake(t)
+	tree = map[string][]string{"/": {"a", "b"}, "/a": nil, "/b": nil}
+	errPaths = map[string]bool{"/a": true}
+	d := schedWithID(9003, Addition{RemotePath: "/fake", SyncCronExpr: "0 3 * * *", ListRateLimit: 1000})
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatalf("init: %+v", err)
+	}
+	t.Cleanup(func() { _ = d.Drop(context.Background()) })
+
+	d.scan()
+
+	reports, err := ListSyncReports(d.ID, 1)
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("reports = %+v, %v", reports, err)
+	}
+	report := reports[0]
+	if report.Listed != 2 || report.Errors != 1 || len(report.ErrorSamples) != 1 || report.Status != model.SyncRunCompleted {
+		t.Fatalf("report = %+v", report)
+	}
+	if _, err = d.Other(context.Background(), model.OtherArgs{Method: "unknown"}); err == nil {
+		t.Fatal("expected an error for an unknown method")
+	}
+}
@@ -0,0 +1,58 @@
+package scheduled_sync
+
+import (
+	"errors"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/gorm"
+)
+
+func GetSyncCheckpoint(storageID uint) (*model.SyncCheckpoint, error) {
+	var checkpoint model.SyncCheckpoint
+	err := db.GetDb().Where("storage_id = ?", storageID).First(&checkpoint).Error
+	if err == nil {
+		return &checkpoint, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+func SaveSyncCheckpoint(checkpoint *model.SyncCheckpoint) error {
+	return db.GetDb().Save(checkpoint).Error
+}
+
+func DeleteSyncCheckpoint(storageID uint) error {
+	return db.GetDb().Where("storage_id = ?", storageID).Delete(&model.SyncCheckpoint{}).Error
+}
+
+func GetSyncReport(id uint) (*model.SyncReport, error) {
+	var report model.SyncReport
+	if err := db.GetDb().First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func SaveSyncReport(report *model.SyncReport) error {
+	return db.GetDb().Save(report).Error
+}
+
+// ListSyncReports returns the newest reports of a storage first.
+func ListSyncReports(storageID uint, limit int) ([]model.SyncReport, error) {
+	var reports []model.SyncReport
+	err := db.GetDb().Where("storage_id = ?", storageID).Order("id desc").Limit(limit).Find(&reports).Error
+	return reports, err
+}
+
+// PruneSyncReports keeps the newest reports of a storage.
+func PruneSyncReports(storageID uint, keep int) error {
+	var ids []uint
+	err := db.GetDb().Model(&model.SyncReport{}).Where("storage_id = ?", storageID).Order("id desc").Pluck("id", &ids).Error
+	if err != nil || len(ids) <= keep {
+		return err
+	}
+	return db.GetDb().Delete(&model.SyncReport{}, ids[keep:]).Error
+}
@@ -3,13 +3,16 @@ package scheduled_sync
 import (
 	"context"
 	"strings"
+	"sync"
 
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 )
 
@@ -18,6 +21,13 @@ type ScheduledSync struct {
 	Addition
 	cron    *cron.Cron
 	limiter *rate.Limiter
+
+	mu sync.Mutex
+	// ctx 在 Drop 时取消，正在进行的遍历随之停止
+	ctx    context.Context
+	cancel context.CancelFunc
+	// done 在当前遍历退出时关闭，没有遍历时为 nil
+	done chan struct{}
 }
 
 func (d *ScheduledSync) Config() driver.Config { return config }
@@ -33,10 +43,7 @@ func (d *ScheduledSync) Init(ctx context.Context) error {
 	if expr == "" {
 		return errors.New("sync_cron_expr must not be empty")
 	}
-	if d.cron != nil {
-		d.cron.Stop()
-		d.cron = nil
-	}
+	d.stop()
 	d.limiter = nil
 	if d.ListRateLimit > 0 {
 		// burst=1：严格按速率放行，每个 List 调用等待令牌
@@ -46,17 +53,41 @@ func (d *ScheduledSync) Init(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrapf(err, "scheduled_sync: invalid sync_cron_expr %q", utils.SanitizeHTML(expr))
 	}
+	d.mu.Lock()
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	d.mu.Unlock()
 	d.cron = c
 	d.cron.Do(d.scan)
+	// 上次遍历被进程重启打断时立即续扫，不等下一次定时
+	if checkpoint, err := GetSyncCheckpoint(d.ID); err != nil {
+		log.Warnf("scheduled_sync: load checkpoint: %+v", err)
+	} else if checkpoint != nil {
+		go d.scan()
+	}
 	return nil
 }
 
 func (d *ScheduledSync) Drop(ctx context.Context) error {
+	d.stop()
+	return nil
+}
+
+// stop 停止定时任务并取消正在进行的遍历，等遍历保存检查点退出后才返回，
+// 之后新实例的 Init 才能从检查点续扫。
+func (d *ScheduledSync) stop() {
 	if d.cron != nil {
 		d.cron.Stop()
 		d.cron = nil
 	}
-	return nil
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if done != nil {
+		<-done
+	}
 }
 
 func (d *ScheduledSync) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
@@ -64,6 +95,9 @@ func (d *ScheduledSync) List(ctx context.Context, dir model.Obj, args model.List
 }
 
 func (d *ScheduledSync) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{Name: "Root", IsFolder: true, Path: "/"}, nil
+	}
 	return nil, errs.NotImplement
 }
 
@@ -71,4 +105,33 @@ func (d *ScheduledSync) Link(ctx context.Context, file model.Obj, args model.Lin
 	return nil, errs.NotImplement
 }
 
+// Other 提供遍历报告与检查点的查询：
+// report 返回最近一次遍历（进行中即为当前进度），reports 返回最近的报告列表，
+// checkpoint 返回未完成遍历的待遍历目录数。
+func (d *ScheduledSync) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	switch args.Method {
+	case "report":
+		reports, err := ListSyncReports(d.ID, 1)
+		if err != nil || len(reports) == 0 {
+			return nil, err
+		}
+		return reports[0], nil
+	case "reports":
+		return ListSyncReports(d.ID, keepReports)
+	case "checkpoint":
+		checkpoint, err := GetSyncCheckpoint(d.ID)
+		if err != nil || checkpoint == nil {
+			return nil, err
+		}
+		return base.Json{
+			"report_id":  checkpoint.ReportID,
+			"pending":    len(checkpoint.Pending),
+			"updated_at": checkpoint.UpdatedAt,
+		}, nil
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
 var _ driver.Driver = (*ScheduledSync)(nil)
+var _ driver.Other = (*ScheduledSync)(nil)
@@ -2,76 +2,138 @@ package scheduled_sync
 
 import (
 	"context"
+	"fmt"
 	stdpath "path"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/search"
 	"github.com/OpenListTeam/OpenList/v4/internal/syncpaths"
-	"github.com/OpenListTeam/OpenList/v4/pkg/generic"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// checkpointInterval 控制检查点落库频率，重启后最多重复这段时间内的 List
+	checkpointInterval = 10 * time.Second
+	maxErrorSamples    = 20
+	keepReports        = 30
+)
+
 // scan 触发一次定时遍历：白名单条目（空白名单为下游根）按深度排序后入
 // BFS 队列，每个目录通过下游自己的 List 获取（Refresh 由配置决定）。
 // 白名单之外的目录不会出现在下游 List 的返回中（Cache 场景），
 // 即使出现（普通驱动场景）也由 WithinSyncPaths 拦截，不会入队。
+// 每个 List 成功的目录都交给搜索索引队列同步。
 // 单目录失败仅记日志继续——保留下游已产生的数据，不删除。
+// 待遍历队列定期写入检查点，进程重启或 Drop 后从检查点继续，
+// 每次遍历产生一份报告，续扫沿用同一份报告。
 func (d *ScheduledSync) scan() {
+	d.mu.Lock()
+	ctx := d.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.Err() != nil {
+		d.mu.Unlock()
+		return
+	}
+	if d.done != nil {
+		d.mu.Unlock()
+		log.Warnf("scheduled_sync: previous scan still running: remote=%s", d.RemotePath)
+		return
+	}
+	done := make(chan struct{})
+	d.done = done
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.done = nil
+		d.mu.Unlock()
+		close(done)
+	}()
+	d.walk(ctx)
+}
+
+func (d *ScheduledSync) walk(ctx context.Context) {
 	log.Infof("scheduled_sync: scan start: remote=%s", d.RemotePath)
-	start := time.Now()
-	listed := 0
+	w, err := d.startWalk()
+	if err != nil {
+		log.Errorf("scheduled_sync: load checkpoint: %+v", err)
+		return
+	}
 	defer func() {
-		log.Infof("scheduled_sync: scan end: remote=%s, listed=%d, elapsed=%s", d.RemotePath, listed, time.Since(start))
+		log.Infof("scheduled_sync: scan end: remote=%s, listed=%d, errors=%d, elapsed=%s",
+			d.RemotePath, w.report.Listed, w.report.Errors, time.Duration(w.report.Duration)*time.Millisecond)
 	}()
 
 	remoteStorage, actualPath, err := op.GetStorageAndActualPath(d.RemotePath)
 	if err != nil {
 		log.Errorf("scheduled_sync: resolve remote %s: %+v", d.RemotePath, err)
+		w.fail(fmt.Sprintf("resolve remote: %s", err))
 		return
 	}
 	entries, whitelisted := syncpaths.ToRelEntries(actualPath, d.SyncPaths)
-	seeds := make([]string, 0)
 	if whitelisted {
-		seeds = append(seeds, entries...)
-	} else {
-		seeds = append(seeds, "/")
+		for _, e := range entries {
+			w.seeds[e] = true
+		}
 	}
-	if len(seeds) == 0 {
-		return
+	if !w.resumed {
+		seeds := make([]string, 0)
+		if whitelisted {
+			seeds = append(seeds, entries...)
+		} else {
+			seeds = append(seeds, "/")
+		}
+		sort.Slice(seeds, func(i, j int) bool {
+			return syncpaths.DirDepth(seeds[i]) < syncpaths.DirDepth(seeds[j])
+		})
+		for _, s := range seeds {
+			if !slices.Contains(w.pending, s) {
+				w.pending = append(w.pending, s)
+			}
+		}
 	}
-	sort.Slice(seeds, func(i, j int) bool {
-		return syncpaths.DirDepth(seeds[i]) < syncpaths.DirDepth(seeds[j])
-	})
-	queue := generic.NewQueue[string]()
-	seen := make(map[string]bool)
-	push := func(p string) {
-		if seen[p] {
+	for len(w.pending) > 0 {
+		if ctx.Err() != nil {
+			log.Infof("scheduled_sync: scan stopped: remote=%s, pending=%d", d.RemotePath, len(w.pending))
+			w.fail("scan stopped")
 			return
 		}
-		seen[p] = true
-		queue.Push(p)
-	}
-	for _, s := range seeds {
-		push(s)
-	}
-	ctx := context.Background()
-	for !queue.IsEmpty() {
-		dirPath := queue.Pop()
+		dirPath := w.pending[0]
 		if d.limiter != nil {
+			waitStart := time.Now()
 			if err := d.limiter.Wait(ctx); err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
 				log.Errorf("scheduled_sync: rate limit wait: %+v", err)
+				w.fail(fmt.Sprintf("rate limit wait: %s", err))
 				return
 			}
+			w.report.RateLimitWait += time.Since(waitStart).Milliseconds()
 		}
 		objs, err := op.List(ctx, remoteStorage, stdpath.Join(actualPath, dirPath), model.ListArgs{Refresh: d.Refresh, ScheduleScan: true})
+		if err != nil && ctx.Err() != nil {
+			// 被 Drop 打断的目录留在队列中，续扫时重新 List
+			continue
+		}
+		w.pending = w.pending[1:]
 		if err != nil {
 			log.Errorf("scheduled_sync: list %s: %+v", dirPath, err)
+			w.recordError(dirPath, err)
+			w.maybeSave()
 			continue
 		}
-		listed++
+		w.report.Listed++
+		// a listing served from the list cache calls no hooks, queue it for
+		// the search index here
+		search.QueueSync(utils.GetFullPath(remoteStorage.GetStorage().MountPath, stdpath.Join(actualPath, dirPath)))
 		for _, o := range objs {
 			if !o.IsDir() {
 				continue
@@ -82,8 +144,133 @@ func (d *ScheduledSync) scan() {
 			}
 			child := stdpath.Join(dirPath, name)
 			if !whitelisted || syncpaths.WithinSyncPaths(child, entries) {
-				push(child)
+				w.push(child)
+			}
+		}
+		w.maybeSave()
+	}
+	w.finish()
+}
+
+// walk 是一次遍历的运行状态，pending 为待 List 的目录。每个目录只由其父目录
+// 入队一次，唯一可能重复的是同为白名单条目的子目录，由 seeds 排除，
+// 因此检查点只需保存 pending。
+type walk struct {
+	storageID  uint
+	checkpoint *model.SyncCheckpoint
+	report     *model.SyncReport
+	pending    []string
+	seeds      map[string]bool
+	resumed    bool
+	lastSave   time.Time
+	segment    time.Time
+}
+
+// startWalk 加载与当前配置一致的检查点继续遍历，否则开始新的遍历。
+// 配置变更后的旧检查点被丢弃，其报告标记为失败。
+func (d *ScheduledSync) startWalk() (*walk, error) {
+	now := time.Now()
+	w := &walk{storageID: d.ID, seeds: make(map[string]bool), lastSave: now, segment: now}
+	checkpoint, err := GetSyncCheckpoint(d.ID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil {
+		report, err := GetSyncReport(checkpoint.ReportID)
+		if err != nil {
+			log.Warnf("scheduled_sync: report %d of checkpoint: %+v", checkpoint.ReportID, err)
+		} else if checkpoint.RemotePath != d.RemotePath || checkpoint.SyncPaths != d.SyncPaths {
+			report.Status = model.SyncRunFailed
+			report.Message = "sync settings changed, walk restarted"
+			report.EndAt = &now
+			if err := SaveSyncReport(report); err != nil {
+				log.Errorf("scheduled_sync: save report: %+v", err)
 			}
+		} else {
+			report.Status = model.SyncRunRunning
+			report.Message = ""
+			report.EndAt = nil
+			report.Resumes++
+			w.checkpoint, w.report, w.resumed = checkpoint, report, true
+			w.pending = append([]string(nil), checkpoint.Pending...)
+			log.Infof("scheduled_sync: resume scan: remote=%s, pending=%d", d.RemotePath, len(w.pending))
+			return w, nil
 		}
 	}
+
+	w.report = &model.SyncReport{StorageID: d.ID, Status: model.SyncRunRunning, StartAt: now}
+	if err := SaveSyncReport(w.report); err != nil {
+		return nil, err
+	}
+	w.checkpoint = &model.SyncCheckpoint{StorageID: d.ID, RemotePath: d.RemotePath, SyncPaths: d.SyncPaths, ReportID: w.report.ID}
+	return w, nil
+}
+
+// push 将 List 得到的子目录入队，白名单条目已作为起点入队，跳过。
+func (w *walk) push(p string) {
+	if w.seeds[p] {
+		return
+	}
+	w.pending = append(w.pending, p)
+}
+
+func (w *walk) recordError(dirPath string, err error) {
+	w.report.Errors++
+	if len(w.report.ErrorSamples) < maxErrorSamples {
+		w.report.ErrorSamples = append(w.report.ErrorSamples, fmt.Sprintf("%s: %s", dirPath, err))
+	}
+}
+
+func (w *walk) maybeSave() {
+	if time.Since(w.lastSave) < checkpointInterval {
+		return
+	}
+	w.save()
+}
+
+// save 同时写入检查点与报告，遍历中途的报告即为当前进度。
+func (w *walk) save() {
+	w.tick()
+	w.checkpoint.Pending = append(w.checkpoint.Pending[:0], w.pending...)
+	if err := SaveSyncCheckpoint(w.checkpoint); err != nil {
+		log.Errorf("scheduled_sync: save checkpoint: %+v", err)
+	}
+	if err := SaveSyncReport(w.report); err != nil {
+		log.Errorf("scheduled_sync: save report: %+v", err)
+	}
+}
+
+func (w *walk) tick() {
+	now := time.Now()
+	w.report.Duration += now.Sub(w.segment).Milliseconds()
+	w.segment, w.lastSave = now, now
+}
+
+func (w *walk) finish() {
+	w.end(model.SyncRunCompleted, "")
+	if err := DeleteSyncCheckpoint(w.storageID); err != nil {
+		log.Errorf("scheduled_sync: delete checkpoint: %+v", err)
+	}
+	if err := PruneSyncReports(w.storageID, keepReports); err != nil {
+		log.Errorf("scheduled_sync: prune reports: %+v", err)
+	}
+}
+
+// fail 结束报告但保留检查点，下次遍历从检查点继续。
+func (w *walk) fail(message string) {
+	if len(w.pending) > 0 {
+		w.save()
+	}
+	w.end(model.SyncRunFailed, message)
+}
+
+func (w *walk) end(status, message string) {
+	w.tick()
+	now := time.Now()
+	w.report.Status = status
+	w.report.Message = message
+	w.report.EndAt = &now
+	if err := SaveSyncReport(w.report); err != nil {
+		log.Errorf("scheduled_sync: save report: %+v", err)
+	}
 }
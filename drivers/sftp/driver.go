@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -101,6 +102,13 @@ func (d *SFTP) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
 	return errs.NotSupport
 }
 
+func (d *SFTP) SetModTime(ctx context.Context, obj model.Obj, modified time.Time) error {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return err
+	}
+	return d.client.Chtimes(obj.GetPath(), modified, modified)
+}
+
 func (d *SFTP) Remove(ctx context.Context, obj model.Obj) error {
 	if err := d.clientReconnectOnConnectionError(); err != nil {
 		return err
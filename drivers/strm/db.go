@@ -0,0 +1,60 @@
+package strm
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetStrmFile(storageID uint, localPath string) (*model.StrmFile, error) {
+	var item model.StrmFile
+	err := db.GetDb().Where("storage_id = ? AND local_path = ?", storageID, localPath).First(&item).Error
+	if err == nil {
+		return &item, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// SaveStrmFile records a produced file, replacing the record of the same
+// local path.
+func SaveStrmFile(item *model.StrmFile) error {
+	item.UpdatedAt = time.Now()
+	return db.GetDb().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "storage_id"}, {Name: "local_path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"local_dir", "source_path", "source_size", "source_modified", "updated_at"}),
+	}).Create(item).Error
+}
+
+// ListStrmFilesInDir returns the files produced directly in localDir.
+func ListStrmFilesInDir(storageID uint, localDir string) ([]model.StrmFile, error) {
+	var items []model.StrmFile
+	err := db.GetDb().Where("storage_id = ? AND local_dir = ?", storageID, localDir).Find(&items).Error
+	return items, err
+}
+
+// ListStrmFilesBySource returns the files produced from sources at or under
+// sourcePath.
+func ListStrmFilesBySource(storageID uint, sourcePath string) ([]model.StrmFile, error) {
+	var items []model.StrmFile
+	err := db.GetDb().Where("storage_id = ? AND source_path LIKE ?", storageID, sourcePath+"%").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	// LIKE treats _ and % in the path as wildcards
+	return slices.DeleteFunc(items, func(item model.StrmFile) bool {
+		return !utils.IsSubPath(sourcePath, item.SourcePath)
+	}), nil
+}
+
+func DeleteStrmFile(storageID uint, localPath string) error {
+	return db.GetDb().Where("storage_id = ? AND local_path = ?", storageID, localPath).Delete(&model.StrmFile{}).Error
+}
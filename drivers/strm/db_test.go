@@ -0,0 +1,70 @@
+package strm
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig("data")
+	db.Init(dB)
+}
+
+func TestStrmFiles(t *testing.T) {
+	files := []model.StrmFile{
+		{StorageID: 1, LocalPath: "/local/a/1.strm", LocalDir: "/local/a", SourcePath: "/src/a_b/1.mkv", SourceSize: 1},
+		{StorageID: 1, LocalPath: "/local/a/2.strm", LocalDir: "/local/a", SourcePath: "/src/axb/2.mkv", SourceSize: 2},
+		{StorageID: 1, LocalPath: "/local/a/b/3.srt", LocalDir: "/local/a/b", SourcePath: "/src/a_b/c/3.srt", SourceSize: 3},
+		{StorageID: 2, LocalPath: "/local/a/1.strm", LocalDir: "/local/a", SourcePath: "/src/a_b/1.mkv", SourceSize: 1},
+	}
+	for i := range files {
+		if err := SaveStrmFile(&files[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// saving the same local path replaces its record
+	if err := SaveStrmFile(&model.StrmFile{StorageID: 1, LocalPath: "/local/a/1.strm", LocalDir: "/local/a",
+		SourcePath: "/src/a_b/1.mkv", SourceSize: 10}); err != nil {
+		t.Fatal(err)
+	}
+	item, err := GetStrmFile(1, "/local/a/1.strm")
+	if err != nil || item == nil || item.SourceSize != 10 {
+		t.Errorf("expected the replaced record, got %+v (%v)", item, err)
+	}
+	if item, err = GetStrmFile(1, "/local/missing.strm"); err != nil || item != nil {
+		t.Errorf("expected no record, got %+v (%v)", item, err)
+	}
+
+	if items, _ := ListStrmFilesInDir(1, "/local/a"); len(items) != 2 {
+		t.Errorf("expected 2 files in dir, got %+v", items)
+	}
+	// _ must not match axb
+	items, err := ListStrmFilesBySource(1, "/src/a_b")
+	if err != nil || len(items) != 2 {
+		t.Errorf("expected 2 files under source, got %+v (%v)", items, err)
+	}
+	for _, item := range items {
+		if item.SourcePath == "/src/axb/2.mkv" {
+			t.Errorf("%s is not under /src/a_b", item.SourcePath)
+		}
+	}
+
+	if err = DeleteStrmFile(1, "/local/a/1.strm"); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ = ListStrmFilesInDir(1, "/local/a"); len(items) != 1 {
+		t.Errorf("expected 1 file left in dir, got %+v", items)
+	}
+	if items, _ = ListStrmFilesInDir(2, "/local/a"); len(items) != 1 {
+		t.Errorf("storage 2 polluted, got %+v", items)
+	}
+}
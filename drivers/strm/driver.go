@@ -15,6 +15,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	log "github.com/sirupsen/logrus"
@@ -252,4 +253,32 @@ func (d *Strm) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*
 	return &resultLink, nil
 }
 
+// Other starts an export task for admins on "export" and reports the files recorded in
+// the manifest on "manifest".
+func (d *Strm) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	switch args.Method {
+	case "export":
+		return task.AddByAdmin(ctx, func() (*ExportTask, error) {
+			data, err := utils.Json.Marshal(args.Data)
+			if err != nil {
+				return nil, err
+			}
+			var exportArgs ExportArgs
+			if err = utils.Json.Unmarshal(data, &exportArgs); err != nil {
+				return nil, err
+			}
+			return d.export(ctx, exportArgs)
+		})
+	case "manifest":
+		path, _ := args.Data.(string)
+		items, err := ListStrmFilesBySource(d.ID, utils.FixAndCleanPath(path))
+		if err != nil {
+			return nil, err
+		}
+		return items, nil
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
 var _ driver.Driver = (*Strm)(nil)
@@ -0,0 +1,290 @@
+package strm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdpath "path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/tache"
+	log "github.com/sirupsen/logrus"
+)
+
+// ExportArgs select what an export task walks and how it treats the files
+// produced before.
+type ExportArgs struct {
+	// Path is a path of the storage, the whole storage when empty
+	Path string `json:"path"`
+	// Incremental skips the files whose source size and mtime are the ones
+	// recorded, and lists from the cache
+	Incremental bool `json:"incremental"`
+	// Cleanup removes the recorded files whose source is gone
+	Cleanup bool `json:"cleanup"`
+	// DryRun reports the files to write and remove without touching any
+	DryRun bool `json:"dry_run"`
+}
+
+// exportReportLimit caps the paths a report lists, the counts are exact.
+const exportReportLimit = 1000
+
+type ExportProgress struct {
+	DirsDone int    `json:"dirs_done"`
+	Queued   int    `json:"queued"`
+	Written  int    `json:"written"`
+	Skipped  int    `json:"skipped"`
+	Removed  int    `json:"removed"`
+	Errors   int    `json:"errors"`
+	Current  string `json:"current"`
+	// the local paths written and removed, filled on dry runs only
+	WrittenFiles []string `json:"written_files,omitempty"`
+	RemovedFiles []string `json:"removed_files,omitempty"`
+}
+
+func (p ExportProgress) Walked() (int, int) {
+	return p.DirsDone, p.Queued
+}
+
+// ExportTask writes the local files of a strm storage from a walk of its
+// sources and records each in the manifest, so later runs can skip the
+// unchanged ones and remove exactly the files they produced.
+type ExportTask struct {
+	task.WalkTask[ExportProgress]
+	storage *Strm
+	args    ExportArgs
+}
+
+func (t *ExportTask) GetName() string {
+	mode := "full"
+	if t.args.Incremental {
+		mode = "incremental"
+	}
+	if t.args.DryRun {
+		mode += ", dry run"
+	}
+	return fmt.Sprintf("strm export [%s](%s, %s)", t.storage.MountPath, t.args.Path, mode)
+}
+
+func (t *ExportTask) GetStatus() string {
+	p := t.Progress()
+	if p.Current == "" {
+		return fmt.Sprintf("%d written, %d skipped, %d removed, %d errors", p.Written, p.Skipped, p.Removed, p.Errors)
+	}
+	return fmt.Sprintf("exporting %s, %d written, %d skipped, %d errors", p.Current, p.Written, p.Skipped, p.Errors)
+}
+
+// Progress returns a copy of the progress, the report included.
+func (t *ExportTask) Progress() ExportProgress {
+	p := t.WalkTask.Progress()
+	p.WrittenFiles = append([]string(nil), p.WrittenFiles...)
+	p.RemovedFiles = append([]string(nil), p.RemovedFiles...)
+	return p
+}
+
+func (t *ExportTask) written(localPath string) {
+	t.Update(func(p *ExportProgress) {
+		p.Written++
+		if t.args.DryRun && len(p.WrittenFiles) < exportReportLimit {
+			p.WrittenFiles = append(p.WrittenFiles, localPath)
+		}
+	})
+}
+
+func (t *ExportTask) removed(localPath string) {
+	t.Update(func(p *ExportProgress) {
+		p.Removed++
+		if t.args.DryRun && len(p.RemovedFiles) < exportReportLimit {
+			p.RemovedFiles = append(p.RemovedFiles, localPath)
+		}
+	})
+}
+
+// exportDir is a source dir to export, with the base path the local dir is
+// mapped from
+type exportDir struct {
+	src  string
+	base string
+}
+
+// exportRoots returns the source dirs behind path of the storage.
+func (d *Strm) exportRoots(path string) []exportDir {
+	var roots []exportDir
+	if utils.PathEqual(path, "/") && !d.autoFlatten {
+		for _, dsts := range d.pathMap {
+			for _, dst := range dsts {
+				roots = append(roots, exportDir{src: dst, base: stdpath.Base(dst)})
+			}
+		}
+		return roots
+	}
+	root, sub := d.getRootAndPath(path)
+	for _, dst := range d.pathMap[root] {
+		roots = append(roots, exportDir{src: stdpath.Join(dst, sub), base: stdpath.Join(stdpath.Base(dst), sub)})
+	}
+	return roots
+}
+
+func (t *ExportTask) Run() error {
+	return t.Walk(t.export)
+}
+
+func (t *ExportTask) export(ctx context.Context) error {
+	d := t.storage
+	roots := d.exportRoots(t.args.Path)
+	if len(roots) == 0 {
+		return fmt.Errorf("%s is not a path of the storage", t.args.Path)
+	}
+	// the local path of every file the sources still hold
+	seen := make(map[string]struct{})
+	// a dir failed to list would have its files taken for orphans
+	listFailed := false
+	queue := append([]exportDir(nil), roots...)
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		dir := queue[0]
+		queue = queue[1:]
+		localDir, ok := d.localDir(dir.base)
+		if !ok {
+			return fmt.Errorf("no local path for %s", dir.base)
+		}
+		objs, err := fs.List(ctx, dir.src, &fs.ListArgs{NoLog: true, Refresh: !t.args.Incremental})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Warnf("strm export: failed to list %s: %+v", dir.src, err)
+			listFailed = true
+			t.Update(func(p *ExportProgress) {
+				p.DirsDone++
+				p.Errors++
+			})
+			continue
+		}
+		for _, obj := range objs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if obj.IsDir() {
+				queue = append(queue, exportDir{
+					src:  stdpath.Join(dir.src, obj.GetName()),
+					base: stdpath.Join(dir.base, obj.GetName()),
+				})
+				continue
+			}
+			strmObj := d.strmObj(ctx, dir.src, obj)
+			if strmObj == nil {
+				continue
+			}
+			localPath := stdpath.Join(localDir, strmObj.GetName())
+			seen[localPath] = struct{}{}
+			t.Update(func(p *ExportProgress) {
+				p.Current = localPath
+				p.Queued = len(queue)
+			})
+			if t.args.Incremental && t.unchanged(obj, strmObj, localPath) {
+				t.Update(func(p *ExportProgress) { p.Skipped++ })
+				continue
+			}
+			if t.args.DryRun {
+				t.written(localPath)
+				continue
+			}
+			if err = d.exportFile(ctx, obj, strmObj, localPath); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Warnf("strm export: failed to write %s: %+v", localPath, err)
+				t.Update(func(p *ExportProgress) { p.Errors++ })
+				continue
+			}
+			t.written(localPath)
+		}
+		t.Update(func(p *ExportProgress) {
+			p.DirsDone++
+			p.Queued = len(queue)
+		})
+	}
+	t.Update(func(p *ExportProgress) { p.Current = "" })
+
+	var cleanupErr error
+	if t.args.Cleanup {
+		if listFailed {
+			cleanupErr = errors.New("cleanup skipped as some dirs failed to list")
+		} else {
+			cleanupErr = t.cleanup(roots, seen)
+		}
+	}
+	p := t.Progress()
+	log.Infof("strm export: completed %s, %d written, %d skipped, %d removed, %d errors",
+		t.GetName(), p.Written, p.Skipped, p.Removed, p.Errors)
+	if cleanupErr != nil {
+		return cleanupErr
+	}
+	if p.Errors > 0 {
+		return fmt.Errorf("%d objects failed to export", p.Errors)
+	}
+	return nil
+}
+
+// unchanged tells whether the local file of obj is the one recorded from the
+// same source.
+func (t *ExportTask) unchanged(obj, strmObj model.Obj, localPath string) bool {
+	item, err := GetStrmFile(t.storage.ID, localPath)
+	if err != nil || item == nil {
+		return false
+	}
+	return item.SourcePath == strmObj.GetPath() &&
+		item.SourceSize == obj.GetSize() &&
+		item.SourceModified.Equal(obj.ModTime()) &&
+		utils.Exists(localPath)
+}
+
+// cleanup removes the recorded files produced from the walked sources that
+// the walk did not see.
+func (t *ExportTask) cleanup(roots []exportDir, seen map[string]struct{}) error {
+	d := t.storage
+	for _, root := range roots {
+		items, err := ListStrmFilesBySource(d.ID, root.src)
+		if err != nil {
+			return fmt.Errorf("failed to read produced files of %s: %w", root.src, err)
+		}
+		for _, item := range items {
+			if _, ok := seen[item.LocalPath]; ok {
+				continue
+			}
+			// keep a file removed by an earlier root from being counted again
+			seen[item.LocalPath] = struct{}{}
+			if !t.args.DryRun {
+				if err = d.removeLocalFile(item.LocalPath); err != nil {
+					log.Warnf("strm export: failed to remove %s: %+v", item.LocalPath, err)
+					t.Update(func(p *ExportProgress) { p.Errors++ })
+					continue
+				}
+			}
+			t.removed(item.LocalPath)
+		}
+	}
+	return nil
+}
+
+var ExportTaskManager *tache.Manager[*ExportTask]
+
+// export adds a task exporting the local files of the storage with args.
+func (d *Strm) export(ctx context.Context, args ExportArgs) (*ExportTask, error) {
+	if !d.SaveStrmToLocal {
+		return nil, errors.New("SaveStrmToLocal is not enabled")
+	}
+	args.Path = utils.FixAndCleanPath(args.Path)
+	t := &ExportTask{
+		WalkTask: task.NewWalkTask[ExportProgress](ctx),
+		storage:  d,
+		args:     args,
+	}
+	ExportTaskManager.Add(t)
+	return t, nil
+}
@@ -3,6 +3,7 @@ package strm
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	stdpath "path"
 	"path/filepath"
@@ -23,35 +24,32 @@ var strmTrie = patricia.NewTrie()
 func UpdateLocalStrm(ctx context.Context, path string, objs []model.Obj) {
 	path = utils.FixAndCleanPath(path)
 	updateLocal := func(driver *Strm, basePath string, objs []model.Obj) {
-
-		relParent := strings.TrimPrefix(basePath, driver.MountPath)
-
-		saveToLocalPath := ""
-		if len(driver.strmLocalPathMap) > 0 {
-			for parentPrefix, parentLocalPath := range driver.strmLocalPathMap {
-				if strings.HasPrefix(relParent, parentPrefix) {
-					saveToLocalPath = parentLocalPath
-					relParent = strings.TrimPrefix(relParent, parentPrefix)
-					break
-				}
-			}
-		} else {
-			saveToLocalPath = driver.SaveStrmLocalPath
+		if driver.ExportByTaskOnly {
+			return
 		}
-
-		if len(saveToLocalPath) == 0 {
+		localParentPath, ok := driver.localDir(basePath)
+		if !ok {
 			log.Warnf("unable to save strm locally to path: %v", driver.SaveStrmLocalPath)
 			return
 		}
 
-		localParentPath := stdpath.Join(saveToLocalPath, relParent)
-
+		var strmObjs []model.Obj
 		for _, obj := range objs {
-			localPath := stdpath.Join(localParentPath, obj.GetName())
-			generateStrm(ctx, driver, obj, localPath)
+			strmObj := driver.strmObj(ctx, path, obj)
+			if strmObj == nil || strmObj.IsDir() {
+				continue
+			}
+			strmObjs = append(strmObjs, strmObj)
+			localPath := stdpath.Join(localParentPath, strmObj.GetName())
+			if utils.Exists(localPath) && !driver.UpdateExistFile {
+				continue
+			}
+			if err := driver.exportFile(ctx, obj, strmObj, localPath); err != nil {
+				log.Warnf("failed to generate strm of obj %s: %v", localPath, err)
+			}
 		}
 		if driver.DeleteExtraLocalFile {
-			deleteExtraFiles(driver, localParentPath, objs)
+			deleteExtraFiles(driver, localParentPath, strmObjs)
 		}
 
 	}
@@ -64,13 +62,36 @@ func UpdateLocalStrm(ctx context.Context, path string, objs []model.Obj) {
 			return nil
 		}
 		for _, strmDriver := range strmDrivers {
-			strmObjs := strmDriver.convert2strmObjs(ctx, path, objs)
-			updateLocal(strmDriver, stdpath.Join(stdpath.Base(needPath), restPath), strmObjs)
+			updateLocal(strmDriver, stdpath.Join(stdpath.Base(needPath), restPath), objs)
 		}
 		return nil
 	})
 }
 
+// localDir maps the base path of a source dir, which is the name of the
+// configured path followed by the dir relative to it, to a local dir.
+func (d *Strm) localDir(basePath string) (string, bool) {
+	relParent := strings.TrimPrefix(basePath, d.MountPath)
+
+	saveToLocalPath := ""
+	if len(d.strmLocalPathMap) > 0 {
+		for parentPrefix, parentLocalPath := range d.strmLocalPathMap {
+			if strings.HasPrefix(relParent, parentPrefix) {
+				saveToLocalPath = parentLocalPath
+				relParent = strings.TrimPrefix(relParent, parentPrefix)
+				break
+			}
+		}
+	} else {
+		saveToLocalPath = d.SaveStrmLocalPath
+	}
+
+	if len(saveToLocalPath) == 0 {
+		return "", false
+	}
+	return stdpath.Join(saveToLocalPath, relParent), true
+}
+
 func InsertStrm(dstPath string, d *Strm) error {
 	prefix := patricia.Prefix(strings.TrimRight(dstPath, "/"))
 	existing := strmTrie.Get(prefix)
@@ -114,56 +135,64 @@ func RemoveStrm(dstPath string, d *Strm) {
 	}
 }
 
-func generateStrm(ctx context.Context, driver *Strm, obj model.Obj, localPath string) {
-	if !obj.IsDir() {
-
-		if utils.Exists(localPath) && !driver.UpdateExistFile {
-			return
-		}
+// exportFile writes the local file of strmObj, converted from the source obj
+// src, and records it in the manifest.
+func (d *Strm) exportFile(ctx context.Context, src, strmObj model.Obj, localPath string) error {
+	link, err := d.Link(ctx, strmObj, model.LinkArgs{})
+	if err != nil {
+		return fmt.Errorf("failed to link: %w", err)
+	}
+	defer link.Close()
+	size := link.ContentLength
+	if size <= 0 {
+		size = strmObj.GetSize()
+	}
+	rrf, err := stream.GetRangeReaderFromLink(size, link)
+	if err != nil {
+		return fmt.Errorf("failed to get range reader: %w", err)
+	}
+	rc, err := rrf.RangeRead(ctx, http_range.Range{Length: -1})
+	if err != nil {
+		return fmt.Errorf("failed to read range: %w", err)
+	}
+	defer rc.Close()
+	err = os.MkdirAll(filepath.Dir(localPath), os.FileMode(d.mkdirPerm))
+	if err != nil {
+		return fmt.Errorf("failed to create local dir: %w", err)
+	}
 
-		link, err := driver.Link(ctx, obj, model.LinkArgs{})
-		if err != nil {
-			log.Warnf("failed to generate strm of obj %s: failed to link: %v", localPath, err)
-			return
-		}
-		defer link.Close()
-		size := link.ContentLength
-		if size <= 0 {
-			size = obj.GetSize()
-		}
-		rrf, err := stream.GetRangeReaderFromLink(size, link)
-		if err != nil {
-			log.Warnf("failed to generate strm of obj %s: failed to get range reader: %v", localPath, err)
-			return
-		}
-		rc, err := rrf.RangeRead(ctx, http_range.Range{Length: -1})
-		if err != nil {
-			log.Warnf("failed to generate strm of obj %s: failed to read range: %v", localPath, err)
-			return
-		}
-		defer rc.Close()
-		err = os.MkdirAll(filepath.Dir(localPath), os.FileMode(driver.mkdirPerm))
-		if err != nil {
-			log.Warnf("failed to generate strm of obj %s: failed to create local file: %v", localPath, err)
-			return
-		}
+	file, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer file.Close()
+	if _, err = utils.CopyWithBuffer(file, rc); err != nil {
+		return fmt.Errorf("copy failed: %w", err)
+	}
+	return SaveStrmFile(&model.StrmFile{
+		StorageID:      d.ID,
+		LocalPath:      localPath,
+		LocalDir:       stdpath.Dir(localPath),
+		SourcePath:     strmObj.GetPath(),
+		SourceSize:     src.GetSize(),
+		SourceModified: src.ModTime(),
+	})
+}
 
-		file, createErr := os.Create(localPath)
-		if createErr != nil {
-			log.Warnf("failed to generate strm of obj %s: failed to create local file: %v", localPath, err)
-			return
-		}
-		defer file.Close()
-		if _, err := utils.CopyWithBuffer(file, rc); err != nil {
-			log.Warnf("failed to generate strm of obj %s: copy failed: %v", localPath, err)
-		}
+// removeLocalFile removes a produced file and its record.
+func (d *Strm) removeLocalFile(localPath string) error {
+	if err := os.Remove(localPath); err != nil && !os.IsNotExist(err) {
+		return err
 	}
+	return DeleteStrmFile(d.ID, localPath)
 }
 
+// deleteExtraFiles removes the files produced in localPath that objs no
+// longer hold, files placed there otherwise are left alone.
 func deleteExtraFiles(driver *Strm, localPath string, objs []model.Obj) {
-	localFiles, err := getLocalFiles(localPath)
+	produced, err := ListStrmFilesInDir(driver.ID, localPath)
 	if err != nil {
-		log.Errorf("Failed to read local files from %s: %v", localPath, err)
+		log.Errorf("Failed to read produced files of %s: %v", localPath, err)
 		return
 	}
 
@@ -180,7 +209,8 @@ func deleteExtraFiles(driver *Strm, localPath string, objs []model.Obj) {
 		objsBaseNameSet[stdpath.Join(localPath, objBaseName[:len(objBaseName)-1])] = struct{}{}
 	}
 
-	for _, localFile := range localFiles {
+	for _, item := range produced {
+		localFile := item.LocalPath
 		if _, exists := objsSet[localFile]; !exists {
 			ext := utils.Ext(localFile)
 			localFileName := stdpath.Base(localFile)
@@ -191,7 +221,7 @@ func deleteExtraFiles(driver *Strm, localPath string, objs []model.Obj) {
 				continue
 			}
 
-			err := os.Remove(localFile)
+			err := driver.removeLocalFile(localFile)
 			if err != nil {
 				log.Errorf("Failed to delete file: %s, error: %v\n", localFile, err)
 			} else {
@@ -201,20 +231,6 @@ func deleteExtraFiles(driver *Strm, localPath string, objs []model.Obj) {
 	}
 }
 
-func getLocalFiles(localPath string) ([]string, error) {
-	var files []string
-	entries, err := os.ReadDir(localPath)
-	if err != nil {
-		return nil, err
-	}
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			files = append(files, stdpath.Join(localPath, entry.Name()))
-		}
-	}
-	return files, nil
-}
-
 func init() {
 	op.RegisterObjsUpdateHook(UpdateLocalStrm)
 }
@@ -20,6 +20,7 @@ type Addition struct {
 	MkdirPerm             string `json:"mkdir_perm" default:"777"`
 	DeleteExtraLocalFile  bool   `json:"deleteExtraLocalFile" default:"false" help:"delete extra file locally"`
 	UpdateExistFile       bool   `json:"updateExistFile" default:"false" help:"override exist file locally"`
+	ExportByTaskOnly      bool   `json:"exportByTaskOnly" default:"false" help:"write local files through export tasks only, not while listing"`
 }
 
 var config = driver.Config{
@@ -102,6 +102,15 @@ func (d *Strm) convert2strmObjs(ctx context.Context, reqPath string, objs []mode
 	return validObjs
 }
 
+// strmObj converts a source obj of srcDir, nil if it is not exported.
+func (d *Strm) strmObj(ctx context.Context, srcDir string, obj model.Obj) model.Obj {
+	objs := d.convert2strmObjs(ctx, srcDir, []model.Obj{obj})
+	if len(objs) == 0 {
+		return nil
+	}
+	return objs[0]
+}
+
 func (d *Strm) getLink(ctx context.Context, path string) string {
 	finalPath := path
 	if d.EncodePath {
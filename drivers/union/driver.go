@@ -0,0 +1,293 @@
+package union
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdpath "path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/sign"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+)
+
+var errNoSpace = errors.New("no branch has enough free space")
+
+// Union pools several branches into one tree, mergerfs style: reads look
+// through the branches in order, new objects go to the branch chosen by
+// the create policy.
+type Union struct {
+	model.Storage
+	Addition
+	branches []string
+	next     atomic.Uint32
+}
+
+func (d *Union) Config() driver.Config {
+	return config
+}
+
+func (d *Union) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Union) Init(ctx context.Context) error {
+	d.branches = nil
+	for _, b := range strings.Split(d.Branches, "\n") {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			continue
+		}
+		b = utils.FixAndCleanPath(b)
+		if utils.IsSubPath(b, d.MountPath) || utils.IsSubPath(d.MountPath, b) {
+			return fmt.Errorf("branch %s overlaps the union itself", b)
+		}
+		d.branches = append(d.branches, b)
+	}
+	if len(d.branches) == 0 {
+		return errors.New("branches is required")
+	}
+	return nil
+}
+
+func (d *Union) Drop(ctx context.Context) error {
+	d.branches = nil
+	return nil
+}
+
+func (d *Union) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	for _, b := range d.branches {
+		obj, err := fs.Get(ctx, stdpath.Join(b, path), &fs.GetArgs{NoLog: true})
+		if err != nil {
+			continue
+		}
+		return &model.Object{
+			Path:     path,
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		}, nil
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Union) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var objs []model.Obj
+	seen := make(map[string]struct{})
+	found := false
+	for _, b := range d.branches {
+		tmp, err := fs.List(ctx, stdpath.Join(b, dir.GetPath()), &fs.ListArgs{
+			NoLog:   true,
+			Refresh: args.Refresh,
+		})
+		if err != nil {
+			continue
+		}
+		found = true
+		for _, obj := range tmp {
+			if _, ok := seen[obj.GetName()]; ok {
+				continue
+			}
+			seen[obj.GetName()] = struct{}{}
+			objs = append(objs, toUnionObj(obj))
+		}
+		if d.SearchPolicy == searchFirstFound {
+			break
+		}
+	}
+	if !found {
+		return nil, errs.ObjectNotFound
+	}
+	return objs, nil
+}
+
+func (d *Union) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	// proxy || ftp,s3
+	if common.GetApiUrl(ctx) == "" {
+		args.Redirect = false
+	}
+	for _, b := range d.branches {
+		reqPath := stdpath.Join(b, file.GetPath())
+		link, fi, err := d.link(ctx, reqPath, args)
+		if err != nil {
+			continue
+		}
+		if link == nil {
+			return &model.Link{
+				URL: fmt.Sprintf("%s/p%s?sign=%s",
+					common.GetApiUrl(ctx),
+					utils.EncodePath(reqPath, true),
+					sign.Sign(reqPath)),
+			}, nil
+		}
+		resultLink := *link
+		resultLink.SyncClosers = utils.NewSyncClosers(link)
+		if !args.Redirect && resultLink.ContentLength == 0 {
+			resultLink.ContentLength = fi.GetSize()
+		}
+		return &resultLink, nil
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Union) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	b, err := d.create(ctx, 0)
+	if err != nil {
+		return err
+	}
+	return fs.MakeDir(ctx, stdpath.Join(b, parentDir.GetPath(), dirName))
+}
+
+func (d *Union) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	branches := d.existing(ctx, srcObj.GetPath(), d.ActionPolicy == actionFirstFound)
+	if len(branches) == 0 {
+		return errs.ObjectNotFound
+	}
+	var err error
+	for _, b := range branches {
+		// objects move within their branch, the destination dir may only
+		// exist on another one so far
+		dst := stdpath.Join(b, dstDir.GetPath())
+		if e := fs.MakeDir(ctx, dst); e != nil {
+			err = errors.Join(err, e)
+			continue
+		}
+		_, e := fs.Move(ctx, stdpath.Join(b, srcObj.GetPath()), dst)
+		err = errors.Join(err, e)
+	}
+	return err
+}
+
+func (d *Union) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	branches := d.existing(ctx, srcObj.GetPath(), d.ActionPolicy == actionFirstFound)
+	if len(branches) == 0 {
+		return errs.ObjectNotFound
+	}
+	var err error
+	for _, b := range branches {
+		err = errors.Join(err, fs.Rename(ctx, stdpath.Join(b, srcObj.GetPath()), newName))
+	}
+	return err
+}
+
+func (d *Union) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	branches := d.existing(ctx, srcObj.GetPath(), true)
+	if len(branches) == 0 {
+		return errs.ObjectNotFound
+	}
+	dst := stdpath.Join(branches[0], dstDir.GetPath())
+	if err := fs.MakeDir(ctx, dst); err != nil {
+		return err
+	}
+	_, err := fs.Copy(ctx, stdpath.Join(branches[0], srcObj.GetPath()), dst)
+	return err
+}
+
+func (d *Union) Remove(ctx context.Context, obj model.Obj) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	branches := d.existing(ctx, obj.GetPath(), d.ActionPolicy == actionFirstFound)
+	if len(branches) == 0 {
+		return errs.ObjectNotFound
+	}
+	var err error
+	for _, b := range branches {
+		err = errors.Join(err, fs.Remove(ctx, stdpath.Join(b, obj.GetPath())))
+	}
+	return err
+}
+
+func (d *Union) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	// an existing file is overwritten in place, or the union would show
+	// the stale copy of the earlier branch
+	var b string
+	if branches := d.existing(ctx, stdpath.Join(dstDir.GetPath(), s.GetName()), true); len(branches) > 0 {
+		b = branches[0]
+	} else {
+		var err error
+		if b, err = d.create(ctx, s.GetSize()); err != nil {
+			return err
+		}
+	}
+	storage, reqActualPath, err := op.GetStorageAndActualPath(stdpath.Join(b, dstDir.GetPath()))
+	if err != nil {
+		return err
+	}
+	return op.Put(ctx, storage, reqActualPath, &stream.FileStream{
+		Obj:      s,
+		Mimetype: s.GetMimetype(),
+		Reader:   s,
+	}, up)
+}
+
+func (d *Union) PutURL(ctx context.Context, dstDir model.Obj, name, url string) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	b, err := d.create(ctx, 0)
+	if err != nil {
+		return err
+	}
+	return fs.PutURL(ctx, stdpath.Join(b, dstDir.GetPath()), name, url)
+}
+
+func (d *Union) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var ret model.StorageDetails
+	counted := make(map[string]struct{})
+	for _, b := range d.branches {
+		storage, _, err := op.GetStorageAndActualPath(b)
+		if err != nil {
+			continue
+		}
+		// branches on one storage share its space
+		if _, ok := counted[storage.GetStorage().MountPath]; ok {
+			continue
+		}
+		details, err := op.GetStorageDetails(ctx, storage)
+		if err != nil {
+			continue
+		}
+		counted[storage.GetStorage().MountPath] = struct{}{}
+		ret.TotalSpace += details.TotalSpace
+		ret.FreeSpace += details.FreeSpace
+	}
+	if len(counted) == 0 {
+		return nil, errs.NotImplement
+	}
+	return &ret, nil
+}
+
+var _ driver.Driver = (*Union)(nil)
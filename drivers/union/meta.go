@@ -0,0 +1,37 @@
+package union
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+type Addition struct {
+	// one openlist path per line, earlier branches win on same-name entries
+	Branches     string `json:"branches" required:"true" type:"text" help:"One path per line, e.g. a storage mount path"`
+	CreatePolicy string `json:"create_policy" type:"select" options:"most_free_space,first_found,least_used,round_robin" default:"most_free_space" help:"Branch new files and dirs are written to"`
+	SearchPolicy string `json:"search_policy" type:"select" options:"all,first_found" default:"all" help:"Merge listings of all branches or only list the first branch having the dir"`
+	ActionPolicy string `json:"action_policy" type:"select" options:"all,first_found" default:"all" help:"Branches a rename, move or delete applies to"`
+	MinFreeSpace int    `json:"min_free_space" type:"number" default:"0" help:"Skip branches with less free space when creating. Unit: GB"`
+	Writable     bool   `json:"writable" type:"bool" default:"true"`
+}
+
+var config = driver.Config{
+	Name:             "Union",
+	LocalSort:        true,
+	NoCache:          true,
+	DefaultRoot:      "/",
+	ProxyRangeOption: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Union{
+			Addition: Addition{
+				CreatePolicy: createMostFreeSpace,
+				SearchPolicy: searchAll,
+				ActionPolicy: actionAll,
+				Writable:     true,
+			},
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package union
+
+import (
+	"context"
+	stdpath "path"
+	"sync/atomic"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+)
+
+const (
+	createMostFreeSpace = "most_free_space"
+	createFirstFound    = "first_found"
+	createLeastUsed     = "least_used"
+	createRoundRobin    = "round_robin"
+
+	searchAll        = "all"
+	searchFirstFound = "first_found"
+
+	actionAll        = "all"
+	actionFirstFound = "first_found"
+)
+
+// existing returns the branches holding path, only the first of them
+// when first is set.
+func (d *Union) existing(ctx context.Context, path string, first bool) []string {
+	var ret []string
+	for _, b := range d.branches {
+		if _, err := fs.Get(ctx, stdpath.Join(b, path), &fs.GetArgs{NoLog: true}); err != nil {
+			continue
+		}
+		ret = append(ret, b)
+		if first {
+			break
+		}
+	}
+	return ret
+}
+
+// spaces returns the details of every branch's storage, nil for the
+// branches which cannot tell.
+func (d *Union) spaces(ctx context.Context) []*model.StorageDetails {
+	ret := make([]*model.StorageDetails, len(d.branches))
+	for i, b := range d.branches {
+		storage, _, err := op.GetStorageAndActualPath(b)
+		if err != nil {
+			continue
+		}
+		details, err := op.GetStorageDetails(ctx, storage)
+		if err != nil {
+			continue
+		}
+		ret[i] = details
+	}
+	return ret
+}
+
+// create picks the branch a new object of size bytes goes to.
+func (d *Union) create(ctx context.Context, size int64) (string, error) {
+	need := uint64(d.MinFreeSpace) * utils.GB
+	if size > 0 {
+		need += uint64(size)
+	}
+	i := pickBranch(d.CreatePolicy, d.spaces(ctx), need, &d.next)
+	if i < 0 {
+		return "", errNoSpace
+	}
+	return d.branches[i], nil
+}
+
+// pickBranch applies a create policy to the branch spaces, returning -1
+// when none has need bytes free. Branches of unknown space always fit.
+func pickBranch(policy string, spaces []*model.StorageDetails, need uint64, next *atomic.Uint32) int {
+	var candidates []int
+	for i, s := range spaces {
+		if s == nil || s.FreeSpace >= need {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return -1
+	}
+	better := func(a, b *model.StorageDetails) bool {
+		if policy == createLeastUsed {
+			return a.TotalSpace-a.FreeSpace < b.TotalSpace-b.FreeSpace
+		}
+		return a.FreeSpace > b.FreeSpace
+	}
+	switch policy {
+	case createFirstFound:
+		return candidates[0]
+	case createRoundRobin:
+		return candidates[int(next.Add(1)-1)%len(candidates)]
+	default:
+		best := -1
+		for _, i := range candidates {
+			if spaces[i] != nil && (best < 0 || better(spaces[i], spaces[best])) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return candidates[0]
+		}
+		return best
+	}
+}
+
+func (d *Union) link(ctx context.Context, reqPath string, args model.LinkArgs) (*model.Link, model.Obj, error) {
+	storage, reqActualPath, err := op.GetStorageAndActualPath(reqPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !args.Redirect {
+		return op.Link(ctx, storage, reqActualPath, args)
+	}
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	if common.ShouldProxy(storage, stdpath.Base(reqPath)) {
+		return nil, obj, nil
+	}
+	return op.Link(ctx, storage, reqActualPath, args)
+}
+
+func toUnionObj(obj model.Obj) model.Obj {
+	objRes := model.Object{
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+	if thumb, ok := model.GetThumb(obj); ok {
+		return &model.ObjThumb{
+			Object: objRes,
+			Thumbnail: model.Thumbnail{
+				Thumbnail: thumb,
+			},
+		}
+	}
+	return &objRes
+}
@@ -0,0 +1,45 @@
+package union
+
+import (
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func space(total, free uint64) *model.StorageDetails {
+	return &model.StorageDetails{DiskUsage: model.DiskUsage{TotalSpace: total, FreeSpace: free}}
+}
+
+func TestPickBranch(t *testing.T) {
+	spaces := []*model.StorageDetails{space(100, 10), space(200, 60), nil, space(50, 40)}
+	cases := []struct {
+		policy string
+		need   uint64
+		want   int
+	}{
+		{createMostFreeSpace, 0, 1},
+		{createMostFreeSpace, 70, 2},
+		{createLeastUsed, 0, 3},
+		{createFirstFound, 0, 0},
+		{createFirstFound, 20, 1},
+	}
+	for _, c := range cases {
+		var next atomic.Uint32
+		if got := pickBranch(c.policy, spaces, c.need, &next); got != c.want {
+			t.Errorf("pickBranch(%s, %d) = %d, want %d", c.policy, c.need, got, c.want)
+		}
+	}
+	if got := pickBranch(createMostFreeSpace, spaces[:2], 100, new(atomic.Uint32)); got != -1 {
+		t.Errorf("pickBranch without room = %d, want -1", got)
+	}
+	var next atomic.Uint32
+	var got []int
+	for range 4 {
+		got = append(got, pickBranch(createRoundRobin, spaces, 30, &next))
+	}
+	if want := []int{1, 2, 3, 1}; !slices.Equal(got, want) {
+		t.Errorf("round robin picked %v, want %v", got, want)
+	}
+}
@@ -7,10 +7,13 @@ import (
 
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/notifier"
 )
 
 var writeNormalizedMediaNFO = UpdateMediaNfo
 
+var notifyMediaWork = notifier.NotifyWork
+
 type MediaNFOSyncOptions struct {
 	Force       bool
 	IncludeCode bool
@@ -24,35 +27,48 @@ func SyncMediaNFOs(storageID uint, source string, options MediaNFOSyncOptions) e
 
 	workErrors := make([]error, 0)
 	for index := range works {
-		work := works[index]
-		identity := MediaIdentity{
-			StorageID: work.StorageID, Source: work.Source, PrimaryDir: work.PrimaryDir, Code: work.Code,
-		}
-		title := strings.TrimSpace(work.TranslatedTitle)
-		if title == "" {
-			title = strings.TrimSpace(work.RawTitle)
-		}
-		if options.IncludeCode || title == "" {
-			title = model.BuildMediaTitle(work.Code, work.RawTitle, work.TranslatedTitle)
-		}
-		info := MediaInfo{
-			Identity: &identity,
-			Title:    title,
-			Synopsis: work.Synopsis,
-			Release:  work.ReleaseDate,
-			Actors:   []string(work.Actors),
-			Tags:     []string(work.Tags),
-		}
-		if writeErr := writeNormalizedMediaNFO(info); writeErr != nil {
-			if updateErr := db.UpdateMediaWorkNFOResult(work.ID, work.NfoVersion, writeErr.Error()); updateErr != nil {
-				workErrors = append(workErrors, fmt.Errorf("record NFO failure for %s: %w", work.Code, updateErr))
-			}
-			workErrors = append(workErrors, fmt.Errorf("write NFO for %s: %w", work.Code, writeErr))
-			continue
-		}
-		if updateErr := db.UpdateMediaWorkNFOResult(work.ID, work.MetadataVersion, ""); updateErr != nil {
-			workErrors = append(workErrors, fmt.Errorf("record NFO success for %s: %w", work.Code, updateErr))
-		}
+		workErrors = append(workErrors, syncMediaWorkNFO(works[index], options.IncludeCode)...)
 	}
 	return errors.Join(workErrors...)
 }
+
+// RewriteMediaWorkNFO writes the NFO of a single work right away, whether
+// it is stale or not, and records the result like SyncMediaNFOs.
+func RewriteMediaWorkNFO(work model.FilmWork, includeCode bool) error {
+	return errors.Join(syncMediaWorkNFO(work, includeCode)...)
+}
+
+func syncMediaWorkNFO(work model.FilmWork, includeCode bool) []error {
+	identity := MediaIdentity{
+		StorageID: work.StorageID, Source: work.Source, PrimaryDir: work.PrimaryDir, Code: work.Code,
+	}
+	title := strings.TrimSpace(work.TranslatedTitle)
+	if title == "" {
+		title = strings.TrimSpace(work.RawTitle)
+	}
+	if includeCode || title == "" {
+		title = model.BuildMediaTitle(work.Code, work.RawTitle, work.TranslatedTitle)
+	}
+	info := MediaInfo{
+		Identity: &identity,
+		Title:    title,
+		Synopsis: work.Synopsis,
+		Release:  work.ReleaseDate,
+		Actors:   []string(work.Actors),
+		Tags:     []string(work.Tags),
+	}
+	var workErrors []error
+	if writeErr := writeNormalizedMediaNFO(info); writeErr != nil {
+		if updateErr := db.UpdateMediaWorkNFOResult(work.ID, work.NfoVersion, writeErr.Error()); updateErr != nil {
+			workErrors = append(workErrors, fmt.Errorf("record NFO failure for %s: %w", work.Code, updateErr))
+		}
+		return append(workErrors, fmt.Errorf("write NFO for %s: %w", work.Code, writeErr))
+	}
+	if updateErr := db.UpdateMediaWorkNFOResult(work.ID, work.MetadataVersion, ""); updateErr != nil {
+		workErrors = append(workErrors, fmt.Errorf("record NFO success for %s: %w", work.Code, updateErr))
+	}
+	if work.NfoVersion != work.MetadataVersion {
+		notifyMediaWork(work)
+	}
+	return workErrors
+}
@@ -0,0 +1,83 @@
+// Package audit records filesystem writes and admin changes made through
+// the HTTP API, WebDAV, FTP, SFTP and S3 front-ends.
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+const pruneInterval = 24 * time.Hour
+
+var (
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+)
+
+// WithSource tags ctx with the front-end and client address of a request,
+// only tagged contexts are audited.
+func WithSource(ctx context.Context, protocol, ip string) context.Context {
+	ctx = context.WithValue(ctx, conf.AuditProtocolKey, protocol)
+	return context.WithValue(ctx, conf.ClientIPKey, ip)
+}
+
+// Fs records a filesystem write. dst is the destination of renames, moves
+// and copies and empty otherwise.
+func Fs(ctx context.Context, action, path, dst string, err error) {
+	record(ctx, &model.AuditLog{Action: action, Path: path, Dst: dst}, err)
+}
+
+// Admin records a change of storages, users, settings or sharings.
+func Admin(ctx context.Context, action, target string, err error) {
+	record(ctx, &model.AuditLog{Action: action, Target: target}, err)
+}
+
+func record(ctx context.Context, l *model.AuditLog, err error) {
+	protocol, _ := ctx.Value(conf.AuditProtocolKey).(string)
+	if protocol == "" {
+		// internal work such as scans and scheduled jobs
+		return
+	}
+	l.Protocol = protocol
+	l.IP, _ = ctx.Value(conf.ClientIPKey).(string)
+	if user, ok := ctx.Value(conf.UserKey).(*model.User); ok && user != nil {
+		l.UserID = user.ID
+		l.Username = user.Username
+	}
+	l.Result = model.AuditSucceeded
+	if err != nil {
+		l.Result = model.AuditFailed
+		l.Error = err.Error()
+	}
+	if e := db.CreateAuditLog(l); e != nil {
+		utils.Log.Errorf("[audit] failed record %s %s: %+v", l.Action, l.Path+l.Target, e)
+	}
+	prune()
+}
+
+// prune drops records past the retention at most once per pruneInterval.
+func prune() {
+	pruneMu.Lock()
+	if time.Since(lastPrune) < pruneInterval {
+		pruneMu.Unlock()
+		return
+	}
+	lastPrune = time.Now()
+	pruneMu.Unlock()
+	days := setting.GetInt(conf.AuditRetentionDays, 90)
+	if days <= 0 {
+		return
+	}
+	go func() {
+		if err := db.DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -days)); err != nil {
+			utils.Log.Warnf("[audit] failed prune audit logs: %+v", err)
+		}
+	}()
+}
@@ -9,38 +9,41 @@ import (
 	"time"
 )
 
-func QueryJavSql(spiderServer, sql string, spiderMaxWaitTime int) []string {
+func QueryJavSql(spiderServer, sql string, spiderMaxWaitTime int) ([]string, error) {
 
 	var res []string
 
-	url := fmt.Sprintf("https://jinjier.art/sql?q=%s;", sql)
+	page := spider.Page{
+		URL:     fmt.Sprintf("https://jinjier.art/sql?q=%s;", sql),
+		Ready:   spider.Selector("#execute"),
+		Timeout: time.Duration(spiderMaxWaitTime) * time.Second,
+	}
 
-	err := spider.Visit(spiderServer, url, time.Duration(spiderMaxWaitTime)*time.Second, func(wd selenium.WebDriver) {
+	err := spider.Fetch(spiderServer, page, func(wd selenium.WebDriver) error {
 
 		buttonEle, err := wd.FindElement(selenium.ByID, "execute")
 		if err != nil {
-			utils.Log.Warnf("failed to find button element: %s", err.Error())
-			return
+			return fmt.Errorf("failed to find button element: %w", err)
 		}
 
 		err = buttonEle.Click()
 		if err != nil {
-			utils.Log.Warnf("failed to click button element: %s", err.Error())
-			return
+			return fmt.Errorf("failed to click button element: %w", err)
 		}
 
-		time.Sleep(time.Duration(spiderMaxWaitTime) * time.Second)
+		err = spider.WaitFor(wd, spider.Selector("#output > *"), page.Timeout)
+		if err != nil {
+			return err
+		}
 
 		tableEle, err := wd.FindElement(selenium.ByID, "output")
 		if err != nil {
-			utils.Log.Warnf("failed to find table element: %s", err.Error())
-			return
+			return fmt.Errorf("failed to find table element: %w", err)
 		}
 
 		aElements, err := tableEle.FindElements(selenium.ByTagName, "a")
 		if err != nil {
-			utils.Log.Warnf("failed to find a elements: %s", err.Error())
-			return
+			return fmt.Errorf("failed to find a elements: %w", err)
 		}
 
 		for _, element := range aElements {
@@ -49,6 +52,7 @@ func QueryJavSql(spiderServer, sql string, spiderMaxWaitTime int) []string {
 				res = append(res, strings.ReplaceAll(href, "/search?q=", ""))
 			}
 		}
+		return nil
 
 	})
 
@@ -56,6 +60,6 @@ func QueryJavSql(spiderServer, sql string, spiderMaxWaitTime int) []string {
 		utils.Log.Warnf("failed to get jav sql info, error message: %s", err.Error())
 	}
 
-	return res
+	return res, err
 
 }
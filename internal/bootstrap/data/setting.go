@@ -86,6 +86,20 @@ func initSettings() {
 	}
 }
 
+// searchIndexOptions leaves sqlite_fts out when the database can not hold an
+// FTS5 index.
+func searchIndexOptions() string {
+	if conf.Conf.Database.Type == "sqlite3" && db.FTS5Available() {
+		return "database,database_non_full_text,sqlite_fts,bleve,meilisearch,none"
+	}
+	return "database,database_non_full_text,bleve,meilisearch,none"
+}
+
+// walkTaskThreads is the setting of the workers of a walk task.
+func walkTaskThreads(key string, c conf.TaskConfig) model.SettingItem {
+	return model.SettingItem{Key: key, Value: strconv.Itoa(c.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE}
+}
+
 func InitialSettings() []model.SettingItem {
 	var token string
 	if flags.Dev {
@@ -180,10 +194,12 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.HandleHookAfterWriting, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE},
 		{Key: conf.HandleHookRateLimit, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE},
 		{Key: conf.IgnoreSystemFiles, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `When enabled, ignores common system files during upload (.DS_Store, desktop.ini, Thumbs.db, and files starting with ._)`},
+		{Key: conf.AuditRetentionDays, Value: "90", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `Days to keep audit records, 0 keeps them forever`},
+		{Key: conf.MediaStarDir, Value: "关注演员", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `Folder of the followed stars in media storages, media servers are notified of the works below it`},
 
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
-		{Key: conf.SearchIndex, Value: "none", Type: conf.TypeSelect, Options: "database,database_non_full_text,bleve,meilisearch,none", Group: model.INDEX},
+		{Key: conf.SearchIndex, Value: "none", Type: conf.TypeSelect, Options: searchIndexOptions(), Group: model.INDEX},
 		{Key: conf.AutoUpdateIndex, Value: "false", Type: conf.TypeBool, Group: model.INDEX},
 		{Key: conf.IgnorePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
 		{Key: conf.MaxIndexDepth, Value: "20", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max depth of index`},
@@ -220,6 +236,8 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.S3AccessKeyId, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 		{Key: conf.S3SecretAccessKey, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 		{Key: conf.S3Buckets, Value: "[]", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
+		{Key: conf.S3User, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
+		{Key: conf.S3VirtualHostDomain, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 
 		// ftp settings
 		{Key: conf.FTPPublicHost, Value: "127.0.0.1", Type: conf.TypeString, Group: model.FTP, Flag: model.PRIVATE},
@@ -237,6 +255,12 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.TaskCopyThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.Copy.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.TaskDecompressDownloadThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.Decompress.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.TaskDecompressUploadThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.DecompressUpload.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
+		walkTaskThreads(conf.TaskScanThreadsNum, conf.Conf.Tasks.Scan),
+		walkTaskThreads(conf.TaskVerifyThreadsNum, conf.Conf.Tasks.Verify),
+		walkTaskThreads(conf.TaskCryptRotateThreadsNum, conf.Conf.Tasks.CryptRotate),
+		walkTaskThreads(conf.TaskChunkGCThreadsNum, conf.Conf.Tasks.ChunkGC),
+		walkTaskThreads(conf.TaskStrmExportThreadsNum, conf.Conf.Tasks.StrmExport),
+		{Key: conf.CloudPlayMaxDownloads, Value: "2", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.StreamMaxClientDownloadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.StreamMaxClientUploadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.StreamMaxServerDownloadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
@@ -16,3 +16,8 @@ func InitIndex() {
 		search.WriteProgress(progress)
 	}
 }
+
+// InitIndexQueue starts applying the index jobs queued by storage changes.
+func InitIndexQueue() {
+	search.StartQueue()
+}
@@ -0,0 +1,27 @@
+package v4_2_0
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// MigratePermissionToGrants turns the Permission bitmask of every user
+// except the admin into a role granted on "/", which keeps what they could
+// do before and lets the grant be narrowed afterwards. The admin keeps the
+// bitmask, GrantAdminPermissions still maintains it.
+func MigratePermissionToGrants() {
+	users, _, err := op.GetUsers(1, -1)
+	if err != nil {
+		utils.Log.Errorf("[migrate permission to grants] failed get users: %v", err)
+		return
+	}
+	for i := range users {
+		user := users[i]
+		if user.IsAdmin() || user.Permission == 0 {
+			continue
+		}
+		if err := op.MigrateUserPermission(&user); err != nil {
+			utils.Log.Errorf("[migrate permission to grants] failed migrate user %s: %v", user.Username, err)
+		}
+	}
+}
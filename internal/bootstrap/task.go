@@ -1,6 +1,9 @@
 package bootstrap
 
 import (
+	"github.com/OpenListTeam/OpenList/v4/drivers/chunk"
+	"github.com/OpenListTeam/OpenList/v4/drivers/crypt"
+	"github.com/OpenListTeam/OpenList/v4/drivers/strm"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
@@ -17,6 +20,16 @@ func taskFilterNegative(num int) int64 {
 	return int64(num)
 }
 
+// newWalkTaskManager returns the manager of a walk task, its workers follow
+// the setting key. Walk tasks will not support persist.
+func newWalkTaskManager[T tache.Task](key string, c conf.TaskConfig) *tache.Manager[T] {
+	m := tache.NewManager[T](tache.WithWorks(setting.GetInt(key, c.Workers)))
+	op.RegisterSettingChangingCallback(func() {
+		m.SetWorkersNumActive(taskFilterNegative(setting.GetInt(key, c.Workers)))
+	})
+	return m
+}
+
 func InitTaskManager() {
 	fs.UploadTaskManager = tache.NewManager[*fs.UploadTask](tache.WithWorks(setting.GetInt(conf.TaskUploadThreadsNum, conf.Conf.Tasks.Upload.Workers)), tache.WithMaxRetry(conf.Conf.Tasks.Upload.MaxRetry)) //upload will not support persist
 	op.RegisterSettingChangingCallback(func() {
@@ -49,4 +62,9 @@ func InitTaskManager() {
 	op.RegisterSettingChangingCallback(func() {
 		fs.ArchiveContentUploadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)))
 	})
+	fs.ScanTaskManager = newWalkTaskManager[*fs.ScanTask](conf.TaskScanThreadsNum, conf.Conf.Tasks.Scan)
+	fs.VerifyTaskManager = newWalkTaskManager[*fs.VerifyTask](conf.TaskVerifyThreadsNum, conf.Conf.Tasks.Verify)
+	crypt.RotateTaskManager = newWalkTaskManager[*crypt.RotateTask](conf.TaskCryptRotateThreadsNum, conf.Conf.Tasks.CryptRotate)
+	chunk.GCTaskManager = newWalkTaskManager[*chunk.GCTask](conf.TaskChunkGCThreadsNum, conf.Conf.Tasks.ChunkGC)
+	strm.ExportTaskManager = newWalkTaskManager[*strm.ExportTask](conf.TaskStrmExportThreadsNum, conf.Conf.Tasks.StrmExport)
 }
@@ -25,6 +25,12 @@ type Meilisearch struct {
 	Index  string `json:"index" env:"INDEX"`
 }
 
+type SqliteFTS struct {
+	// Tokenizer is trigram, which matches substrings of CJK names as well,
+	// or unicode61, which matches word prefixes
+	Tokenizer string `json:"tokenizer" env:"TOKENIZER"`
+}
+
 type Scheme struct {
 	Address      string `json:"address" env:"ADDR"`
 	HttpPort     int    `json:"http_port" env:"HTTP_PORT"`
@@ -73,6 +79,11 @@ type TasksConfig struct {
 	Move               TaskConfig `json:"move" envPrefix:"MOVE_"`
 	Decompress         TaskConfig `json:"decompress" envPrefix:"DECOMPRESS_"`
 	DecompressUpload   TaskConfig `json:"decompress_upload" envPrefix:"DECOMPRESS_UPLOAD_"`
+	Scan               TaskConfig `json:"scan" envPrefix:"SCAN_"`
+	Verify             TaskConfig `json:"verify" envPrefix:"VERIFY_"`
+	CryptRotate        TaskConfig `json:"crypt_rotate" envPrefix:"CRYPT_ROTATE_"`
+	ChunkGC            TaskConfig `json:"chunk_gc" envPrefix:"CHUNK_GC_"`
+	StrmExport         TaskConfig `json:"strm_export" envPrefix:"STRM_EXPORT_"`
 	AllowRetryCanceled bool       `json:"allow_retry_canceled" env:"ALLOW_RETRY_CANCELED"`
 }
 
@@ -114,6 +125,7 @@ type Config struct {
 	TokenExpiresIn        int         `json:"token_expires_in" env:"TOKEN_EXPIRES_IN"`
 	Database              Database    `json:"database" envPrefix:"DB_"`
 	Meilisearch           Meilisearch `json:"meilisearch" envPrefix:"MEILISEARCH_"`
+	SqliteFTS             SqliteFTS   `json:"sqlite_fts" envPrefix:"SQLITE_FTS_"`
 	Scheme                Scheme      `json:"scheme"`
 	TempDir               string      `json:"temp_dir" env:"TEMP_DIR"`
 	BleveDir              string      `json:"bleve_dir" env:"BLEVE_DIR"`
@@ -162,6 +174,9 @@ func DefaultConfig(dataDir string) *Config {
 			Host:  "http://localhost:7700",
 			Index: "openlist",
 		},
+		SqliteFTS: SqliteFTS{
+			Tokenizer: "trigram",
+		},
 		BleveDir: indexDir,
 		Log: LogConfig{
 			Enable:     true,
@@ -216,6 +231,21 @@ func DefaultConfig(dataDir string) *Config {
 				Workers:  5,
 				MaxRetry: 2,
 			},
+			Scan: TaskConfig{
+				Workers: 3,
+			},
+			Verify: TaskConfig{
+				Workers: 1,
+			},
+			CryptRotate: TaskConfig{
+				Workers: 1,
+			},
+			ChunkGC: TaskConfig{
+				Workers: 1,
+			},
+			StrmExport: TaskConfig{
+				Workers: 1,
+			},
 			AllowRetryCanceled: false,
 		},
 		Cors: Cors{
@@ -61,6 +61,8 @@ const (
 	HandleHookAfterWriting  = "handle_hook_after_writing"
 	HandleHookRateLimit     = "handle_hook_rate_limit"
 	IgnoreSystemFiles       = "ignore_system_files"
+	AuditRetentionDays      = "audit_retention_days"
+	MediaStarDir            = "media_star_dir"
 
 	// index
 	SearchIndex     = "search_index"
@@ -94,6 +96,9 @@ const (
 	// thunder_browser
 	ThunderBrowserTempDir = "thunder_browser_temp_dir"
 
+	// cloud play
+	CloudPlayMaxDownloads = "cloud_play_max_downloads"
+
 	// single
 	Token         = "token"
 	IndexProgress = "index_progress"
@@ -126,9 +131,11 @@ const (
 	LdapLoginTips         = "ldap_login_tips"
 
 	// s3
-	S3Buckets         = "s3_buckets"
-	S3AccessKeyId     = "s3_access_key_id"
-	S3SecretAccessKey = "s3_secret_access_key"
+	S3Buckets           = "s3_buckets"
+	S3AccessKeyId       = "s3_access_key_id"
+	S3SecretAccessKey   = "s3_secret_access_key"
+	S3User              = "s3_user"
+	S3VirtualHostDomain = "s3_virtual_host_domain"
 
 	// qbittorrent
 	QbittorrentUrl      = "qbittorrent_url"
@@ -155,6 +162,11 @@ const (
 	TaskMoveThreadsNum                    = "move_task_threads_num"
 	TaskDecompressDownloadThreadsNum      = "decompress_download_task_threads_num"
 	TaskDecompressUploadThreadsNum        = "decompress_upload_task_threads_num"
+	TaskScanThreadsNum                    = "scan_task_threads_num"
+	TaskVerifyThreadsNum                  = "verify_task_threads_num"
+	TaskCryptRotateThreadsNum             = "crypt_rotate_task_threads_num"
+	TaskChunkGCThreadsNum                 = "chunk_gc_task_threads_num"
+	TaskStrmExportThreadsNum              = "strm_export_task_threads_num"
 	StreamMaxClientDownloadSpeed          = "max_client_download_speed"
 	StreamMaxClientUploadSpeed            = "max_client_upload_speed"
 	StreamMaxServerDownloadSpeed          = "max_server_download_speed"
@@ -166,6 +178,14 @@ const (
 	OpenAiTranslatePromote = "open_ai_translate_promote"
 	OpenAiTranslateModel   = "open_ai_translate_model"
 	OpenAiExtraBody        = "open_ai_extra_body"
+
+	// translator
+	TranslateProvider = "translate_provider"
+	OllamaUrl         = "ollama_url"
+	OllamaModel       = "ollama_model"
+	DeepLUrl          = "deepl_url"
+	DeepLApiKey       = "deepl_api_key"
+	DeepLTargetLang   = "deepl_target_lang"
 )
 
 const (
@@ -195,4 +215,5 @@ const (
 	UserAgentKey
 	PathKey
 	SharingIDKey
+	AuditProtocolKey
 )
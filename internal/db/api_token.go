@@ -0,0 +1,49 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateAPIToken(t *model.APIToken) error {
+	return errors.WithStack(db.Create(t).Error)
+}
+
+func GetAPITokensByUserId(userID uint) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	if err := db.Where("user_id = ?", userID).Order(columnName("id")).Find(&tokens).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find api tokens")
+	}
+	return tokens, nil
+}
+
+func GetAPITokenByHash(hash string) (*model.APIToken, error) {
+	var t model.APIToken
+	if err := db.Where("hash = ?", hash).First(&t).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get api token")
+	}
+	return &t, nil
+}
+
+// DeleteAPIToken removes a token of the given user, it is a no-op for tokens
+// of other users.
+func DeleteAPIToken(id, userID uint) error {
+	res := db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.APIToken{})
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("api token not found")
+	}
+	return nil
+}
+
+func DeleteAPITokensByUserId(userID uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userID).Delete(&model.APIToken{}).Error)
+}
+
+func UpdateAPITokenLastUsed(id uint, t time.Time) error {
+	return errors.WithStack(db.Model(&model.APIToken{}).Where("id = ?", id).Update("last_used_at", t).Error)
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateAuditLog(l *model.AuditLog) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+type AuditLogFilter struct {
+	Username string
+	Protocol string
+	Action   string
+	// Path matches records whose path or destination is at or below it
+	Path   string
+	Result string
+	Since  *time.Time
+	Until  *time.Time
+}
+
+// GetAuditLogs lists audit records newest first.
+func GetAuditLogs(f AuditLogFilter, pageIndex, pageSize int) (logs []model.AuditLog, count int64, err error) {
+	auditDB := db.Model(&model.AuditLog{})
+	if f.Username != "" {
+		auditDB = auditDB.Where("username = ?", f.Username)
+	}
+	if f.Protocol != "" {
+		auditDB = auditDB.Where("protocol = ?", f.Protocol)
+	}
+	if f.Action != "" {
+		auditDB = auditDB.Where("action = ?", f.Action)
+	}
+	if f.Result != "" {
+		auditDB = auditDB.Where("result = ?", f.Result)
+	}
+	if f.Path != "" && f.Path != "/" {
+		auditDB = auditDB.Where("path = ? OR path LIKE ? OR dst = ? OR dst LIKE ?", f.Path, f.Path+"/%", f.Path, f.Path+"/%")
+	}
+	if f.Since != nil {
+		auditDB = auditDB.Where("created_at >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		auditDB = auditDB.Where("created_at < ?", *f.Until)
+	}
+	if err = auditDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	if err = auditDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+func DeleteAuditLogsBefore(t time.Time) error {
+	return errors.WithStack(db.Where("created_at < ?", t).Delete(&model.AuditLog{}).Error)
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestGetAuditLogsFilters(t *testing.T) {
+	t.Cleanup(func() {
+		db.Where("1 = 1").Delete(&model.AuditLog{})
+	})
+	old := time.Now().AddDate(0, 0, -100)
+	logs := []*model.AuditLog{
+		{CreatedAt: old, Username: "alice", Protocol: model.AuditProtocolHTTP, Action: model.AuditRemove, Path: "/media/a.mkv", Result: model.AuditSucceeded},
+		{Username: "alice", Protocol: model.AuditProtocolWebDAV, Action: model.AuditMove, Path: "/inbox/b.mkv", Dst: "/media/b.mkv", Result: model.AuditSucceeded},
+		{Username: "bob", Protocol: model.AuditProtocolFTP, Action: model.AuditUpload, Path: "/mediaserver/c", Result: model.AuditFailed},
+		{Username: "admin", Protocol: model.AuditProtocolHTTP, Action: model.AuditSettingSave, Target: "site_title", Result: model.AuditSucceeded},
+	}
+	for _, l := range logs {
+		if err := CreateAuditLog(l); err != nil {
+			t.Fatalf("create audit log: %v", err)
+		}
+	}
+
+	got, total, err := GetAuditLogs(AuditLogFilter{Path: "/media"}, 1, 10)
+	if err != nil {
+		t.Fatalf("get audit logs: %v", err)
+	}
+	if total != 2 || len(got) != 2 {
+		t.Fatalf("path filter: got %d records, total %d, want 2", len(got), total)
+	}
+	if got[0].ID != logs[1].ID {
+		t.Fatalf("want newest first, got id %d", got[0].ID)
+	}
+
+	_, total, err = GetAuditLogs(AuditLogFilter{Username: "alice", Protocol: model.AuditProtocolWebDAV}, 1, 10)
+	if err != nil || total != 1 {
+		t.Fatalf("user and protocol filter: total %d, err %v", total, err)
+	}
+	_, total, err = GetAuditLogs(AuditLogFilter{Result: model.AuditFailed}, 1, 10)
+	if err != nil || total != 1 {
+		t.Fatalf("result filter: total %d, err %v", total, err)
+	}
+
+	if err := DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -90)); err != nil {
+		t.Fatalf("prune audit logs: %v", err)
+	}
+	_, total, err = GetAuditLogs(AuditLogFilter{}, 1, 10)
+	if err != nil || total != 3 {
+		t.Fatalf("after prune: total %d, err %v", total, err)
+	}
+}
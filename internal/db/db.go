@@ -11,7 +11,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) error {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.Film), new(model.MissedFilm), new(model.MagnetCache), new(model.Actor), new(model.VirtualFile), new(model.Replacement), new(model.TaskItem), new(model.SSHPublicKey), new(model.MovedItem), new(model.SharingDB), new(model.FilmWork), new(model.FilmFile), new(model.SourceMagnet), new(model.CacheList))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.IndexJob), new(model.Film), new(model.MissedFilm), new(model.MagnetCache), new(model.Actor), new(model.VirtualFile), new(model.Replacement), new(model.TaskItem), new(model.SSHPublicKey), new(model.MovedItem), new(model.SharingDB), new(model.FilmWork), new(model.FilmFile), new(model.SourceMagnet), new(model.CacheList), new(model.CacheChange), new(model.SyncCheckpoint), new(model.SyncReport), new(model.MediaNotifier), new(model.MediaNotifyDelivery), new(model.MediaJob), new(model.MediaJobRun), new(model.TranslationCache), new(model.Role), new(model.UserGroup), new(model.UserGroupMember), new(model.PathGrant), new(model.APIToken), new(model.AuditLog), new(model.TrafficLimit), new(model.TrafficUsage), new(model.S3Credential), new(model.ChunkRef), new(model.StrmFile))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+// CreateIndexJob appends a job to the index queue. A sync job is dropped when
+// a sync job of the same path is pending, it lists the path when applied.
+func CreateIndexJob(job *model.IndexJob) error {
+	if job.Op == model.IndexJobSync {
+		var count int64
+		err := db.Model(&model.IndexJob{}).Where(&model.IndexJob{Op: model.IndexJobSync, Path: job.Path}).Count(&count).Error
+		if err != nil || count > 0 {
+			return errors.WithStack(err)
+		}
+	}
+	return errors.WithStack(db.Create(job).Error)
+}
+
+// GetIndexJobs returns the oldest queued jobs in the order they were queued.
+func GetIndexJobs(limit int) ([]model.IndexJob, error) {
+	var jobs []model.IndexJob
+	if err := db.Order("id").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return jobs, nil
+}
+
+func CountIndexJobs() (int64, error) {
+	var count int64
+	err := db.Model(&model.IndexJob{}).Count(&count).Error
+	return count, errors.WithStack(err)
+}
+
+func DeleteIndexJob(id uint) error {
+	return errors.WithStack(db.Delete(&model.IndexJob{}, id).Error)
+}
+
+// FailIndexJob records a failed attempt of the job, it stays queued.
+func FailIndexJob(id uint, attempts int, message string) error {
+	return errors.WithStack(db.Model(&model.IndexJob{}).Where("id = ?", id).Updates(map[string]any{
+		"attempts":   attempts,
+		"last_error": message,
+	}).Error)
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestIndexJobQueueKeepsOrderAndCoalescesSync(t *testing.T) {
+	t.Cleanup(func() { db.Where("1 = 1").Delete(&model.IndexJob{}) })
+	jobs := []*model.IndexJob{
+		{Op: model.IndexJobSync, Path: "/a"},
+		{Op: model.IndexJobDelete, Path: "/a/x"},
+		{Op: model.IndexJobSync, Path: "/b"},
+		{Op: model.IndexJobSync, Path: "/a"},
+		{Op: model.IndexJobUpsert, Path: "/b", Nodes: []model.SearchNode{{Parent: "/b", Name: "new", Size: 3}}},
+	}
+	for _, job := range jobs {
+		if err := CreateIndexJob(job); err != nil {
+			t.Fatalf("create index job: %v", err)
+		}
+	}
+
+	queued, err := GetIndexJobs(10)
+	if err != nil {
+		t.Fatalf("get index jobs: %v", err)
+	}
+	var got []string
+	for _, job := range queued {
+		got = append(got, job.Op+" "+job.Path)
+	}
+	want := []string{"sync /a", "delete /a/x", "sync /b", "upsert /b"}
+	if len(got) != len(want) {
+		t.Fatalf("queued = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("queued = %v, want %v", got, want)
+		}
+	}
+	if nodes := queued[3].Nodes; len(nodes) != 1 || nodes[0].Name != "new" || nodes[0].Size != 3 {
+		t.Fatalf("upsert nodes = %+v", nodes)
+	}
+
+	if err = FailIndexJob(queued[0].ID, 1, "boom"); err != nil {
+		t.Fatalf("fail index job: %v", err)
+	}
+	if err = DeleteIndexJob(queued[1].ID); err != nil {
+		t.Fatalf("delete index job: %v", err)
+	}
+	count, err := CountIndexJobs()
+	if err != nil || count != 3 {
+		t.Fatalf("count = %d, %v, want 3", count, err)
+	}
+	queued, err = GetIndexJobs(1)
+	if err != nil || len(queued) != 1 {
+		t.Fatalf("get index jobs = %v, %v", queued, err)
+	}
+	if queued[0].Attempts != 1 || queued[0].LastError != "boom" {
+		t.Fatalf("failed job = %+v", queued[0])
+	}
+}
@@ -12,7 +12,8 @@ import (
 )
 
 func UpsertDiscoveredWork(work *model.FilmWork) error {
-	return db.Transaction(func(tx *gorm.DB) error {
+	var updated map[string]interface{}
+	err := db.Transaction(func(tx *gorm.DB) error {
 		var existing model.FilmWork
 		err := tx.Where("storage_id = ? AND source = ? AND code = ?", work.StorageID, work.Source, work.Code).First(&existing).Error
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -37,23 +38,29 @@ func UpsertDiscoveredWork(work *model.FilmWork) error {
 		}
 
 		rawTitle := existing.RawTitle
-		if work.RawTitle != "" && work.RawTitle != existing.RawTitle {
+		if work.RawTitle != "" && work.RawTitle != existing.RawTitle && !existing.IsFieldLocked(model.MediaFieldRawTitle) {
 			updates["raw_title"] = work.RawTitle
 			rawTitle = work.RawTitle
 		}
-		if work.ImageURL != "" && work.ImageURL != existing.ImageURL {
+		if work.ImageURL != "" && work.ImageURL != existing.ImageURL && !existing.IsFieldLocked(model.MediaFieldImageURL) {
 			updates["image_url"] = work.ImageURL
 		}
 		releaseDate := existing.ReleaseDate
-		if !work.ReleaseDate.IsZero() && !work.ReleaseDate.Equal(existing.ReleaseDate) {
+		if !work.ReleaseDate.IsZero() && !work.ReleaseDate.Equal(existing.ReleaseDate) && !existing.IsFieldLocked(model.MediaFieldReleaseDate) {
 			updates["release_date"] = work.ReleaseDate
 			releaseDate = work.ReleaseDate
 		}
-		actors := stableUnionStringArrays(existing.Actors, work.Actors)
+		actors := existing.Actors
+		if !existing.IsFieldLocked(model.MediaFieldActors) {
+			actors = stableUnionStringArrays(existing.Actors, work.Actors)
+		}
 		if !slices.Equal(existing.Actors, actors) {
 			updates["actors"] = actors
 		}
-		tags := stableUnionStringArrays(existing.Tags, work.Tags)
+		tags := existing.Tags
+		if !existing.IsFieldLocked(model.MediaFieldTags) {
+			tags = stableUnionStringArrays(existing.Tags, work.Tags)
+		}
 		if !slices.Equal(existing.Tags, tags) {
 			updates["tags"] = tags
 		}
@@ -72,8 +79,13 @@ func UpsertDiscoveredWork(work *model.FilmWork) error {
 		if metadataChanged {
 			updates["metadata_version"] = gorm.Expr("metadata_version + 1")
 		}
+		updated = updates
 		return tx.Model(&model.FilmWork{}).Where("id = ?", existing.ID).Updates(updates).Error
 	})
+	if err == nil && updated != nil {
+		callMediaWorkHooks(work.ID, updated)
+	}
+	return err
 }
 
 func stableUnionStringArrays(current, incoming model.StringArray) model.StringArray {
@@ -126,6 +138,15 @@ func GetFilmWork(id uint) (model.FilmWork, error) {
 	return work, err
 }
 
+func GetFilmWorksByIDs(ids []uint) ([]model.FilmWork, error) {
+	var works []model.FilmWork
+	if len(ids) == 0 {
+		return works, nil
+	}
+	err := db.Where("id IN ?", ids).Find(&works).Error
+	return works, err
+}
+
 func GetFilmWorkByIdentity(storageID uint, source, code string) (model.FilmWork, error) {
 	var work model.FilmWork
 	err := db.Where("storage_id = ? AND source = ? AND code = ?", storageID, source, code).First(&work).Error
@@ -133,14 +154,13 @@ func GetFilmWorkByIdentity(storageID uint, source, code string) (model.FilmWork,
 }
 
 func UpdateMediaWorkDetails(workID uint, translatedTitle, synopsis string, actors, tags model.StringArray) error {
-	updates := map[string]interface{}{
+	return updateUnlockedMediaWork(workID, map[string]interface{}{
 		"translated_title": translatedTitle,
 		"synopsis":         synopsis,
 		"actors":           actors,
 		"tags":             tags,
 		"metadata_version": gorm.Expr("metadata_version + 1"),
-	}
-	return db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(updates).Error
+	})
 }
 
 func GetFilmFile(id uint) (model.FilmFile, error) {
@@ -191,6 +211,39 @@ func ListFilmFiles(workID uint) ([]model.FilmFile, error) {
 	return files, err
 }
 
+// QueryRecentFilmFiles returns the first part of the works added since the
+// given time, newest first.
+func QueryRecentFilmFiles(storageID uint, source string, since time.Time, limit int) ([]model.FilmFileWithWork, error) {
+	var works []model.FilmWork
+	query := db.Where("storage_id = ? AND source = ? AND created_at >= ?", storageID, source, since).
+		Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&works).Error; err != nil || len(works) == 0 {
+		return []model.FilmFileWithWork{}, err
+	}
+	workIDs := make([]uint, len(works))
+	for i, work := range works {
+		workIDs[i] = work.ID
+	}
+	var files []model.FilmFile
+	if err := db.Where("work_id IN ? AND part_index = ?", workIDs, 1).Find(&files).Error; err != nil {
+		return nil, err
+	}
+	fileByWork := make(map[uint]model.FilmFile, len(files))
+	for _, file := range files {
+		fileByWork[file.WorkID] = file
+	}
+	result := make([]model.FilmFileWithWork, 0, len(files))
+	for _, work := range works {
+		if file, ok := fileByWork[work.ID]; ok {
+			result = append(result, model.FilmFileWithWork{FilmFile: file, Work: work})
+		}
+	}
+	return result, nil
+}
+
 func ListFilmFilesWithWorks(storageID uint, source, primaryDir string) ([]model.FilmFileWithWork, error) {
 	works, err := ListFilmWorks(storageID, source, primaryDir)
 	if err != nil || len(works) == 0 {
@@ -296,14 +349,14 @@ func QueryTranslationMediaWorks(source string, translationVersion uint, limit in
 }
 
 func UpdateMediaWorkTranslation(workID uint, translatedTitle string, translationVersion uint) error {
-	return db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(map[string]interface{}{
+	return updateUnlockedMediaWork(workID, map[string]interface{}{
 		"translated_title":          translatedTitle,
 		"translation_status":        "success",
 		"translation_next_retry_at": nil,
 		"translation_last_error":    "",
 		"translation_version":       translationVersion,
 		"metadata_version":          gorm.Expr("metadata_version + 1"),
-	}).Error
+	})
 }
 
 func UpdateMediaWorkTranslationRetry(workID uint, nextRetryAt time.Time, lastError string, translationVersion uint) error {
@@ -331,14 +384,14 @@ func QueryEmptySynopsisMediaWorks(source string, scanInterval time.Duration, lim
 }
 
 func UpdateMediaWorkSynopsis(workID uint, synopsis string) error {
-	return db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(map[string]interface{}{
+	return updateUnlockedMediaWork(workID, map[string]interface{}{
 		"synopsis":               synopsis,
 		"synopsis_scan_at":       time.Now(),
 		"synopsis_next_retry_at": nil,
 		"synopsis_last_error":    "",
 		"synopsis_excluded":      false,
 		"metadata_version":       gorm.Expr("metadata_version + 1"),
-	}).Error
+	})
 }
 
 func UpdateMediaWorkSynopsisRetry(workID uint, nextRetryAt time.Time, lastError string) error {
@@ -405,9 +458,12 @@ func QueryPendingMediaWorks(source string, stages MediaWorkScanStages, limit int
 
 func UpdateMediaWorkTags(workID uint, tags model.StringArray, tagVersion uint) error {
 	var existing model.FilmWork
-	if err := db.Select("tags").First(&existing, workID).Error; err != nil {
+	if err := db.Select("tags", "locked_fields").First(&existing, workID).Error; err != nil {
 		return err
 	}
+	if existing.IsFieldLocked(model.MediaFieldTags) {
+		tags = existing.Tags
+	}
 	tags = stableUnionStringArrays(existing.Tags, tags)
 	updates := map[string]interface{}{
 		"tag_scan_at":       time.Now(),
@@ -419,14 +475,18 @@ func UpdateMediaWorkTags(workID uint, tags model.StringArray, tagVersion uint) e
 		updates["tags"] = tags
 		updates["metadata_version"] = gorm.Expr("metadata_version + 1")
 	}
-	return db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(updates).Error
+	if err := db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(updates).Error; err != nil {
+		return err
+	}
+	callMediaWorkHooks(workID, updates)
+	return nil
 }
 
 func MergePendingMediaWorkTags(workID uint, tags model.StringArray) error {
-	return db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(map[string]interface{}{
+	return updateUnlockedMediaWork(workID, map[string]interface{}{
 		"tags":             tags,
 		"metadata_version": gorm.Expr("metadata_version + 1"),
-	}).Error
+	})
 }
 
 func UpdateMediaWorkTagRetry(workID uint, nextRetryAt time.Time, lastError string) error {
@@ -439,9 +499,12 @@ func UpdateMediaWorkTagRetry(workID uint, nextRetryAt time.Time, lastError strin
 
 func UpdateMediaWorkActors(workID uint, actors model.StringArray) error {
 	var existing model.FilmWork
-	if err := db.Select("actors").First(&existing, workID).Error; err != nil {
+	if err := db.Select("actors", "locked_fields").First(&existing, workID).Error; err != nil {
 		return err
 	}
+	if existing.IsFieldLocked(model.MediaFieldActors) {
+		actors = existing.Actors
+	}
 	actors = stableUnionStringArrays(existing.Actors, actors)
 	updates := map[string]interface{}{
 		"actor_scan_at":       time.Now(),
@@ -452,7 +515,11 @@ func UpdateMediaWorkActors(workID uint, actors model.StringArray) error {
 		updates["actors"] = actors
 		updates["metadata_version"] = gorm.Expr("metadata_version + 1")
 	}
-	return db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(updates).Error
+	if err := db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(updates).Error; err != nil {
+		return err
+	}
+	callMediaWorkHooks(workID, updates)
+	return nil
 }
 
 func UpdateMediaWorkActorRetry(workID uint, nextRetryAt time.Time, lastError string) error {
@@ -485,13 +552,13 @@ func QueryReleaseMediaWorks(source string, limit int) ([]model.FilmWork, error)
 }
 
 func UpdateMediaWorkRelease(workID uint, releaseDate time.Time) error {
-	return db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(map[string]interface{}{
+	return updateUnlockedMediaWork(workID, map[string]interface{}{
 		"release_date":          releaseDate,
 		"release_scan_at":       time.Now(),
 		"release_next_retry_at": nil,
 		"release_last_error":    "",
 		"metadata_version":      gorm.Expr("metadata_version + 1"),
-	}).Error
+	})
 }
 
 func UpdateMediaWorkReleaseRetry(workID uint, nextRetryAt time.Time, lastError string) error {
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/gorm"
+)
+
+// MediaWorkFilter narrows SearchFilmWorks, empty fields match everything.
+type MediaWorkFilter struct {
+	StorageID uint
+	Source    string
+	// Code matches a substring of the code, case-insensitively
+	Code  string
+	Actor string
+	Tag   string
+}
+
+func SearchFilmWorks(filter MediaWorkFilter, pageIndex, pageSize int) ([]model.FilmWork, int64, error) {
+	query := db.Model(&model.FilmWork{})
+	if filter.StorageID != 0 {
+		query = query.Where("storage_id = ?", filter.StorageID)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if code := strings.TrimSpace(filter.Code); code != "" {
+		query = query.Where("UPPER(code) LIKE ?", "%"+strings.ToUpper(code)+"%")
+	}
+	if actor := strings.TrimSpace(filter.Actor); actor != "" {
+		query = query.Where("actors LIKE ?", jsonArrayElementPattern(actor))
+	}
+	if tag := strings.TrimSpace(filter.Tag); tag != "" {
+		query = query.Where("tags LIKE ?", jsonArrayElementPattern(tag))
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, fmt.Errorf("count film works: %w", err)
+	}
+	var works []model.FilmWork
+	if err := query.Order("id DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&works).Error; err != nil {
+		return nil, 0, fmt.Errorf("find film works: %w", err)
+	}
+	return works, count, nil
+}
+
+// jsonArrayElementPattern matches a whole element of a StringArray column,
+// encoded the same way the json serializer stores it.
+func jsonArrayElementPattern(value string) string {
+	encoded, _ := json.Marshal(value)
+	return "%" + string(encoded) + "%"
+}
+
+// MediaWorkEdit is a manual change of a FilmWork.
+type MediaWorkEdit struct {
+	// Fields maps lockable columns to their new values
+	Fields map[string]interface{}
+	// Locks replaces the locked fields when set, otherwise the edited fields
+	// are locked in addition to the existing locks
+	Locks *model.StringArray
+}
+
+// EditMediaWork applies a manual edit. Edited fields stay as they are when
+// scrapers later call UpsertDiscoveredWork or the UpdateMediaWork* helpers,
+// until they are unlocked.
+func EditMediaWork(workID uint, edit MediaWorkEdit) (model.FilmWork, error) {
+	for field := range edit.Fields {
+		if !slices.Contains(model.MediaLockableFields, field) {
+			return model.FilmWork{}, fmt.Errorf("field %q can not be edited", field)
+		}
+	}
+	if edit.Locks != nil {
+		for _, field := range *edit.Locks {
+			if !slices.Contains(model.MediaLockableFields, field) {
+				return model.FilmWork{}, fmt.Errorf("field %q can not be locked", field)
+			}
+		}
+	}
+
+	var work model.FilmWork
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&work, workID).Error; err != nil {
+			return err
+		}
+		updates := make(map[string]interface{}, len(edit.Fields)+2)
+		for field, value := range edit.Fields {
+			updates[field] = value
+		}
+		locks := work.LockedFields
+		if edit.Locks != nil {
+			locks = *edit.Locks
+		} else {
+			for field := range edit.Fields {
+				locks = append(locks, field)
+			}
+		}
+		locks = stableUnionStringArrays(nil, locks)
+		slices.Sort(locks)
+		if !slices.Equal(locks, work.LockedFields) {
+			updates["locked_fields"] = locks
+		}
+		if len(edit.Fields) > 0 {
+			updates["metadata_version"] = gorm.Expr("metadata_version + 1")
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.First(&work, workID).Error
+	})
+	if err == nil {
+		callMediaWorkHooks(workID, edit.Fields)
+	}
+	return work, err
+}
+
+// updateUnlockedMediaWork is used by scraper updates, it leaves out the
+// locked columns and only bumps metadata_version when something is left.
+func updateUnlockedMediaWork(workID uint, updates map[string]interface{}) error {
+	var existing model.FilmWork
+	err := db.Select("id", "locked_fields").First(&existing, workID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if len(existing.LockedFields) > 0 {
+		for _, field := range existing.LockedFields {
+			delete(updates, field)
+		}
+		changed := false
+		for _, field := range model.MediaLockableFields {
+			if _, ok := updates[field]; ok {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			delete(updates, "metadata_version")
+		}
+	}
+	if err := db.Model(&model.FilmWork{}).Where("id = ?", workID).Updates(updates).Error; err != nil {
+		return err
+	}
+	callMediaWorkHooks(workID, updates)
+	return nil
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestEditMediaWorkLocksSurviveUpsertDiscoveredWork(t *testing.T) {
+	setupMediaRepositoryTestDB(t)
+
+	work := createMediaTestWork(t, 1, "javdb", "ABP-123", "个人收藏")
+	release := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	edited, err := EditMediaWork(work.ID, MediaWorkEdit{Fields: map[string]interface{}{
+		model.MediaFieldRawTitle:    "Fixed title",
+		model.MediaFieldReleaseDate: release,
+		model.MediaFieldActors:      model.StringArray{"Actor A"},
+	}})
+	if err != nil {
+		t.Fatalf("edit media work: %v", err)
+	}
+	wantLocks := model.StringArray{model.MediaFieldActors, model.MediaFieldRawTitle, model.MediaFieldReleaseDate}
+	if !reflect.DeepEqual(edited.LockedFields, wantLocks) {
+		t.Fatalf("locked fields = %v, want %v", edited.LockedFields, wantLocks)
+	}
+	if edited.MetadataVersion != work.MetadataVersion+1 {
+		t.Fatalf("metadata version = %d, want %d", edited.MetadataVersion, work.MetadataVersion+1)
+	}
+
+	discovered := model.FilmWork{
+		StorageID: 1, Source: "javdb", Code: "ABP-123", SourceRef: "javdb/ABP-123", PrimaryDir: "个人收藏",
+		RawTitle: "Scraped title", ReleaseDate: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Actors: model.StringArray{"Actor B"}, Tags: model.StringArray{"tag"}, ImageURL: "https://example.com/cover.jpg",
+	}
+	if err = UpsertDiscoveredWork(&discovered); err != nil {
+		t.Fatalf("upsert discovered work: %v", err)
+	}
+	got, err := GetFilmWork(work.ID)
+	if err != nil {
+		t.Fatalf("get media work: %v", err)
+	}
+	if got.RawTitle != "Fixed title" || !got.ReleaseDate.Equal(release) || !reflect.DeepEqual(got.Actors, model.StringArray{"Actor A"}) {
+		t.Fatalf("locked fields were overwritten: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Tags, model.StringArray{"tag"}) || got.ImageURL != "https://example.com/cover.jpg" {
+		t.Fatalf("unlocked fields were not updated: %+v", got)
+	}
+
+	unlocked, err := EditMediaWork(work.ID, MediaWorkEdit{Locks: &model.StringArray{}})
+	if err != nil {
+		t.Fatalf("unlock media work: %v", err)
+	}
+	if len(unlocked.LockedFields) != 0 || unlocked.MetadataVersion != got.MetadataVersion {
+		t.Fatalf("unlocked work = %+v", unlocked)
+	}
+	if err = UpsertDiscoveredWork(&discovered); err != nil {
+		t.Fatalf("upsert discovered work: %v", err)
+	}
+	if got, _ = GetFilmWork(work.ID); got.RawTitle != "Scraped title" {
+		t.Fatalf("raw title after unlock = %q", got.RawTitle)
+	}
+}
+
+func TestScraperUpdatesSkipLockedFields(t *testing.T) {
+	setupMediaRepositoryTestDB(t)
+
+	work := createMediaTestWork(t, 1, "javdb", "ABP-124", "个人收藏")
+	locked, err := EditMediaWork(work.ID, MediaWorkEdit{Fields: map[string]interface{}{
+		model.MediaFieldTranslatedTitle: "Manual title",
+		model.MediaFieldTags:            model.StringArray{"manual"},
+	}})
+	if err != nil {
+		t.Fatalf("edit media work: %v", err)
+	}
+
+	if err = UpdateMediaWorkTranslation(work.ID, "Machine title", model.CurrentTranslationVersion); err != nil {
+		t.Fatalf("update translation: %v", err)
+	}
+	if err = UpdateMediaWorkTags(work.ID, model.StringArray{"scraped"}, 2); err != nil {
+		t.Fatalf("update tags: %v", err)
+	}
+	if err = UpdateMediaWorkSynopsis(work.ID, "Synopsis"); err != nil {
+		t.Fatalf("update synopsis: %v", err)
+	}
+
+	got, err := GetFilmWork(work.ID)
+	if err != nil {
+		t.Fatalf("get media work: %v", err)
+	}
+	if got.TranslatedTitle != "Manual title" || !reflect.DeepEqual(got.Tags, model.StringArray{"manual"}) {
+		t.Fatalf("locked fields were overwritten: %+v", got)
+	}
+	if got.TranslationStatus != "success" || got.TagVersion != 2 {
+		t.Fatalf("scan state was not recorded: %+v", got)
+	}
+	if got.Synopsis != "Synopsis" || got.MetadataVersion != locked.MetadataVersion+1 {
+		t.Fatalf("synopsis = %q, metadata version = %d, want one bump from %d", got.Synopsis, got.MetadataVersion, locked.MetadataVersion)
+	}
+}
+
+func TestEditMediaWorkRejectsUnknownFields(t *testing.T) {
+	setupMediaRepositoryTestDB(t)
+
+	work := createMediaTestWork(t, 1, "javdb", "ABP-125", "个人收藏")
+	if _, err := EditMediaWork(work.ID, MediaWorkEdit{Fields: map[string]interface{}{"code": "OTHER-1"}}); err == nil {
+		t.Fatal("expected an error for a non-editable field")
+	}
+	if _, err := EditMediaWork(work.ID, MediaWorkEdit{Locks: &model.StringArray{"primary_dir"}}); err == nil {
+		t.Fatal("expected an error for a non-lockable field")
+	}
+}
+
+func TestSearchFilmWorksFilters(t *testing.T) {
+	setupMediaRepositoryTestDB(t)
+
+	first := createMediaTestWork(t, 1, "javdb", "ABP-126", "个人收藏")
+	second := createMediaTestWork(t, 2, "fc2", "FC2-PPV-1", "个人收藏")
+	createMediaTestWork(t, 1, "javdb", "SSIS-001", "个人收藏")
+	if err := UpdateMediaWorkActors(first.ID, model.StringArray{"演员 A", "Actor B"}); err != nil {
+		t.Fatalf("update actors: %v", err)
+	}
+	if err := UpdateMediaWorkTags(second.ID, model.StringArray{"字幕"}, 1); err != nil {
+		t.Fatalf("update tags: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter MediaWorkFilter
+		want   []uint
+	}{
+		{name: "storage", filter: MediaWorkFilter{StorageID: 2}, want: []uint{second.ID}},
+		{name: "source", filter: MediaWorkFilter{Source: "fc2"}, want: []uint{second.ID}},
+		{name: "code", filter: MediaWorkFilter{Code: "abp"}, want: []uint{first.ID}},
+		{name: "actor", filter: MediaWorkFilter{Actor: "演员 A"}, want: []uint{first.ID}},
+		{name: "partial actor", filter: MediaWorkFilter{Actor: "Actor"}, want: nil},
+		{name: "tag", filter: MediaWorkFilter{Tag: "字幕"}, want: []uint{second.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			works, total, err := SearchFilmWorks(tt.filter, 1, 10)
+			if err != nil {
+				t.Fatalf("search film works: %v", err)
+			}
+			var ids []uint
+			for _, work := range works {
+				ids = append(ids, work.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) || total != int64(len(tt.want)) {
+				t.Fatalf("ids = %v (total %d), want %v", ids, total, tt.want)
+			}
+		})
+	}
+}
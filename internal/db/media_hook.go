@@ -0,0 +1,39 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+var (
+	mediaWorkHooks   []func(workID uint)
+	mediaWorkHooksMu sync.RWMutex
+)
+
+// RegisterMediaWorkHook registers a hook called after the searchable fields
+// of a work changed, see model.MediaSearchFields.
+func RegisterMediaWorkHook(hook func(workID uint)) {
+	mediaWorkHooksMu.Lock()
+	defer mediaWorkHooksMu.Unlock()
+	mediaWorkHooks = append(mediaWorkHooks, hook)
+}
+
+// callMediaWorkHooks calls the hooks when updates touch a searchable field.
+func callMediaWorkHooks(workID uint, updates map[string]interface{}) {
+	changed := false
+	for _, field := range model.MediaSearchFields {
+		if _, ok := updates[field]; ok {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+	mediaWorkHooksMu.RLock()
+	defer mediaWorkHooksMu.RUnlock()
+	for _, hook := range mediaWorkHooks {
+		hook(workID)
+	}
+}
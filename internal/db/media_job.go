@@ -0,0 +1,99 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/gorm"
+)
+
+// EnsureMediaJob returns the job of a storage by name, creating it on first use.
+func EnsureMediaJob(storageID uint, source, name string) (model.MediaJob, error) {
+	var job model.MediaJob
+	err := db.Where("storage_id = ? AND name = ?", storageID, name).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		job = model.MediaJob{StorageID: storageID, Source: source, Name: name}
+		err = db.Create(&job).Error
+	}
+	return job, err
+}
+
+func GetMediaJob(id uint) (model.MediaJob, error) {
+	var job model.MediaJob
+	return job, db.First(&job, id).Error
+}
+
+// ListMediaJobs lists the jobs of a storage, storageID 0 lists all of them.
+func ListMediaJobs(storageID uint) ([]model.MediaJob, error) {
+	var jobs []model.MediaJob
+	query := db.Order("storage_id, id")
+	if storageID != 0 {
+		query = query.Where("storage_id = ?", storageID)
+	}
+	return jobs, query.Find(&jobs).Error
+}
+
+func UpdateMediaJobSchedule(id uint, interval time.Duration, nextRunAt time.Time) error {
+	return db.Model(&model.MediaJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"interval":    int64(interval / time.Second),
+		"next_run_at": nextRunAt,
+	}).Error
+}
+
+func UpdateMediaJobNextRun(id uint, nextRunAt time.Time) error {
+	return db.Model(&model.MediaJob{}).Where("id = ?", id).Update("next_run_at", nextRunAt).Error
+}
+
+func SetMediaJobPaused(id uint, paused bool) error {
+	result := db.Model(&model.MediaJob{}).Where("id = ?", id).Update("paused", paused)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func StartMediaJobRun(run *model.MediaJobRun) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.MediaJob{}).Where("id = ?", run.JobID).Update("last_start_at", run.StartAt).Error
+	})
+}
+
+// FinishMediaJobRun saves the outcome of a run and copies it onto the job.
+func FinishMediaJobRun(run *model.MediaJobRun) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(run).Updates(map[string]interface{}{
+			"end_at":    run.EndAt,
+			"processed": run.Processed,
+			"failed":    run.Failed,
+			"error":     run.Error,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.MediaJob{}).Where("id = ?", run.JobID).Updates(map[string]interface{}{
+			"last_end_at":    run.EndAt,
+			"last_processed": run.Processed,
+			"last_failed":    run.Failed,
+			"last_error":     run.Error,
+		}).Error
+	})
+}
+
+func ListMediaJobRuns(jobID uint, limit int) ([]model.MediaJobRun, error) {
+	var runs []model.MediaJobRun
+	query := db.Where("job_id = ?", jobID).Order("id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return runs, query.Find(&runs).Error
+}
+
+func DeleteMediaJobRunsBefore(before time.Time) error {
+	return db.Where("start_at < ?", before).Delete(&model.MediaJobRun{}).Error
+}
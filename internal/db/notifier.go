@@ -0,0 +1,77 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetMediaNotifiers(pageIndex, pageSize int) (notifiers []model.MediaNotifier, count int64, err error) {
+	notifierDB := db.Model(&model.MediaNotifier{})
+	if err = notifierDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get media notifiers count")
+	}
+	if err = notifierDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&notifiers).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find media notifiers")
+	}
+	return notifiers, count, nil
+}
+
+func GetEnabledMediaNotifiers() ([]model.MediaNotifier, error) {
+	var notifiers []model.MediaNotifier
+	if err := db.Where("disabled = ?", false).Order(columnName("id")).Find(&notifiers).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find enabled media notifiers")
+	}
+	return notifiers, nil
+}
+
+func GetMediaNotifierById(id uint) (*model.MediaNotifier, error) {
+	var n model.MediaNotifier
+	if err := db.First(&n, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get media notifier")
+	}
+	return &n, nil
+}
+
+func CreateMediaNotifier(n *model.MediaNotifier) error {
+	return errors.WithStack(db.Create(n).Error)
+}
+
+func UpdateMediaNotifier(n *model.MediaNotifier) error {
+	return errors.WithStack(db.Save(n).Error)
+}
+
+func DeleteMediaNotifierById(id uint) error {
+	return errors.WithStack(db.Delete(&model.MediaNotifier{}, id).Error)
+}
+
+func CreateMediaNotifyDelivery(d *model.MediaNotifyDelivery) error {
+	return errors.WithStack(db.Create(d).Error)
+}
+
+func UpdateMediaNotifyDeliveryResult(id uint, status, lastError string) error {
+	return errors.WithStack(db.Model(&model.MediaNotifyDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     status,
+		"last_error": lastError,
+	}).Error)
+}
+
+// GetMediaNotifyDeliveries lists deliveries newest first, notifierID 0 lists all of them.
+func GetMediaNotifyDeliveries(notifierID uint, pageIndex, pageSize int) (deliveries []model.MediaNotifyDelivery, count int64, err error) {
+	deliveryDB := db.Model(&model.MediaNotifyDelivery{})
+	if notifierID != 0 {
+		deliveryDB = deliveryDB.Where("notifier_id = ?", notifierID)
+	}
+	if err = deliveryDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get media notify deliveries count")
+	}
+	if err = deliveryDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find media notify deliveries")
+	}
+	return deliveries, count, nil
+}
+
+func DeleteMediaNotifyDeliveriesBefore(before time.Time) error {
+	return errors.WithStack(db.Where("created_at < ?", before).Delete(&model.MediaNotifyDelivery{}).Error)
+}
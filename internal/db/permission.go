@@ -0,0 +1,187 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetRoles() ([]model.Role, error) {
+	var roles []model.Role
+	if err := db.Order(columnName("id")).Find(&roles).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find roles")
+	}
+	return roles, nil
+}
+
+func GetRoleById(id uint) (*model.Role, error) {
+	var r model.Role
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get role")
+	}
+	return &r, nil
+}
+
+func GetRoleByName(name string) (*model.Role, error) {
+	r := model.Role{Name: name}
+	if err := db.Where(r).First(&r).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get role")
+	}
+	return &r, nil
+}
+
+func CreateRole(r *model.Role) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateRole(r *model.Role) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+// DeleteRoleById removes the role together with every grant of it.
+func DeleteRoleById(id uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&model.PathGrant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Role{}, id).Error
+	}))
+}
+
+func GetUserGroups() ([]model.UserGroup, error) {
+	var groups []model.UserGroup
+	if err := db.Order(columnName("id")).Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find user groups")
+	}
+	return groups, nil
+}
+
+func GetUserGroupById(id uint) (*model.UserGroup, error) {
+	var g model.UserGroup
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get user group")
+	}
+	return &g, nil
+}
+
+func CreateUserGroup(g *model.UserGroup) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateUserGroup(g *model.UserGroup) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+// DeleteUserGroupById removes the group, its memberships, grants and
+// traffic limits.
+func DeleteUserGroupById(id uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&model.PathGrant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&model.TrafficLimit{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&model.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.UserGroup{}, id).Error
+	}))
+}
+
+func GetUserGroupMemberIds(groupID uint) ([]uint, error) {
+	var ids []uint
+	if err := db.Model(&model.UserGroupMember{}).Where("group_id = ?", groupID).Order(columnName("user_id")).Pluck("user_id", &ids).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get user group members")
+	}
+	return ids, nil
+}
+
+// SetUserGroupMembers replaces the members of a group with userIDs.
+func SetUserGroupMembers(groupID uint, userIDs []uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupID).Delete(&model.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		for _, id := range userIDs {
+			if err := tx.Create(&model.UserGroupMember{GroupID: groupID, UserID: id}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func DeleteUserGroupMembersByUserId(userID uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userID).Delete(&model.UserGroupMember{}).Error)
+}
+
+// GetPathGrants lists grants, filtered by user or group when their id is set.
+func GetPathGrants(userID, groupID uint) ([]model.PathGrant, error) {
+	var grants []model.PathGrant
+	grantDB := db.Model(&model.PathGrant{})
+	if userID != 0 {
+		grantDB = grantDB.Where("user_id = ?", userID)
+	}
+	if groupID != 0 {
+		grantDB = grantDB.Where("group_id = ?", groupID)
+	}
+	if err := grantDB.Order(columnName("id")).Find(&grants).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find path grants")
+	}
+	return grants, nil
+}
+
+func GetPathGrantById(id uint) (*model.PathGrant, error) {
+	var g model.PathGrant
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get path grant")
+	}
+	return &g, nil
+}
+
+func CreatePathGrant(g *model.PathGrant) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdatePathGrant(g *model.PathGrant) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+func DeletePathGrantById(id uint) error {
+	return errors.WithStack(db.Delete(&model.PathGrant{}, id).Error)
+}
+
+func DeletePathGrantsByUserId(userID uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userID).Delete(&model.PathGrant{}).Error)
+}
+
+// GetUserPathPermissions resolves the grants that apply to a user, both the
+// direct ones and those given to any group the user is a member of.
+func GetUserPathPermissions(userID uint) ([]model.PathPermission, error) {
+	var grants []model.PathGrant
+	groupIDs := db.Model(&model.UserGroupMember{}).Select("group_id").Where("user_id = ?", userID)
+	if err := db.Where("user_id = ?", userID).Or("group_id IN (?)", groupIDs).Find(&grants).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find user path grants")
+	}
+	if len(grants) == 0 {
+		return nil, nil
+	}
+	var roles []model.Role
+	if err := db.Find(&roles).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find roles")
+	}
+	rolePerm := make(map[uint]int32, len(roles))
+	for _, r := range roles {
+		rolePerm[r.ID] = r.Permission
+	}
+	res := make([]model.PathPermission, 0, len(grants))
+	for _, g := range grants {
+		perm, ok := rolePerm[g.RoleID]
+		if !ok {
+			continue
+		}
+		res = append(res, model.PathPermission{Path: g.Path, Permission: perm})
+	}
+	return res, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestGetUserPathPermissionsIncludesGroups(t *testing.T) {
+	t.Cleanup(func() {
+		db.Where("1 = 1").Delete(&model.PathGrant{})
+		db.Where("1 = 1").Delete(&model.UserGroupMember{})
+		db.Where("1 = 1").Delete(&model.UserGroup{})
+		db.Where("1 = 1").Delete(&model.Role{})
+	})
+	editor := &model.Role{Name: "editor", Permission: model.PermWrite | model.PermRemove}
+	sharer := &model.Role{Name: "sharer", Permission: model.PermShare}
+	for _, r := range []*model.Role{editor, sharer} {
+		if err := CreateRole(r); err != nil {
+			t.Fatalf("create role: %v", err)
+		}
+	}
+	group := &model.UserGroup{Name: "media-curation"}
+	if err := CreateUserGroup(group); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := SetUserGroupMembers(group.ID, []uint{7, 8}); err != nil {
+		t.Fatalf("set members: %v", err)
+	}
+	grants := []*model.PathGrant{
+		{RoleID: editor.ID, GroupID: group.ID, Path: "/media"},
+		{RoleID: sharer.ID, UserID: 7, Path: "/media/public"},
+		{RoleID: sharer.ID, UserID: 9, Path: "/"},
+	}
+	for _, g := range grants {
+		if err := CreatePathGrant(g); err != nil {
+			t.Fatalf("create grant: %v", err)
+		}
+	}
+
+	perms, err := GetUserPathPermissions(7)
+	if err != nil {
+		t.Fatalf("get permissions: %v", err)
+	}
+	u := &model.User{Grants: perms}
+	if len(perms) != 2 || !u.CanAt(model.PermWrite, "/media/a") || !u.CanAt(model.PermShare, "/media/public/b") {
+		t.Fatalf("user 7 permissions = %+v", perms)
+	}
+	if perms, _ = GetUserPathPermissions(8); len(perms) != 1 || perms[0].Path != "/media" {
+		t.Fatalf("user 8 permissions = %+v", perms)
+	}
+
+	if err = DeleteUserGroupById(group.ID); err != nil {
+		t.Fatalf("delete group: %v", err)
+	}
+	if perms, _ = GetUserPathPermissions(8); len(perms) != 0 {
+		t.Fatalf("group grants should go with the group, got %+v", perms)
+	}
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateS3Credential(c *model.S3Credential) error {
+	return errors.WithStack(db.Create(c).Error)
+}
+
+func GetS3Credentials() ([]model.S3Credential, error) {
+	var creds []model.S3Credential
+	if err := db.Order(columnName("id")).Find(&creds).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find s3 credentials")
+	}
+	return creds, nil
+}
+
+func GetS3CredentialsByUserId(userID uint) ([]model.S3Credential, error) {
+	var creds []model.S3Credential
+	if err := db.Where("user_id = ?", userID).Order(columnName("id")).Find(&creds).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find s3 credentials")
+	}
+	return creds, nil
+}
+
+func GetS3CredentialById(id uint) (*model.S3Credential, error) {
+	var c model.S3Credential
+	if err := db.First(&c, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 credential")
+	}
+	return &c, nil
+}
+
+// DeleteS3Credential removes a credential of the given user, it is a no-op
+// for credentials of other users.
+func DeleteS3Credential(id, userID uint) error {
+	res := db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.S3Credential{})
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("s3 credential not found")
+	}
+	return nil
+}
+
+func DeleteS3CredentialsByUserId(userID uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userID).Delete(&model.S3Credential{}).Error)
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"encoding/json"
 	"fmt"
 	stdpath "path"
 	"strings"
@@ -51,41 +52,136 @@ func GetSearchNodesByParent(parent string) ([]model.SearchNode, error) {
 		columnName("parent")), parent).Find(&nodes).Error; err != nil {
 		return nil, err
 	}
-	return nodes, nil
+	return trimEmptyMedia(nodes), nil
 }
 
 func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int64, error) {
-	var searchDB *gorm.DB
-	if !useFullText || conf.Conf.Database.Type == "sqlite3" {
-		keywordsClause := db.Where("1 = 1")
-		for _, keyword := range strings.Fields(req.Keywords) {
-			keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
-		}
-		searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).Where(keywordsClause)
-	} else {
-		switch conf.Conf.Database.Type {
-		case "mysql":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
-		case "postgres":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent))
+	if strings.TrimSpace(req.Keywords) != "" {
+		if !useFullText || conf.Conf.Database.Type == "sqlite3" {
+			keywordsClause := db.Where("1 = 1")
+			for _, keyword := range strings.Fields(req.Keywords) {
+				keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
+			}
+			searchDB = searchDB.Where(keywordsClause)
+		} else {
+			switch conf.Conf.Database.Type {
+			case "mysql":
+				searchDB = searchDB.Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
+			case "postgres":
+				searchDB = searchDB.Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+			}
 		}
 	}
 
+	return findSearchNodes(searchDB, req)
+}
+
+// findSearchNodes applies the filters, sort order and page of req to the
+// nodes matched by searchDB.
+func findSearchNodes(searchDB *gorm.DB, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
 		searchDB.Where(db.Where("is_dir = ?", isDir))
 	}
+	if !req.SearchMediaFilter.IsZero() {
+		searchDB = searchDB.Where(whereMedia(req.SearchMediaFilter))
+	}
+	searchDB = whereFilter(searchDB, req.SearchFilter)
 
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Order(searchOrder(req)).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
-	return files, count, nil
+	return trimEmptyMedia(files), count, nil
+}
+
+// whereFilter applies the attribute filters, Exts must be lower case.
+func whereFilter(searchDB *gorm.DB, filter model.SearchFilter) *gorm.DB {
+	if filter.MinSize > 0 {
+		searchDB = searchDB.Where("size >= ?", filter.MinSize)
+	}
+	if filter.MaxSize > 0 {
+		searchDB = searchDB.Where("size <= ?", filter.MaxSize)
+	}
+	if filter.ModifiedFrom != nil {
+		searchDB = searchDB.Where("modified >= ?", *filter.ModifiedFrom)
+	}
+	if filter.ModifiedTo != nil {
+		searchDB = searchDB.Where("modified <= ?", *filter.ModifiedTo)
+	}
+	if len(filter.Exts) > 0 {
+		extsClause := db.Where("1 = 0")
+		for _, ext := range filter.Exts {
+			extsClause = extsClause.Or("LOWER(name) LIKE ?", "%."+ext)
+		}
+		searchDB = searchDB.Where("is_dir = ?", false).Where(extsClause)
+	}
+	return searchDB
+}
+
+func searchOrder(req model.SearchReq) string {
+	order := "asc"
+	if req.Order == "desc" {
+		order = "desc"
+	}
+	switch req.Sort {
+	case model.SearchSortSize, model.SearchSortModified:
+		return fmt.Sprintf("%s %s, name asc", columnName(req.Sort), order)
+	}
+	return "name " + order
+}
+
+// whereMedia matches codes and titles case-insensitively, actors and tags
+// must equal one of the indexed values.
+func whereMedia(filter model.SearchMediaFilter) *gorm.DB {
+	clause := db.Where("media_work_id > 0")
+	if filter.Code != "" {
+		clause = clause.Where("LOWER(media_code) = ?", strings.ToLower(filter.Code))
+	}
+	if filter.Title != "" {
+		title := "%" + strings.ToLower(filter.Title) + "%"
+		clause = clause.Where(db.Where("LOWER(media_raw_title) LIKE ?", title).Or("LOWER(media_translated_title) LIKE ?", title))
+	}
+	if filter.Actor != "" {
+		clause = clause.Where("media_actors LIKE ?", jsonElementPattern(filter.Actor))
+	}
+	if filter.Tag != "" {
+		clause = clause.Where("media_tags LIKE ?", jsonElementPattern(filter.Tag))
+	}
+	if filter.YearFrom > 0 {
+		clause = clause.Where("media_release_year >= ?", filter.YearFrom)
+	}
+	if filter.YearTo > 0 {
+		clause = clause.Where("media_release_year > 0 AND media_release_year <= ?", filter.YearTo)
+	}
+	return clause
+}
+
+// jsonElementPattern matches a string element of a JSON encoded array.
+func jsonElementPattern(value string) string {
+	encoded, _ := json.Marshal(value)
+	return "%" + string(encoded) + "%"
+}
+
+func trimEmptyMedia(nodes []model.SearchNode) []model.SearchNode {
+	for i := range nodes {
+		if nodes[i].Media != nil && nodes[i].Media.WorkID == 0 {
+			nodes[i].Media = nil
+		}
+	}
+	return nodes
+}
+
+// UpdateSearchNodesMedia replaces the media fields of the nodes of a work.
+func UpdateSearchNodesMedia(media *model.SearchMedia) error {
+	return db.Model(&model.SearchNode{}).Where("media_work_id = ?", media.WorkID).
+		Select("media_code", "media_raw_title", "media_translated_title", "media_actors", "media_tags",
+			"media_release_date", "media_release_year").
+		Updates(&model.SearchNode{Media: media}).Error
 }
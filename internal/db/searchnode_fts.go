@@ -0,0 +1,148 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// The FTS5 index of the search nodes is an external content table over the
+// search node table, kept up to date by triggers, so nodes are written and
+// deleted as for the database searcher. The index refers to nodes by rowid,
+// clear and build the index again after a manual VACUUM.
+
+const (
+	FTSTokenizerTrigram   = "trigram"
+	FTSTokenizerUnicode61 = "unicode61"
+)
+
+var ftsTokenizer = FTSTokenizerTrigram
+
+func searchNodeTables() (table, fts string) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&model.SearchNode{}); err != nil {
+		panic(err)
+	}
+	return stmt.Schema.Table, stmt.Schema.Table + "_fts"
+}
+
+// FTS5Available tells whether sqlite is built with FTS5, which needs the
+// sqlite_fts5 build tag.
+func FTS5Available() bool {
+	var used int
+	err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used).Error
+	return err == nil && used == 1
+}
+
+// InitSearchNodeFTS creates the FTS5 index of the search nodes, an index
+// created with another tokenizer is built again.
+func InitSearchNodeFTS(tokenizer string) error {
+	var tokenize string
+	switch tokenizer {
+	case FTSTokenizerTrigram:
+		tokenize = "trigram"
+	case FTSTokenizerUnicode61:
+		tokenize = "unicode61 remove_diacritics 2"
+	default:
+		return errors.Errorf("unknown fts tokenizer: %s", tokenizer)
+	}
+	table, fts := searchNodeTables()
+	createSQL := fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(name, content='%s', tokenize='%s')", fts, table, tokenize)
+	var existing string
+	err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", fts).Scan(&existing).Error
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if existing == createSQL {
+		ftsTokenizer = tokenizer
+		return nil
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if existing != "" {
+			if err := tx.Exec(fmt.Sprintf("DROP TABLE %s", fts)).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec(createSQL).Error; err != nil {
+			if strings.Contains(err.Error(), "no such module: fts5") {
+				return errs.FTS5NotAvailable
+			}
+			return err
+		}
+		if err := createSearchNodeFTSTriggers(tx, table, fts); err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf("INSERT INTO %s(%s) VALUES('rebuild')", fts, fts)).Error
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ftsTokenizer = tokenizer
+	return nil
+}
+
+func createSearchNodeFTSTriggers(tx *gorm.DB, table, fts string) error {
+	triggers := map[string]string{
+		"ai": fmt.Sprintf("AFTER INSERT ON %s BEGIN INSERT INTO %s(rowid, name) VALUES (new.rowid, new.name); END", table, fts),
+		"ad": fmt.Sprintf("AFTER DELETE ON %s BEGIN INSERT INTO %s(%s, rowid, name) VALUES('delete', old.rowid, old.name); END", table, fts, fts),
+		"au": fmt.Sprintf("AFTER UPDATE OF name ON %s BEGIN INSERT INTO %s(%s, rowid, name) VALUES('delete', old.rowid, old.name); "+
+			"INSERT INTO %s(rowid, name) VALUES (new.rowid, new.name); END", table, fts, fts, fts),
+	}
+	for suffix, body := range triggers {
+		name := fts + "_" + suffix
+		if err := tx.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s", name)).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf("CREATE TRIGGER %s %s", name, body)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchNodeFTS searches the names in the FTS5 index, keywords too short for
+// the trigram tokenizer fall back to LIKE.
+func SearchNodeFTS(req model.SearchReq) ([]model.SearchNode, int64, error) {
+	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent))
+	var terms []string
+	for _, keyword := range strings.Fields(req.Keywords) {
+		phrase := `"` + strings.ReplaceAll(keyword, `"`, `""`) + `"`
+		switch {
+		case ftsTokenizer == FTSTokenizerUnicode61:
+			terms = append(terms, phrase+"*")
+		case utf8.RuneCountInString(keyword) >= 3:
+			terms = append(terms, phrase)
+		default:
+			searchDB = searchDB.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
+		}
+	}
+	if len(terms) > 0 {
+		_, fts := searchNodeTables()
+		searchDB = searchDB.Where(fmt.Sprintf("rowid IN (SELECT rowid FROM %s WHERE %s MATCH ?)", fts, fts),
+			strings.Join(terms, " AND "))
+	}
+	return findSearchNodes(searchDB, req)
+}
+
+// ClearSearchNodesFTS deletes all nodes, the index is emptied at once
+// instead of by the delete trigger of every node.
+func ClearSearchNodesFTS() error {
+	table, fts := searchNodeTables()
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s_ad", fts)).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&model.SearchNode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf("INSERT INTO %s(%s) VALUES('delete-all')", fts, fts)).Error; err != nil {
+			return err
+		}
+		return createSearchNodeFTSTriggers(tx, table, fts)
+	}))
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestSearchNodeFiltersByMediaFields(t *testing.T) {
+	if err := ClearSearchNodes(); err != nil {
+		t.Fatalf("clear search nodes: %v", err)
+	}
+	t.Cleanup(func() { _ = ClearSearchNodes() })
+	release := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	nodes := []model.SearchNode{
+		{Parent: "/media", Name: "ABC-001.mp4", Media: &model.SearchMedia{
+			WorkID: 1, Code: "ABC-001", RawTitle: "Summer Story", TranslatedTitle: "夏日物语",
+			Actors: model.StringArray{"Alice", "Bob"}, Tags: model.StringArray{"drama"}, ReleaseDate: &release, ReleaseYear: 2021,
+		}},
+		{Parent: "/media", Name: "ABC-002.mp4", Media: &model.SearchMedia{
+			WorkID: 2, Code: "ABC-002", RawTitle: "Winter", Actors: model.StringArray{"Alice Smith"}, ReleaseYear: 2019,
+		}},
+		{Parent: "/media", Name: "ABC-003.nfo"},
+	}
+	if err := BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("create search nodes: %v", err)
+	}
+
+	search := func(keywords string, filter model.SearchMediaFilter) []string {
+		t.Helper()
+		found, total, err := SearchNode(model.SearchReq{
+			Parent: "/media", Keywords: keywords, SearchMediaFilter: filter,
+			PageReq: model.PageReq{Page: 1, PerPage: 10},
+		}, false)
+		if err != nil {
+			t.Fatalf("search %+v: %v", filter, err)
+		}
+		if int(total) != len(found) {
+			t.Fatalf("total = %d, found %d", total, len(found))
+		}
+		names := make([]string, len(found))
+		for i, node := range found {
+			names[i] = node.Name
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		keywords string
+		filter   model.SearchMediaFilter
+		want     []string
+	}{
+		{name: "keywords only", keywords: "ABC", want: []string{"ABC-001.mp4", "ABC-002.mp4", "ABC-003.nfo"}},
+		{name: "actor is exact", filter: model.SearchMediaFilter{Actor: "Alice"}, want: []string{"ABC-001.mp4"}},
+		{name: "tag", filter: model.SearchMediaFilter{Tag: "drama"}, want: []string{"ABC-001.mp4"}},
+		{name: "code ignores case", filter: model.SearchMediaFilter{Code: "abc-002"}, want: []string{"ABC-002.mp4"}},
+		{name: "translated title", filter: model.SearchMediaFilter{Title: "物语"}, want: []string{"ABC-001.mp4"}},
+		{name: "raw title", filter: model.SearchMediaFilter{Title: "winter"}, want: []string{"ABC-002.mp4"}},
+		{name: "year range", filter: model.SearchMediaFilter{YearFrom: 2020, YearTo: 2022}, want: []string{"ABC-001.mp4"}},
+		{name: "year upper bound", filter: model.SearchMediaFilter{YearTo: 2020}, want: []string{"ABC-002.mp4"}},
+		{name: "keywords and filter", keywords: "002", filter: model.SearchMediaFilter{Actor: "Alice"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := search(tt.keywords, tt.filter); !slices.Equal(got, tt.want) {
+				t.Fatalf("found %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	plain, err := GetSearchNodesByParent("/media")
+	if err != nil {
+		t.Fatalf("get nodes: %v", err)
+	}
+	for _, node := range plain {
+		if (node.Media == nil) != (node.Name == "ABC-003.nfo") {
+			t.Fatalf("node %s media = %+v", node.Name, node.Media)
+		}
+	}
+
+	if err := UpdateSearchNodesMedia(&model.SearchMedia{WorkID: 2, Code: "ABC-002", Actors: model.StringArray{"Carol"}}); err != nil {
+		t.Fatalf("update media: %v", err)
+	}
+	if got := search("", model.SearchMediaFilter{Actor: "Carol"}); !slices.Equal(got, []string{"ABC-002.mp4"}) {
+		t.Fatalf("after update found %v", got)
+	}
+	if got := search("", model.SearchMediaFilter{Title: "winter"}); len(got) != 0 {
+		t.Fatalf("stale title still matches: %v", got)
+	}
+}
+
+func TestMediaWorkHooksFollowSearchableFields(t *testing.T) {
+	var changed []uint
+	RegisterMediaWorkHook(func(workID uint) { changed = append(changed, workID) })
+	t.Cleanup(func() {
+		mediaWorkHooksMu.Lock()
+		mediaWorkHooks = mediaWorkHooks[:len(mediaWorkHooks)-1]
+		mediaWorkHooksMu.Unlock()
+	})
+
+	work := model.FilmWork{StorageID: 1, Source: "hook", Code: "HOOK-001", SourceRef: "ref", PrimaryDir: "/"}
+	if err := UpsertDiscoveredWork(&work); err != nil {
+		t.Fatalf("create work: %v", err)
+	}
+	if err := UpdateMediaWorkSynopsis(work.ID, "synopsis only"); err != nil {
+		t.Fatalf("update synopsis: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("hooks called for unsearchable changes: %v", changed)
+	}
+	if err := UpdateMediaWorkActors(work.ID, model.StringArray{"Alice"}); err != nil {
+		t.Fatalf("update actors: %v", err)
+	}
+	if err := UpdateMediaWorkTranslation(work.ID, "translated", model.CurrentTranslationVersion); err != nil {
+		t.Fatalf("update translation: %v", err)
+	}
+	if !slices.Equal(changed, []uint{work.ID, work.ID}) {
+		t.Fatalf("hooks called for %v", changed)
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"errors"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"gorm.io/gorm"
@@ -89,6 +90,33 @@ func UpdateSourceMagnetLastError(magnetID uint, message string) error {
 	return nil
 }
 
+// RecordSourceMagnetSuccess 记录一次成功的播放解析，清除连续失败与冷却。
+func RecordSourceMagnetSuccess(magnetID uint) error {
+	return updateSourceMagnetHealth(magnetID, func(magnet *model.SourceMagnet, now time.Time) {
+		magnet.RecordSuccess(now)
+	})
+}
+
+// RecordSourceMagnetFailure 记录一次失败的播放解析，磁力进入冷却期。
+func RecordSourceMagnetFailure(magnetID uint, message string) error {
+	return updateSourceMagnetHealth(magnetID, func(magnet *model.SourceMagnet, now time.Time) {
+		magnet.RecordFailure(message, now)
+	})
+}
+
+func updateSourceMagnetHealth(magnetID uint, record func(magnet *model.SourceMagnet, now time.Time)) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var magnet model.SourceMagnet
+		if err := tx.First(&magnet, magnetID).Error; err != nil {
+			return err
+		}
+		record(&magnet, time.Now())
+		return tx.Model(&magnet).Select(
+			"success_count", "failure_count", "failure_streak", "last_success_at", "cooldown_until", "last_error",
+		).Updates(&magnet).Error
+	})
+}
+
 func ensureSelectedSourceMagnet(tx *gorm.DB, workID uint) (model.SourceMagnet, error) {
 	var magnets []model.SourceMagnet
 	if err := tx.Where("work_id = ?", workID).Order("selected DESC, priority ASC, id ASC").Find(&magnets).Error; err != nil {
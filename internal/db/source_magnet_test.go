@@ -4,6 +4,7 @@ import (
 	"errors"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"gorm.io/gorm"
@@ -251,6 +252,45 @@ func TestUpdateSourceMagnetLastErrorChangesOnlyCandidate(t *testing.T) {
 	}
 }
 
+func TestRecordSourceMagnetHealthBacksOffAndResets(t *testing.T) {
+	setupSourceMagnetRepositoryTestDB(t)
+
+	if err := UpsertSourceMagnets(20, []model.SourceMagnet{{MagnetURI: "magnet:flaky", Fingerprint: "flaky"}}); err != nil {
+		t.Fatalf("upsert health magnet: %v", err)
+	}
+	stored, err := ListSourceMagnets(20)
+	if err != nil {
+		t.Fatalf("list health magnet: %v", err)
+	}
+	id := stored[0].ID
+	for _, message := range []string{"timeout", "no playable file"} {
+		if err := RecordSourceMagnetFailure(id, message); err != nil {
+			t.Fatalf("record failure: %v", err)
+		}
+	}
+	stored, _ = ListSourceMagnets(20)
+	failed := stored[0]
+	if failed.FailureCount != 2 || failed.FailureStreak != 2 || failed.LastError != "no playable file" || !failed.CoolingDown(time.Now()) {
+		t.Fatalf("after failures = %+v", failed)
+	}
+	if remaining := time.Until(*failed.CooldownUntil); remaining <= 10*time.Minute || remaining > 20*time.Minute {
+		t.Fatalf("cooldown after two failures = %s, want doubled base cooldown", remaining)
+	}
+
+	if err := RecordSourceMagnetSuccess(id); err != nil {
+		t.Fatalf("record success: %v", err)
+	}
+	stored, _ = ListSourceMagnets(20)
+	healed := stored[0]
+	if healed.SuccessCount != 1 || healed.FailureCount != 2 || healed.FailureStreak != 0 ||
+		healed.CooldownUntil != nil || healed.LastSuccessAt == nil || healed.LastError != "" {
+		t.Fatalf("after success = %+v", healed)
+	}
+	if healed.HealthScore() != 0 {
+		t.Fatalf("health score = %d, want 0", healed.HealthScore())
+	}
+}
+
 func selectedSourceMagnets(magnets []model.SourceMagnet) []model.SourceMagnet {
 	selected := make([]model.SourceMagnet, 0, len(magnets))
 	for _, magnet := range magnets {
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetTrafficLimits() ([]model.TrafficLimit, error) {
+	var limits []model.TrafficLimit
+	if err := db.Order(columnName("id")).Find(&limits).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find traffic limits")
+	}
+	return limits, nil
+}
+
+func GetTrafficLimitById(id uint) (*model.TrafficLimit, error) {
+	var l model.TrafficLimit
+	if err := db.First(&l, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get traffic limit")
+	}
+	return &l, nil
+}
+
+func CreateTrafficLimit(l *model.TrafficLimit) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+func UpdateTrafficLimit(l *model.TrafficLimit) error {
+	return errors.WithStack(db.Save(l).Error)
+}
+
+func DeleteTrafficLimitById(id uint) error {
+	return errors.WithStack(db.Delete(&model.TrafficLimit{}, id).Error)
+}
+
+func DeleteTrafficLimitsByUserId(userID uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userID).Delete(&model.TrafficLimit{}).Error)
+}
+
+// GetUserTrafficLimits returns the user's own limit, nil without one, and
+// the limits of the groups the user is a member of.
+func GetUserTrafficLimits(userID uint) (*model.TrafficLimit, []model.TrafficLimit, error) {
+	var own []model.TrafficLimit
+	if err := db.Where("user_id = ?", userID).Order(columnName("id")).Limit(1).Find(&own).Error; err != nil {
+		return nil, nil, errors.Wrapf(err, "failed find user traffic limit")
+	}
+	var groups []model.TrafficLimit
+	groupIDs := db.Model(&model.UserGroupMember{}).Select("group_id").Where("user_id = ?", userID)
+	if err := db.Where("group_id IN (?)", groupIDs).Order(columnName("id")).Find(&groups).Error; err != nil {
+		return nil, nil, errors.Wrapf(err, "failed find group traffic limits")
+	}
+	if len(own) == 0 {
+		return nil, groups, nil
+	}
+	return &own[0], groups, nil
+}
+
+// GetTrafficUsage returns what the user transferred on day, zero when
+// nothing was recorded.
+func GetTrafficUsage(userID uint, day string) (*model.TrafficUsage, error) {
+	var usages []model.TrafficUsage
+	if err := db.Where("user_id = ? AND day = ?", userID, day).Limit(1).Find(&usages).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get traffic usage")
+	}
+	if len(usages) == 0 {
+		return &model.TrafficUsage{UserID: userID, Day: day}, nil
+	}
+	return &usages[0], nil
+}
+
+func GetTrafficUsages(day string) ([]model.TrafficUsage, error) {
+	var usages []model.TrafficUsage
+	if err := db.Where("day = ?", day).Order(columnName("user_id")).Find(&usages).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find traffic usages")
+	}
+	return usages, nil
+}
+
+// AddTrafficUsage adds transferred bytes to the user's counters of day.
+func AddTrafficUsage(userID uint, day string, downloaded, uploaded int64) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		usage := model.TrafficUsage{UserID: userID, Day: day}
+		if err := tx.FirstOrCreate(&usage).Error; err != nil {
+			return err
+		}
+		return tx.Model(&usage).UpdateColumns(map[string]any{
+			"downloaded": gorm.Expr("downloaded + ?", downloaded),
+			"uploaded":   gorm.Expr("uploaded + ?", uploaded),
+		}).Error
+	}))
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestAddTrafficUsage(t *testing.T) {
+	t.Cleanup(func() {
+		db.Where("1 = 1").Delete(&model.TrafficUsage{})
+	})
+	for _, n := range []int64{100, 250} {
+		if err := AddTrafficUsage(3, "2026-10-18", n, n/2); err != nil {
+			t.Fatalf("add usage: %v", err)
+		}
+	}
+	usage, err := GetTrafficUsage(3, "2026-10-18")
+	if err != nil {
+		t.Fatalf("get usage: %v", err)
+	}
+	if usage.Downloaded != 350 || usage.Uploaded != 175 {
+		t.Fatalf("got %+v, want 350 down and 175 up", usage)
+	}
+	usage, err = GetTrafficUsage(3, "2026-10-19")
+	if err != nil || usage.Downloaded != 0 {
+		t.Fatalf("day without usage: %+v, err %v", usage, err)
+	}
+}
+
+func TestGetUserTrafficLimits(t *testing.T) {
+	t.Cleanup(func() {
+		db.Where("1 = 1").Delete(&model.TrafficLimit{})
+		db.Where("1 = 1").Delete(&model.UserGroupMember{})
+		db.Where("1 = 1").Delete(&model.UserGroup{})
+	})
+	group := &model.UserGroup{Name: "viewers"}
+	if err := CreateUserGroup(group); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := SetUserGroupMembers(group.ID, []uint{4}); err != nil {
+		t.Fatalf("set members: %v", err)
+	}
+	limits := []*model.TrafficLimit{
+		{GroupID: group.ID, DownloadSpeed: 512},
+		{UserID: 5, MaxStreams: 1},
+	}
+	for _, l := range limits {
+		if err := CreateTrafficLimit(l); err != nil {
+			t.Fatalf("create limit: %v", err)
+		}
+	}
+
+	own, groups, err := GetUserTrafficLimits(4)
+	if err != nil {
+		t.Fatalf("get limits: %v", err)
+	}
+	if own != nil || len(groups) != 1 || groups[0].DownloadSpeed != 512 {
+		t.Fatalf("member: own %+v, groups %+v", own, groups)
+	}
+	own, groups, err = GetUserTrafficLimits(5)
+	if err != nil {
+		t.Fatalf("get limits: %v", err)
+	}
+	if own == nil || own.MaxStreams != 1 || len(groups) != 0 {
+		t.Fatalf("user with own limit: own %+v, groups %+v", own, groups)
+	}
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/gorm/clause"
+)
+
+// GetTranslationCaches returns the cached results of the given hashes, keyed by hash.
+func GetTranslationCaches(backend string, version uint, hashes []string) (map[string]string, error) {
+	results := make(map[string]string, len(hashes))
+	if len(hashes) == 0 {
+		return results, nil
+	}
+	var caches []model.TranslationCache
+	if err := db.Where("backend = ? AND version = ? AND hash IN ?", backend, version, hashes).Find(&caches).Error; err != nil {
+		return nil, err
+	}
+	for _, cache := range caches {
+		results[cache.Hash] = cache.Result
+	}
+	return results, nil
+}
+
+func SaveTranslationCaches(caches []model.TranslationCache) error {
+	if len(caches) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "backend"}, {Name: "version"}, {Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"result"}),
+	}).Create(&caches).Error
+}
+
+// DeleteStaleTranslationCaches drops the entries of other translation versions.
+func DeleteStaleTranslationCaches(version uint) error {
+	return db.Where("version <> ?", version).Delete(&model.TranslationCache{}).Error
+}
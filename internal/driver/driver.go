@@ -2,6 +2,7 @@ package driver
 
 import (
 	"context"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 )
@@ -80,6 +81,18 @@ type Remove interface {
 	Remove(ctx context.Context, obj model.Obj) error
 }
 
+// LinkTarget is implemented by drivers whose root stands for one other
+// path, front-ends may show their mount point as a symbolic link to it.
+type LinkTarget interface {
+	LinkTarget() (string, bool)
+}
+
+// SetModTime is implemented by drivers able to change the modification
+// time of an object, as asked by sftp clients preserving times.
+type SetModTime interface {
+	SetModTime(ctx context.Context, obj model.Obj, modified time.Time) error
+}
+
 type Put interface {
 	// Put a file (provided as a FileStreamer) into the driver
 	// Besides the most basic upload functionality, the following features also need to be implemented:
@@ -5,4 +5,5 @@ import "fmt"
 var (
 	SearchNotAvailable  = fmt.Errorf("search not available")
 	BuildIndexIsRunning = fmt.Errorf("build index is running, please try later")
+	FTS5NotAvailable    = fmt.Errorf("sqlite is built without FTS5, build with the sqlite_fts5 tag")
 )
@@ -8,3 +8,8 @@ var (
 	WrongPassword      = errors.New("password is incorrect")
 	DeleteAdminOrGuest = errors.New("cannot delete admin or guest")
 )
+
+var (
+	TrafficQuotaExceeded = errors.New("daily traffic quota exceeded")
+	TooManyStreams       = errors.New("too many concurrent streams")
+)
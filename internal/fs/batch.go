@@ -2,34 +2,46 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/audit"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/pkg/errors"
 )
 
 func BatchMove(ctx context.Context, srcDirPath, dstDirPath string, objectNames []string) (bool, error) {
-	return batchFsOperate(ctx, srcDirPath, dstDirPath, objectNames,
+	done, err := batchFsOperate(ctx, srcDirPath, dstDirPath, objectNames,
 		func(d driver.Driver) bool {
 			_, ok := d.(driver.BatchMove)
 			return ok
 		},
 		op.BatchMove,
 	)
+	auditBatch(ctx, model.AuditMove, srcDirPath, dstDirPath, objectNames, done, err)
+	return done, err
 }
 
 func BatchCopy(ctx context.Context, srcDirPath, dstDirPath string, objectNames []string) (bool, error) {
-	return batchFsOperate(ctx, srcDirPath, dstDirPath, objectNames,
+	done, err := batchFsOperate(ctx, srcDirPath, dstDirPath, objectNames,
 		func(d driver.Driver) bool {
 			_, ok := d.(driver.BatchCopy)
 			return ok
 		},
 		op.BatchCopy,
 	)
+	auditBatch(ctx, model.AuditCopy, srcDirPath, dstDirPath, objectNames, done, err)
+	return done, err
 }
 
 func BatchRemove(ctx context.Context, srcDirPath string, objectNames []string) (bool, error) {
+	done, err := batchRemove(ctx, srcDirPath, objectNames)
+	auditBatch(ctx, model.AuditRemove, srcDirPath, "", objectNames, done, err)
+	return done, err
+}
 
+func batchRemove(ctx context.Context, srcDirPath string, objectNames []string) (bool, error) {
 	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcDirPath)
 	if err != nil {
 		return false, errors.WithMessage(err, "failed to get source storage")
@@ -48,6 +60,16 @@ func BatchRemove(ctx context.Context, srcDirPath string, objectNames []string) (
 }
 
 func BatchRename(ctx context.Context, srcDirPath string, nameMapping map[string]string) (bool, error) {
+	done, err := batchRename(ctx, srcDirPath, nameMapping)
+	if done || err != nil {
+		for src, dst := range nameMapping {
+			audit.Fs(ctx, model.AuditRename, stdpath.Join(srcDirPath, src), stdpath.Join(srcDirPath, dst), err)
+		}
+	}
+	return done, err
+}
+
+func batchRename(ctx context.Context, srcDirPath string, nameMapping map[string]string) (bool, error) {
 	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcDirPath)
 	if err != nil {
 		return false, errors.WithMessage(err, "failed to get source storage")
@@ -65,6 +87,21 @@ func BatchRename(ctx context.Context, srcDirPath string, nameMapping map[string]
 
 }
 
+// auditBatch records a batch operation per object, unless the driver
+// has no batch support and nothing was done.
+func auditBatch(ctx context.Context, action, srcDirPath, dstDirPath string, objectNames []string, done bool, err error) {
+	if !done && err == nil {
+		return
+	}
+	for _, name := range objectNames {
+		dst := ""
+		if dstDirPath != "" {
+			dst = stdpath.Join(dstDirPath, name)
+		}
+		audit.Fs(ctx, action, stdpath.Join(srcDirPath, name), dst, err)
+	}
+}
+
 func batchFsOperate(ctx context.Context, srcDirPath, dstDirPath string, objectNames []string,
 	capabilityCheck func(d driver.Driver) bool,
 	operation func(ctx context.Context, storage driver.Driver, srcPath, dstPath string, names []string) error) (bool, error) {
@@ -3,9 +3,12 @@ package fs
 import (
 	"context"
 	"io"
+	stdpath "path"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/audit"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -68,6 +71,7 @@ func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
 	}
+	audit.Fs(ctx, model.AuditMkdir, path, "", err)
 	return err
 }
 
@@ -76,6 +80,7 @@ func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) (t
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
 	}
+	audit.Fs(ctx, model.AuditMove, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)), err)
 	return req, err
 }
 
@@ -84,6 +89,7 @@ func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
+	audit.Fs(ctx, model.AuditCopy, srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)), err)
 	return res, err
 }
 
@@ -92,6 +98,7 @@ func Merge(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 	if err != nil {
 		log.Errorf("failed merge %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
+	audit.Fs(ctx, model.AuditMerge, srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)), err)
 	return res, err
 }
 
@@ -100,6 +107,18 @@ func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
 	}
+	audit.Fs(ctx, model.AuditRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName), err)
+	return err
+}
+
+// SetModTime changes the modification time of path, failing with
+// errs.NotSupport when its driver cannot.
+func SetModTime(ctx context.Context, path string, modified time.Time) error {
+	err := setModTime(ctx, path, modified)
+	if err != nil && !errors.Is(err, errs.NotSupport) {
+		log.Errorf("failed set modification time of %s: %+v", path, err)
+	}
+	audit.Fs(ctx, model.AuditSetModTime, path, "", err)
 	return err
 }
 
@@ -108,6 +127,7 @@ func Remove(ctx context.Context, path string) error {
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
 	}
+	audit.Fs(ctx, model.AuditRemove, path, "", err)
 	return err
 }
 
@@ -116,6 +136,7 @@ func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
+	audit.Fs(ctx, model.AuditUpload, stdpath.Join(dstDirPath, file.GetName()), "", err)
 	return err
 }
 
@@ -124,6 +145,7 @@ func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
+	audit.Fs(ctx, model.AuditUpload, stdpath.Join(dstDirPath, file.GetName()), "", err)
 	return t, err
 }
 
@@ -148,6 +170,7 @@ func ArchiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args
 	if err != nil {
 		log.Errorf("failed decompress [%s]%s: %+v", srcObjPath, args.InnerPath, err)
 	}
+	audit.Fs(ctx, model.AuditDecompress, srcObjPath, dstDirPath, err)
 	return t, err
 }
 
@@ -182,6 +205,20 @@ func GetStorageAndActualPath(path string) (driver.Driver, string, error) {
 	return op.GetStorageAndActualPath(path)
 }
 
+// LinkTarget returns the path the storage mounted at path stands for, when
+// its driver implements driver.LinkTarget.
+func LinkTarget(path string) (string, bool) {
+	storage, err := op.GetStorageByMountPath(path)
+	if err != nil {
+		return "", false
+	}
+	l, ok := storage.(driver.LinkTarget)
+	if !ok {
+		return "", false
+	}
+	return l.LinkTarget()
+}
+
 func GetByActualPath(ctx context.Context, storage driver.Driver, actualPath string) (model.Obj, error) {
 	return op.Get(ctx, storage, actualPath)
 }
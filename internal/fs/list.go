@@ -49,7 +49,7 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 
 func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is admin, don't hide
-	if user == nil || user.CanSeeHides() {
+	if user == nil || user.CanAt(model.PermSeeHides, path) {
 		return false
 	}
 	// if meta is nil, don't hide
@@ -2,6 +2,7 @@ package fs
 
 import (
 	"context"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -26,6 +27,14 @@ func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	return op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
 }
 
+func setModTime(ctx context.Context, path string, modified time.Time) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	return op.SetModTime(ctx, storage, actualPath, modified)
+}
+
 func remove(ctx context.Context, path string) error {
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
@@ -0,0 +1,154 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/tache"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+type ScanArgs struct {
+	Path    string
+	Refresh bool
+	// Limit bounds the directories listed per second, 0 means unlimited
+	Limit float64
+	// Interval pauses between two directories
+	Interval time.Duration
+	Meta     *model.Meta
+	// User is who lists, so the hide rules of Meta apply to the walk. It
+	// defaults to the user in the context of ScanAsTask
+	User *model.User
+}
+
+type ScanProgress struct {
+	DirsDone int    `json:"dirs_done"`
+	Queued   int    `json:"queued"`
+	Errors   int    `json:"errors"`
+	Objs     uint64 `json:"objs"`
+	Current  string `json:"current"`
+}
+
+func (p ScanProgress) Walked() (int, int) {
+	return p.DirsDone, p.Queued
+}
+
+// ScanTask walks a path breadth first and lists every directory, with
+// Refresh the storages reload their listings on the way.
+type ScanTask struct {
+	task.WalkTask[ScanProgress]
+	args ScanArgs
+}
+
+func (t *ScanTask) GetName() string {
+	if t.args.Refresh {
+		return fmt.Sprintf("refresh scan [%s]", t.args.Path)
+	}
+	return fmt.Sprintf("scan [%s]", t.args.Path)
+}
+
+func (t *ScanTask) GetStatus() string {
+	p := t.Progress()
+	if p.Current == "" {
+		return fmt.Sprintf("%d dirs scanned, %d errors", p.DirsDone, p.Errors)
+	}
+	return fmt.Sprintf("%d dirs scanned, %d queued, %d errors, scanning %s", p.DirsDone, p.Queued, p.Errors, p.Current)
+}
+
+func (t *ScanTask) Run() error {
+	return t.Walk(t.scan)
+}
+
+func (t *ScanTask) scan(ctx context.Context) error {
+	if t.args.User != nil {
+		ctx = context.WithValue(ctx, conf.UserKey, t.args.User)
+	}
+	if t.args.Meta != nil {
+		ctx = context.WithValue(ctx, conf.MetaKey, t.args.Meta)
+	}
+	var limiter *rate.Limiter
+	if t.args.Limit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(t.args.Limit), 1)
+	}
+
+	queue := []string{t.args.Path}
+	visited := map[string]bool{t.args.Path: true}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		currentPath := queue[0]
+		queue = queue[1:]
+		t.Update(func(p *ScanProgress) {
+			p.Current = currentPath
+			p.Queued = len(queue)
+		})
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		objs, err := List(ctx, currentPath, &ListArgs{Refresh: t.args.Refresh, NoLog: true})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Warnf("scan: failed to list %s: %+v", currentPath, err)
+			t.Update(func(p *ScanProgress) {
+				p.DirsDone++
+				p.Errors++
+			})
+			continue
+		}
+		for _, obj := range objs {
+			if !obj.IsDir() {
+				continue
+			}
+			subPath := stdpath.Join(currentPath, obj.GetName())
+			if !visited[subPath] {
+				visited[subPath] = true
+				queue = append(queue, subPath)
+			}
+		}
+		t.Update(func(p *ScanProgress) {
+			p.DirsDone++
+			p.Queued = len(queue)
+			p.Objs += uint64(len(objs))
+		})
+		if t.args.Interval > 0 && len(queue) > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(t.args.Interval):
+			}
+		}
+	}
+	t.Update(func(p *ScanProgress) { p.Current = "" })
+	log.Infof("scan: completed %s, %d dirs", t.args.Path, t.Progress().DirsDone)
+	return nil
+}
+
+var ScanTaskManager *tache.Manager[*ScanTask]
+
+// ScanAsTask adds a scan task and returns immediately, scans of different
+// paths run concurrently up to the worker count of the manager.
+func ScanAsTask(ctx context.Context, args ScanArgs) *ScanTask {
+	args.Path = utils.FixAndCleanPath(args.Path)
+	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User)
+	if args.User == nil {
+		args.User = taskCreator
+	}
+	t := &ScanTask{
+		WalkTask: task.NewWalkTask[ScanProgress](ctx),
+		args:     args,
+	}
+	ScanTaskManager.Add(t)
+	return t
+}
@@ -0,0 +1,124 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/OpenListTeam/OpenList/v4/drivers/local"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/tache"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig("data")
+	db.Init(dB)
+	ScanTaskManager = tache.NewManager[*ScanTask](tache.WithWorks(2))
+}
+
+func mountScanTree(t *testing.T, mountPath string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"a/b", "c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.WriteFile(filepath.Join(root, "a", "b", "f.txt"), []byte("f"), 0o644)
+	id, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("create storage: %+v", err)
+	}
+	t.Cleanup(func() { _ = op.DeleteStorageById(context.Background(), id) })
+}
+
+func waitScan(t *testing.T, task *ScanTask) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		switch task.GetState() {
+		case tache.StateSucceeded, tache.StateFailed, tache.StateCanceled:
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("scan %s did not finish, state %v", task.GetName(), task.GetState())
+}
+
+func TestScanTasksRunConcurrently(t *testing.T) {
+	mountScanTree(t, "/scan1")
+	mountScanTree(t, "/scan2")
+
+	first := ScanAsTask(context.Background(), ScanArgs{Path: "/scan1"})
+	second := ScanAsTask(context.Background(), ScanArgs{Path: "/scan2", Refresh: true})
+	if first.GetID() == second.GetID() {
+		t.Fatal("scans share an ID")
+	}
+	for _, task := range []*ScanTask{first, second} {
+		waitScan(t, task)
+		progress := task.Progress()
+		if task.GetState() != tache.StateSucceeded || progress.DirsDone != 4 || progress.Queued != 0 || progress.Errors != 0 || progress.Objs != 4 {
+			t.Fatalf("scan %s: state %v, progress %+v", task.GetName(), task.GetState(), progress)
+		}
+		if task.GetProgress() != 100 {
+			t.Fatalf("scan %s: progress %v", task.GetName(), task.GetProgress())
+		}
+	}
+}
+
+func TestScanTaskCanBeCanceled(t *testing.T) {
+	mountScanTree(t, "/scan3")
+
+	task := ScanAsTask(context.Background(), ScanArgs{Path: "/scan3", Interval: time.Hour})
+	deadline := time.Now().Add(5 * time.Second)
+	for task.Progress().DirsDone == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ScanTaskManager.Cancel(task.GetID())
+	waitScan(t, task)
+	// tache ends canceled tasks as failed with the context error
+	if !errors.Is(task.GetErr(), context.Canceled) {
+		t.Fatalf("state after cancel = %v, err = %v", task.GetState(), task.GetErr())
+	}
+	if progress := task.Progress(); progress.DirsDone != 1 || progress.Queued != 2 {
+		t.Fatalf("progress after cancel = %+v", progress)
+	}
+}
+
+func TestScanTaskAppliesHideRulesOfCreator(t *testing.T) {
+	mountScanTree(t, "/scan4")
+	meta := &model.Meta{Path: "/scan4", Hide: "^a$", HSub: true}
+
+	user := &model.User{Username: "reader"}
+	ctx := context.WithValue(context.Background(), conf.UserKey, user)
+	task := ScanAsTask(ctx, ScanArgs{Path: "/scan4", Meta: meta})
+	waitScan(t, task)
+	// the hidden a and a/b are not walked
+	if progress := task.Progress(); task.GetState() != tache.StateSucceeded || progress.DirsDone != 2 {
+		t.Fatalf("scan as %s: state %v, progress %+v", user.Username, task.GetState(), progress)
+	}
+
+	admin := &model.User{Username: "admin", Permission: model.PermSeeHides}
+	task = ScanAsTask(context.Background(), ScanArgs{Path: "/scan4", Meta: meta, User: admin})
+	waitScan(t, task)
+	if progress := task.Progress(); progress.DirsDone != 4 {
+		t.Fatalf("scan as %s: progress %+v", admin.Username, progress)
+	}
+}
@@ -0,0 +1,224 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/tache"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DivergenceMissing = "missing"
+	DivergenceType    = "type"
+	DivergenceSize    = "size"
+	DivergenceHash    = "hash"
+)
+
+type VerifyArgs struct {
+	// Replicas are the roots holding the same tree, earlier ones are
+	// taken as the source when repairing
+	Replicas []string
+	// Repair copies missing or differing objects from the source replica
+	Repair  bool
+	Refresh bool
+}
+
+// Divergence is an object of Source that Replica lacks or holds otherwise.
+// Path is relative to the replica roots.
+type Divergence struct {
+	Path     string `json:"path"`
+	Source   string `json:"source"`
+	Replica  string `json:"replica"`
+	Reason   string `json:"reason"`
+	Repaired bool   `json:"repaired"`
+}
+
+type VerifyReport struct {
+	DirsDone     int          `json:"dirs_done"`
+	Queued       int          `json:"queued"`
+	FilesChecked int          `json:"files_checked"`
+	Errors       int          `json:"errors"`
+	Divergences  []Divergence `json:"divergences"`
+}
+
+func (r VerifyReport) Walked() (int, int) {
+	return r.DirsDone, r.Queued
+}
+
+// VerifyTask walks replicas side by side and compares their listings,
+// sizes and hashes.
+type VerifyTask struct {
+	task.WalkTask[VerifyReport]
+	args VerifyArgs
+}
+
+func (t *VerifyTask) GetName() string {
+	return fmt.Sprintf("verify [%s]", strings.Join(t.args.Replicas, ", "))
+}
+
+func (t *VerifyTask) GetStatus() string {
+	r := t.Report()
+	return fmt.Sprintf("%d dirs verified, %d divergences, %d errors", r.DirsDone, len(r.Divergences), r.Errors)
+}
+
+// Report returns a copy of the progress, the divergences included.
+func (t *VerifyTask) Report() VerifyReport {
+	r := t.Progress()
+	r.Divergences = append([]Divergence(nil), r.Divergences...)
+	return r
+}
+
+func (t *VerifyTask) Run() error {
+	return t.Walk(t.verify)
+}
+
+func (t *VerifyTask) verify(ctx context.Context) error {
+	queue := []string{"/"}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		dir := queue[0]
+		queue = queue[1:]
+		var replicas []string
+		var lists [][]model.Obj
+		for _, r := range t.args.Replicas {
+			objs, err := List(ctx, stdpath.Join(r, dir), &ListArgs{Refresh: t.args.Refresh, NoLog: true})
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Warnf("verify: failed to list %s: %+v", stdpath.Join(r, dir), err)
+				t.Update(func(r *VerifyReport) { r.Errors++ })
+				continue
+			}
+			replicas = append(replicas, r)
+			lists = append(lists, objs)
+		}
+		divs, dirs, files := compareListings(dir, replicas, lists)
+		if t.args.Repair {
+			for i := range divs {
+				divs[i].Repaired = t.repair(ctx, divs[i])
+			}
+		}
+		queue = append(queue, dirs...)
+		t.Update(func(r *VerifyReport) {
+			r.DirsDone++
+			r.Queued = len(queue)
+			r.FilesChecked += files
+			r.Divergences = append(r.Divergences, divs...)
+		})
+	}
+	r := t.Report()
+	log.Infof("verify: completed %s, %d dirs, %d divergences", t.GetName(), r.DirsDone, len(r.Divergences))
+	return nil
+}
+
+// repair copies the object of the divergence from its source, a differing
+// file is overwritten.
+func (t *VerifyTask) repair(ctx context.Context, d Divergence) bool {
+	if d.Reason == DivergenceType {
+		return false
+	}
+	_, err := Copy(ctx, stdpath.Join(d.Source, d.Path), stdpath.Join(d.Replica, stdpath.Dir(d.Path)))
+	if err != nil {
+		log.Warnf("verify: failed to repair %s on %s: %+v", d.Path, d.Replica, err)
+		return false
+	}
+	return true
+}
+
+// compareListings compares the listings of dir on each replica. It returns
+// the divergences, the sub dirs every replica holds and the files checked.
+func compareListings(dir string, replicas []string, lists [][]model.Obj) ([]Divergence, []string, int) {
+	var names []string
+	byName := make(map[string][]model.Obj)
+	for i, objs := range lists {
+		for _, obj := range objs {
+			entries, ok := byName[obj.GetName()]
+			if !ok {
+				names = append(names, obj.GetName())
+				entries = make([]model.Obj, len(lists))
+				byName[obj.GetName()] = entries
+			}
+			entries[i] = obj
+		}
+	}
+	var divs []Divergence
+	var dirs []string
+	files := 0
+	for _, name := range names {
+		entries := byName[name]
+		path := stdpath.Join(dir, name)
+		src := -1
+		allDirs := true
+		for i, obj := range entries {
+			if obj == nil || !obj.IsDir() {
+				allDirs = false
+			}
+			if obj == nil {
+				continue
+			}
+			if src < 0 {
+				src = i
+			}
+		}
+		for i, obj := range entries {
+			if i == src {
+				continue
+			}
+			reason := ""
+			switch {
+			case obj == nil:
+				reason = DivergenceMissing
+			case obj.IsDir() != entries[src].IsDir():
+				reason = DivergenceType
+			case obj.IsDir():
+			case obj.GetSize() != entries[src].GetSize():
+				reason = DivergenceSize
+			case hashesDiffer(obj.GetHash(), entries[src].GetHash()):
+				reason = DivergenceHash
+			}
+			if reason != "" {
+				divs = append(divs, Divergence{Path: path, Source: replicas[src], Replica: replicas[i], Reason: reason})
+			}
+		}
+		if allDirs {
+			dirs = append(dirs, path)
+		} else if !entries[src].IsDir() {
+			files++
+		}
+	}
+	return divs, dirs, files
+}
+
+// hashesDiffer tells whether a and b hold different values for a hash type
+// both know.
+func hashesDiffer(a, b utils.HashInfo) bool {
+	for ht, v := range a.All() {
+		if w := b.GetHash(ht); v != "" && w != "" && !strings.EqualFold(v, w) {
+			return true
+		}
+	}
+	return false
+}
+
+var VerifyTaskManager *tache.Manager[*VerifyTask]
+
+func VerifyAsTask(ctx context.Context, args VerifyArgs) *VerifyTask {
+	for i, r := range args.Replicas {
+		args.Replicas[i] = utils.FixAndCleanPath(r)
+	}
+	t := &VerifyTask{
+		WalkTask: task.NewWalkTask[VerifyReport](ctx),
+		args:     args,
+	}
+	VerifyTaskManager.Add(t)
+	return t
+}
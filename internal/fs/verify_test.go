@@ -0,0 +1,47 @@
+package fs
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+func TestCompareListings(t *testing.T) {
+	file := func(name string, size int64, md5 string) model.Obj {
+		return &model.Object{Name: name, Size: size, HashInfo: utils.NewHashInfo(utils.MD5, md5)}
+	}
+	dir := func(name string) model.Obj {
+		return &model.Object{Name: name, IsFolder: true}
+	}
+	replicas := []string{"/115/lib", "/pikpak/lib", "/aliyun/lib"}
+	lists := [][]model.Obj{
+		{file("a.mkv", 10, "aa"), file("b.mkv", 20, "bb"), dir("shows"), dir("extra")},
+		{file("a.mkv", 10, "AA"), file("b.mkv", 21, "bb"), dir("shows"), file("extra", 1, "")},
+		{file("a.mkv", 10, "ff"), dir("shows"), file("c.mkv", 5, "")},
+	}
+	divs, dirs, files := compareListings("/movies", replicas, lists)
+	want := []Divergence{
+		{Path: "/movies/a.mkv", Source: "/115/lib", Replica: "/aliyun/lib", Reason: DivergenceHash},
+		{Path: "/movies/b.mkv", Source: "/115/lib", Replica: "/pikpak/lib", Reason: DivergenceSize},
+		{Path: "/movies/b.mkv", Source: "/115/lib", Replica: "/aliyun/lib", Reason: DivergenceMissing},
+		{Path: "/movies/extra", Source: "/115/lib", Replica: "/pikpak/lib", Reason: DivergenceType},
+		{Path: "/movies/extra", Source: "/115/lib", Replica: "/aliyun/lib", Reason: DivergenceMissing},
+		{Path: "/movies/c.mkv", Source: "/aliyun/lib", Replica: "/115/lib", Reason: DivergenceMissing},
+		{Path: "/movies/c.mkv", Source: "/aliyun/lib", Replica: "/pikpak/lib", Reason: DivergenceMissing},
+	}
+	if len(divs) != len(want) {
+		t.Fatalf("got %d divergences %+v, want %d", len(divs), divs, len(want))
+	}
+	for i := range want {
+		if divs[i] != want[i] {
+			t.Errorf("divergence %d = %+v, want %+v", i, divs[i], want[i])
+		}
+	}
+	if len(dirs) != 1 || dirs[0] != "/movies/shows" {
+		t.Errorf("dirs = %v, want [/movies/shows]", dirs)
+	}
+	if files != 3 {
+		t.Errorf("files = %d, want 3", files)
+	}
+}
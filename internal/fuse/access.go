@@ -0,0 +1,44 @@
+package fuse
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/pkg/errors"
+)
+
+// access checks reqPath against the hide rules and passwords of its nearest
+// meta, as the other front-ends do. A mount has no way to send a password,
+// so protected paths are reachable only by users allowed to skip them. The
+// returned context carries the meta, so listings apply its hide rules.
+func access(ctx context.Context, user *model.User, reqPath string) (context.Context, error) {
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	if !common.CanAccess(user, meta, reqPath, "") {
+		return nil, errs.PermissionDenied
+	}
+	return context.WithValue(ctx, conf.MetaKey, meta), nil
+}
+
+// renameFirst tells how to rename srcPath to dstPath in another dir, which
+// takes a rename and a move. Moving first fails when the destination dir
+// holds the old name, renaming first fails when the source dir holds the
+// new name, so the step that cannot collide goes first.
+func renameFirst(srcPath, dstPath string, exists func(path string) bool) (bool, error) {
+	srcDir, srcBase := stdpath.Split(srcPath)
+	dstDir, dstBase := stdpath.Split(dstPath)
+	if !exists(stdpath.Join(dstDir, srcBase)) {
+		return false, nil
+	}
+	if exists(stdpath.Join(srcDir, dstBase)) {
+		return false, errs.ObjectAlreadyExists
+	}
+	return true, nil
+}
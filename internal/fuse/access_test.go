@@ -0,0 +1,64 @@
+package fuse
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig("data")
+	db.Init(dB)
+}
+
+func TestAccess(t *testing.T) {
+	if err := op.CreateMeta(&model.Meta{Path: "/fuse", Password: "pwd", PSub: true, Hide: "^secret$", HSub: true}); err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Permission: model.PermWebdavRead}
+	privileged := &model.User{Permission: model.PermSeeHides | model.PermAccessWithoutPassword}
+	for _, path := range []string{"/fuse", "/fuse/a", "/fuse/secret"} {
+		if _, err := access(context.Background(), user, path); !errors.Is(err, errs.PermissionDenied) {
+			t.Errorf("%s: expected permission denied, got %v", path, err)
+		}
+		ctx, err := access(context.Background(), privileged, path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if meta, _ := ctx.Value(conf.MetaKey).(*model.Meta); meta == nil || meta.Path != "/fuse" {
+			t.Errorf("%s: expected the meta in context, got %+v", path, meta)
+		}
+	}
+	if _, err := access(context.Background(), user, "/other"); err != nil {
+		t.Errorf("/other: %v", err)
+	}
+}
+
+func TestRenameFirst(t *testing.T) {
+	existing := map[string]bool{}
+	exists := func(path string) bool { return existing[path] }
+	if first, err := renameFirst("/a/x", "/b/y", exists); err != nil || first {
+		t.Errorf("expected move first, got %v %v", first, err)
+	}
+	existing["/b/x"] = true
+	if first, err := renameFirst("/a/x", "/b/y", exists); err != nil || !first {
+		t.Errorf("expected rename first, got %v %v", first, err)
+	}
+	existing["/a/y"] = true
+	if _, err := renameFirst("/a/x", "/b/y", exists); !errors.Is(err, errs.ObjectAlreadyExists) {
+		t.Errorf("expected object already exists, got %v", err)
+	}
+}
@@ -1,170 +1,453 @@
-package fuse
+//go:build fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+package fuse
 
+import (
+	"context"
+	"io"
+	stdpath "path"
+	"strings"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// Fs exposes the OpenList virtual file system below RootFolder as a FUSE
+// file system. Every call is made on behalf of User.
 type Fs struct {
 	RootFolder string
+	User       *model.User
 	fuse.FileSystemBase
-}
-
-func (fs *Fs) Init() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Destroy() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mknod(path string, mode uint32, dev uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mkdir(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Unlink(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rmdir(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Link(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Symlink(target string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readlink(path string) (int, string) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rename(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chmod(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chown(path string, uid uint32, gid uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Access(path string, mask uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
 
-func (fs *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
+	mu      sync.Mutex
+	nextFh  uint64
+	handles map[uint64]*handle
+}
+
+func (f *Fs) Init() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handles = make(map[uint64]*handle)
+}
+
+func (f *Fs) Destroy() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for fh, h := range f.handles {
+		if err := h.flush(f.ctx()); err != nil {
+			utils.Log.Errorf("[fuse] failed to upload [%s] on unmount: %+v", h.path, err)
+		}
+		h.release()
+		delete(f.handles, fh)
+	}
+}
+
+func (f *Fs) ctx() context.Context {
+	return context.WithValue(context.Background(), conf.UserKey, f.User)
+}
+
+func (f *Fs) reqPath(path string) string {
+	return stdpath.Join(f.RootFolder, path)
+}
+
+// check returns the context to serve reqPath with, or the error code to fail
+// with when the meta of reqPath hides or protects it.
+func (f *Fs) check(reqPath string) (context.Context, int) {
+	ctx, err := access(f.ctx(), f.User, reqPath)
+	if err != nil {
+		return nil, errno(err)
+	}
+	return ctx, 0
+}
+
+func (f *Fs) getHandle(fh uint64) *handle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.handles[fh]
+}
+
+// findHandle returns a write handle opened on the given path, so Getattr
+// reports the staged size while a file is being written.
+func (f *Fs) findHandle(path string) *handle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, h := range f.handles {
+		if h.path == path && h.staging != nil {
+			return h
+		}
+	}
+	return nil
+}
+
+func (f *Fs) addHandle(h *handle) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextFh++
+	f.handles[f.nextFh] = h
+	return f.nextFh
+}
+
+func (f *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
+	*stat = fuse.Statfs_t{}
+	stat.Bsize = 4096
+	stat.Frsize = 4096
+	stat.Blocks = 1 << 40 / 4096
+	stat.Bfree = stat.Blocks
+	stat.Bavail = stat.Blocks
+	stat.Namemax = 255
+	return 0
+}
+
+func (f *Fs) Mkdir(path string, mode uint32) int {
+	reqPath := f.reqPath(path)
+	if !f.User.CanAt(model.PermWrite, reqPath) {
+		return -fuse.EACCES
+	}
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code
+	}
+	return errno(fs.MakeDir(ctx, reqPath))
+}
+
+func (f *Fs) Unlink(path string) int {
+	reqPath := f.reqPath(path)
+	if !f.User.CanAt(model.PermRemove, reqPath) {
+		return -fuse.EACCES
+	}
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code
+	}
+	return errno(fs.Remove(ctx, reqPath))
+}
+
+func (f *Fs) Rmdir(path string) int {
+	reqPath := f.reqPath(path)
+	if !f.User.CanAt(model.PermRemove, reqPath) {
+		return -fuse.EACCES
+	}
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code
+	}
+	objs, err := fs.List(ctx, reqPath, &fs.ListArgs{})
+	if err != nil {
+		return errno(err)
+	}
+	if len(objs) > 0 {
+		return -fuse.ENOTEMPTY
+	}
+	return errno(fs.Remove(ctx, reqPath))
+}
+
+func (f *Fs) Rename(oldpath string, newpath string) int {
+	srcPath, dstPath := f.reqPath(oldpath), f.reqPath(newpath)
+	ctx, code := f.check(srcPath)
+	if code != 0 {
+		return code
+	}
+	if _, code = f.check(dstPath); code != 0 {
+		return code
+	}
+	srcDir, srcBase := stdpath.Split(srcPath)
+	dstDir, dstBase := stdpath.Split(dstPath)
+	if srcDir == dstDir {
+		if !f.User.CanAt(model.PermRename, srcPath) {
+			return -fuse.EACCES
+		}
+		return errno(fs.Rename(ctx, srcPath, dstBase))
+	}
+	perm := model.PermMove
+	if srcBase != dstBase {
+		perm |= model.PermRename
+	}
+	if !f.User.CanAt(perm, srcPath) || !f.User.CanAt(perm, dstDir) {
+		return -fuse.EACCES
+	}
+	if srcBase == dstBase {
+		_, err := fs.Move(ctx, srcPath, dstDir)
+		return errno(err)
+	}
+	first, err := renameFirst(srcPath, dstPath, func(path string) bool {
+		_, err := fs.Get(ctx, path, &fs.GetArgs{NoLog: true})
+		return err == nil
+	})
+	if err != nil {
+		return errno(err)
+	}
+	if first {
+		if err = fs.Rename(ctx, srcPath, dstBase, true); err != nil {
+			return errno(err)
+		}
+		_, err = fs.Move(ctx, stdpath.Join(srcDir, dstBase), dstDir)
+		return errno(err)
+	}
+	if _, err = fs.Move(ctx, srcPath, dstDir); err != nil {
+		return errno(err)
+	}
+	return errno(fs.Rename(ctx, stdpath.Join(dstDir, srcBase), dstBase, true))
+}
+
+func (f *Fs) Chmod(path string, mode uint32) int {
+	return 0
+}
+
+func (f *Fs) Chown(path string, uid uint32, gid uint32) int {
+	return 0
+}
+
+func (f *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
+	return 0
+}
+
+func (f *Fs) Access(path string, mask uint32) int {
+	return 0
+}
+
+func (f *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
+	reqPath := f.reqPath(path)
+	if !f.User.CanAt(model.PermWrite, reqPath) {
+		return -fuse.EACCES, ^uint64(0)
+	}
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code, ^uint64(0)
+	}
+	h := &handle{path: reqPath}
+	if err := h.truncate(ctx, 0); err != nil {
+		return errno(err), ^uint64(0)
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Open(path string, flags int) (int, uint64) {
+	reqPath := f.reqPath(path)
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code, ^uint64(0)
+	}
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR, ^uint64(0)
+	}
+	h := &handle{path: reqPath, obj: obj, size: obj.GetSize()}
+	if flags&fuse.O_ACCMODE != fuse.O_RDONLY {
+		if !f.User.CanAt(model.PermWrite, reqPath) {
+			return -fuse.EACCES, ^uint64(0)
+		}
+		if flags&fuse.O_TRUNC != 0 {
+			if err = h.truncate(ctx, 0); err != nil {
+				return errno(err), ^uint64(0)
+			}
+		}
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
+	reqPath := f.reqPath(path)
+	h := f.getHandle(fh)
+	if h == nil {
+		h = f.findHandle(reqPath)
+	}
+	if h != nil {
+		if size, staged := h.stat(); staged {
+			fillStat(stat, &model.Object{Name: stdpath.Base(reqPath), Size: size})
+			return 0
+		}
+	}
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code
+	}
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	fillStat(stat, obj)
+	return 0
+}
+
+func (f *Fs) Truncate(path string, size int64, fh uint64) int {
+	reqPath := f.reqPath(path)
+	if !f.User.CanAt(model.PermWrite, reqPath) {
+		return -fuse.EACCES
+	}
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code
+	}
+	if h := f.getHandle(fh); h != nil {
+		return errno(h.truncate(ctx, size))
+	}
+	// truncate(2) without an open file, stage and upload right away
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	h := &handle{path: reqPath, obj: obj, size: obj.GetSize()}
+	defer h.release()
+	if err = h.truncate(ctx, size); err != nil {
+		return errno(err)
+	}
+	return errno(h.flush(ctx))
+}
+
+func (f *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	n, err := h.readAt(f.ctx(), buff, ofst)
+	if n > 0 || err == nil {
+		return n
+	}
+	return readErrno(err)
+}
+
+func (f *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	n, err := h.writeAt(f.ctx(), buff, ofst)
+	if err != nil && n == 0 {
+		return errno(err)
+	}
+	return n
+}
+
+func (f *Fs) Flush(path string, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	return errno(h.flush(f.ctx()))
+}
+
+func (f *Fs) Release(path string, fh uint64) int {
+	f.mu.Lock()
+	h := f.handles[fh]
+	delete(f.handles, fh)
+	f.mu.Unlock()
+	if h == nil {
+		return -fuse.EBADF
+	}
+	defer h.release()
+	return errno(h.flush(f.ctx()))
+}
+
+func (f *Fs) Fsync(path string, datasync bool, fh uint64) int {
+	return f.Flush(path, fh)
+}
+
+func (f *Fs) Opendir(path string) (int, uint64) {
+	reqPath := f.reqPath(path)
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code, ^uint64(0)
+	}
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if !obj.IsDir() {
+		return -fuse.ENOTDIR, ^uint64(0)
+	}
+	return 0, 0
+}
+
+func (f *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
+	reqPath := f.reqPath(path)
+	ctx, code := f.check(reqPath)
+	if code != 0 {
+		return code
+	}
+	objs, err := fs.List(ctx, reqPath, &fs.ListArgs{})
+	if err != nil {
+		return errno(err)
+	}
+	fill(".", nil, 0)
+	fill("..", nil, 0)
+	for _, obj := range objs {
+		stat := &fuse.Stat_t{}
+		fillStat(stat, obj)
+		if !fill(obj.GetName(), stat, 0) {
+			break
+		}
+	}
+	return 0
+}
+
+func (f *Fs) Releasedir(path string, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
+	return 0
+}
+
+func fillStat(stat *fuse.Stat_t, obj model.Obj) {
+	uid, gid, _ := fuse.Getcontext()
+	*stat = fuse.Stat_t{}
+	if obj.IsDir() {
+		stat.Mode = fuse.S_IFDIR | 0o755
+		stat.Nlink = 2
+	} else {
+		stat.Mode = fuse.S_IFREG | 0o644
+		stat.Nlink = 1
+		stat.Size = obj.GetSize()
+	}
+	stat.Uid, stat.Gid = uid, gid
+	stat.Blksize = 4096
+	stat.Blocks = (stat.Size + 511) / 512
+	mtime := fuse.NewTimespec(obj.ModTime())
+	stat.Mtim, stat.Ctim, stat.Atim = mtime, mtime, mtime
+	stat.Birthtim = fuse.NewTimespec(obj.CreateTime())
+}
+
+// errno maps errors of the fs package to negative FUSE error codes.
+func errno(err error) int {
+	if err == nil {
+		return 0
+	}
+	cause := errors.Cause(err)
+	switch {
+	case errs.IsObjectNotFound(err), errs.IsNotFoundError(err),
+		errors.Is(cause, errs.StorageNotFound):
+		return -fuse.ENOENT
+	case errors.Is(cause, errs.PermissionDenied):
+		return -fuse.EACCES
+	case errors.Is(cause, errs.ObjectAlreadyExists):
+		return -fuse.EEXIST
+	case errors.Is(cause, errs.NotFolder):
+		return -fuse.ENOTDIR
+	case errors.Is(cause, errs.NotFile):
+		return -fuse.EISDIR
+	case errs.IsNotSupportError(err), errs.IsNotImplementError(err),
+		errors.Is(cause, errs.UploadNotSupported):
+		return -fuse.ENOSYS
+	}
+	if strings.Contains(err.Error(), "not found") {
+		return -fuse.ENOENT
+	}
+	utils.Log.Debugf("[fuse] %+v", err)
+	return -fuse.EIO
+}
+
+func readErrno(err error) int {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0
+	}
+	return errno(err)
 }
-
-func (fs *Fs) Open(path string, flags int) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Truncate(path string, size int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Flush(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Release(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsync(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Opendir(path string) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Releasedir(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Setxattr(path string, name string, value []byte, flags int) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getxattr(path string, name string) (int, []byte) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Removexattr(path string, name string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Listxattr(path string, fill func(name string) bool) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-var _ fuse.FileSystemInterface = (*Fs)(nil)
@@ -0,0 +1,183 @@
+package fuse
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// handle is an opened file. Reads without pending writes are served by a
+// range reader built from fs.Link; once written, the content lives in a
+// local staging file and is uploaded through fs.PutDirectly on flush.
+type handle struct {
+	mu   sync.Mutex
+	path string
+	obj  model.Obj
+
+	reader model.File
+	closer io.Closer
+
+	staging *os.File
+	size    int64
+	dirty   bool
+}
+
+func (h *handle) readAt(ctx context.Context, buff []byte, ofst int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.staging != nil {
+		return h.staging.ReadAt(buff, ofst)
+	}
+	if h.reader == nil {
+		if err := h.openReader(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return h.reader.ReadAt(buff, ofst)
+}
+
+func (h *handle) openReader(ctx context.Context) error {
+	link, obj, err := fs.Link(ctx, h.path, model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		_ = link.Close()
+		return err
+	}
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		_ = ss.Close()
+		return err
+	}
+	h.obj, h.reader, h.closer = obj, reader, ss
+	return nil
+}
+
+// stage makes sure the handle owns a staging file. When keep is set the
+// current remote content is copied into it first so partial writes do not
+// lose the rest of the file.
+func (h *handle) stage(ctx context.Context, keep bool) error {
+	if h.staging != nil {
+		return nil
+	}
+	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "fuse-*")
+	if err != nil {
+		return err
+	}
+	if keep && h.obj != nil && h.obj.GetSize() > 0 {
+		if h.reader == nil {
+			if err = h.openReader(ctx); err != nil {
+				_ = tmpFile.Close()
+				_ = os.Remove(tmpFile.Name())
+				return err
+			}
+		}
+		n, err := io.Copy(tmpFile, io.NewSectionReader(h.reader, 0, h.obj.GetSize()))
+		if err != nil {
+			_ = tmpFile.Close()
+			_ = os.Remove(tmpFile.Name())
+			return errors.WithMessage(err, "failed copy remote content to staging file")
+		}
+		h.size = n
+	}
+	h.staging = tmpFile
+	return nil
+}
+
+func (h *handle) writeAt(ctx context.Context, buff []byte, ofst int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.stage(ctx, true); err != nil {
+		return 0, err
+	}
+	n, err := h.staging.WriteAt(buff, ofst)
+	if end := ofst + int64(n); end > h.size {
+		h.size = end
+	}
+	if n > 0 {
+		h.dirty = true
+	}
+	return n, err
+}
+
+func (h *handle) truncate(ctx context.Context, size int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.stage(ctx, size > 0); err != nil {
+		return err
+	}
+	if err := h.staging.Truncate(size); err != nil {
+		return err
+	}
+	h.size = size
+	h.dirty = true
+	return nil
+}
+
+// flush uploads the staging file if it has changed since the last upload.
+func (h *handle) flush(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.dirty || h.staging == nil {
+		return nil
+	}
+	dir, name := stdpath.Split(h.path)
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     h.size,
+			Modified: time.Now(),
+		},
+		Mimetype: utils.GetMimeType(name),
+		Reader:   io.NewSectionReader(h.staging, 0, h.size),
+	}
+	if err := fs.PutDirectly(ctx, dir, s); err != nil {
+		return err
+	}
+	h.dirty = false
+	// the remote object changed, drop the stale reader
+	if h.closer != nil {
+		_ = h.closer.Close()
+		h.reader, h.closer = nil, nil
+	}
+	return nil
+}
+
+func (h *handle) stat() (int64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.size, h.staging != nil
+}
+
+func (h *handle) release() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closer != nil {
+		_ = h.closer.Close()
+		h.reader, h.closer = nil, nil
+	}
+	if h.staging != nil {
+		name := h.staging.Name()
+		_ = h.staging.Close()
+		if err := os.Remove(name); err != nil {
+			log.Warnf("[fuse] failed to remove staging file [%s]: %+v", name, err)
+		}
+		h.staging = nil
+	}
+}
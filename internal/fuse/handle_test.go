@@ -0,0 +1,41 @@
+package fuse
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+)
+
+func TestHandleStaging(t *testing.T) {
+	conf.Conf.TempDir = t.TempDir()
+	h := &handle{path: "/new.txt"}
+	defer h.release()
+	ctx := context.Background()
+	if err := h.truncate(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.writeAt(ctx, []byte("hello world"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.writeAt(ctx, []byte("W"), 6); err != nil {
+		t.Fatal(err)
+	}
+	if size, staged := h.stat(); !staged || size != 11 {
+		t.Errorf("expected 11 staged bytes, got %d (%v)", size, staged)
+	}
+	if err := h.truncate(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	buff := make([]byte, 16)
+	n, _ := h.readAt(ctx, buff, 0)
+	if string(buff[:n]) != "hello" {
+		t.Errorf("expected hello, got %q", buff[:n])
+	}
+	name := h.staging.Name()
+	h.release()
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("staging file %s left behind", name)
+	}
+}
@@ -1,9 +1,22 @@
+//go:build fuse
+
 package fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/winfsp/cgofuse/fuse"
+)
 
-func Mount(mountSrc, mountDst string, opts []string) {
-	fs := &Fs{RootFolder: mountSrc}
+// NewHost creates a FUSE host serving mountSrc (a path of the OpenList
+// virtual file system, relative to the user's base path) for user.
+// Call Mount on the returned host to mount it; Mount blocks until Unmount.
+func NewHost(user *model.User, mountSrc string) (*fuse.FileSystemHost, error) {
+	root, err := user.JoinPath(mountSrc)
+	if err != nil {
+		return nil, err
+	}
+	fs := &Fs{RootFolder: root, User: user}
 	host := fuse.NewFileSystemHost(fs)
-	go host.Mount(mountDst, opts)
+	host.SetCapReaddirPlus(true)
+	return host, nil
 }
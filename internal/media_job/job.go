@@ -0,0 +1,217 @@
+package media_job
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// runRetention is how long finished runs are kept in the database.
+const runRetention = 30 * 24 * time.Hour
+
+var (
+	ErrJobNotActive = errors.New("media job is not active")
+	ErrJobRunning   = errors.New("media job is already running")
+)
+
+// Job is a named step of a media storage's scheduled maintenance.
+type Job struct {
+	Name string
+	Run  func() error
+}
+
+// Func adapts a scan without an error result to Job.Run.
+func Func(f func()) func() error {
+	return func() error {
+		f()
+		return nil
+	}
+}
+
+type entry struct {
+	Job
+	id uint
+	// running is set once a run is claimed, including while it waits for
+	// the other jobs of the group
+	running bool
+}
+
+// Group runs the jobs of one storage one after another on a fixed interval,
+// as the driver crons did before, while tracking every job in the database.
+// Jobs of a group never run concurrently, including manual runs.
+type Group struct {
+	storageID uint
+	source    string
+	interval  time.Duration
+	jobs      []*entry
+	cron      *cron.Cron
+
+	runMu   sync.Mutex
+	mu      sync.Mutex
+	current *model.MediaJobRun
+}
+
+func NewGroup(storageID uint, source string, interval time.Duration, jobs ...Job) *Group {
+	g := &Group{storageID: storageID, source: source, interval: interval}
+	for _, job := range jobs {
+		g.jobs = append(g.jobs, &entry{Job: job})
+	}
+	return g
+}
+
+// Start registers the jobs and schedules the first run one interval from now.
+func (g *Group) Start() {
+	next := time.Now().Add(g.interval)
+	for _, e := range g.jobs {
+		job, err := db.EnsureMediaJob(g.storageID, g.source, e.Name)
+		if err != nil {
+			utils.Log.Warnf("[media_job] failed to register %s job %s: %v", g.source, e.Name, err)
+			continue
+		}
+		e.id = job.ID
+		if err = db.UpdateMediaJobSchedule(e.id, g.interval, next); err != nil {
+			utils.Log.Warnf("[media_job] failed to update schedule of job %d: %v", e.id, err)
+		}
+		register(e.id, g)
+	}
+	g.cron = cron.NewCron(g.interval)
+	g.cron.Do(g.tick)
+}
+
+func (g *Group) Stop() {
+	if g == nil {
+		return
+	}
+	if g.cron != nil {
+		g.cron.Stop()
+	}
+	for _, e := range g.jobs {
+		if e.id != 0 {
+			unregister(e.id, g)
+		}
+	}
+}
+
+// Processed adds n items to the counters of the run in progress.
+// It is a no-op outside a run, so scans can also be called directly.
+func (g *Group) Processed(n int) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.current != nil {
+		g.current.Processed += n
+	}
+}
+
+// Failed adds n items to the failures of the run in progress.
+func (g *Group) Failed(n int) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.current != nil {
+		g.current.Failed += n
+	}
+}
+
+func (g *Group) tick() {
+	next := time.Now().Add(g.interval)
+	for _, e := range g.jobs {
+		if e.id != 0 {
+			if err := db.UpdateMediaJobNextRun(e.id, next); err != nil {
+				utils.Log.Warnf("[media_job] failed to update schedule of job %d: %v", e.id, err)
+			}
+			if job, err := db.GetMediaJob(e.id); err == nil && job.Paused {
+				continue
+			}
+		}
+		if !g.claim(e) {
+			continue
+		}
+		g.run(e, model.MediaJobTriggerSchedule)
+	}
+}
+
+// claim marks e as running, false if a run of it is claimed already.
+func (g *Group) claim(e *entry) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if e.running {
+		return false
+	}
+	e.running = true
+	return true
+}
+
+// run runs e, which the caller has claimed.
+func (g *Group) run(e *entry, trigger string) {
+	g.runMu.Lock()
+	defer g.runMu.Unlock()
+
+	run := &model.MediaJobRun{JobID: e.id, Trigger: trigger, StartAt: time.Now()}
+	if e.id != 0 {
+		if err := db.StartMediaJobRun(run); err != nil {
+			utils.Log.Warnf("[media_job] failed to record run of job %d: %v", e.id, err)
+		}
+	}
+	g.mu.Lock()
+	g.current = run
+	g.mu.Unlock()
+
+	err := safeRun(e.Run)
+
+	g.mu.Lock()
+	g.current = nil
+	e.running = false
+	g.mu.Unlock()
+
+	end := time.Now()
+	run.EndAt = &end
+	if err != nil {
+		run.Error = err.Error()
+		utils.Log.Warnf("[media_job] %s job %s failed: %v", g.source, e.Name, err)
+	}
+	if e.id == 0 || run.ID == 0 {
+		return
+	}
+	if err = db.FinishMediaJobRun(run); err != nil {
+		utils.Log.Warnf("[media_job] failed to record result of job %d: %v", e.id, err)
+	}
+	if err = db.DeleteMediaJobRunsBefore(end.Add(-runRetention)); err != nil {
+		utils.Log.Warnf("[media_job] failed to clean up job runs: %v", err)
+	}
+}
+
+func safeRun(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f()
+}
+
+func (g *Group) entry(id uint) *entry {
+	for _, e := range g.jobs {
+		if e.id == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (g *Group) isRunning(id uint) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	e := g.entry(id)
+	return e != nil && e.running
+}
@@ -0,0 +1,193 @@
+package media_job
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	dataDir, err := os.MkdirTemp("", "media-job-test-")
+	if err != nil {
+		panic(err)
+	}
+	conf.Conf = conf.DefaultConfig(dataDir)
+	database, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "media-job-test.db")), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+	if err := db.Init(database); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	if sqlDB, sqlErr := database.DB(); sqlErr == nil {
+		_ = sqlDB.Close()
+	}
+	_ = os.RemoveAll(dataDir)
+	os.Exit(code)
+}
+
+func findJob(t *testing.T, storageID uint, name string) Info {
+	t.Helper()
+	infos, err := List(storageID)
+	if err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	for _, info := range infos {
+		if info.Name == name {
+			return info
+		}
+	}
+	t.Fatalf("job %s of storage %d not found in %+v", name, storageID, infos)
+	return Info{}
+}
+
+func TestGroupRecordsRuns(t *testing.T) {
+	var g *Group
+	var scanned []string
+	g = NewGroup(1, "javdb", time.Hour,
+		Job{Name: "scan", Run: func() error {
+			scanned = append(scanned, "scan")
+			g.Processed(3)
+			g.Failed(1)
+			return nil
+		}},
+		Job{Name: "broken", Run: func() error {
+			scanned = append(scanned, "broken")
+			return errors.New("spider unavailable")
+		}},
+	)
+	g.Start()
+	defer g.Stop()
+
+	job := findJob(t, 1, "scan")
+	if !job.Active || job.Interval != int64(time.Hour/time.Second) || job.NextRunAt == nil {
+		t.Fatalf("registered job = %+v", job)
+	}
+
+	g.tick()
+	if len(scanned) != 2 || scanned[0] != "scan" || scanned[1] != "broken" {
+		t.Fatalf("scanned = %v", scanned)
+	}
+	job = findJob(t, 1, "scan")
+	if job.LastStartAt == nil || job.LastEndAt == nil || job.LastProcessed != 3 || job.LastFailed != 1 || job.LastError != "" {
+		t.Fatalf("scan job = %+v", job)
+	}
+	broken := findJob(t, 1, "broken")
+	if broken.LastError != "spider unavailable" {
+		t.Fatalf("broken job = %+v", broken)
+	}
+	runs, err := db.ListMediaJobRuns(job.ID, 10)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Trigger != model.MediaJobTriggerSchedule || runs[0].Processed != 3 || runs[0].EndAt == nil {
+		t.Fatalf("runs = %+v", runs)
+	}
+
+	// counters outside a run are ignored
+	g.Processed(5)
+	var nilGroup *Group
+	nilGroup.Failed(1)
+	nilGroup.Stop()
+}
+
+func TestPauseSkipsScheduledRunsButNotManual(t *testing.T) {
+	done := make(chan struct{}, 1)
+	runs := 0
+	g := NewGroup(2, "fc2", time.Hour, Job{Name: "nfo", Run: func() error {
+		runs++
+		done <- struct{}{}
+		return nil
+	}})
+	g.Start()
+
+	job := findJob(t, 2, "nfo")
+	if err := Pause(job.ID); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	g.tick()
+	if runs != 0 {
+		t.Fatalf("paused job ran %d times", runs)
+	}
+
+	if err := RunNow(job.ID); err != nil {
+		t.Fatalf("run now: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("manual run did not start")
+	}
+	// wait for the run to be recorded
+	g.runMu.Lock()
+	g.runMu.Unlock()
+	recorded, err := db.ListMediaJobRuns(job.ID, 10)
+	if err != nil || len(recorded) != 1 || recorded[0].Trigger != model.MediaJobTriggerManual {
+		t.Fatalf("runs = %+v, err = %v", recorded, err)
+	}
+
+	if err = Resume(job.ID); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	g.tick()
+	if runs != 2 {
+		t.Fatalf("runs = %d after resume", runs)
+	}
+
+	g.Stop()
+	if err = RunNow(job.ID); !errors.Is(err, ErrJobNotActive) {
+		t.Fatalf("run stopped job err = %v", err)
+	}
+	if info := findJob(t, 2, "nfo"); info.Active {
+		t.Fatalf("stopped job still active: %+v", info)
+	}
+}
+
+func TestRunNowClaimsOnce(t *testing.T) {
+	started, release := make(chan struct{}, 8), make(chan struct{})
+	g := NewGroup(3, "javdb", time.Hour, Job{Name: "slow", Run: func() error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}})
+	g.Start()
+	defer g.Stop()
+
+	job := findJob(t, 3, "slow")
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		go func() { errs <- RunNow(job.ID) }()
+	}
+	claimed := 0
+	for i := 0; i < cap(errs); i++ {
+		err := <-errs
+		if err == nil {
+			claimed++
+		} else if !errors.Is(err, ErrJobRunning) {
+			t.Fatalf("run now: %v", err)
+		}
+	}
+	if claimed != 1 {
+		t.Fatalf("%d runs claimed", claimed)
+	}
+	if !findJob(t, 3, "slow").Running {
+		t.Fatal("claimed job not reported running")
+	}
+	<-started
+	close(release)
+	g.runMu.Lock()
+	g.runMu.Unlock()
+	if len(started) != 0 {
+		t.Fatalf("%d more runs started", len(started))
+	}
+}
@@ -0,0 +1,87 @@
+package media_job
+
+import (
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[uint]*Group{}
+)
+
+// Info is a MediaJob together with its state in this process.
+type Info struct {
+	model.MediaJob
+	// Active is false when the storage owning the job is disabled or gone
+	Active  bool `json:"active"`
+	Running bool `json:"running"`
+}
+
+func register(id uint, g *Group) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[id] = g
+}
+
+func unregister(id uint, g *Group) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if registry[id] == g {
+		delete(registry, id)
+	}
+}
+
+func lookup(id uint) *Group {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[id]
+}
+
+// List returns the jobs of a storage, storageID 0 lists all of them.
+func List(storageID uint) ([]Info, error) {
+	jobs, err := db.ListMediaJobs(storageID)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]Info, 0, len(jobs))
+	for _, job := range jobs {
+		info := Info{MediaJob: job}
+		if g := lookup(job.ID); g != nil {
+			info.Active = true
+			info.Running = g.isRunning(job.ID)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// RunNow starts a job in the background, ignoring its paused flag.
+// The run waits for any other job of the same storage to finish first.
+func RunNow(id uint) error {
+	g := lookup(id)
+	if g == nil {
+		return ErrJobNotActive
+	}
+	e := g.entry(id)
+	if e == nil {
+		return ErrJobNotActive
+	}
+	// claimed before the goroutine starts, so concurrent calls run it once
+	if !g.claim(e) {
+		return ErrJobRunning
+	}
+	go g.run(e, model.MediaJobTriggerManual)
+	return nil
+}
+
+// Pause skips the job on its schedule until it is resumed.
+func Pause(id uint) error {
+	return db.SetMediaJobPaused(id, true)
+}
+
+func Resume(id uint) error {
+	return db.SetMediaJobPaused(id, false)
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// APITokenPrefix starts every personal API token, it tells them apart from
+// login JWTs and the admin token.
+const APITokenPrefix = "olt_"
+
+const (
+	TokenScopeFsRead       = "fs:read"
+	TokenScopeFsWrite      = "fs:write"
+	TokenScopeAdminStorage = "admin:storage"
+	TokenScopeTasks        = "tasks"
+)
+
+var TokenScopes = []string{TokenScopeFsRead, TokenScopeFsWrite, TokenScopeAdminStorage, TokenScopeTasks}
+
+// WritePermissions are the permission bits a token without the fs:write
+// scope drops from its user.
+const WritePermissions = PermOfflineDownload | PermWrite | PermRename | PermMove | PermCopy | PermRemove |
+	PermWebdavManage | PermFTPManage | PermDecompress | PermShare
+
+// APIToken is a personal token acting as its user, narrowed down to Scopes
+// and, when set, to Paths and everything below them. Paths are relative to
+// the user's base path, like any path the user sends.
+type APIToken struct {
+	ID         uint        `json:"id" gorm:"primaryKey"`
+	UserID     uint        `json:"-" gorm:"index"`
+	Name       string      `json:"name"`
+	Hash       string      `json:"-" gorm:"uniqueIndex;size:64"`
+	Hint       string      `json:"hint"`
+	Scopes     StringArray `json:"scopes" gorm:"type:json;serializer:json"`
+	Paths      StringArray `json:"paths" gorm:"type:json;serializer:json"`
+	ExpiresAt  *time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time  `json:"last_used_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func HashAPIToken(raw string) string {
+	return utils.HashData(utils.SHA256, []byte(raw))
+}
+
+func IsAPIToken(raw string) bool {
+	return strings.HasPrefix(raw, APITokenPrefix)
+}
+
+func (t *APIToken) Validate() error {
+	for _, scope := range t.Scopes {
+		if !utils.SliceContains(TokenScopes, scope) {
+			return fmt.Errorf("unknown scope: %s", scope)
+		}
+	}
+	for i, p := range t.Paths {
+		t.Paths[i] = utils.FixAndCleanPath(p)
+	}
+	return nil
+}
+
+func (t *APIToken) Expired() bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.IsZero() && t.ExpiresAt.Before(time.Now())
+}
+
+func (t *APIToken) HasScope(scope string) bool {
+	return utils.SliceContains(t.Scopes, scope)
+}
+
+// Narrow returns a copy of u limited to what the token allows.
+func (t *APIToken) Narrow(u *User) *User {
+	narrowed := *u.WithPathLimits(t.Paths)
+	if !t.HasScope(TokenScopeFsWrite) {
+		narrowed.Permission &^= WritePermissions
+		narrowed.Grants = make([]PathPermission, len(u.Grants))
+		for i, g := range u.Grants {
+			narrowed.Grants[i] = PathPermission{Path: g.Path, Permission: g.Permission &^ WritePermissions}
+		}
+	}
+	return &narrowed
+}
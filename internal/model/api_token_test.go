@@ -0,0 +1,50 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPITokenNarrow(t *testing.T) {
+	u := &User{
+		BasePath:   "/library",
+		Permission: PermWrite | PermWebdavRead,
+		Grants:     []PathPermission{{Path: "/library/media", Permission: PermRemove | PermShare}},
+	}
+	token := &APIToken{Scopes: StringArray{TokenScopeFsRead}, Paths: StringArray{"/media"}}
+	if err := token.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	narrowed := token.Narrow(u)
+	if narrowed.CanAt(PermWrite, "/library/media") || narrowed.CanAt(PermRemove, "/library/media/a") {
+		t.Errorf("read-only token kept write permissions")
+	}
+	if !narrowed.CanAt(PermWebdavRead, "/library/media") {
+		t.Errorf("read-only token lost read permissions")
+	}
+	if !u.CanAt(PermWrite|PermRemove, "/library/media") {
+		t.Errorf("narrowing changed the original user")
+	}
+	if p, err := narrowed.JoinPath("/media/movies"); err != nil || p != "/library/media/movies" {
+		t.Errorf("JoinPath inside limit = %q, %v", p, err)
+	}
+	if _, err := narrowed.JoinPath("/docs"); err == nil {
+		t.Errorf("JoinPath outside limit should fail")
+	}
+}
+
+func TestAPITokenValidateAndExpiry(t *testing.T) {
+	if err := (&APIToken{Scopes: StringArray{"admin:all"}}).Validate(); err == nil {
+		t.Errorf("unknown scope should fail")
+	}
+	past := time.Now().Add(-time.Hour)
+	if !(&APIToken{ExpiresAt: &past}).Expired() {
+		t.Errorf("token with past expiry should be expired")
+	}
+	if (&APIToken{}).Expired() {
+		t.Errorf("token without expiry never expires")
+	}
+	if !IsAPIToken(APITokenPrefix+"abc") || IsAPIToken("openlist-abc") {
+		t.Errorf("IsAPIToken mismatch")
+	}
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+const (
+	AuditProtocolHTTP   = "http"
+	AuditProtocolWebDAV = "webdav"
+	AuditProtocolFTP    = "ftp"
+	AuditProtocolSFTP   = "sftp"
+	AuditProtocolS3     = "s3"
+)
+
+const (
+	AuditSucceeded = "succeeded"
+	AuditFailed    = "failed"
+)
+
+// Audit actions of filesystem writes.
+const (
+	AuditMkdir      = "mkdir"
+	AuditRename     = "rename"
+	AuditMove       = "move"
+	AuditCopy       = "copy"
+	AuditMerge      = "merge"
+	AuditRemove     = "remove"
+	AuditUpload     = "upload"
+	AuditDecompress = "decompress"
+	AuditSetModTime = "set_mtime"
+)
+
+// Audit actions of admin changes.
+const (
+	AuditShareCreate    = "share_create"
+	AuditShareUpdate    = "share_update"
+	AuditShareDelete    = "share_delete"
+	AuditStorageCreate  = "storage_create"
+	AuditStorageUpdate  = "storage_update"
+	AuditStorageDelete  = "storage_delete"
+	AuditStorageEnable  = "storage_enable"
+	AuditStorageDisable = "storage_disable"
+	AuditUserCreate     = "user_create"
+	AuditUserUpdate     = "user_update"
+	AuditUserDelete     = "user_delete"
+	AuditSettingSave    = "setting_save"
+	AuditSettingDelete  = "setting_delete"
+)
+
+// AuditLog records who did what through which front-end. Path is the object
+// acted on, Dst the destination of renames, moves and copies. Admin changes
+// keep what they changed (a mount path, username or setting keys) in Target.
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username" gorm:"index"`
+	Protocol  string    `json:"protocol" gorm:"index"`
+	IP        string    `json:"ip"`
+	Action    string    `json:"action" gorm:"index"`
+	Path      string    `json:"path" gorm:"index"`
+	Dst       string    `json:"dst"`
+	Target    string    `json:"target"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error" gorm:"type:text"`
+}
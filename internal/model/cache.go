@@ -21,3 +21,27 @@ type CacheList struct {
 	Data      []CachedObj `gorm:"type:json;serializer:json"`
 	UpdatedAt time.Time
 }
+
+const (
+	CacheChangeAdded    = "added"
+	CacheChangeRemoved  = "removed"
+	CacheChangeModified = "modified"
+)
+
+// CacheChange records one entry that differs between two snapshots of a
+// cached directory.
+type CacheChange struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	StorageID   uint      `json:"storage_id" gorm:"index:idx_cache_change_storage_time"`
+	DirPath     string    `json:"dir_path"`
+	Path        string    `json:"path"`
+	Name        string    `json:"name"`
+	IsFolder    bool      `json:"is_folder"`
+	Type        string    `json:"type"`
+	Reasons     string    `json:"reasons"` // comma separated type, size, mtime and hash for modified entries
+	Size        int64     `json:"size"`
+	OldSize     int64     `json:"old_size"`
+	Modified    time.Time `json:"modified"`
+	OldModified time.Time `json:"old_modified"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index:idx_cache_change_storage_time"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// ChunkRef counts the manifests of a Chunk storage in dedup mode that
+// reference a pooled chunk.
+type ChunkRef struct {
+	ID        uint   `gorm:"primaryKey"`
+	StorageID uint   `gorm:"uniqueIndex:idx_chunk_ref_storage_hash"`
+	Hash      string `gorm:"uniqueIndex:idx_chunk_ref_storage_hash"`
+	Size      int64
+	Refs      int
+	UpdatedAt time.Time
+}
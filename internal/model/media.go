@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -118,8 +119,35 @@ type FilmWork struct {
 	MetadataVersion uint `gorm:"not null;default:1"`
 	NfoVersion      uint `gorm:"not null;default:0"`
 	NfoLastError    string
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	// LockedFields are columns edited by hand that scrapers must not overwrite
+	LockedFields StringArray `gorm:"type:json;serializer:json"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Columns of FilmWork that can be edited by hand and locked against scrapers.
+const (
+	MediaFieldRawTitle        = "raw_title"
+	MediaFieldTranslatedTitle = "translated_title"
+	MediaFieldSynopsis        = "synopsis"
+	MediaFieldImageURL        = "image_url"
+	MediaFieldReleaseDate     = "release_date"
+	MediaFieldActors          = "actors"
+	MediaFieldTags            = "tags"
+)
+
+var MediaLockableFields = []string{
+	MediaFieldRawTitle, MediaFieldTranslatedTitle, MediaFieldSynopsis, MediaFieldImageURL,
+	MediaFieldReleaseDate, MediaFieldActors, MediaFieldTags,
+}
+
+// MediaSearchFields are the columns of FilmWork copied into the search index.
+var MediaSearchFields = []string{
+	MediaFieldRawTitle, MediaFieldTranslatedTitle, MediaFieldReleaseDate, MediaFieldActors, MediaFieldTags,
+}
+
+func (w FilmWork) IsFieldLocked(field string) bool {
+	return slices.Contains(w.LockedFields, field)
 }
 
 type FilmFile struct {
@@ -146,10 +174,53 @@ type SourceMagnet struct {
 	Subtitle  bool
 	ScanAt    *time.Time
 	LastError string
+
+	SuccessCount  int
+	FailureCount  int
+	FailureStreak int
+	LastSuccessAt *time.Time
+	CooldownUntil *time.Time
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+const (
+	sourceMagnetBaseCooldown = 10 * time.Minute
+	sourceMagnetMaxCooldown  = 24 * time.Hour
+)
+
+// HealthScore ranks magnets for playback, every success outweighs a failure.
+func (m SourceMagnet) HealthScore() int {
+	return 2*m.SuccessCount - m.FailureCount
+}
+
+func (m SourceMagnet) CoolingDown(now time.Time) bool {
+	return m.CooldownUntil != nil && now.Before(*m.CooldownUntil)
+}
+
+func (m *SourceMagnet) RecordSuccess(now time.Time) {
+	m.SuccessCount++
+	m.FailureStreak = 0
+	m.LastSuccessAt = &now
+	m.CooldownUntil = nil
+	m.LastError = ""
+}
+
+// RecordFailure puts the magnet into a cooldown that doubles with every
+// consecutive failure.
+func (m *SourceMagnet) RecordFailure(message string, now time.Time) {
+	m.FailureCount++
+	m.FailureStreak++
+	m.LastError = message
+	cooldown := sourceMagnetMaxCooldown
+	if m.FailureStreak <= 8 {
+		cooldown = min(sourceMagnetBaseCooldown<<(m.FailureStreak-1), sourceMagnetMaxCooldown)
+	}
+	until := now.Add(cooldown)
+	m.CooldownUntil = &until
+}
+
 type FilmFileWithWork struct {
 	FilmFile
 	Work FilmWork `gorm:"foreignKey:WorkID"`
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+const (
+	MediaJobTriggerSchedule = "schedule"
+	MediaJobTriggerManual   = "manual"
+)
+
+// MediaJob is a maintenance scan of a media storage (translation, subtitles,
+// NFO sync and so on). The row keeps the outcome of the latest run and
+// whether the job is paused, so both survive restarts.
+type MediaJob struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	StorageID uint   `json:"storage_id" gorm:"not null;uniqueIndex:idx_media_job_name"`
+	Source    string `json:"source" gorm:"not null"`
+	Name      string `json:"name" gorm:"not null;uniqueIndex:idx_media_job_name"`
+	Paused    bool   `json:"paused"`
+	// Interval between scheduled runs in seconds
+	Interval int64 `json:"interval"`
+
+	LastStartAt   *time.Time `json:"last_start_at"`
+	LastEndAt     *time.Time `json:"last_end_at"`
+	LastProcessed int        `json:"last_processed"`
+	LastFailed    int        `json:"last_failed"`
+	LastError     string     `json:"last_error"`
+	NextRunAt     *time.Time `json:"next_run_at"`
+}
+
+// MediaJobRun is the history of a single MediaJob run.
+type MediaJobRun struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	JobID     uint       `json:"job_id" gorm:"not null;index"`
+	Trigger   string     `json:"trigger"`
+	StartAt   time.Time  `json:"start_at" gorm:"index"`
+	EndAt     *time.Time `json:"end_at"`
+	Processed int        `json:"processed"`
+	Failed    int        `json:"failed"`
+	Error     string     `json:"error"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	MediaNotifierEmby     = "emby"
+	MediaNotifierJellyfin = "jellyfin"
+	MediaNotifierPlex     = "plex"
+	MediaNotifierWebhook  = "webhook"
+)
+
+const (
+	MediaNotifyPending   = "pending"
+	MediaNotifySucceeded = "succeeded"
+	MediaNotifyFailed    = "failed"
+)
+
+// MediaNotifier is a media server that is told about changed library items.
+// PathPrefix selects the OpenList paths the server cares about, and is
+// replaced by ServerPath to get the path the server sees on its side.
+type MediaNotifier struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	Name       string `json:"name" gorm:"unique" binding:"required"`
+	Type       string `json:"type" binding:"required"`
+	URL        string `json:"url" binding:"required"`
+	Token      string `json:"token"`
+	PathPrefix string `json:"path_prefix"`
+	ServerPath string `json:"server_path"`
+	Disabled   bool   `json:"disabled"`
+}
+
+// MediaNotifyDelivery records one batched call to a notifier.
+type MediaNotifyDelivery struct {
+	ID         uint        `json:"id" gorm:"primaryKey"`
+	NotifierID uint        `json:"notifier_id" gorm:"index"`
+	Target     string      `json:"target"`
+	Paths      StringArray `json:"paths" gorm:"type:json;serializer:json"`
+	Status     string      `json:"status" gorm:"index"`
+	LastError  string      `json:"last_error"`
+	CreatedAt  time.Time   `json:"created_at" gorm:"index"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// Permission bits, shared by User.Permission and Role.Permission.
+const (
+	PermSeeHides int32 = 1 << iota
+	PermAccessWithoutPassword
+	PermOfflineDownload
+	PermWrite
+	PermRename
+	PermMove
+	PermCopy
+	PermRemove
+	PermWebdavRead
+	PermWebdavManage
+	PermFTPAccess
+	PermFTPManage
+	PermReadArchives
+	PermDecompress
+	PermShare
+)
+
+// Grant actions are the coarse names roles are edited with, each one is a
+// set of permission bits.
+const (
+	ActionRead            = "read"
+	ActionWrite           = "write"
+	ActionDelete          = "delete"
+	ActionShare           = "share"
+	ActionOfflineDownload = "offline_download"
+	ActionArchive         = "archive"
+	ActionSeeHides        = "see_hides"
+	ActionSkipPassword    = "skip_password"
+)
+
+var actionPermissions = []struct {
+	Action     string
+	Permission int32
+}{
+	{ActionRead, PermWebdavRead | PermFTPAccess},
+	{ActionWrite, PermWrite | PermRename | PermMove | PermCopy | PermWebdavManage | PermFTPManage},
+	{ActionDelete, PermRemove | PermWebdavManage | PermFTPManage},
+	{ActionShare, PermShare},
+	{ActionOfflineDownload, PermOfflineDownload},
+	{ActionArchive, PermReadArchives | PermDecompress},
+	{ActionSeeHides, PermSeeHides},
+	{ActionSkipPassword, PermAccessWithoutPassword},
+}
+
+// ActionsPermission turns grant action names into permission bits.
+func ActionsPermission(actions []string) (int32, error) {
+	var perm int32
+	for _, action := range actions {
+		found := false
+		for _, ap := range actionPermissions {
+			if ap.Action == action {
+				perm |= ap.Permission
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown action: %s", action)
+		}
+	}
+	return perm, nil
+}
+
+// PermissionActions lists the actions whose bits are all set in perm.
+func PermissionActions(perm int32) []string {
+	actions := make([]string, 0, len(actionPermissions))
+	for _, ap := range actionPermissions {
+		if perm&ap.Permission == ap.Permission {
+			actions = append(actions, ap.Action)
+		}
+	}
+	return actions
+}
+
+// Role is a named set of permission bits that can be granted on a path.
+type Role struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"unique" binding:"required"`
+	Description string `json:"description"`
+	Permission  int32  `json:"permission"`
+}
+
+// UserGroup collects users so they can share grants.
+type UserGroup struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"unique" binding:"required"`
+	Description string `json:"description"`
+}
+
+type UserGroupMember struct {
+	GroupID uint `json:"group_id" gorm:"primaryKey"`
+	UserID  uint `json:"user_id" gorm:"primaryKey;index"`
+}
+
+// PathGrant gives a role on Path and everything below it, either to a
+// single user or to every member of a group.
+type PathGrant struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	RoleID  uint   `json:"role_id" gorm:"index" binding:"required"`
+	UserID  uint   `json:"user_id" gorm:"index"`
+	GroupID uint   `json:"group_id" gorm:"index"`
+	Path    string `json:"path" binding:"required"`
+}
+
+func (g *PathGrant) Validate() error {
+	if (g.UserID == 0) == (g.GroupID == 0) {
+		return fmt.Errorf("grant must target exactly one of user or group")
+	}
+	g.Path = utils.FixAndCleanPath(g.Path)
+	return nil
+}
+
+// PathPermission is a resolved grant, loaded onto User.Grants.
+type PathPermission struct {
+	Path       string
+	Permission int32
+}
+
+// LegacyRoleName names the role a migrated Permission bitmask ends up in.
+func LegacyRoleName(perm int32) string {
+	return fmt.Sprintf("legacy-%#x", perm)
+}
@@ -0,0 +1,93 @@
+package model
+
+import "testing"
+
+func TestUserPermissionAtGrantedPath(t *testing.T) {
+	editor, err := ActionsPermission([]string{ActionRead, ActionWrite, ActionDelete})
+	if err != nil {
+		t.Fatalf("actions permission: %v", err)
+	}
+	u := &User{
+		BasePath:   "/",
+		Permission: PermWebdavRead,
+		Grants:     []PathPermission{{Path: "/media", Permission: editor}},
+	}
+	cases := []struct {
+		perm int32
+		path string
+		want bool
+	}{
+		{PermWrite, "/media", true},
+		{PermWrite, "/media/movies/a.mkv", true},
+		{PermRemove | PermWebdavManage, "/media/tv", true},
+		{PermWrite, "/", false},
+		{PermWrite, "/mediax", false},
+		{PermWrite, "/docs", false},
+		{PermWebdavRead, "/docs", true},
+		{PermShare, "/media", false},
+	}
+	for _, c := range cases {
+		if got := u.CanAt(c.perm, c.path); got != c.want {
+			t.Errorf("CanAt(%#x, %s) = %v, want %v", c.perm, c.path, got, c.want)
+		}
+	}
+	if u.CanWrite() {
+		t.Errorf("CanWrite() at base path should not see the /media grant")
+	}
+	if !u.CanReach(PermFTPAccess, "/") || u.CanReach(PermFTPAccess, "/docs") {
+		t.Errorf("CanReach should only allow the way down to /media")
+	}
+	if !u.CanAnywhere(PermWrite) || u.CanAnywhere(PermShare) {
+		t.Errorf("CanAnywhere mismatch")
+	}
+}
+
+func TestPermissionActions(t *testing.T) {
+	perm, err := ActionsPermission([]string{ActionShare, ActionArchive})
+	if err != nil {
+		t.Fatalf("actions permission: %v", err)
+	}
+	if perm != PermShare|PermReadArchives|PermDecompress {
+		t.Fatalf("perm = %#x", perm)
+	}
+	got := PermissionActions(perm | PermWrite)
+	if len(got) != 2 || got[0] != ActionShare || got[1] != ActionArchive {
+		t.Fatalf("actions = %v", got)
+	}
+	if perm, _ = ActionsPermission([]string{ActionRead}); perm&(PermSeeHides|PermAccessWithoutPassword) != 0 {
+		t.Fatalf("read should not see hides or skip passwords, perm = %#x", perm)
+	}
+	perm, _ = ActionsPermission([]string{ActionSeeHides, ActionSkipPassword})
+	if perm != PermSeeHides|PermAccessWithoutPassword {
+		t.Fatalf("perm = %#x", perm)
+	}
+	if _, err = ActionsPermission([]string{"admin"}); err == nil {
+		t.Fatalf("unknown action should fail")
+	}
+}
+
+func TestUserWithPathLimits(t *testing.T) {
+	u := &User{BasePath: "/home/alice"}
+	if u.WithPathLimits(nil) != u {
+		t.Errorf("no paths should leave the user as is")
+	}
+	limited := u.WithPathLimits([]string{"backup", "/photos/2024"})
+	if len(u.PathLimits) != 0 {
+		t.Errorf("WithPathLimits changed the original user")
+	}
+	cases := []struct {
+		path string
+		ok   bool
+	}{
+		{"/backup", true},
+		{"/backup/db.tar", true},
+		{"/photos/2024/a.jpg", true},
+		{"/photos", false},
+		{"/backups", false},
+	}
+	for _, c := range cases {
+		if _, err := limited.JoinPath(c.path); (err == nil) != c.ok {
+			t.Errorf("JoinPath(%s) err = %v, want ok %v", c.path, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// S3Credential is an access key of the S3 server acting as its user. The
+// secret is kept in clear since request signatures are checked against it.
+type S3Credential struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	Name      string    `json:"name"`
+	AccessKey string    `json:"access_key" gorm:"uniqueIndex;size:32"`
+	SecretKey string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
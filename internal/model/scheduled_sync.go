@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	SyncRunRunning   = "running"
+	SyncRunCompleted = "completed"
+	SyncRunFailed    = "failed"
+)
+
+// SyncCheckpoint is the walk state of a ScheduledSync storage, saved while a
+// scan runs so a restart can resume it. Only the dirs still to list are kept,
+// each dir is queued by its parent alone so no visited set is needed.
+type SyncCheckpoint struct {
+	StorageID  uint        `json:"storage_id" gorm:"primaryKey;autoIncrement:false"`
+	RemotePath string      `json:"remote_path"`
+	SyncPaths  string      `json:"sync_paths"`
+	ReportID   uint        `json:"report_id"`
+	Pending    StringArray `json:"pending" gorm:"type:json;serializer:json"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// SyncReport summarizes one scan of a ScheduledSync storage, a resumed scan
+// keeps adding to the report it started.
+type SyncReport struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`
+	StorageID     uint        `json:"storage_id" gorm:"index"`
+	Status        string      `json:"status"`
+	StartAt       time.Time   `json:"start_at"`
+	EndAt         *time.Time  `json:"end_at"`
+	Resumes       int         `json:"resumes"`
+	Listed        int         `json:"listed"`
+	Errors        int         `json:"errors"`
+	ErrorSamples  StringArray `json:"error_samples" gorm:"type:json;serializer:json"`
+	RateLimitWait int64       `json:"rate_limit_wait"` // milliseconds spent waiting for the list rate limit
+	Duration      int64       `json:"duration"`        // milliseconds spent walking, excluding downtime between resumes
+	Message       string      `json:"message"`
+}
@@ -10,6 +10,33 @@ type IndexProgress struct {
 	IsDone       bool       `json:"is_done"`
 	LastDoneTime *time.Time `json:"last_done_time"`
 	Error        string     `json:"error"`
+	// QueueDepth is the number of index jobs waiting to be applied
+	QueueDepth int64 `json:"queue_depth"`
+}
+
+const (
+	// IndexJobSync replaces the children of Path with its listing at the time
+	// the job is applied
+	IndexJobSync = "sync"
+	// IndexJobUpsert adds or replaces Nodes under Path, other children are kept
+	IndexJobUpsert = "upsert"
+	// IndexJobDelete removes Path and everything below it
+	IndexJobDelete = "delete"
+	// IndexJobWalk re-indexes Path and everything below it from the storage
+	IndexJobWalk = "walk"
+)
+
+// IndexJob is a pending change of the search index. Jobs are persisted and
+// applied in order, so changes made while the index is busy or before a
+// restart are not lost.
+type IndexJob struct {
+	ID        uint         `json:"id" gorm:"primaryKey"`
+	Op        string       `json:"op"`
+	Path      string       `json:"path" gorm:"index"`
+	Nodes     []SearchNode `json:"nodes" gorm:"type:text;serializer:json"`
+	Attempts  int          `json:"attempts"`
+	LastError string       `json:"last_error"`
+	CreatedAt time.Time    `json:"created_at"`
 }
 
 type SearchReq struct {
@@ -17,14 +44,106 @@ type SearchReq struct {
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
 	Scope int `json:"scope"`
+	SearchMediaFilter
+	SearchFilter
+	// Sort is name, size or modified, empty for the order of the searcher
+	Sort string `json:"sort" form:"sort"`
+	// Order is asc or desc, asc when empty
+	Order string `json:"order" form:"order"`
+	// Facets asks for the hit counts per top-level storage as well
+	Facets bool `json:"facets" form:"facets"`
 	PageReq
 }
 
+const (
+	SearchSortName     = "name"
+	SearchSortSize     = "size"
+	SearchSortModified = "modified"
+)
+
+// File types of SearchFilter.Types, their extensions come from the type
+// settings like video_types.
+const (
+	SearchTypeVideo = "video"
+	SearchTypeAudio = "audio"
+	SearchTypeImage = "image"
+	SearchTypeText  = "text"
+)
+
+// SearchFilter narrows a search by the attributes of the nodes, zero values
+// are ignored.
+type SearchFilter struct {
+	MinSize      int64      `json:"min_size" form:"min_size"`
+	MaxSize      int64      `json:"max_size" form:"max_size"`
+	ModifiedFrom *time.Time `json:"modified_from" form:"modified_from"`
+	ModifiedTo   *time.Time `json:"modified_to" form:"modified_to"`
+	// Exts are file extensions without the dot, matched case-insensitively,
+	// only files have an extension
+	Exts []string `json:"exts" form:"exts"`
+	// Types adds the extensions of the file types to Exts
+	Types []string `json:"types" form:"types"`
+}
+
+// SearchFacet is the number of hits in a top-level storage.
+type SearchFacet struct {
+	Storage string `json:"storage"`
+	Count   int64  `json:"count"`
+}
+
+// SearchMediaFilter narrows a search to the nodes of media works, it has no
+// effect when all fields are empty.
+type SearchMediaFilter struct {
+	Code string `json:"code" form:"code"`
+	// Title matches the raw or the translated title
+	Title    string `json:"title" form:"title"`
+	Actor    string `json:"actor" form:"actor"`
+	Tag      string `json:"tag" form:"tag"`
+	YearFrom int    `json:"year_from" form:"year_from"`
+	YearTo   int    `json:"year_to" form:"year_to"`
+}
+
+func (f SearchMediaFilter) IsZero() bool {
+	return f == SearchMediaFilter{}
+}
+
 type SearchNode struct {
 	Parent string `json:"parent" gorm:"index"`
 	Name   string `json:"name"`
 	IsDir  bool   `json:"is_dir"`
 	Size   int64  `json:"size"`
+	// Modified is zero for nodes indexed before it was recorded
+	Modified time.Time `json:"modified"`
+	// Media is set for the nodes of media storages
+	Media *SearchMedia `json:"media,omitempty" gorm:"embedded;embeddedPrefix:media_"`
+}
+
+// SearchMedia is the FilmWork metadata indexed with a node.
+type SearchMedia struct {
+	WorkID          uint        `json:"work_id" gorm:"index"`
+	Code            string      `json:"code"`
+	RawTitle        string      `json:"raw_title"`
+	TranslatedTitle string      `json:"translated_title"`
+	Actors          StringArray `json:"actors" gorm:"type:text;serializer:json"`
+	Tags            StringArray `json:"tags" gorm:"type:text;serializer:json"`
+	ReleaseDate     *time.Time  `json:"release_date"`
+	ReleaseYear     int         `json:"release_year"`
+}
+
+func NewSearchMedia(work FilmWork) *SearchMedia {
+	media := &SearchMedia{
+		WorkID:          work.ID,
+		Code:            work.Code,
+		RawTitle:        work.RawTitle,
+		TranslatedTitle: work.TranslatedTitle,
+		Actors:          work.Actors,
+		Tags:            work.Tags,
+	}
+	if !work.ReleaseDate.IsZero() && work.ReleaseDate.Year() > 1 {
+		release := work.ReleaseDate
+		media.ReleaseDate = &release
+		media.ReleaseYear = release.Year()
+	}
+	return media
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,6 +153,21 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	switch p.Sort {
+	case "", SearchSortName, SearchSortSize, SearchSortModified:
+	default:
+		return fmt.Errorf("unknown sort: %s", p.Sort)
+	}
+	if p.Order != "" && p.Order != "asc" && p.Order != "desc" {
+		return fmt.Errorf("unknown order: %s", p.Order)
+	}
+	for _, t := range p.Types {
+		switch t {
+		case SearchTypeVideo, SearchTypeAudio, SearchTypeImage, SearchTypeText:
+		default:
+			return fmt.Errorf("unknown type: %s", t)
+		}
+	}
 	return nil
 }
 
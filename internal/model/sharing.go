@@ -33,9 +33,14 @@ func (s *Sharing) Valid() bool {
 	if len(s.Files) == 0 {
 		return false
 	}
-	if s.Creator == nil || !s.Creator.CanShare() {
+	if s.Creator == nil {
 		return false
 	}
+	for _, f := range s.Files {
+		if !s.Creator.CanAt(PermShare, f) {
+			return false
+		}
+	}
 	if s.Expires != nil && !s.Expires.IsZero() && s.Expires.Before(time.Now()) {
 		return false
 	}
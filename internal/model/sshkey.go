@@ -5,14 +5,18 @@ import (
 	"time"
 )
 
+// SSHPublicKey logs its user in to the sftp server. When Paths is set the
+// session only reaches them and everything below, relative to the user's
+// base path.
 type SSHPublicKey struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	UserId       uint      `json:"-"`
-	Title        string    `json:"title"`
-	Fingerprint  string    `json:"fingerprint"`
-	KeyStr       string    `gorm:"type:text" json:"-"`
-	AddedTime    time.Time `json:"added_time"`
-	LastUsedTime time.Time `json:"last_used_time"`
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	UserId       uint        `json:"-"`
+	Title        string      `json:"title"`
+	Fingerprint  string      `json:"fingerprint"`
+	KeyStr       string      `gorm:"type:text" json:"-"`
+	Paths        StringArray `json:"paths" gorm:"type:json;serializer:json"`
+	AddedTime    time.Time   `json:"added_time"`
+	LastUsedTime time.Time   `json:"last_used_time"`
 }
 
 func (k *SSHPublicKey) GetKey() (ssh.PublicKey, error) {
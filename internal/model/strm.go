@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// StrmFile is a local file a Strm storage wrote, with the source it was
+// produced from.
+type StrmFile struct {
+	ID             uint   `gorm:"primaryKey"`
+	StorageID      uint   `gorm:"uniqueIndex:idx_strm_file_storage_path;index:idx_strm_file_storage_dir"`
+	LocalPath      string `gorm:"uniqueIndex:idx_strm_file_storage_path"`
+	LocalDir       string `gorm:"index:idx_strm_file_storage_dir"`
+	SourcePath     string
+	SourceSize     int64
+	SourceModified time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,66 @@
+package model
+
+import "fmt"
+
+// TrafficLimit caps the transfers of a user, or of every member of a group.
+// Speeds are in KiB/s like the global stream limits, daily quotas in bytes,
+// zero leaves a field unlimited.
+type TrafficLimit struct {
+	ID            uint  `json:"id" gorm:"primaryKey"`
+	UserID        uint  `json:"user_id" gorm:"index"`
+	GroupID       uint  `json:"group_id" gorm:"index"`
+	DownloadSpeed int64 `json:"download_speed"`
+	UploadSpeed   int64 `json:"upload_speed"`
+	DailyDownload int64 `json:"daily_download"`
+	DailyUpload   int64 `json:"daily_upload"`
+	MaxStreams    int   `json:"max_streams"`
+}
+
+func (l *TrafficLimit) Validate() error {
+	if (l.UserID == 0) == (l.GroupID == 0) {
+		return fmt.Errorf("traffic limit must target exactly one of user or group")
+	}
+	if l.DownloadSpeed < 0 || l.UploadSpeed < 0 || l.DailyDownload < 0 || l.DailyUpload < 0 || l.MaxStreams < 0 {
+		return fmt.Errorf("traffic limit can not be negative")
+	}
+	return nil
+}
+
+// generous returns the larger of two limits, where zero is unlimited.
+func generous(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return max(a, b)
+}
+
+// MergeTrafficLimits resolves the limit a user transfers under. The user's
+// own limit wins, without one every field takes the most generous value of
+// the user's groups. No limit at all leaves the user unlimited.
+func MergeTrafficLimits(own *TrafficLimit, groups []TrafficLimit) TrafficLimit {
+	if own != nil {
+		return *own
+	}
+	if len(groups) == 0 {
+		return TrafficLimit{}
+	}
+	res := groups[0]
+	for _, g := range groups[1:] {
+		res.DownloadSpeed = generous(res.DownloadSpeed, g.DownloadSpeed)
+		res.UploadSpeed = generous(res.UploadSpeed, g.UploadSpeed)
+		res.DailyDownload = generous(res.DailyDownload, g.DailyDownload)
+		res.DailyUpload = generous(res.DailyUpload, g.DailyUpload)
+		res.MaxStreams = int(generous(int64(res.MaxStreams), int64(g.MaxStreams)))
+	}
+	res.ID, res.UserID, res.GroupID = 0, 0, 0
+	return res
+}
+
+// TrafficUsage is what a user transferred on a day, formatted 2006-01-02
+// in local time.
+type TrafficUsage struct {
+	UserID     uint   `json:"user_id" gorm:"primaryKey"`
+	Day        string `json:"day" gorm:"primaryKey;size:10"`
+	Downloaded int64  `json:"downloaded"`
+	Uploaded   int64  `json:"uploaded"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "testing"
+
+func TestMergeTrafficLimits(t *testing.T) {
+	groups := []TrafficLimit{
+		{ID: 1, GroupID: 1, DownloadSpeed: 1024, DailyDownload: 1 << 30, MaxStreams: 2},
+		{ID: 2, GroupID: 2, DownloadSpeed: 4096, UploadSpeed: 512, DailyDownload: 0, MaxStreams: 1},
+	}
+	got := MergeTrafficLimits(nil, groups)
+	want := TrafficLimit{DownloadSpeed: 4096, UploadSpeed: 0, DailyDownload: 0, MaxStreams: 2}
+	if got != want {
+		t.Fatalf("merge groups: got %+v, want %+v", got, want)
+	}
+
+	own := &TrafficLimit{ID: 3, UserID: 5, DownloadSpeed: 64}
+	if got := MergeTrafficLimits(own, groups); got != *own {
+		t.Fatalf("own limit should win, got %+v", got)
+	}
+	if got := MergeTrafficLimits(nil, nil); got != (TrafficLimit{}) {
+		t.Fatalf("no limits should be unlimited, got %+v", got)
+	}
+}
+
+func TestTrafficLimitValidate(t *testing.T) {
+	if err := (&TrafficLimit{UserID: 1, GroupID: 2}).Validate(); err == nil {
+		t.Fatal("limit with both user and group should fail")
+	}
+	if err := (&TrafficLimit{GroupID: 2, MaxStreams: -1}).Validate(); err == nil {
+		t.Fatal("negative limit should fail")
+	}
+	if err := (&TrafficLimit{UserID: 1, DailyDownload: 1 << 20}).Validate(); err != nil {
+		t.Fatalf("valid limit: %v", err)
+	}
+}
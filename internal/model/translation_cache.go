@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// TranslationCache keeps a translation per backend so the same text is not
+// sent twice. Entries of an older CurrentTranslationVersion are ignored.
+type TranslationCache struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Backend string `json:"backend" gorm:"not null;uniqueIndex:idx_translation_cache_key"`
+	Version uint   `json:"version" gorm:"not null;uniqueIndex:idx_translation_cache_key"`
+	// Hash is a sha256 of the prompt and the source text
+	Hash      string    `json:"hash" gorm:"not null;size:64;uniqueIndex:idx_translation_cache_key"`
+	Result    string    `json:"result"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	stdpath "path"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -59,6 +60,12 @@ type User struct {
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	// SSHKeyOnly refuses password logins to the sftp server
+	SSHKeyOnly bool `json:"ssh_key_only"`
+	// path grants from the user's roles and groups, loaded by op
+	Grants []PathPermission `json:"-" gorm:"-"`
+	// when set, JoinPath only resolves paths below one of them
+	PathLimits []string `json:"-" gorm:"-"`
 }
 
 func (u *User) IsGuest() bool {
@@ -90,68 +97,139 @@ func (u *User) SetPassword(pwd string) *User {
 	return u
 }
 
+// PermissionAt returns the permission bits that apply to reqPath: the user's
+// own Permission plus every grant on reqPath or one of its parents.
+func (u *User) PermissionAt(reqPath string) int32 {
+	perm := u.Permission
+	for _, g := range u.Grants {
+		if utils.IsSubPath(g.Path, reqPath) {
+			perm |= g.Permission
+		}
+	}
+	return perm
+}
+
+// CanAt reports whether all bits of perm apply to reqPath.
+func (u *User) CanAt(perm int32, reqPath string) bool {
+	return u.PermissionAt(reqPath)&perm == perm
+}
+
+// CanReach is CanAt, but also true when perm is granted somewhere below
+// reqPath, so the user can walk down to the granted directory.
+func (u *User) CanReach(perm int32, reqPath string) bool {
+	if u.CanAt(perm, reqPath) {
+		return true
+	}
+	for _, g := range u.Grants {
+		if g.Permission&perm == perm && utils.IsSubPath(reqPath, g.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAnywhere reports whether perm applies to at least one path, it gates
+// logins into the webdav and ftp servers.
+func (u *User) CanAnywhere(perm int32) bool {
+	if u.Permission&perm == perm {
+		return true
+	}
+	for _, g := range u.Grants {
+		if (u.Permission|g.Permission)&perm == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// The CanXxx helpers below check the user's base path, that is the
+// Permission bitmask plus grants on the base path or above it.
+
 func (u *User) CanSeeHides() bool {
-	return u.Permission&1 == 1
+	return u.CanAt(PermSeeHides, u.BasePath)
 }
 
 func (u *User) CanAccessWithoutPassword() bool {
-	return (u.Permission>>1)&1 == 1
+	return u.CanAt(PermAccessWithoutPassword, u.BasePath)
 }
 
 func (u *User) CanAddOfflineDownloadTasks() bool {
-	return (u.Permission>>2)&1 == 1
+	return u.CanAt(PermOfflineDownload, u.BasePath)
 }
 
 func (u *User) CanWrite() bool {
-	return (u.Permission>>3)&1 == 1
+	return u.CanAt(PermWrite, u.BasePath)
 }
 
 func (u *User) CanRename() bool {
-	return (u.Permission>>4)&1 == 1
+	return u.CanAt(PermRename, u.BasePath)
 }
 
 func (u *User) CanMove() bool {
-	return (u.Permission>>5)&1 == 1
+	return u.CanAt(PermMove, u.BasePath)
 }
 
 func (u *User) CanCopy() bool {
-	return (u.Permission>>6)&1 == 1
+	return u.CanAt(PermCopy, u.BasePath)
 }
 
 func (u *User) CanRemove() bool {
-	return (u.Permission>>7)&1 == 1
+	return u.CanAt(PermRemove, u.BasePath)
 }
 
 func (u *User) CanWebdavRead() bool {
-	return (u.Permission>>8)&1 == 1
+	return u.CanAt(PermWebdavRead, u.BasePath)
 }
 
 func (u *User) CanWebdavManage() bool {
-	return (u.Permission>>9)&1 == 1
+	return u.CanAt(PermWebdavManage, u.BasePath)
 }
 
 func (u *User) CanFTPAccess() bool {
-	return (u.Permission>>10)&1 == 1
+	return u.CanAt(PermFTPAccess, u.BasePath)
 }
 
 func (u *User) CanFTPManage() bool {
-	return (u.Permission>>11)&1 == 1
+	return u.CanAt(PermFTPManage, u.BasePath)
 }
 
 func (u *User) CanReadArchives() bool {
-	return (u.Permission>>12)&1 == 1
+	return u.CanAt(PermReadArchives, u.BasePath)
 }
 
 func (u *User) CanDecompress() bool {
-	return (u.Permission>>13)&1 == 1
+	return u.CanAt(PermDecompress, u.BasePath)
 }
 
 func (u *User) CanShare() bool {
-	return (u.Permission>>14)&1 == 1
+	return u.CanAt(PermShare, u.BasePath)
 }
 
 func (u *User) JoinPath(reqPath string) (string, error) {
-	return utils.JoinBasePath(u.BasePath, reqPath)
+	p, err := utils.JoinBasePath(u.BasePath, reqPath)
+	if err != nil || len(u.PathLimits) == 0 {
+		return p, err
+	}
+	for _, limit := range u.PathLimits {
+		if utils.IsSubPath(limit, p) {
+			return p, nil
+		}
+	}
+	return "", errors.WithStack(errs.PermissionDenied)
+}
+
+// WithPathLimits returns a copy of u whose JoinPath only resolves below
+// paths, which are relative to the base path. No paths leaves u as is.
+func (u *User) WithPathLimits(paths []string) *User {
+	if len(paths) == 0 {
+		return u
+	}
+	limited := *u
+	limited.PathLimits = make([]string, 0, len(paths))
+	for _, p := range paths {
+		limited.PathLimits = append(limited.PathLimits, stdpath.Join(utils.FixAndCleanPath(u.BasePath), utils.FixAndCleanPath(p)))
+	}
+	return &limited
 }
 
 func StaticHash(password string) string {
@@ -0,0 +1,211 @@
+package notifier
+
+import (
+	"context"
+	stdpath "path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// debounceDelay is how long the notifier waits for more changes before
+	// sending a batch, maxDelay bounds the wait while changes keep coming.
+	debounceDelay     = 10 * time.Second
+	maxDelay          = time.Minute
+	deliveryTimeout   = time.Minute
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+var client *resty.Client
+
+var (
+	mu          sync.Mutex
+	pending     = make(map[string]struct{})
+	pendingURLs = make(map[string]struct{})
+	firstQueued time.Time
+	timer       *time.Timer
+)
+
+// defaultStarDir is the folder of the followed stars the media drivers list,
+// the media_star_dir setting overrides it.
+const defaultStarDir = "关注演员"
+
+// MediaItemPath returns the OpenList path of a media work directory inside a
+// media storage (javdb, fc2) mounted at mountPath.
+func MediaItemPath(mountPath, primaryDir, code string) string {
+	if primaryDir == "个人收藏" {
+		return stdpath.Join(utils.FixAndCleanPath(mountPath), primaryDir, code)
+	}
+	starDir := setting.GetStr(conf.MediaStarDir, defaultStarDir)
+	return stdpath.Join(utils.FixAndCleanPath(mountPath), starDir, primaryDir, code)
+}
+
+// NotifyWork queues the directory of a FilmWork for every configured notifier.
+func NotifyWork(work model.FilmWork) {
+	storage, err := db.GetStorageById(work.StorageID)
+	if err != nil {
+		utils.Log.Warnf("[notifier] failed get storage of media work %s: %+v", work.Code, err)
+		return
+	}
+	Notify(MediaItemPath(storage.MountPath, work.PrimaryDir, work.Code))
+}
+
+// Notify queues OpenList paths whose library items have been updated.
+// Calls are debounced and sent as one batch per notifier.
+func Notify(paths ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, p := range paths {
+		pending[utils.FixAndCleanPath(p)] = struct{}{}
+	}
+	schedule()
+}
+
+// NotifyURLs queues the legacy newline separated refresh URLs of the
+// EmbyServers driver option, they are posted as generic webhooks.
+func NotifyURLs(urls string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, u := range strings.Split(urls, "\n") {
+		if u = strings.TrimSpace(u); u != "" {
+			pendingURLs[u] = struct{}{}
+		}
+	}
+	schedule()
+}
+
+func schedule() {
+	if len(pending) == 0 && len(pendingURLs) == 0 {
+		return
+	}
+	now := time.Now()
+	if timer == nil {
+		firstQueued = now
+		timer = time.AfterFunc(debounceDelay, Flush)
+		return
+	}
+	delay := debounceDelay
+	if deadline := firstQueued.Add(maxDelay); now.Add(delay).After(deadline) {
+		delay = deadline.Sub(now)
+	}
+	timer.Reset(delay)
+}
+
+// Flush sends the queued changes right away.
+func Flush() {
+	mu.Lock()
+	if timer != nil {
+		timer.Stop()
+		timer = nil
+	}
+	paths := make([]string, 0, len(pending))
+	for p := range pending {
+		paths = append(paths, p)
+	}
+	urls := make([]string, 0, len(pendingURLs))
+	for u := range pendingURLs {
+		urls = append(urls, u)
+	}
+	pending = make(map[string]struct{})
+	pendingURLs = make(map[string]struct{})
+	mu.Unlock()
+	if len(paths) == 0 && len(urls) == 0 {
+		return
+	}
+	slices.Sort(paths)
+
+	notifiers, err := db.GetEnabledMediaNotifiers()
+	if err != nil {
+		utils.Log.Errorf("[notifier] %+v", err)
+	}
+	var wg sync.WaitGroup
+	for _, n := range notifiers {
+		mapped := MapPaths(n, paths)
+		if len(mapped) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(n model.MediaNotifier) {
+			defer wg.Done()
+			deliver(n, mapped)
+		}(n)
+	}
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			deliver(model.MediaNotifier{Name: u, Type: model.MediaNotifierWebhook, URL: u}, paths)
+		}(u)
+	}
+	wg.Wait()
+	if err = db.DeleteMediaNotifyDeliveriesBefore(time.Now().Add(-deliveryRetention)); err != nil {
+		utils.Log.Warnf("[notifier] failed clean up deliveries: %+v", err)
+	}
+}
+
+// MapPaths keeps the paths below the notifier's PathPrefix and rewrites them
+// to the notifier's ServerPath.
+func MapPaths(n model.MediaNotifier, paths []string) []string {
+	prefix := utils.FixAndCleanPath(n.PathPrefix)
+	mapped := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if !utils.IsSubPath(prefix, p) {
+			continue
+		}
+		if n.ServerPath != "" {
+			rel := strings.TrimPrefix(utils.FixAndCleanPath(p), prefix)
+			p = strings.TrimSuffix(n.ServerPath, "/") + "/" + strings.TrimPrefix(rel, "/")
+			p = strings.TrimSuffix(p, "/")
+		}
+		if !slices.Contains(mapped, p) {
+			mapped = append(mapped, p)
+		}
+	}
+	return mapped
+}
+
+func deliver(n model.MediaNotifier, paths []string) {
+	delivery := &model.MediaNotifyDelivery{
+		NotifierID: n.ID,
+		Target:     n.Name,
+		Paths:      paths,
+		Status:     model.MediaNotifyPending,
+	}
+	if err := db.CreateMediaNotifyDelivery(delivery); err != nil {
+		utils.Log.Warnf("[notifier] failed record delivery to %s: %+v", n.Name, err)
+	}
+	status, lastError := model.MediaNotifySucceeded, ""
+	target, err := NewTarget(n)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		err = target.Notify(ctx, paths)
+		cancel()
+	}
+	if err != nil {
+		status, lastError = model.MediaNotifyFailed, err.Error()
+		utils.Log.Warnf("[notifier] failed notify %s of %d items: %s", n.Name, len(paths), lastError)
+	} else {
+		utils.Log.Debugf("[notifier] notified %s of %d items", n.Name, len(paths))
+	}
+	if delivery.ID == 0 {
+		return
+	}
+	if err = db.UpdateMediaNotifyDeliveryResult(delivery.ID, status, lastError); err != nil {
+		utils.Log.Warnf("[notifier] failed update delivery to %s: %+v", n.Name, err)
+	}
+}
+
+func init() {
+	client = resty.New().
+		SetRetryCount(3).
+		SetRetryResetReaders(true)
+}
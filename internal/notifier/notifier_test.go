@@ -0,0 +1,151 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	dataDir, err := os.MkdirTemp("", "notifier-test-")
+	if err != nil {
+		panic(err)
+	}
+	conf.Conf = conf.DefaultConfig(dataDir)
+	database, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "notifier-test.db")), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+	if err := db.Init(database); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	if sqlDB, sqlErr := database.DB(); sqlErr == nil {
+		_ = sqlDB.Close()
+	}
+	_ = os.RemoveAll(dataDir)
+	os.Exit(code)
+}
+
+func TestMediaItemPath(t *testing.T) {
+	if got := MediaItemPath("/javdb/", "个人收藏", "ABC-123"); got != "/javdb/个人收藏/ABC-123" {
+		t.Fatalf("favorite path = %q", got)
+	}
+	if got := MediaItemPath("/javdb", "Actor", "ABC-123"); got != "/javdb/关注演员/Actor/ABC-123" {
+		t.Fatalf("actor path = %q", got)
+	}
+	if err := op.SaveSettingItem(&model.SettingItem{Key: conf.MediaStarDir, Value: "Stars"}); err != nil {
+		t.Fatal(err)
+	}
+	defer op.DeleteSettingItemByKey(conf.MediaStarDir)
+	if got := MediaItemPath("/javdb", "Actor", "ABC-123"); got != "/javdb/Stars/Actor/ABC-123" {
+		t.Fatalf("configured actor path = %q", got)
+	}
+}
+
+func TestPlexSectionLongestPrefix(t *testing.T) {
+	var s plexSections
+	if err := json.Unmarshal([]byte(`{"MediaContainer":{"Directory":[
+		{"key":"2","Location":[{"path":"/media/javdb/关注演员"}]},
+		{"key":"1","Location":[{"path":"/media"}]},
+		{"key":"3","Location":[{"path":"/media/jav"}]}]}}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	for p, want := range map[string]string{
+		"/media/javdb/关注演员/Actor/ABC-123": "2",
+		"/media/javdb/个人收藏/ABC-123":       "1",
+		"/media/jav/x":                    "3",
+		"/other":                          "",
+	} {
+		if got := s.find(p); got != want {
+			t.Errorf("%s: section %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestMapPathsFiltersAndRewritesPrefix(t *testing.T) {
+	n := model.MediaNotifier{PathPrefix: "/javdb", ServerPath: "/mnt/openlist/javdb/"}
+	got := MapPaths(n, []string{"/javdb/个人收藏/ABC-123", "/fc2/个人收藏/FC2-PPV-1", "/javdb2/x", "/javdb/个人收藏/ABC-123"})
+	want := []string{"/mnt/openlist/javdb/个人收藏/ABC-123"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("mapped = %v, want %v", got, want)
+	}
+
+	all := MapPaths(model.MediaNotifier{}, []string{"/fc2/a"})
+	if !slices.Equal(all, []string{"/fc2/a"}) {
+		t.Fatalf("mapped without prefix = %v", all)
+	}
+}
+
+func TestFlushBatchesPathsAndRecordsDelivery(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []map[string][]embyMediaUpdate
+		tokens   []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Library/Media/Updated" {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string][]embyMediaUpdate
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, body)
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	good := &model.MediaNotifier{Name: "jellyfin", Type: model.MediaNotifierJellyfin, URL: server.URL, Token: "secret", PathPrefix: "/javdb", ServerPath: "/media"}
+	bad := &model.MediaNotifier{Name: "broken", Type: model.MediaNotifierEmby, URL: server.URL + "/missing", PathPrefix: "/javdb"}
+	for _, n := range []*model.MediaNotifier{good, bad} {
+		if err := db.CreateMediaNotifier(n); err != nil {
+			t.Fatalf("create notifier: %v", err)
+		}
+	}
+
+	Notify("/javdb/个人收藏/ABC-123")
+	Notify("/javdb/个人收藏/ABC-124", "/fc2/个人收藏/FC2-PPV-1")
+	Flush()
+
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d, want one batched call", len(requests))
+	}
+	if tokens[0] != `MediaBrowser Token="secret"` {
+		t.Fatalf("authorization = %q", tokens[0])
+	}
+	updates := requests[0]["Updates"]
+	if len(updates) != 2 || updates[0].Path != "/media/个人收藏/ABC-123" || updates[1].Path != "/media/个人收藏/ABC-124" {
+		t.Fatalf("updates = %+v", updates)
+	}
+
+	deliveries, total, err := db.GetMediaNotifyDeliveries(0, 1, 10)
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("deliveries = %+v, want two", deliveries)
+	}
+	statuses := map[uint]string{}
+	for _, d := range deliveries {
+		statuses[d.NotifierID] = d.Status
+	}
+	if statuses[good.ID] != model.MediaNotifySucceeded || statuses[bad.ID] != model.MediaNotifyFailed {
+		t.Fatalf("statuses = %v", statuses)
+	}
+}
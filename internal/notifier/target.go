@@ -0,0 +1,174 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// Target tells one media server that the items at paths have changed.
+// The paths are already mapped to the server's point of view.
+type Target interface {
+	Notify(ctx context.Context, paths []string) error
+}
+
+type NewTargetFunc func(n model.MediaNotifier) Target
+
+var targetConstructors = map[string]NewTargetFunc{}
+
+// RegisterTarget registers the constructor of a notifier type.
+func RegisterTarget(typ string, newFunc NewTargetFunc) {
+	targetConstructors[typ] = newFunc
+}
+
+func NewTarget(n model.MediaNotifier) (Target, error) {
+	newFunc, ok := targetConstructors[n.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported media notifier type: %s", n.Type)
+	}
+	return newFunc(n), nil
+}
+
+func TargetTypes() []string {
+	types := make([]string, 0, len(targetConstructors))
+	for typ := range targetConstructors {
+		types = append(types, typ)
+	}
+	return types
+}
+
+func checkResponse(res *resty.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if res.IsError() {
+		return fmt.Errorf("unexpected status %d: %s", res.StatusCode(), strings.TrimSpace(res.String()))
+	}
+	return nil
+}
+
+// embyTarget uses the /Library/Media/Updated endpoint shared by Emby and Jellyfin,
+// which only rescans the given paths instead of the whole library.
+type embyTarget struct {
+	url      string
+	token    string
+	jellyfin bool
+}
+
+type embyMediaUpdate struct {
+	Path       string `json:"Path"`
+	UpdateType string `json:"UpdateType"`
+}
+
+func (t *embyTarget) Notify(ctx context.Context, paths []string) error {
+	updates := make([]embyMediaUpdate, 0, len(paths))
+	for _, p := range paths {
+		updates = append(updates, embyMediaUpdate{Path: p, UpdateType: "Modified"})
+	}
+	req := client.R().SetContext(ctx).SetBody(map[string]any{"Updates": updates})
+	if t.jellyfin {
+		req.SetHeader("Authorization", fmt.Sprintf(`MediaBrowser Token="%s"`, t.token))
+	} else {
+		req.SetHeader("X-Emby-Token", t.token)
+	}
+	return checkResponse(req.Post(t.url + "/Library/Media/Updated"))
+}
+
+// plexTarget refreshes the section that contains each path with a partial scan.
+type plexTarget struct {
+	url   string
+	token string
+}
+
+type plexSections struct {
+	MediaContainer struct {
+		Directory []struct {
+			Key      string `json:"key"`
+			Location []struct {
+				Path string `json:"path"`
+			} `json:"Location"`
+		} `json:"Directory"`
+	} `json:"MediaContainer"`
+}
+
+// find returns the key of the section whose location is the longest prefix
+// of p, libraries may be nested in each other.
+func (s *plexSections) find(p string) string {
+	key, longest := "", -1
+	for _, dir := range s.MediaContainer.Directory {
+		for _, loc := range dir.Location {
+			if utils.IsSubPath(loc.Path, p) && len(loc.Path) > longest {
+				key, longest = dir.Key, len(loc.Path)
+			}
+		}
+	}
+	return key
+}
+
+func (t *plexTarget) Notify(ctx context.Context, paths []string) error {
+	var sections plexSections
+	res, err := client.R().SetContext(ctx).
+		SetHeader("Accept", "application/json").
+		SetQueryParam("X-Plex-Token", t.token).
+		SetResult(&sections).
+		Get(t.url + "/library/sections")
+	if err = checkResponse(res, err); err != nil {
+		return fmt.Errorf("failed list plex sections: %w", err)
+	}
+	var errs []string
+	for _, p := range paths {
+		key := sections.find(p)
+		if key == "" {
+			errs = append(errs, fmt.Sprintf("no plex section contains %s", p))
+			continue
+		}
+		res, err = client.R().SetContext(ctx).
+			SetQueryParam("X-Plex-Token", t.token).
+			SetQueryParam("path", p).
+			Get(fmt.Sprintf("%s/library/sections/%s/refresh", t.url, url.PathEscape(key)))
+		if err = checkResponse(res, err); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", p, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// webhookTarget posts the changed paths as JSON to an arbitrary URL.
+type webhookTarget struct {
+	url   string
+	token string
+}
+
+func (t *webhookTarget) Notify(ctx context.Context, paths []string) error {
+	req := client.R().SetContext(ctx).SetBody(map[string]any{
+		"event": "library.updated",
+		"paths": paths,
+	})
+	if t.token != "" {
+		req.SetAuthToken(t.token)
+	}
+	return checkResponse(req.Post(t.url))
+}
+
+func init() {
+	RegisterTarget(model.MediaNotifierEmby, func(n model.MediaNotifier) Target {
+		return &embyTarget{url: strings.TrimSuffix(n.URL, "/"), token: n.Token}
+	})
+	RegisterTarget(model.MediaNotifierJellyfin, func(n model.MediaNotifier) Target {
+		return &embyTarget{url: strings.TrimSuffix(n.URL, "/"), token: n.Token, jellyfin: true}
+	})
+	RegisterTarget(model.MediaNotifierPlex, func(n model.MediaNotifier) Target {
+		return &plexTarget{url: strings.TrimSuffix(n.URL, "/"), token: n.Token}
+	})
+	RegisterTarget(model.MediaNotifierWebhook, func(n model.MediaNotifier) Target {
+		return &webhookTarget{url: n.URL, token: n.Token}
+	})
+}
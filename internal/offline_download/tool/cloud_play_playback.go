@@ -1,9 +1,13 @@
 package tool
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
+	"time"
 
 	_115 "github.com/OpenListTeam/OpenList/v4/drivers/115"
 	"github.com/OpenListTeam/OpenList/v4/internal/av"
@@ -25,22 +29,19 @@ type cloudPlaySession struct {
 }
 
 var (
-	getCloudPlayStorage     = op.GetBalancedStorage
-	queryCloudCache         = db.QueryMagnetCacheByName
-	resolveCloudCacheLink   = getLinkByCache
-	attemptCloudMagnet      = playSingleMagnet
-	updateSourceMagnetError = db.UpdateSourceMagnetLastError
-	selectPlaybackMagnet    = db.SelectSourceMagnet
+	getCloudPlayStorage   = op.GetBalancedStorage
+	queryCloudCache       = db.QueryMagnetCacheByName
+	resolveCloudCacheLink = getLinkByCache
+	attemptCloudMagnet    = playSingleMagnet
+	recordMagnetSuccess   = db.RecordSourceMagnetSuccess
+	recordMagnetFailure   = db.RecordSourceMagnetFailure
+	selectPlaybackMagnet  = db.SelectSourceMagnet
+	maxCloudPlayDownloads = func() int { return setting.GetInt(conf.CloudPlayMaxDownloads, 2) }
+
+	cloudPlayDownloads = &downloadLimiter{released: make(chan struct{})}
 )
 
-func CloudPlay(
-	ctx context.Context,
-	args model.LinkArgs,
-	driverType string,
-	driverPath string,
-	downloadingFile model.Obj,
-	magnets []model.SourceMagnet,
-) (*model.Link, error) {
+func newCloudPlaySession(args model.LinkArgs, driverType, driverPath string, downloadingFile model.Obj) (cloudPlaySession, error) {
 	if driverPath == "" {
 		switch driverType {
 		case "115 Cloud":
@@ -50,57 +51,154 @@ func CloudPlay(
 		}
 	}
 	if driverPath == "" {
-		return nil, errors.New("尚未配置用于云播的网盘")
+		return cloudPlaySession{}, errors.New("尚未配置用于云播的网盘")
 	}
 
 	storage := getCloudPlayStorage(driverPath)
 	if storage == nil {
-		return nil, errors.New("网盘配置未找到")
+		return cloudPlaySession{}, errors.New("网盘配置未找到")
 	}
-	session := cloudPlaySession{
+	return cloudPlaySession{
 		args: args, driverType: driverType, driverPath: driverPath,
 		downloadingFile: downloadingFile, storage: storage,
+	}, nil
+}
+
+// CloudPlay 优先使用已缓存的云盘文件，否则按健康度依次尝试磁力离线下载。
+// 冷却中的磁力会被跳过，仅当全部磁力都在冷却时才按冷却结束时间依次尝试。
+func CloudPlay(
+	ctx context.Context,
+	args model.LinkArgs,
+	driverType string,
+	driverPath string,
+	downloadingFile model.Obj,
+	magnets []model.SourceMagnet,
+) (*model.Link, error) {
+	session, err := newCloudPlaySession(args, driverType, driverPath, downloadingFile)
+	if err != nil {
+		return nil, err
+	}
+	if link := session.cachedLink(ctx); link != nil {
+		return link, nil
 	}
 
-	fileCache := queryCloudCache(driverType, downloadingFile.GetName())
-	if fileCache.FileId != "" {
-		link, err := resolveCloudCacheLink(ctx, args, driverType, storage, fileCache)
-		if err != nil {
-			utils.Log.Warnf("failed to resolve cached cloud-play file %s: %s", fileCache.Name, err)
-		} else if link != nil {
-			return link, nil
+	ready, cooling := SortMagnetsByHealth(magnets, time.Now())
+	if len(ready) == 0 {
+		ready = cooling
+	}
+	return session.playMagnets(ctx, ready)
+}
+
+// PrewarmCloudPlay 在无人播放时提前完成离线下载并缓存云盘文件，
+// 仅尝试选中的磁力，已有缓存或该磁力在冷却时直接返回 false。
+func PrewarmCloudPlay(
+	ctx context.Context,
+	driverType string,
+	driverPath string,
+	downloadingFile model.Obj,
+	magnets []model.SourceMagnet,
+) (bool, error) {
+	session, err := newCloudPlaySession(model.LinkArgs{}, driverType, driverPath, downloadingFile)
+	if err != nil {
+		return false, err
+	}
+	if queryCloudCache(driverType, downloadingFile.GetName()).FileId != "" {
+		return false, nil
+	}
+	// 只预热选中的磁力，避免为同一作品发起多个离线下载
+	selected, ok := selectedMagnet(magnets)
+	if !ok || selected.CoolingDown(time.Now()) {
+		return false, nil
+	}
+	if _, err = session.playMagnets(ctx, []model.SourceMagnet{selected}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// selectedMagnet 返回标记为选中的磁力，没有标记时取排在最前的磁力。
+func selectedMagnet(magnets []model.SourceMagnet) (model.SourceMagnet, bool) {
+	if len(magnets) == 0 {
+		return model.SourceMagnet{}, false
+	}
+	for _, magnet := range magnets {
+		if magnet.Selected {
+			return magnet, true
 		}
 	}
+	return magnets[0], true
+}
 
+// SortMagnetsByHealth 按指纹去重后把磁力分为可用与冷却中两组。
+// 可用磁力按健康分从高到低排列，同分时保持传入顺序（已选中优先、再按优先级），
+// 冷却中的磁力按冷却结束时间排列。
+func SortMagnetsByHealth(magnets []model.SourceMagnet, now time.Time) (ready, cooling []model.SourceMagnet) {
 	seen := make(map[string]struct{}, len(magnets))
-	attemptErrors := make([]error, 0, len(magnets))
 	for _, magnet := range magnets {
-		fingerprint := magnet.Fingerprint
-		if fingerprint == "" {
-			fingerprint = magnet.MagnetURI
-		}
+		fingerprint := magnetFingerprint(magnet)
 		if _, exists := seen[fingerprint]; exists {
 			continue
 		}
 		seen[fingerprint] = struct{}{}
+		if magnet.CoolingDown(now) {
+			cooling = append(cooling, magnet)
+		} else {
+			ready = append(ready, magnet)
+		}
+	}
+	slices.SortStableFunc(ready, func(a, b model.SourceMagnet) int {
+		return cmp.Compare(b.HealthScore(), a.HealthScore())
+	})
+	slices.SortStableFunc(cooling, func(a, b model.SourceMagnet) int {
+		return a.CooldownUntil.Compare(*b.CooldownUntil)
+	})
+	return ready, cooling
+}
 
-		link, err := attemptCloudMagnet(ctx, session, magnet)
+func magnetFingerprint(magnet model.SourceMagnet) string {
+	if magnet.Fingerprint == "" {
+		return magnet.MagnetURI
+	}
+	return magnet.Fingerprint
+}
+
+func (s cloudPlaySession) cachedLink(ctx context.Context) *model.Link {
+	fileCache := queryCloudCache(s.driverType, s.downloadingFile.GetName())
+	if fileCache.FileId == "" {
+		return nil
+	}
+	link, err := resolveCloudCacheLink(ctx, s.args, s.driverType, s.storage, fileCache)
+	if err != nil {
+		utils.Log.Warnf("failed to resolve cached cloud-play file %s: %s", fileCache.Name, err)
+		return nil
+	}
+	return link
+}
+
+func (s cloudPlaySession) playMagnets(ctx context.Context, magnets []model.SourceMagnet) (*model.Link, error) {
+	attemptErrors := make([]error, 0, len(magnets))
+	for _, magnet := range magnets {
+		fingerprint := magnetFingerprint(magnet)
+		link, err := s.attempt(ctx, magnet)
+		if err == nil && link == nil {
+			err = errors.New("returned no playback link")
+		}
 		if err != nil {
 			attemptErrors = append(attemptErrors, fmt.Errorf("magnet %s: %w", fingerprint, err))
-			if updateErr := updateSourceMagnetError(magnet.ID, err.Error()); updateErr != nil {
-				attemptErrors = append(attemptErrors, fmt.Errorf("record magnet %d failure: %w", magnet.ID, updateErr))
+			// 请求被取消不代表磁力不可用
+			if ctx.Err() != nil {
+				return nil, errors.Join(attemptErrors...)
+			}
+			if recordErr := recordMagnetFailure(magnet.ID, err.Error()); recordErr != nil {
+				attemptErrors = append(attemptErrors, fmt.Errorf("record magnet %d failure: %w", magnet.ID, recordErr))
 			}
-			continue
-		}
-		if link == nil {
-			attemptErrors = append(attemptErrors, fmt.Errorf("magnet %s returned no playback link", fingerprint))
 			continue
 		}
 		if err := selectPlaybackMagnet(magnet.WorkID, magnet.ID); err != nil {
 			return nil, fmt.Errorf("select source magnet %d: %w", magnet.ID, err)
 		}
-		if err := updateSourceMagnetError(magnet.ID, ""); err != nil {
-			return nil, fmt.Errorf("clear source magnet %d failure: %w", magnet.ID, err)
+		if err := recordMagnetSuccess(magnet.ID); err != nil {
+			return nil, fmt.Errorf("record source magnet %d success: %w", magnet.ID, err)
 		}
 		return link, nil
 	}
@@ -111,6 +209,49 @@ func CloudPlay(
 	return nil, errors.Join(attemptErrors...)
 }
 
+// attempt 离线下载单个磁力，同时进行的离线下载数量受 cloud_play_max_downloads 限制。
+func (s cloudPlaySession) attempt(ctx context.Context, magnet model.SourceMagnet) (*model.Link, error) {
+	release, err := cloudPlayDownloads.acquire(ctx, max(maxCloudPlayDownloads(), 1))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return attemptCloudMagnet(ctx, s, magnet)
+}
+
+// downloadLimiter 限制并发数，上限在每次获取时读取，修改设置后立即生效。
+type downloadLimiter struct {
+	mu       sync.Mutex
+	running  int
+	released chan struct{}
+}
+
+func (l *downloadLimiter) acquire(ctx context.Context, limit int) (func(), error) {
+	for {
+		l.mu.Lock()
+		if l.running < limit {
+			l.running++
+			l.mu.Unlock()
+			return l.release, nil
+		}
+		released := l.released
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+func (l *downloadLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
 func playSingleMagnet(ctx context.Context, session cloudPlaySession, magnet model.SourceMagnet) (*model.Link, error) {
 	fileName := session.downloadingFile.GetName()
 	status, _, err := downloadMagnet(
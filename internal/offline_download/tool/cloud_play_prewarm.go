@@ -0,0 +1,92 @@
+package tool
+
+import (
+	"context"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/media_job"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+const (
+	// cloudPlayPrewarmWindow 内新增的作品才会被预热
+	cloudPlayPrewarmWindow       = 72 * time.Hour
+	cloudPlayPrewarmScanLimit    = 20
+	cloudPlayPrewarmMaxDownloads = 5
+)
+
+var queryRecentFilmFiles = db.QueryRecentFilmFiles
+
+// CloudPlayPrewarm 描述一个影片存储的云播预热任务。
+type CloudPlayPrewarm struct {
+	StorageID uint
+	// Source 为作品来源，同时用于日志
+	Source     string
+	DriverType string
+	DriverPath string
+	// Magnets 返回作品可用于播放的磁力
+	Magnets func(ctx context.Context, work model.FilmWork) ([]model.SourceMagnet, error)
+	Jobs    *media_job.Group
+}
+
+// CloudPlayFile 返回影片文件在云播缓存中的文件对象。
+func CloudPlayFile(file model.FilmFileWithWork) (*model.EmbyFileObj, error) {
+	fileName, err := model.BuildMediaFileName(file.Work.Code, file.PartIndex, file.PartCount)
+	if err != nil {
+		return nil, err
+	}
+	return &model.EmbyFileObj{
+		ObjThumb: model.ObjThumb{Object: model.Object{
+			Name:     fileName,
+			Path:     file.Work.PrimaryDir,
+			IsFolder: false,
+		}},
+		WorkID:     file.WorkID,
+		FilmFileID: file.ID,
+	}, nil
+}
+
+// Run 为近期新增的作品提前离线下载磁力，播放时直接命中云盘缓存。
+func (p CloudPlayPrewarm) Run() {
+	utils.Log.Infof("start pre-warming %s cloud play", p.Source)
+	defer utils.Log.Infof("finish pre-warming %s cloud play", p.Source)
+
+	files, err := queryRecentFilmFiles(p.StorageID, p.Source, time.Now().Add(-cloudPlayPrewarmWindow), cloudPlayPrewarmScanLimit)
+	if err != nil {
+		utils.Log.Warnf("failed to query recent %s works for pre-warm: %s", p.Source, err)
+		return
+	}
+	p.Jobs.Processed(len(files))
+	ctx := context.Background()
+	remaining := cloudPlayPrewarmMaxDownloads
+	for index := range files {
+		if remaining == 0 {
+			return
+		}
+		file := files[index]
+		playbackFile, fileErr := CloudPlayFile(file)
+		if fileErr != nil {
+			p.Jobs.Failed(1)
+			continue
+		}
+		magnets, magnetErr := p.Magnets(ctx, file.Work)
+		if magnetErr != nil {
+			p.Jobs.Failed(1)
+			utils.Log.Warnf("failed to load %s magnets of %s for pre-warm: %s", p.Source, file.Work.Code, magnetErr)
+			continue
+		}
+		warmed, warmErr := PrewarmCloudPlay(ctx, p.DriverType, p.DriverPath, playbackFile, magnets)
+		if warmErr != nil {
+			remaining--
+			p.Jobs.Failed(1)
+			utils.Log.Warnf("failed to pre-warm %s cloud play of %s: %s", p.Source, file.Work.Code, warmErr)
+			continue
+		}
+		if warmed {
+			remaining--
+			utils.Log.Infof("pre-warmed %s cloud play of %s", p.Source, file.Work.Code)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package tool
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -61,14 +64,13 @@ func TestCloudPlayKeepsStaleRemoteCacheAndTriesSourceMagnets(t *testing.T) {
 	oldQuery := queryCloudCache
 	oldResolve := resolveCloudCacheLink
 	oldAttempt := attemptCloudMagnet
-	oldUpdate := updateSourceMagnetError
+	stubMagnetHealth(t)
 	oldSelect := selectPlaybackMagnet
 	t.Cleanup(func() {
 		getCloudPlayStorage = oldStorage
 		queryCloudCache = oldQuery
 		resolveCloudCacheLink = oldResolve
 		attemptCloudMagnet = oldAttempt
-		updateSourceMagnetError = oldUpdate
 		selectPlaybackMagnet = oldSelect
 	})
 
@@ -81,7 +83,6 @@ func TestCloudPlayKeepsStaleRemoteCacheAndTriesSourceMagnets(t *testing.T) {
 	attemptCloudMagnet = func(_ context.Context, _ cloudPlaySession, magnet model.SourceMagnet) (*model.Link, error) {
 		return &model.Link{URL: "https://download.example/" + magnet.Fingerprint}, nil
 	}
-	updateSourceMagnetError = func(uint, string) error { return nil }
 	selectPlaybackMagnet = func(uint, uint) error { return nil }
 
 	link, err := CloudPlay(context.Background(), model.LinkArgs{}, "115 Cloud", "/cloud", playbackFile("ABC-001.mp4"), []model.SourceMagnet{{ID: 11, WorkID: 3, MagnetURI: "magnet:?xt=one", Fingerprint: "one"}})
@@ -112,13 +113,12 @@ func TestCloudPlayRetriesDistinctMagnetsWithOneConfiguredProvider(t *testing.T)
 	oldStorage := getCloudPlayStorage
 	oldQuery := queryCloudCache
 	oldAttempt := attemptCloudMagnet
-	oldUpdate := updateSourceMagnetError
+	stubMagnetHealth(t)
 	oldSelect := selectPlaybackMagnet
 	t.Cleanup(func() {
 		getCloudPlayStorage = oldStorage
 		queryCloudCache = oldQuery
 		attemptCloudMagnet = oldAttempt
-		updateSourceMagnetError = oldUpdate
 		selectPlaybackMagnet = oldSelect
 	})
 
@@ -134,7 +134,7 @@ func TestCloudPlayRetriesDistinctMagnetsWithOneConfiguredProvider(t *testing.T)
 		return &model.Link{URL: "https://download.example/success"}, nil
 	}
 	errorsByID := make(map[uint]string)
-	updateSourceMagnetError = func(id uint, message string) error {
+	recordMagnetFailure = func(id uint, message string) error {
 		errorsByID[id] = message
 		return nil
 	}
@@ -159,6 +159,151 @@ func TestCloudPlayRetriesDistinctMagnetsWithOneConfiguredProvider(t *testing.T)
 	require.Equal(t, uint(2), selectedID)
 }
 
+func TestSortMagnetsByHealthOrdersByScoreAndSkipsCooldown(t *testing.T) {
+	now := time.Now()
+	soon, later := now.Add(time.Minute), now.Add(time.Hour)
+	ready, cooling := SortMagnetsByHealth([]model.SourceMagnet{
+		{ID: 1, Fingerprint: "selected", Selected: true, FailureCount: 1},
+		{ID: 2, Fingerprint: "fresh", Priority: 1},
+		{ID: 3, Fingerprint: "proven", Priority: 2, SuccessCount: 2, FailureCount: 1},
+		{ID: 4, Fingerprint: "cooling-long", Priority: 3, SuccessCount: 5, CooldownUntil: &later},
+		{ID: 5, Fingerprint: "cooling-short", Priority: 4, CooldownUntil: &soon},
+		{ID: 6, Fingerprint: "fresh", Priority: 5},
+	}, now)
+
+	require.Equal(t, []uint{3, 2, 1}, magnetIDs(ready))
+	require.Equal(t, []uint{5, 4}, magnetIDs(cooling))
+}
+
+func TestCloudPlayFallsBackToCoolingMagnets(t *testing.T) {
+	stubCloudPlay(t)
+	until := time.Now().Add(time.Hour)
+	var attempted []string
+	attemptCloudMagnet = func(_ context.Context, _ cloudPlaySession, magnet model.SourceMagnet) (*model.Link, error) {
+		attempted = append(attempted, magnet.Fingerprint)
+		return &model.Link{URL: "https://download.example/" + magnet.Fingerprint}, nil
+	}
+	magnets := []model.SourceMagnet{{ID: 1, WorkID: 9, Fingerprint: "cooling", CooldownUntil: &until}}
+
+	warmed, err := PrewarmCloudPlay(context.Background(), "PikPak", "/cloud", playbackFile("ABC-001.mp4"), magnets)
+	require.NoError(t, err)
+	require.False(t, warmed)
+	require.Empty(t, attempted)
+
+	link, err := CloudPlay(context.Background(), model.LinkArgs{}, "PikPak", "/cloud", playbackFile("ABC-001.mp4"), magnets)
+	require.NoError(t, err)
+	require.Equal(t, "https://download.example/cooling", link.URL)
+	require.Equal(t, []string{"cooling"}, attempted)
+}
+
+func TestCloudPlayDoesNotBlameMagnetForCanceledRequest(t *testing.T) {
+	stubCloudPlay(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	attemptCloudMagnet = func(ctx context.Context, _ cloudPlaySession, _ model.SourceMagnet) (*model.Link, error) {
+		cancel()
+		return nil, ctx.Err()
+	}
+	recordMagnetFailure = func(uint, string) error {
+		t.Fatal("failure recorded for a canceled request")
+		return nil
+	}
+
+	_, err := CloudPlay(ctx, model.LinkArgs{}, "PikPak", "/cloud", playbackFile("ABC-001.mp4"), []model.SourceMagnet{
+		{ID: 1, Fingerprint: "one"}, {ID: 2, Fingerprint: "two"},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCloudPlayLimitsParallelDownloads(t *testing.T) {
+	stubCloudPlay(t)
+	maxCloudPlayDownloads = func() int { return 1 }
+	var running, peak atomic.Int32
+	attemptCloudMagnet = func(context.Context, cloudPlaySession, model.SourceMagnet) (*model.Link, error) {
+		current := running.Add(1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return &model.Link{URL: "https://download.example/video"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := CloudPlay(context.Background(), model.LinkArgs{}, "PikPak", "/cloud", playbackFile("ABC-001.mp4"), []model.SourceMagnet{{ID: 1, Fingerprint: "one"}})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), peak.Load())
+}
+
+func stubCloudPlay(t *testing.T) {
+	t.Helper()
+	oldStorage := getCloudPlayStorage
+	oldQuery := queryCloudCache
+	oldAttempt := attemptCloudMagnet
+	oldSelect := selectPlaybackMagnet
+	t.Cleanup(func() {
+		getCloudPlayStorage = oldStorage
+		queryCloudCache = oldQuery
+		attemptCloudMagnet = oldAttempt
+		selectPlaybackMagnet = oldSelect
+	})
+	stubMagnetHealth(t)
+	getCloudPlayStorage = func(string) driver.Driver { return cloudPlayTestDriver{} }
+	queryCloudCache = func(string, string) model.MagnetCache { return model.MagnetCache{} }
+	selectPlaybackMagnet = func(uint, uint) error { return nil }
+}
+
+func stubMagnetHealth(t *testing.T) {
+	t.Helper()
+	oldSuccess := recordMagnetSuccess
+	oldFailure := recordMagnetFailure
+	oldMax := maxCloudPlayDownloads
+	t.Cleanup(func() {
+		recordMagnetSuccess = oldSuccess
+		recordMagnetFailure = oldFailure
+		maxCloudPlayDownloads = oldMax
+	})
+	recordMagnetSuccess = func(uint) error { return nil }
+	recordMagnetFailure = func(uint, string) error { return nil }
+	maxCloudPlayDownloads = func() int { return 2 }
+}
+
+func magnetIDs(magnets []model.SourceMagnet) []uint {
+	ids := make([]uint, len(magnets))
+	for i, magnet := range magnets {
+		ids[i] = magnet.ID
+	}
+	return ids
+}
+
 func playbackFile(name string) *model.EmbyFileObj {
 	return &model.EmbyFileObj{ObjThumb: model.ObjThumb{Object: model.Object{Name: name, Path: "actor"}}}
 }
+
+func TestPrewarmCloudPlayTriesSelectedMagnetOnly(t *testing.T) {
+	stubCloudPlay(t)
+	var attempted []string
+	attemptCloudMagnet = func(_ context.Context, _ cloudPlaySession, magnet model.SourceMagnet) (*model.Link, error) {
+		attempted = append(attempted, magnet.Fingerprint)
+		return nil, errors.New("offline download failed")
+	}
+	magnets := []model.SourceMagnet{
+		{ID: 1, WorkID: 9, Fingerprint: "proven", SuccessCount: 3},
+		{ID: 2, WorkID: 9, Fingerprint: "selected", Selected: true, Priority: 1},
+		{ID: 3, WorkID: 9, Fingerprint: "fresh", Priority: 2},
+	}
+
+	warmed, err := PrewarmCloudPlay(context.Background(), "PikPak", "/cloud", playbackFile("ABC-001.mp4"), magnets)
+	require.Error(t, err)
+	require.False(t, warmed)
+	require.Equal(t, []string{"selected"}, attempted)
+}
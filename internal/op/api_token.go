@@ -0,0 +1,66 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+// apiTokenTouchInterval throttles the last-used writes of busy tokens.
+const apiTokenTouchInterval = time.Minute
+
+// CreateAPIToken stores t for user and returns the raw token, which is not
+// kept anywhere and can't be shown again.
+func CreateAPIToken(user *model.User, t *model.APIToken) (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	raw := model.APITokenPrefix + random.String(40)
+	t.ID = 0
+	t.UserID = user.ID
+	t.Hash = model.HashAPIToken(raw)
+	t.Hint = raw[len(raw)-4:]
+	t.LastUsedAt = nil
+	if err := db.CreateAPIToken(t); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func GetAPITokensByUserId(userID uint) ([]model.APIToken, error) {
+	return db.GetAPITokensByUserId(userID)
+}
+
+func DeleteAPIToken(id, userID uint) error {
+	return db.DeleteAPIToken(id, userID)
+}
+
+// AuthByAPIToken resolves a raw API token to the token and its user, already
+// narrowed to the token's scopes and paths.
+func AuthByAPIToken(raw string) (*model.User, *model.APIToken, error) {
+	t, err := db.GetAPITokenByHash(model.HashAPIToken(raw))
+	if err != nil {
+		return nil, nil, errors.New("invalid api token")
+	}
+	if t.Expired() {
+		return nil, nil, errors.New("api token has expired")
+	}
+	user, err := GetUserById(t.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Disabled {
+		return nil, nil, errors.WithStack(errs.PermissionDenied)
+	}
+	now := time.Now()
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) > apiTokenTouchInterval {
+		if err = db.UpdateAPITokenLastUsed(t.ID, now); err == nil {
+			t.LastUsedAt = &now
+		}
+	}
+	return t.Narrow(user), t, nil
+}
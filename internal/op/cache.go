@@ -139,6 +139,11 @@ func (cm *CacheManager) DeleteUser(username string) {
 	cm.userCache.Delete(username)
 }
 
+// remove all user data from cache
+func (cm *CacheManager) ClearUsers() {
+	cm.userCache.Clear()
+}
+
 // caches setting
 func (cm *CacheManager) SetSetting(key string, setting *model.SettingItem) {
 	cm.settingCache.Set(key, setting)
@@ -47,7 +47,8 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 	}
 
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
-		files, err := storage.List(ctx, dir, args)
+		listCtx, slot := withObjsChangeSlot(ctx)
+		files, err := storage.List(listCtx, dir, args)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
@@ -60,8 +61,12 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		// warp obj name
 		model.WrapObjsName(files)
 		// call hooks
+		hookCtx := context.WithoutCancel(ctx)
+		if change := slot.get(); change != nil {
+			hookCtx = WithObjsChange(hookCtx, change)
+		}
 		go func(reqPath string, files []model.Obj) {
-			HandleObjsUpdateHook(context.WithoutCancel(ctx), reqPath, files)
+			HandleObjsUpdateHook(hookCtx, reqPath, files)
 		}(utils.GetFullPath(storage.GetStorage().MountPath, path), files)
 
 		// sort objs
@@ -318,6 +323,9 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 				default:
 					return nil, errs.NotImplement
 				}
+				if err == nil {
+					handleObjWrite(ctx, storage, "", path)
+				}
 				return nil, errors.WithStack(err)
 			}
 			return nil, errors.WithMessage(err, "failed to check if dir exists")
@@ -378,6 +386,9 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		err = errs.NotImplement
 	}
+	if err == nil {
+		handleObjWrite(ctx, storage, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()))
+	}
 
 	if !utils.IsBool(lazyCache...) && err == nil && needHandleObjsUpdateHook() {
 		if !srcObj.IsDir() {
@@ -438,6 +449,9 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		handleObjWrite(ctx, storage, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	}
 	return errors.WithStack(err)
 }
 
@@ -482,6 +496,9 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		err = errs.NotImplement
 	}
+	if err == nil {
+		handleObjWrite(ctx, storage, "", stdpath.Join(dstDirPath, srcObj.GetName()))
+	}
 
 	if !utils.IsBool(lazyCache...) && err == nil && needHandleObjsUpdateHook() {
 		if !srcObj.IsDir() {
@@ -501,6 +518,28 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	return errors.WithStack(err)
 }
 
+// SetModTime changes the modification time of the object at path, for
+// drivers implementing driver.SetModTime.
+func SetModTime(ctx context.Context, storage driver.Driver, path string, modified time.Time) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.SetModTime)
+	if !ok {
+		return errs.NotSupport
+	}
+	path = utils.FixAndCleanPath(path)
+	rawObj, err := Get(ctx, storage, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get object")
+	}
+	if err = s.SetModTime(ctx, model.UnwrapObj(rawObj), modified); err != nil {
+		return errors.WithStack(err)
+	}
+	Cache.DeleteDirectory(storage, stdpath.Dir(path))
+	return nil
+}
+
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.WithMessagef(errs.StorageNotInit, "storage status: %s", storage.GetStorage().Status)
@@ -525,6 +564,7 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 		err = s.Remove(ctx, model.UnwrapObj(rawObj))
 		if err == nil {
 			Cache.removeDirectoryObject(storage, dirPath, rawObj)
+			handleObjWrite(ctx, storage, path, "")
 		}
 	default:
 		return errs.NotImplement
@@ -625,6 +665,9 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 			err = Remove(ctx, storage, tempPath)
 		}
 	}
+	if err == nil {
+		handleObjWrite(ctx, storage, "", dstPath)
+	}
 	if !utils.IsBool(lazyCache...) && err == nil && needHandleObjsUpdateHook() {
 		go List(context.Background(), storage, dstDirPath, model.ListArgs{Refresh: true})
 	}
@@ -692,6 +735,9 @@ func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url
 	default:
 		return errors.WithStack(errs.NotImplement)
 	}
+	if err == nil {
+		handleObjWrite(ctx, storage, "", dstPath)
+	}
 	if !utils.IsBool(lazyCache...) && err == nil && needHandleObjsUpdateHook() {
 		go List(context.Background(), storage, dstDirPath, model.ListArgs{Refresh: true})
 	}
@@ -739,6 +785,18 @@ func GetDirectUploadInfo(ctx context.Context, tool string, storage driver.Driver
 	return info, nil
 }
 
+// handleObjWrite calls the write hooks with the full paths of the storage.
+func handleObjWrite(ctx context.Context, storage driver.Driver, from, to string) {
+	mountPath := storage.GetStorage().MountPath
+	if from != "" {
+		from = utils.GetFullPath(mountPath, from)
+	}
+	if to != "" {
+		to = utils.GetFullPath(mountPath, to)
+	}
+	HandleObjWriteHook(context.WithoutCancel(ctx), from, to)
+}
+
 func needHandleObjsUpdateHook() bool {
 	needHandle, _ := GetSettingItemByKey(conf.HandleHookAfterWriting)
 	return needHandle != nil && (needHandle.Value == "true" || needHandle.Value == "1")
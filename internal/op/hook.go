@@ -3,7 +3,9 @@ package op
 import (
 	"context"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
@@ -16,20 +18,111 @@ import (
 // Obj
 type ObjsUpdateHook = func(ctx context.Context, parent string, objs []model.Obj)
 
+type objsUpdateHookEntry struct {
+	hook ObjsUpdateHook
+}
+
 var (
-	objsUpdateHooks = make([]ObjsUpdateHook, 0)
+	objsUpdateHooksMu sync.RWMutex
+	objsUpdateHooks   = make([]*objsUpdateHookEntry, 0)
 )
 
-func RegisterObjsUpdateHook(hook ObjsUpdateHook) {
-	objsUpdateHooks = append(objsUpdateHooks, hook)
+// RegisterObjsUpdateHook adds hook, the returned func removes it again.
+func RegisterObjsUpdateHook(hook ObjsUpdateHook) (unregister func()) {
+	objsUpdateHooksMu.Lock()
+	defer objsUpdateHooksMu.Unlock()
+	e := &objsUpdateHookEntry{hook: hook}
+	objsUpdateHooks = append(objsUpdateHooks, e)
+	return func() {
+		objsUpdateHooksMu.Lock()
+		defer objsUpdateHooksMu.Unlock()
+		objsUpdateHooks = slices.DeleteFunc(slices.Clone(objsUpdateHooks), func(x *objsUpdateHookEntry) bool {
+			return x == e
+		})
+	}
 }
 
 func HandleObjsUpdateHook(ctx context.Context, parent string, objs []model.Obj) {
-	for _, hook := range objsUpdateHooks {
-		hook(ctx, parent, objs)
+	objsUpdateHooksMu.RLock()
+	hooks := objsUpdateHooks
+	objsUpdateHooksMu.RUnlock()
+	for _, e := range hooks {
+		e.hook(ctx, parent, objs)
 	}
 }
 
+// ObjWriteHook is called after a write operation succeeded, paths are full
+// paths. from is the path that went away by remove, move or rename, to the
+// path that was created by put, copy, move, rename or mkdir, the other one
+// is empty.
+type ObjWriteHook = func(ctx context.Context, from, to string)
+
+var (
+	objWriteHooks = make([]ObjWriteHook, 0)
+)
+
+func RegisterObjWriteHook(hook ObjWriteHook) {
+	objWriteHooks = append(objWriteHooks, hook)
+}
+
+func HandleObjWriteHook(ctx context.Context, from, to string) {
+	for _, hook := range objWriteHooks {
+		hook(ctx, from, to)
+	}
+}
+
+// ObjsChange is the delta between two listings of the same directory.
+// Drivers that keep snapshots of their listings, like Cache, attach it to the
+// context of the objs update hook so hooks can skip unchanged entries.
+type ObjsChange struct {
+	Added    []model.Obj
+	Removed  []model.Obj
+	Modified []model.Obj
+}
+
+type objsChangeKey struct{}
+
+type objsChangeSlotKey struct{}
+
+// objsChangeSlot receives the delta a driver reports while op.List lists it.
+type objsChangeSlot struct {
+	mu     sync.Mutex
+	change *ObjsChange
+}
+
+func withObjsChangeSlot(ctx context.Context) (context.Context, *objsChangeSlot) {
+	slot := &objsChangeSlot{}
+	return context.WithValue(ctx, objsChangeSlotKey{}, slot), slot
+}
+
+func (s *objsChangeSlot) get() *ObjsChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.change
+}
+
+// SetObjsChange attaches change to the objs update hooks op.List calls for
+// the listing in progress, so a driver reports a delta without calling the
+// hooks a second time. Outside op.List it does nothing.
+func SetObjsChange(ctx context.Context, change *ObjsChange) {
+	if slot, ok := ctx.Value(objsChangeSlotKey{}).(*objsChangeSlot); ok {
+		slot.mu.Lock()
+		slot.change = change
+		slot.mu.Unlock()
+	}
+}
+
+func WithObjsChange(ctx context.Context, change *ObjsChange) context.Context {
+	return context.WithValue(ctx, objsChangeKey{}, change)
+}
+
+// ObjsChangeFromContext returns the delta attached by WithObjsChange,
+// hooks called with a plain listing get false.
+func ObjsChangeFromContext(ctx context.Context) (*ObjsChange, bool) {
+	change, ok := ctx.Value(objsChangeKey{}).(*ObjsChange)
+	return change, ok && change != nil
+}
+
 // Setting
 type SettingItemHook func(item *model.SettingItem) error
 
@@ -0,0 +1,167 @@
+package op
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
+	"github.com/pkg/errors"
+)
+
+func loadUserGrants(u *model.User) error {
+	grants, err := db.GetUserPathPermissions(u.ID)
+	if err != nil {
+		return err
+	}
+	u.Grants = grants
+	return nil
+}
+
+// clearUsersCache drops every cached user, since a role, group or grant
+// change may affect any number of them. Group changes also move users
+// between traffic limits.
+func clearUsersCache() {
+	adminUser = nil
+	guestUser = nil
+	Cache.ClearUsers()
+	traffic.Reset()
+}
+
+func GetRoles() ([]model.Role, error) {
+	return db.GetRoles()
+}
+
+func GetRoleById(id uint) (*model.Role, error) {
+	return db.GetRoleById(id)
+}
+
+func CreateRole(r *model.Role) error {
+	return db.CreateRole(r)
+}
+
+func UpdateRole(r *model.Role) error {
+	if _, err := db.GetRoleById(r.ID); err != nil {
+		return err
+	}
+	defer clearUsersCache()
+	return db.UpdateRole(r)
+}
+
+func DeleteRoleById(id uint) error {
+	defer clearUsersCache()
+	return db.DeleteRoleById(id)
+}
+
+func GetUserGroups() ([]model.UserGroup, error) {
+	return db.GetUserGroups()
+}
+
+func GetUserGroupById(id uint) (*model.UserGroup, error) {
+	return db.GetUserGroupById(id)
+}
+
+func CreateUserGroup(g *model.UserGroup) error {
+	return db.CreateUserGroup(g)
+}
+
+func UpdateUserGroup(g *model.UserGroup) error {
+	if _, err := db.GetUserGroupById(g.ID); err != nil {
+		return err
+	}
+	return db.UpdateUserGroup(g)
+}
+
+func DeleteUserGroupById(id uint) error {
+	defer clearUsersCache()
+	return db.DeleteUserGroupById(id)
+}
+
+func GetUserGroupMemberIds(groupID uint) ([]uint, error) {
+	return db.GetUserGroupMemberIds(groupID)
+}
+
+func SetUserGroupMembers(groupID uint, userIDs []uint) error {
+	if _, err := db.GetUserGroupById(groupID); err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		if _, err := db.GetUserById(id); err != nil {
+			return err
+		}
+	}
+	defer clearUsersCache()
+	return db.SetUserGroupMembers(groupID, userIDs)
+}
+
+func GetPathGrants(userID, groupID uint) ([]model.PathGrant, error) {
+	return db.GetPathGrants(userID, groupID)
+}
+
+func checkPathGrant(g *model.PathGrant) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.GetRoleById(g.RoleID); err != nil {
+		return err
+	}
+	if g.UserID != 0 {
+		if _, err := db.GetUserById(g.UserID); err != nil {
+			return err
+		}
+	}
+	if g.GroupID != 0 {
+		if _, err := db.GetUserGroupById(g.GroupID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func CreatePathGrant(g *model.PathGrant) error {
+	if err := checkPathGrant(g); err != nil {
+		return err
+	}
+	defer clearUsersCache()
+	return db.CreatePathGrant(g)
+}
+
+func UpdatePathGrant(g *model.PathGrant) error {
+	if _, err := db.GetPathGrantById(g.ID); err != nil {
+		return err
+	}
+	if err := checkPathGrant(g); err != nil {
+		return err
+	}
+	defer clearUsersCache()
+	return db.UpdatePathGrant(g)
+}
+
+func DeletePathGrantById(id uint) error {
+	defer clearUsersCache()
+	return db.DeletePathGrantById(id)
+}
+
+// MigrateUserPermission moves the legacy bitmask of a user into a grant on
+// "/" of a role with the same bits, creating the role on first use. Roles
+// are shared by users with equal masks.
+func MigrateUserPermission(u *model.User) error {
+	if u.Permission == 0 {
+		return nil
+	}
+	name := model.LegacyRoleName(u.Permission)
+	role, err := db.GetRoleByName(name)
+	if err != nil {
+		role = &model.Role{
+			Name:        name,
+			Description: "migrated from the user permission bitmask",
+			Permission:  u.Permission,
+		}
+		if err = db.CreateRole(role); err != nil {
+			return errors.WithMessage(err, "failed create legacy role")
+		}
+	}
+	if err = db.CreatePathGrant(&model.PathGrant{RoleID: role.ID, UserID: u.ID, Path: "/"}); err != nil {
+		return errors.WithMessage(err, "failed create legacy grant")
+	}
+	u.Permission = 0
+	return UpdateUser(u)
+}
@@ -0,0 +1,59 @@
+package op
+
+import (
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+)
+
+var s3CredentialChangingCallbacks = make([]func(), 0)
+
+// RegisterS3CredentialChangingCallback lets the S3 server reload its keys
+// whenever a credential is added or removed.
+func RegisterS3CredentialChangingCallback(f func()) {
+	s3CredentialChangingCallbacks = append(s3CredentialChangingCallbacks, f)
+}
+
+func s3CredentialChanged() {
+	for _, cb := range s3CredentialChangingCallbacks {
+		cb()
+	}
+}
+
+// CreateS3Credential generates an access key pair for user. The returned
+// credential is the only place the secret is shown.
+func CreateS3Credential(user *model.User, name string) (*model.S3Credential, error) {
+	c := &model.S3Credential{
+		UserID:    user.ID,
+		Name:      name,
+		AccessKey: "OL" + strings.ToUpper(random.String(18)),
+		SecretKey: random.String(40),
+	}
+	if err := db.CreateS3Credential(c); err != nil {
+		return nil, err
+	}
+	s3CredentialChanged()
+	return c, nil
+}
+
+func GetS3Credentials() ([]model.S3Credential, error) {
+	return db.GetS3Credentials()
+}
+
+func GetS3CredentialsByUserId(userID uint) ([]model.S3Credential, error) {
+	return db.GetS3CredentialsByUserId(userID)
+}
+
+func GetS3CredentialById(id uint) (*model.S3Credential, error) {
+	return db.GetS3CredentialById(id)
+}
+
+func DeleteS3Credential(id, userID uint) error {
+	if err := db.DeleteS3Credential(id, userID); err != nil {
+		return err
+	}
+	s3CredentialChanged()
+	return nil
+}
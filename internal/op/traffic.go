@@ -0,0 +1,52 @@
+package op
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
+)
+
+func GetTrafficLimits() ([]model.TrafficLimit, error) {
+	return db.GetTrafficLimits()
+}
+
+func checkTrafficLimit(l *model.TrafficLimit) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	if l.UserID != 0 {
+		if _, err := db.GetUserById(l.UserID); err != nil {
+			return err
+		}
+	}
+	if l.GroupID != 0 {
+		if _, err := db.GetUserGroupById(l.GroupID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func CreateTrafficLimit(l *model.TrafficLimit) error {
+	if err := checkTrafficLimit(l); err != nil {
+		return err
+	}
+	defer traffic.Reset()
+	return db.CreateTrafficLimit(l)
+}
+
+func UpdateTrafficLimit(l *model.TrafficLimit) error {
+	if _, err := db.GetTrafficLimitById(l.ID); err != nil {
+		return err
+	}
+	if err := checkTrafficLimit(l); err != nil {
+		return err
+	}
+	defer traffic.Reset()
+	return db.UpdateTrafficLimit(l)
+}
+
+func DeleteTrafficLimitById(id uint) error {
+	defer traffic.Reset()
+	return db.DeleteTrafficLimitById(id)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
 	"github.com/OpenListTeam/OpenList/v4/pkg/singleflight"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/pkg/errors"
@@ -19,6 +20,9 @@ func GetAdmin() (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadUserGrants(user); err != nil {
+			return nil, err
+		}
 		adminUser = user
 	}
 	return adminUser, nil
@@ -30,6 +34,9 @@ func GetGuest() (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadUserGrants(user); err != nil {
+			return nil, err
+		}
 		guestUser = user
 	}
 	return guestUser, nil
@@ -51,6 +58,9 @@ func GetUserByName(username string) (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadUserGrants(_user); err != nil {
+			return nil, err
+		}
 		Cache.SetUser(username, _user)
 		return _user, nil
 	})
@@ -58,7 +68,14 @@ func GetUserByName(username string) (*model.User, error) {
 }
 
 func GetUserById(id uint) (*model.User, error) {
-	return db.GetUserById(id)
+	user, err := db.GetUserById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = loadUserGrants(user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func GetUsers(pageIndex, pageSize int) (users []model.User, count int64, err error) {
@@ -82,6 +99,23 @@ func DeleteUserById(id uint) error {
 	if err := DeleteSharingsByCreatorId(id); err != nil {
 		return errors.WithMessage(err, "failed to delete user's sharings")
 	}
+	if err := db.DeletePathGrantsByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's grants")
+	}
+	if err := db.DeleteUserGroupMembersByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's group memberships")
+	}
+	if err := db.DeleteAPITokensByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's api tokens")
+	}
+	if err := db.DeleteTrafficLimitsByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's traffic limits")
+	}
+	if err := db.DeleteS3CredentialsByUserId(id); err != nil {
+		return errors.WithMessage(err, "failed to delete user's s3 credentials")
+	}
+	s3CredentialChanged()
+	traffic.Reset()
 	return db.DeleteUserById(id)
 }
 
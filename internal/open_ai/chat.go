@@ -0,0 +1,136 @@
+package open_ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// chatTranslator translates through a chat model, the backends only differ
+// in how a chat request is sent.
+type chatTranslator struct {
+	name    string
+	models  []string
+	promote string
+	chat    func(model string, messages []map[string]any) (string, error)
+}
+
+func newChatTranslator(name, models, promote string, chat func(string, []map[string]any) (string, error)) *chatTranslator {
+	t := &chatTranslator{name: name, promote: promote, chat: chat}
+	for _, model := range strings.Split(models, ",") {
+		if model = strings.TrimSpace(model); model != "" {
+			t.models = append(t.models, model)
+		}
+	}
+	if len(t.models) == 0 {
+		t.models = []string{""}
+	}
+	return t
+}
+
+func (t *chatTranslator) Name() string {
+	return t.name
+}
+
+func (t *chatTranslator) PromptKey() string {
+	return strings.Join(t.models, ",") + "\x00" + t.promote
+}
+
+func (t *chatTranslator) messages(content string) []map[string]any {
+	var param []map[string]any
+	if t.promote != "" {
+		param = append(param, map[string]any{
+			"role":    "system",
+			"content": t.promote,
+		})
+	}
+	return append(param, map[string]any{
+		"role":    "user",
+		"content": content,
+	})
+}
+
+func (t *chatTranslator) Translate(text string) (string, error) {
+	var errs []error
+	for _, model := range t.models {
+		utils.Log.Debugf("开始翻译:%s", text)
+		ans, err := t.chat(model, t.messages(text))
+		if err == nil && ans == "" {
+			err = errors.New("翻译结果为空")
+		}
+		if err == nil {
+			return ans, nil
+		}
+		utils.Log.Warnf("翻译失败:%s", err.Error())
+		errs = append(errs, fmt.Errorf("model %s: %w", model, err))
+	}
+	return "", errors.Join(errs...)
+}
+
+func (t *chatTranslator) BatchTranslate(items []TranslateItem) ([]string, error) {
+	jsonInput, _ := json.Marshal(items)
+	basePrompt := fmt.Sprintf(`对于以下JSON数组中的每个对象：
+- 如果candidate已经是通顺的中文，保留candidate
+- 如果candidate不是中文或不流畅，根据origin重新翻译为中文
+- 如果candidate为空，根据origin翻译为中文
+只返回相同顺序的JSON字符串数组，不要其他内容。
+输出示例：["翻译1","翻译2","翻译3"]
+输入数据：
+%s`, string(jsonInput))
+
+	var retryReason string
+	for _, model := range t.models {
+		prompt := basePrompt
+		for attempt := 0; attempt < 3; attempt++ {
+			utils.Log.Debugf("开始批量翻译:%d个文本", len(items))
+			var translations []string
+			content, err := t.chat(model, t.messages(prompt))
+			if err != nil {
+				retryReason = fmt.Sprintf("API请求失败:%s", err.Error())
+			} else if content == "" {
+				retryReason = "翻译结果为空"
+			} else {
+				translations, retryReason = parseBatchTranslations(content, len(items))
+			}
+			if translations != nil {
+				return translations, nil
+			}
+			utils.Log.Warnf("批量翻译失败:%s", retryReason)
+			prompt = basePrompt + fmt.Sprintf("\n\n上次翻译出错：%s\n请重试，确保返回恰好%d个翻译的JSON数组。", retryReason, len(items))
+			utils.Log.Warnf("批量翻译第%d次重试", attempt+1)
+		}
+	}
+	return nil, errors.New(retryReason)
+}
+
+func parseBatchTranslations(content string, count int) ([]string, string) {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	content = strings.TrimSpace(content)
+
+	var translations []string
+	if err := json.Unmarshal([]byte(content), &translations); err != nil {
+		var altItems []TranslateItem
+		if err2 := json.Unmarshal([]byte(content), &altItems); err2 != nil {
+			return nil, fmt.Sprintf("JSON解析失败(字符串数组:%s,对象数组:%s),原始响应:%s", err.Error(), err2.Error(), content)
+		}
+		translations = nil
+		for _, item := range altItems {
+			if item.Candidate != "" {
+				translations = append(translations, item.Candidate)
+			} else {
+				translations = append(translations, item.Origin)
+			}
+		}
+	}
+
+	if len(translations) != count {
+		return nil, fmt.Sprintf("翻译数量不匹配:期望%d,实际%d,响应:%s", count, len(translations), content)
+	}
+	return translations, ""
+}
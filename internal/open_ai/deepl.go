@@ -0,0 +1,88 @@
+package open_ai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/go-resty/resty/v2"
+)
+
+const defaultDeepLUrl = "https://api-free.deepl.com"
+
+// deepLTranslator uses DeepL style APIs (DeepL itself, DeepLX and the like).
+// They translate text as is, so batch candidates are ignored.
+type deepLTranslator struct {
+	client     *resty.Client
+	url        string
+	apiKey     string
+	targetLang string
+}
+
+func newDeepLTranslator(url, apiKey, targetLang string) *deepLTranslator {
+	if url == "" {
+		url = defaultDeepLUrl
+	}
+	if targetLang == "" {
+		targetLang = "ZH"
+	}
+	return &deepLTranslator{
+		client:     base.NewRestyClient().SetTimeout(TIMEOUT),
+		url:        strings.TrimSuffix(url, "/"),
+		apiKey:     apiKey,
+		targetLang: strings.ToUpper(targetLang),
+	}
+}
+
+func (t *deepLTranslator) Name() string {
+	return ProviderDeepL
+}
+
+func (t *deepLTranslator) PromptKey() string {
+	return t.targetLang
+}
+
+func (t *deepLTranslator) Translate(text string) (string, error) {
+	translations, err := t.translate([]string{text})
+	if err != nil {
+		return "", err
+	}
+	return translations[0], nil
+}
+
+func (t *deepLTranslator) BatchTranslate(items []TranslateItem) ([]string, error) {
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.Origin
+	}
+	return t.translate(texts)
+}
+
+func (t *deepLTranslator) translate(texts []string) ([]string, error) {
+	var result struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+		Message string `json:"message"`
+	}
+	response, err := t.client.R().
+		SetHeader("Authorization", "DeepL-Auth-Key "+t.apiKey).
+		SetBody(base.Json{"text": texts, "target_lang": t.targetLang}).
+		SetResult(&result).SetError(&result).
+		Post(t.url + "/v2/translate")
+	if err != nil {
+		return nil, err
+	}
+	if response.IsError() {
+		return nil, fmt.Errorf("状态码:%d,错误:%s", response.StatusCode(), result.Message)
+	}
+	if len(result.Translations) != len(texts) {
+		return nil, errors.New("翻译数量不匹配")
+	}
+	translations := make([]string, len(texts))
+	for i, translation := range result.Translations {
+		translations[i] = translation.Text
+	}
+	return translations, nil
+}
@@ -0,0 +1,35 @@
+package open_ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+)
+
+// newOllamaTranslator uses Ollama's native chat API, which needs neither an
+// API key nor the OpenAI compatibility layer.
+func newOllamaTranslator(url, models, promote string) *chatTranslator {
+	client := base.NewRestyClient().SetTimeout(TIMEOUT)
+	url = strings.TrimSuffix(url, "/")
+	return newChatTranslator(ProviderOllama, models, promote, func(model string, messages []map[string]any) (string, error) {
+		var result struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			Error string `json:"error"`
+		}
+		response, err := client.R().SetBody(base.Json{
+			"model":    model,
+			"messages": messages,
+			"stream":   false,
+		}).SetResult(&result).SetError(&result).Post(url + "/api/chat")
+		if err != nil {
+			return "", err
+		}
+		if response.IsError() {
+			return "", fmt.Errorf("状态码:%d,错误:%s", response.StatusCode(), result.Error)
+		}
+		return result.Message.Content, nil
+	})
+}
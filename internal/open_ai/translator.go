@@ -0,0 +1,193 @@
+package open_ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+	ProviderDeepL  = "deepl"
+)
+
+// Translator is a translation backend.
+type Translator interface {
+	// Name identifies the backend in the translation cache
+	Name() string
+	// PromptKey changes whenever the backend would translate the same text
+	// differently, e.g. with another model, system prompt or target language
+	PromptKey() string
+	Translate(text string) (string, error)
+	// BatchTranslate returns exactly one translation per item
+	BatchTranslate(items []TranslateItem) ([]string, error)
+}
+
+type TranslateItem struct {
+	Origin    string `json:"origin"`
+	Candidate string `json:"candidate"`
+}
+
+// NewTranslator builds the backend configured in the settings,
+// it returns nil when the backend is not configured.
+func NewTranslator() Translator {
+	switch setting.GetStr(conf.TranslateProvider, ProviderOpenAI) {
+	case ProviderOllama:
+		url := setting.GetStr(conf.OllamaUrl)
+		if url == "" {
+			return nil
+		}
+		return newOllamaTranslator(url, setting.GetStr(conf.OllamaModel), setting.GetStr(conf.OpenAiTranslatePromote))
+	case ProviderDeepL:
+		apiKey := setting.GetStr(conf.DeepLApiKey)
+		if apiKey == "" {
+			return nil
+		}
+		return newDeepLTranslator(setting.GetStr(conf.DeepLUrl), apiKey, setting.GetStr(conf.DeepLTargetLang))
+	default:
+		url := setting.GetStr(conf.OpenAiUrl)
+		apiKey := setting.GetStr(conf.OpenAiApiKey)
+		if url == "" || apiKey == "" {
+			return nil
+		}
+		return newOpenAITranslator(url, apiKey, setting.GetStr(conf.OpenAiTranslateModel), setting.GetStr(conf.OpenAiTranslatePromote))
+	}
+}
+
+// Translate translates a single text with the configured backend,
+// it returns the text itself when the backend is missing or fails.
+func Translate(text string) string {
+	translator := NewTranslator()
+	if translator == nil {
+		return text
+	}
+	if translated := translateWith(translator, text); translated != "" {
+		return translated
+	}
+	return text
+}
+
+// BatchTranslate translates the items with the configured backend. Without a
+// backend the candidates (or origins) are returned as they are, a failed
+// batch yields empty strings.
+func BatchTranslate(items []TranslateItem) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	translator := NewTranslator()
+	if translator == nil {
+		results := make([]string, len(items))
+		for i, item := range items {
+			if item.Candidate != "" {
+				results[i] = item.Candidate
+			} else {
+				results[i] = item.Origin
+			}
+		}
+		return results
+	}
+	return batchTranslateWith(translator, items)
+}
+
+func translateWith(translator Translator, text string) string {
+	hash := cacheHash(translator, "text", text)
+	if cached := loadCachedTranslations(translator, []string{hash}); cached[hash] != "" {
+		return cached[hash]
+	}
+	translated, err := translator.Translate(text)
+	if err != nil {
+		utils.Log.Warnf("[translate] %s failed: %s", translator.Name(), err)
+		return ""
+	}
+	storeCachedTranslations(translator, map[string]string{hash: translated})
+	return translated
+}
+
+func batchTranslateWith(translator Translator, items []TranslateItem) []string {
+	results := make([]string, len(items))
+	hashes := make([]string, len(items))
+	for i, item := range items {
+		hashes[i] = cacheHash(translator, "item", item.Origin, item.Candidate)
+	}
+	cached := loadCachedTranslations(translator, hashes)
+
+	var missing []int
+	for i, hash := range hashes {
+		if result := cached[hash]; result != "" {
+			results[i] = result
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return results
+	}
+	utils.Log.Debugf("[translate] %d of %d items cached", len(items)-len(missing), len(items))
+
+	pending := make([]TranslateItem, len(missing))
+	for i, index := range missing {
+		pending[i] = items[index]
+	}
+	translations, err := translator.BatchTranslate(pending)
+	if err != nil {
+		utils.Log.Warnf("[translate] %s batch failed: %s", translator.Name(), err)
+		return results
+	}
+	fresh := make(map[string]string, len(missing))
+	for i, index := range missing {
+		if i < len(translations) {
+			results[index] = translations[i]
+			fresh[hashes[index]] = translations[i]
+		}
+	}
+	storeCachedTranslations(translator, fresh)
+	return results
+}
+
+func cacheHash(translator Translator, kind string, parts ...string) string {
+	sum := sha256.New()
+	for _, part := range append([]string{kind, translator.PromptKey()}, parts...) {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+var pruneStaleCaches sync.Once
+
+func loadCachedTranslations(translator Translator, hashes []string) map[string]string {
+	pruneStaleCaches.Do(func() {
+		if err := db.DeleteStaleTranslationCaches(model.CurrentTranslationVersion); err != nil {
+			utils.Log.Warnf("[translate] failed to prune translation cache: %s", err)
+		}
+	})
+	cached, err := db.GetTranslationCaches(translator.Name(), model.CurrentTranslationVersion, hashes)
+	if err != nil {
+		utils.Log.Warnf("[translate] failed to read translation cache: %s", err)
+		return nil
+	}
+	return cached
+}
+
+func storeCachedTranslations(translator Translator, translations map[string]string) {
+	caches := make([]model.TranslationCache, 0, len(translations))
+	for hash, result := range translations {
+		if strings.TrimSpace(result) == "" {
+			continue
+		}
+		caches = append(caches, model.TranslationCache{
+			Backend: translator.Name(), Version: model.CurrentTranslationVersion, Hash: hash, Result: result,
+		})
+	}
+	if err := db.SaveTranslationCaches(caches); err != nil {
+		utils.Log.Warnf("[translate] failed to write translation cache: %s", err)
+	}
+}
@@ -0,0 +1,162 @@
+package open_ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	dataDir, err := os.MkdirTemp("", "translator-test-")
+	if err != nil {
+		panic(err)
+	}
+	conf.Conf = conf.DefaultConfig(dataDir)
+	database, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "translator-test.db")), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+	if err := db.Init(database); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	if sqlDB, sqlErr := database.DB(); sqlErr == nil {
+		_ = sqlDB.Close()
+	}
+	_ = os.RemoveAll(dataDir)
+	os.Exit(code)
+}
+
+func TestOllamaBatchTranslateUsesCachePerItem(t *testing.T) {
+	var calls atomic.Int32
+	var lastPrompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		var body struct {
+			Model    string           `json:"model"`
+			Messages []map[string]any `json:"messages"`
+			Stream   bool             `json:"stream"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "qwen" || body.Stream {
+			t.Errorf("request = %+v", body)
+		}
+		lastPrompt = body.Messages[len(body.Messages)-1]["content"].(string)
+		var answer []string
+		if strings.Contains(lastPrompt, "title three") {
+			answer = []string{"标题三"}
+		} else {
+			answer = []string{"标题一", "标题二"}
+		}
+		content, _ := json.Marshal(answer)
+		_ = json.NewEncoder(w).Encode(map[string]any{"message": map[string]string{"content": "```json\n" + string(content) + "\n```"}})
+	}))
+	defer server.Close()
+
+	translator := newOllamaTranslator(server.URL, "qwen", "translate to chinese")
+	first := batchTranslateWith(translator, []TranslateItem{{Origin: "title one"}, {Origin: "title two"}})
+	if !slices.Equal(first, []string{"标题一", "标题二"}) {
+		t.Fatalf("first batch = %v", first)
+	}
+
+	second := batchTranslateWith(translator, []TranslateItem{{Origin: "title two"}, {Origin: "title three"}, {Origin: "title one"}})
+	if !slices.Equal(second, []string{"标题二", "标题三", "标题一"}) {
+		t.Fatalf("second batch = %v", second)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want one request per batch", calls.Load())
+	}
+	if strings.Contains(lastPrompt, "title one") || strings.Contains(lastPrompt, "title two") {
+		t.Fatalf("cached items were sent again: %s", lastPrompt)
+	}
+
+	// another system prompt must not reuse the cached translations
+	changed := newOllamaTranslator(server.URL, "qwen", "another prompt")
+	batchTranslateWith(changed, []TranslateItem{{Origin: "title one"}, {Origin: "title two"}})
+	if calls.Load() != 3 {
+		t.Fatalf("calls = %d after prompt change", calls.Load())
+	}
+}
+
+func TestCacheHashCoversModel(t *testing.T) {
+	qwen := newOllamaTranslator("http://localhost", "qwen", "translate to chinese")
+	llama := newOllamaTranslator("http://localhost", "llama", "translate to chinese")
+	if cacheHash(qwen, "text", "title") == cacheHash(llama, "text", "title") {
+		t.Fatal("another model reuses the cached translations")
+	}
+}
+
+func TestDeepLTranslateAndStaleCacheVersion(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v2/translate" || r.Header.Get("Authorization") != "DeepL-Auth-Key secret" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "forbidden"})
+			return
+		}
+		calls.Add(1)
+		var body struct {
+			Text       []string `json:"text"`
+			TargetLang string   `json:"target_lang"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		translations := make([]map[string]string, len(body.Text))
+		for i, text := range body.Text {
+			translations[i] = map[string]string{"text": body.TargetLang + ":" + text}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"translations": translations})
+	}))
+	defer server.Close()
+
+	translator := newDeepLTranslator(server.URL, "secret", "zh")
+	if got := translateWith(translator, "hello"); got != "ZH:hello" {
+		t.Fatalf("translate = %q", got)
+	}
+	if got := translateWith(translator, "hello"); got != "ZH:hello" || calls.Load() != 1 {
+		t.Fatalf("cached translate = %q after %d calls", got, calls.Load())
+	}
+
+	// entries of an older translation version are not reused
+	if err := db.SaveTranslationCaches([]model.TranslationCache{{
+		Backend: ProviderDeepL, Version: model.CurrentTranslationVersion - 1, Hash: cacheHash(translator, "text", "stale"), Result: "old",
+	}}); err != nil {
+		t.Fatalf("save stale cache: %v", err)
+	}
+	if got := translateWith(translator, "stale"); got != "ZH:stale" {
+		t.Fatalf("translate stale = %q", got)
+	}
+
+	broken := newDeepLTranslator(server.URL, "wrong", "zh")
+	if got := translateWith(broken, "other"); got != "" {
+		t.Fatalf("failed translate = %q", got)
+	}
+}
+
+func TestParseBatchTranslationsAcceptsObjects(t *testing.T) {
+	got, reason := parseBatchTranslations(`[{"origin":"a","candidate":"甲"},{"origin":"b"}]`, 2)
+	if reason != "" || !slices.Equal(got, []string{"甲", "b"}) {
+		t.Fatalf("parsed = %v, reason = %s", got, reason)
+	}
+	if _, reason = parseBatchTranslations(`["甲"]`, 2); reason == "" {
+		t.Fatal("expected a count mismatch")
+	}
+}
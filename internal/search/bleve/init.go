@@ -11,6 +11,13 @@ var config = searcher.Config{
 	Name: "bleve",
 }
 
+// indexVersion is stored in the index when it is created, indexes without it
+// lack the ext and parent_paths fields and are searched the old way until
+// they are rebuilt.
+const indexVersion = "2"
+
+var indexVersionKey = []byte("openlist_index_version")
+
 func Init(indexPath *string) (bleve.Index, error) {
 	log.Debugf("bleve path: %s", *indexPath)
 	fileIndex, err := bleve.Open(*indexPath)
@@ -26,22 +33,43 @@ func Init(indexPath *string) (bleve.Index, error) {
 		nameFieldMapping := bleve.NewKeywordFieldMapping()
 		searchNodeMapping.AddFieldMappingsAt("name", nameFieldMapping)
 		indexMapping.AddDocumentMapping("SearchNode", searchNodeMapping)
+		// nodes are indexed with the default mapping, filter fields are
+		// matched exactly
+		indexMapping.DefaultMapping.AddFieldMappingsAt("ext", bleve.NewKeywordFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("parent_paths", bleve.NewKeywordFieldMapping())
 		fileIndex, err = bleve.New(*indexPath, indexMapping)
 		if err != nil {
 			return nil, err
 		}
+		if err = fileIndex.SetInternal(indexVersionKey, []byte(indexVersion)); err != nil {
+			_ = fileIndex.Close()
+			return nil, err
+		}
 	} else if err != nil {
 		return nil, err
 	}
 	return fileIndex, nil
 }
 
+// isLegacy reports whether index was created before indexVersion.
+func isLegacy(index bleve.Index) bool {
+	version, err := index.GetInternal(indexVersionKey)
+	if err != nil {
+		log.Errorf("failed to read bleve index version: %+v", err)
+	}
+	return string(version) != indexVersion
+}
+
 func init() {
 	searcher.RegisterSearcher(config, func() (searcher.Searcher, error) {
 		b, err := Init(&conf.Conf.BleveDir)
 		if err != nil {
 			return nil, err
 		}
-		return &Bleve{BIndex: b}, nil
+		legacy := isLegacy(b)
+		if legacy {
+			log.Warnf("the bleve index was built by an older version, rebuild it to filter by parent and extension efficiently")
+		}
+		return &Bleve{BIndex: b, legacy: legacy}, nil
 	})
 }
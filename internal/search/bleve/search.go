@@ -3,6 +3,7 @@ package bleve
 import (
 	"context"
 	"os"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
@@ -19,6 +20,24 @@ import (
 
 type Bleve struct {
 	BIndex bleve.Index
+	// legacy is set for indexes without ext and parent_paths
+	legacy bool
+}
+
+// document is what gets indexed for a node, ext and parent_paths are only
+// used for filtering. parent_paths holds the parent and all its ancestors.
+type document struct {
+	model.SearchNode
+	Ext         string   `json:"ext"`
+	ParentPaths []string `json:"parent_paths"`
+}
+
+func newDocument(node model.SearchNode) document {
+	return document{
+		SearchNode:  node,
+		Ext:         searcher.NodeExt(node),
+		ParentPaths: utils.GetPathHierarchy(node.Parent),
+	}
 }
 
 func (b *Bleve) Config() searcher.Config {
@@ -27,17 +46,30 @@ func (b *Bleve) Config() searcher.Config {
 
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	var queries []query2.Query
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	queries = append(queries, query)
+	if req.Keywords != "" {
+		query := bleve.NewMatchQuery(req.Keywords)
+		query.SetField("name")
+		queries = append(queries, query)
+	} else {
+		queries = append(queries, bleve.NewMatchAllQuery())
+	}
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
 		isDirQuery := bleve.NewBoolFieldQuery(isDir)
 		queries = append(queries, isDirQuery)
 	}
+	// a legacy index can't filter by parent, the handler keeps the nodes of
+	// the base path as before
+	if req.Parent != "" && req.Parent != "/" && !b.legacy {
+		parentQuery := bleve.NewTermQuery(req.Parent)
+		parentQuery.SetField("parent_paths")
+		queries = append(queries, parentQuery)
+	}
+	queries = append(queries, mediaQueries(req.SearchMediaFilter)...)
+	queries = append(queries, filterQueries(req.SearchFilter, b.legacy)...)
 	reqQuery := bleve.NewConjunctionQuery(queries...)
 	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	search.SortBy(sortFields(req))
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
 	search.Fields = []string{"*"}
@@ -47,24 +79,168 @@ func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.Search
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
-			Parent: src.Fields["parent"].(string),
-			Name:   src.Fields["name"].(string),
-			IsDir:  src.Fields["is_dir"].(bool),
-			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		return nodeFromFields(src.Fields), nil
 	})
 	return res, int64(searchResults.Total), nil
 }
 
+// mediaQueries builds the queries of a media filter. Media fields use the
+// default analyzer, so codes, actors and tags are matched as phrases.
+func mediaQueries(filter model.SearchMediaFilter) []query2.Query {
+	var queries []query2.Query
+	phrase := func(field, value string) {
+		if value == "" {
+			return
+		}
+		query := bleve.NewMatchPhraseQuery(value)
+		query.SetField(field)
+		queries = append(queries, query)
+	}
+	phrase("media.code", filter.Code)
+	phrase("media.actors", filter.Actor)
+	phrase("media.tags", filter.Tag)
+	if filter.Title != "" {
+		raw := bleve.NewMatchQuery(filter.Title)
+		raw.SetField("media.raw_title")
+		raw.SetOperator(query2.MatchQueryOperatorAnd)
+		translated := bleve.NewMatchQuery(filter.Title)
+		translated.SetField("media.translated_title")
+		translated.SetOperator(query2.MatchQueryOperatorAnd)
+		queries = append(queries, bleve.NewDisjunctionQuery(raw, translated))
+	}
+	if filter.YearFrom > 0 || filter.YearTo > 0 {
+		minYear := float64(max(filter.YearFrom, 1))
+		var maxYear *float64
+		if filter.YearTo > 0 {
+			year := float64(filter.YearTo)
+			maxYear = &year
+		}
+		inclusive := true
+		query := bleve.NewNumericRangeInclusiveQuery(&minYear, maxYear, &inclusive, &inclusive)
+		query.SetField("media.release_year")
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+func filterQueries(filter model.SearchFilter, legacy bool) []query2.Query {
+	var queries []query2.Query
+	inclusive := true
+	if filter.MinSize > 0 || filter.MaxSize > 0 {
+		var minSize, maxSize *float64
+		if filter.MinSize > 0 {
+			size := float64(filter.MinSize)
+			minSize = &size
+		}
+		if filter.MaxSize > 0 {
+			size := float64(filter.MaxSize)
+			maxSize = &size
+		}
+		query := bleve.NewNumericRangeInclusiveQuery(minSize, maxSize, &inclusive, &inclusive)
+		query.SetField("size")
+		queries = append(queries, query)
+	}
+	if filter.ModifiedFrom != nil || filter.ModifiedTo != nil {
+		var from, to time.Time
+		if filter.ModifiedFrom != nil {
+			from = *filter.ModifiedFrom
+		}
+		if filter.ModifiedTo != nil {
+			to = *filter.ModifiedTo
+		}
+		query := bleve.NewDateRangeInclusiveQuery(from, to, &inclusive, &inclusive)
+		query.SetField("modified")
+		queries = append(queries, query)
+	}
+	if len(filter.Exts) > 0 {
+		exts := make([]query2.Query, 0, len(filter.Exts))
+		for _, ext := range filter.Exts {
+			if legacy {
+				query := bleve.NewWildcardQuery("*." + ext)
+				query.SetField("name")
+				exts = append(exts, query)
+				continue
+			}
+			query := bleve.NewTermQuery(ext)
+			query.SetField("ext")
+			exts = append(exts, query)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(exts...))
+	}
+	return queries
+}
+
+func sortFields(req model.SearchReq) []string {
+	prefix := ""
+	if req.Order == "desc" {
+		prefix = "-"
+	}
+	switch req.Sort {
+	case model.SearchSortSize, model.SearchSortModified:
+		return []string{prefix + req.Sort, "name"}
+	}
+	return []string{prefix + "name"}
+}
+
+func nodeFromFields(fields map[string]interface{}) model.SearchNode {
+	node := model.SearchNode{}
+	node.Parent, _ = fields["parent"].(string)
+	node.Name, _ = fields["name"].(string)
+	node.IsDir, _ = fields["is_dir"].(bool)
+	if size, ok := fields["size"].(float64); ok {
+		node.Size = int64(size)
+	}
+	if modified, ok := fields["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	workID, ok := fields["media.work_id"].(float64)
+	if !ok || workID == 0 {
+		return node
+	}
+	media := &model.SearchMedia{WorkID: uint(workID)}
+	media.Code, _ = fields["media.code"].(string)
+	media.RawTitle, _ = fields["media.raw_title"].(string)
+	media.TranslatedTitle, _ = fields["media.translated_title"].(string)
+	media.Actors = stringsField(fields["media.actors"])
+	media.Tags = stringsField(fields["media.tags"])
+	if release, ok := fields["media.release_date"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, release); err == nil {
+			media.ReleaseDate = &t
+		}
+	}
+	if year, ok := fields["media.release_year"].(float64); ok {
+		media.ReleaseYear = int(year)
+	}
+	node.Media = media
+	return node
+}
+
+// stringsField reads an array field, bleve returns arrays of one element as
+// a single value.
+func stringsField(value interface{}) model.StringArray {
+	switch v := value.(type) {
+	case string:
+		return model.StringArray{v}
+	case []interface{}:
+		result := make(model.StringArray, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
-	return b.BIndex.Index(uuid.NewString(), node)
+	return b.BIndex.Index(uuid.NewString(), newDocument(node))
 }
 
 func (b *Bleve) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	batch := b.BIndex.NewBatch()
 	for _, node := range nodes {
-		batch.Index(uuid.NewString(), node)
+		batch.Index(uuid.NewString(), newDocument(node))
 	}
 	return b.BIndex.Batch(batch)
 }
@@ -77,6 +253,36 @@ func (b *Bleve) Del(ctx context.Context, prefix string) error {
 	return errs.NotSupport
 }
 
+func (b *Bleve) UpdateMedia(ctx context.Context, media *model.SearchMedia) error {
+	workID := float64(media.WorkID)
+	inclusive := true
+	query := bleve.NewNumericRangeInclusiveQuery(&workID, &workID, &inclusive, &inclusive)
+	query.SetField("media.work_id")
+	const pageSize = 1000
+	for from := 0; ; from += pageSize {
+		search := bleve.NewSearchRequestOptions(query, pageSize, from, false)
+		search.Fields = []string{"*"}
+		results, err := b.BIndex.SearchInContext(ctx, search)
+		if err != nil {
+			return err
+		}
+		batch := b.BIndex.NewBatch()
+		for _, hit := range results.Hits {
+			node := nodeFromFields(hit.Fields)
+			node.Media = media
+			if err = batch.Index(hit.ID, newDocument(node)); err != nil {
+				return err
+			}
+		}
+		if err = b.BIndex.Batch(batch); err != nil {
+			return err
+		}
+		if len(results.Hits) < pageSize {
+			return nil
+		}
+	}
+}
+
 func (b *Bleve) Release(ctx context.Context) error {
 	if b.BIndex != nil {
 		return b.BIndex.Close()
@@ -99,6 +305,7 @@ func (b *Bleve) Clear(ctx context.Context) error {
 		return err
 	}
 	b.BIndex = bIndex
+	b.legacy = false
 	return nil
 }
 
@@ -0,0 +1,60 @@
+package bleve
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher/searchertest"
+)
+
+func TestSearch(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "bleve")
+	index, err := Init(&indexPath)
+	if err != nil {
+		t.Fatalf("init bleve: %v", err)
+	}
+	b := &Bleve{BIndex: index}
+	t.Cleanup(func() { _ = index.Close() })
+	searchertest.Run(t, b)
+}
+
+func TestSearchLegacyIndex(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "bleve")
+	index, err := Init(&indexPath)
+	if err != nil {
+		t.Fatalf("init bleve: %v", err)
+	}
+	t.Cleanup(func() { _ = index.Close() })
+	if isLegacy(index) {
+		t.Fatal("a new index is legacy")
+	}
+	if err = index.DeleteInternal(indexVersionKey); err != nil {
+		t.Fatalf("delete index version: %v", err)
+	}
+	if !isLegacy(index) {
+		t.Fatal("an index without version is not legacy")
+	}
+	b := &Bleve{BIndex: index, legacy: true}
+	err = b.BatchIndex(context.Background(), []model.SearchNode{
+		{Parent: "/movies", Name: "a.mkv"},
+		{Parent: "/movies", Name: "c.mp4"},
+		{Parent: "/music", Name: "e.mkv"},
+	})
+	if err != nil {
+		t.Fatalf("batch index: %v", err)
+	}
+	nodes, total, err := b.Search(context.Background(), model.SearchReq{
+		Parent:       "/movies",
+		SearchFilter: model.SearchFilter{Exts: []string{"mkv"}},
+		PageReq:      model.PageReq{Page: 1, PerPage: 10},
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	// the parent is not filtered by a legacy index
+	if total != 2 || nodes[0].Name != "a.mkv" || nodes[1].Name != "e.mkv" {
+		t.Fatalf("search = %+v, %d", nodes, total)
+	}
+}
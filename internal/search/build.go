@@ -15,10 +15,8 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
-	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/pkg/mq"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
-	mapset "github.com/deckarep/golang-set/v2"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -199,79 +197,38 @@ func Config(ctx context.Context) searcher.Config {
 	return instance.Config()
 }
 
-func Update(ctx context.Context, parent string, objs []model.Obj) {
-	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) || Running() {
+// Update queues the change of a listed directory. Drivers that attach the
+// delta of the listing only queue the changed entries, other listings queue
+// a sync of parent.
+func Update(ctx context.Context, parent string, _ []model.Obj) {
+	if ctx.Value(noJobsKey{}) != nil || !acceptJobs(parent) {
 		return
 	}
-	if isIgnorePath(parent) {
-		return
-	}
-	// only update when index have built
-	progress, err := Progress()
-	if err != nil {
-		log.Errorf("update search index error while get progress: %+v", err)
-		return
-	}
-	if !progress.IsDone {
-		return
-	}
-
-	// Use task queue for Meilisearch to avoid race conditions with async indexing
-	if msInstance, ok := instance.(interface {
-		EnqueueUpdate(parent string, objs []model.Obj)
-	}); ok {
-		// Enqueue task for async processing (diff calculation happens at consumption time)
-		msInstance.EnqueueUpdate(parent, objs)
+	if change, ok := op.ObjsChangeFromContext(ctx); ok {
+		for _, obj := range change.Removed {
+			enqueueJob(&model.IndexJob{Op: model.IndexJobDelete, Path: path.Join(parent, obj.GetName())})
+		}
+		changed := append(append([]model.Obj{}, change.Added...), change.Modified...)
+		if len(changed) > 0 {
+			enqueueJob(&model.IndexJob{Op: model.IndexJobUpsert, Path: parent, Nodes: newNodes(parent, changed)})
+		}
 		return
 	}
+	enqueueJob(&model.IndexJob{Op: model.IndexJobSync, Path: parent})
+}
 
-	nodes, err := instance.Get(ctx, parent)
-	if err != nil {
-		log.Errorf("update search index error while get nodes: %+v", err)
-		return
+// updateWritten queues the paths changed by a write operation, the created
+// path is walked again since its subtree was never listed.
+func updateWritten(ctx context.Context, from, to string) {
+	if from != "" && acceptJobs(from) {
+		enqueueJob(&model.IndexJob{Op: model.IndexJobDelete, Path: from})
 	}
-	now := mapset.NewSet[string]()
-	for i := range objs {
-		now.Add(objs[i].GetName())
-	}
-	old := mapset.NewSet[string]()
-	for i := range nodes {
-		old.Add(nodes[i].Name)
-	}
-	// delete data that no longer exists
-	toDelete := old.Difference(now)
-	toAdd := now.Difference(old)
-	for i := range nodes {
-		if toDelete.Contains(nodes[i].Name) && !op.HasStorage(path.Join(parent, nodes[i].Name)) {
-			log.Debugf("delete index: %s", path.Join(parent, nodes[i].Name))
-			err = instance.Del(ctx, path.Join(parent, nodes[i].Name))
-			if err != nil {
-				log.Errorf("update search index error while del old node: %+v", err)
-				return
-			}
-		}
-	}
-	// collect files and folders to add in batch
-	var toAddObjs []ObjWithParent
-	for i := range objs {
-		if toAdd.Contains(objs[i].GetName()) {
-			log.Debugf("add index: %s", path.Join(parent, objs[i].GetName()))
-			toAddObjs = append(toAddObjs, ObjWithParent{
-				Parent: parent,
-				Obj:    objs[i],
-			})
-		}
-	}
-	// batch index all files and folders at once
-	if len(toAddObjs) > 0 {
-		err = BatchIndex(ctx, toAddObjs)
-		if err != nil {
-			log.Errorf("update search index error while batch index new nodes: %+v", err)
-			return
-		}
+	if to != "" && acceptJobs(to) {
+		enqueueJob(&model.IndexJob{Op: model.IndexJobWalk, Path: to})
 	}
 }
 
 func init() {
 	op.RegisterObjsUpdateHook(Update)
+	op.RegisterObjWriteHook(updateWritten)
 }
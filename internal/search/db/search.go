@@ -34,6 +34,10 @@ func (D DB) Del(ctx context.Context, path string) error {
 	return db.DeleteSearchNodesByParent(path)
 }
 
+func (D DB) UpdateMedia(ctx context.Context, media *model.SearchMedia) error {
+	return db.UpdateSearchNodesMedia(media)
+}
+
 func (D DB) Release(ctx context.Context) error {
 	return nil
 }
@@ -25,6 +25,11 @@ type searchDocument struct {
 	// Can be used for filtering all descendants exactly.
 	// Storing path hashes instead of plaintext paths benefits disk usage and case-sensitive filter.
 	ParentPathHashes []string `json:"parent_path_hashes"`
+	// Ext of files for filtering, empty for directories.
+	Ext string `json:"ext"`
+	// Modification time in unix seconds, time strings can not be filtered
+	// or sorted by range.
+	ModifiedUnix int64 `json:"modified_unix"`
 	model.SearchNode
 }
 
@@ -33,6 +38,7 @@ type Meilisearch struct {
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 	taskQueue            *TaskQueueManager
 }
 
@@ -55,22 +61,37 @@ func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.
 		parentHash := hashPath(req.Parent)
 		filters = append(filters, fmt.Sprintf("parent_path_hashes = '%s'", parentHash))
 	}
+	filters = append(filters, mediaFilters(req.SearchMediaFilter)...)
+	filters = append(filters, attributeFilters(req.SearchFilter)...)
 	if len(filters) > 0 {
 		mReq.Filter = strings.Join(filters, " AND ")
 	}
+	if req.Sort != "" {
+		order := "asc"
+		if req.Order == "desc" {
+			order = "desc"
+		}
+		field := req.Sort
+		if field == model.SearchSortModified {
+			field = "modified_unix"
+		}
+		mReq.Sort = []string{field + ":" + order}
+	}
+	keywords := req.Keywords
+	if req.Title != "" {
+		// titles can not be filtered by substring, search them instead
+		keywords = strings.TrimSpace(keywords + " " + req.Title)
+		if req.Keywords == "" {
+			mReq.AttributesToSearchOn = []string{"media.raw_title", "media.translated_title"}
+		}
+	}
 
-	search, err := m.Client.Index(m.IndexUid).SearchWithContext(ctx, req.Keywords, mReq)
+	search, err := m.Client.Index(m.IndexUid).SearchWithContext(ctx, keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
-		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		return buildSearchDocumentFromResults(src.(map[string]any)).SearchNode, nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -78,36 +99,69 @@ func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.
 	return nodes, search.TotalHits, nil
 }
 
+// mediaFilters builds the filter expressions of a media filter, string
+// filters of meilisearch are case-insensitive and match any array element.
+func mediaFilters(filter model.SearchMediaFilter) []string {
+	var filters []string
+	if filter.IsZero() {
+		return filters
+	}
+	filters = append(filters, "media.work_id > 0")
+	if filter.Code != "" {
+		filters = append(filters, fmt.Sprintf("media.code = %s", quoteFilter(filter.Code)))
+	}
+	if filter.Actor != "" {
+		filters = append(filters, fmt.Sprintf("media.actors = %s", quoteFilter(filter.Actor)))
+	}
+	if filter.Tag != "" {
+		filters = append(filters, fmt.Sprintf("media.tags = %s", quoteFilter(filter.Tag)))
+	}
+	if filter.YearFrom > 0 {
+		filters = append(filters, fmt.Sprintf("media.release_year >= %d", filter.YearFrom))
+	}
+	if filter.YearTo > 0 {
+		filters = append(filters, fmt.Sprintf("media.release_year > 0 AND media.release_year <= %d", filter.YearTo))
+	}
+	return filters
+}
+
+func attributeFilters(filter model.SearchFilter) []string {
+	var filters []string
+	if filter.MinSize > 0 {
+		filters = append(filters, fmt.Sprintf("size >= %d", filter.MinSize))
+	}
+	if filter.MaxSize > 0 {
+		filters = append(filters, fmt.Sprintf("size <= %d", filter.MaxSize))
+	}
+	if filter.ModifiedFrom != nil {
+		filters = append(filters, fmt.Sprintf("modified_unix >= %d", filter.ModifiedFrom.Unix()))
+	}
+	if filter.ModifiedTo != nil {
+		filters = append(filters, fmt.Sprintf("modified_unix <= %d", filter.ModifiedTo.Unix()))
+	}
+	if len(filter.Exts) > 0 {
+		exts := make([]string, len(filter.Exts))
+		for i, ext := range filter.Exts {
+			exts[i] = quoteFilter(ext)
+		}
+		filters = append(filters, fmt.Sprintf("ext IN [%s]", strings.Join(exts, ", ")))
+	}
+	return filters
+}
+
+func quoteFilter(value string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", `\'`) + "'"
+}
+
 func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 	return m.BatchIndex(ctx, []model.SearchNode{node})
 }
 
 func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
-	documents, err := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
-		parentHash := hashPath(src.Parent)
-		nodePath := path.Join(src.Parent, src.Name)
-		nodePathHash := hashPath(nodePath)
-		parentPaths := utils.GetPathHierarchy(src.Parent)
-		parentPathHashes, err := utils.SliceConvert(parentPaths, func(parentPath string) (string, error) {
-			return hashPath(parentPath), nil
-		})
-		if err != nil {
-			return nil, err
-		}
-
-		return &searchDocument{
-			ID:               nodePathHash,
-			ParentHash:       parentHash,
-			ParentPathHashes: parentPathHashes,
-			SearchNode:       src,
-		}, nil
-	})
-	if err != nil {
-		return err
-	}
+	documents := utils.MustSliceConvert(nodes, newSearchDocument)
 
 	// max up to 10,000 documents per batch to reduce error rate while uploading over the Internet
-	_, err = m.Client.Index(m.IndexUid).AddDocumentsInBatchesWithContext(ctx, documents, 10000)
+	_, err := m.Client.Index(m.IndexUid).AddDocumentsInBatchesWithContext(ctx, documents, 10000)
 	if err != nil {
 		return err
 	}
@@ -209,6 +263,29 @@ func (m *Meilisearch) Del(ctx context.Context, prefix string) error {
 	return err
 }
 
+func (m *Meilisearch) UpdateMedia(ctx context.Context, media *model.SearchMedia) error {
+	var result meilisearch.DocumentsResult
+	err := m.Client.Index(m.IndexUid).GetDocumentsWithContext(ctx, &meilisearch.DocumentsQuery{
+		Limit:  int64(model.MaxInt),
+		Filter: fmt.Sprintf("media.work_id = %d", media.WorkID),
+	}, &result)
+	if err != nil {
+		return err
+	}
+	if len(result.Results) == 0 {
+		return nil
+	}
+	documents := make([]*searchDocument, 0, len(result.Results))
+	for _, src := range result.Results {
+		document := buildSearchDocumentFromResults(src)
+		document.Media = media
+		documents = append(documents, document)
+	}
+	_, err = m.Client.Index(m.IndexUid).AddDocumentsWithContext(ctx, documents)
+	// task was enqueued (if succeed), no need to wait
+	return err
+}
+
 func (m *Meilisearch) Release(ctx context.Context) error {
 	if m.taskQueue != nil {
 		m.taskQueue.Stop()
@@ -231,12 +308,12 @@ func (m *Meilisearch) getTaskStatus(ctx context.Context, taskUID int64) (meilise
 }
 
 // EnqueueUpdate enqueues an update task to the task queue
-func (m *Meilisearch) EnqueueUpdate(parent string, objs []model.Obj) {
+func (m *Meilisearch) EnqueueUpdate(parent string, nodes []model.SearchNode) {
 	if m.taskQueue == nil {
 		return
 	}
 
-	m.taskQueue.Enqueue(parent, objs)
+	m.taskQueue.Enqueue(parent, nodes)
 }
 
 // batchIndexWithTaskUID indexes documents and returns all taskUIDs
@@ -245,28 +322,7 @@ func (m *Meilisearch) batchIndexWithTaskUID(ctx context.Context, nodes []model.S
 		return nil, nil
 	}
 
-	documents, err := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
-		parentHash := hashPath(src.Parent)
-		nodePath := path.Join(src.Parent, src.Name)
-		nodePathHash := hashPath(nodePath)
-		parentPaths := utils.GetPathHierarchy(src.Parent)
-		parentPathHashes, err := utils.SliceConvert(parentPaths, func(parentPath string) (string, error) {
-			return hashPath(parentPath), nil
-		})
-		if err != nil {
-			return nil, err
-		}
-
-		return &searchDocument{
-			ID:               nodePathHash,
-			ParentHash:       parentHash,
-			ParentPathHashes: parentPathHashes,
-			SearchNode:       src,
-		}, nil
-	})
-	if err != nil {
-		return nil, err
-	}
+	documents := utils.MustSliceConvert(nodes, newSearchDocument)
 
 	// max up to 10,000 documents per batch to reduce error rate while uploading over the Internet
 	tasks, err := m.Client.Index(m.IndexUid).AddDocumentsInBatchesWithContext(ctx, documents, 10000)
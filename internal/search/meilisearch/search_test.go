@@ -0,0 +1,54 @@
+package meilisearch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher/searchertest"
+)
+
+// waitIndexed waits for the indexing tasks, meilisearch indexes
+// asynchronously.
+type waitIndexed struct {
+	*Meilisearch
+}
+
+func (m waitIndexed) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
+	taskUIDs, err := m.batchIndexWithTaskUID(ctx, nodes)
+	if err != nil {
+		return err
+	}
+	for _, taskUID := range taskUIDs {
+		if _, err = m.Client.WaitForTaskWithContext(ctx, taskUID, 50*time.Millisecond); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSearch(t *testing.T) {
+	host := os.Getenv("MEILISEARCH_TEST_HOST")
+	if host == "" {
+		t.Skip("MEILISEARCH_TEST_HOST is not set")
+	}
+	conf.Conf = conf.DefaultConfig(t.TempDir())
+	conf.Conf.Meilisearch.Host = host
+	conf.Conf.Meilisearch.APIKey = os.Getenv("MEILISEARCH_TEST_API_KEY")
+	conf.Conf.Meilisearch.Index = fmt.Sprintf("openlist-test-%d", time.Now().UnixNano())
+	s, err := searcher.NewMap[config.Name]()
+	if err != nil {
+		t.Fatalf("init meilisearch: %v", err)
+	}
+	m := s.(*Meilisearch)
+	t.Cleanup(func() {
+		_ = m.Release(context.Background())
+		_, _ = m.Client.DeleteIndex(m.IndexUid)
+	})
+	searchertest.Run(t, waitIndexed{m})
+}
@@ -2,7 +2,6 @@ package meilisearch
 
 import (
 	"context"
-	"path"
 	"sort"
 	"strings"
 	"sync"
@@ -11,16 +10,16 @@ import (
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
-	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
 	log "github.com/sirupsen/logrus"
 )
 
 // QueuedTask represents a task in the queue
 type QueuedTask struct {
 	Parent    string
-	Objs      []model.Obj // current file system state
-	Depth     int         // path depth for sorting
-	EnqueueAt time.Time   // enqueue time
+	Nodes     []model.SearchNode // current file system state
+	Depth     int                // path depth for sorting
+	EnqueueAt time.Time          // enqueue time
 }
 
 // TaskQueueManager manages the task queue for async index operations
@@ -53,18 +52,18 @@ func calculateDepth(path string) int {
 }
 
 // Enqueue enqueues a task with current file system state
-func (tqm *TaskQueueManager) Enqueue(parent string, objs []model.Obj) {
+func (tqm *TaskQueueManager) Enqueue(parent string, nodes []model.SearchNode) {
 	tqm.mu.Lock()
 	defer tqm.mu.Unlock()
 
 	// deduplicate: overwrite existing task with the same parent
 	tqm.queue[parent] = &QueuedTask{
 		Parent:    parent,
-		Objs:      objs,
+		Nodes:     nodes,
 		Depth:     calculateDepth(parent),
 		EnqueueAt: time.Now(),
 	}
-	log.Debugf("enqueued update task for parent: %s, depth: %d, objs: %d", parent, calculateDepth(parent), len(objs))
+	log.Debugf("enqueued update task for parent: %s, depth: %d, nodes: %d", parent, calculateDepth(parent), len(nodes))
 }
 
 // Start starts the task queue consumer
@@ -186,7 +185,6 @@ func (tqm *TaskQueueManager) consume() {
 // executeTask executes a single task
 func (tqm *TaskQueueManager) executeTask(ctx context.Context, task *QueuedTask) {
 	parent := task.Parent
-	currentObjs := task.Objs
 
 	// Query index to get old state
 	nodes, err := tqm.m.Get(ctx, parent)
@@ -196,23 +194,11 @@ func (tqm *TaskQueueManager) executeTask(ctx context.Context, task *QueuedTask)
 	}
 
 	// Calculate diff based on current index state
-	now := mapset.NewSet[string]()
-	for i := range currentObjs {
-		now.Add(currentObjs[i].GetName())
-	}
-	old := mapset.NewSet[string]()
-	for i := range nodes {
-		old.Add(nodes[i].Name)
-	}
-
-	toDelete := old.Difference(now)
-	toAdd := now.Difference(old)
-
-	// Collect paths to delete
 	var pathsToDelete []string
-	for i := range nodes {
-		if toDelete.Contains(nodes[i].Name) && !op.HasStorage(path.Join(parent, nodes[i].Name)) {
-			pathsToDelete = append(pathsToDelete, path.Join(parent, nodes[i].Name))
+	toDelete, nodesToAdd := searcher.DiffNodes(parent, nodes, task.Nodes, true)
+	for _, p := range toDelete {
+		if !op.HasStorage(p) {
+			pathsToDelete = append(pathsToDelete, p)
 		}
 	}
 
@@ -230,20 +216,6 @@ func (tqm *TaskQueueManager) executeTask(ctx context.Context, task *QueuedTask)
 		}
 	}
 
-	// Collect objects to add
-	var nodesToAdd []model.SearchNode
-	for i := range currentObjs {
-		if toAdd.Contains(currentObjs[i].GetName()) {
-			log.Debugf("will add index: %s", path.Join(parent, currentObjs[i].GetName()))
-			nodesToAdd = append(nodesToAdd, model.SearchNode{
-				Parent: parent,
-				Name:   currentObjs[i].GetName(),
-				IsDir:  currentObjs[i].IsDir(),
-				Size:   currentObjs[i].GetSize(),
-			})
-		}
-	}
-
 	// Execute add
 	if len(nodesToAdd) > 0 {
 		log.Debugf("executing add for parent %s: %d nodes", parent, len(nodesToAdd))
@@ -1,7 +1,12 @@
 package meilisearch
 
 import (
+	"path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
 )
 
 // hashPath hashes a path with SHA-1.
@@ -11,20 +16,34 @@ func hashPath(path string) string {
 	return utils.HashData(utils.SHA1, []byte(path))
 }
 
+func newSearchDocument(node model.SearchNode) *searchDocument {
+	parentPaths := utils.GetPathHierarchy(node.Parent)
+	parentPathHashes := make([]string, len(parentPaths))
+	for i, parentPath := range parentPaths {
+		parentPathHashes[i] = hashPath(parentPath)
+	}
+	document := &searchDocument{
+		ID:               hashPath(path.Join(node.Parent, node.Name)),
+		ParentHash:       hashPath(node.Parent),
+		ParentPathHashes: parentPathHashes,
+		Ext:              searcher.NodeExt(node),
+		SearchNode:       node,
+	}
+	if !node.Modified.IsZero() {
+		document.ModifiedUnix = node.Modified.Unix()
+	}
+	return document
+}
+
 func buildSearchDocumentFromResults(results map[string]any) *searchDocument {
 	document := &searchDocument{}
-
-	// use assertion test to avoid panic
-	document.SearchNode.Parent, _ = results["parent"].(string)
-	document.SearchNode.Name, _ = results["name"].(string)
-	document.SearchNode.IsDir, _ = results["is_dir"].(bool)
-	// JSON numbers are typically float64, not int64
-	if size, ok := results["size"].(float64); ok {
-		document.SearchNode.Size = int64(size)
+	// round trip through JSON, numbers come as float64 and arrays as []any
+	data, err := utils.Json.Marshal(results)
+	if err == nil {
+		err = utils.Json.Unmarshal(data, document)
+	}
+	if err != nil {
+		log.Warnf("failed to decode meilisearch document: %+v", err)
 	}
-
-	document.ID, _ = results["id"].(string)
-	document.ParentHash, _ = results["parent_hash"].(string)
-	document.ParentPathHashes, _ = results["parent_path_hashes"].([]string)
 	return document
 }
@@ -0,0 +1,254 @@
+package search
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	jobBatchSize   = 100
+	jobMaxAttempts = 5
+	jobInterval    = 5 * time.Second
+	walkBatchSize  = 1000
+)
+
+var (
+	jobSignal    = make(chan struct{}, 1)
+	queueStarted atomic.Bool
+)
+
+// acceptJobs reports whether changes below p are queued, only searchers which
+// keep themselves up to date get index jobs.
+func acceptJobs(p string) bool {
+	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) {
+		return false
+	}
+	// only queue when the index is built or being built, the jobs queued during
+	// a build are applied after it
+	if !Running() {
+		progress, err := Progress()
+		if err != nil {
+			log.Errorf("failed to get index progress: %+v", err)
+			return false
+		}
+		if !progress.IsDone {
+			return false
+		}
+	}
+	return !isIgnorePath(p)
+}
+
+type noJobsKey struct{}
+
+// withoutJobs marks ctx so the listings made with it queue no jobs.
+func withoutJobs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noJobsKey{}, true)
+}
+
+// QueueSync queues the listing of the directory p. Only the path is stored,
+// the directory is listed again when the job is applied, so listing a
+// directory many times before that costs a single job.
+func QueueSync(p string) {
+	if acceptJobs(p) {
+		enqueueJob(&model.IndexJob{Op: model.IndexJobSync, Path: p})
+	}
+}
+
+func enqueueJob(job *model.IndexJob) {
+	if err := db.CreateIndexJob(job); err != nil {
+		log.Errorf("failed to queue index job %s [%s]: %+v", job.Op, job.Path, err)
+		return
+	}
+	select {
+	case jobSignal <- struct{}{}:
+	default:
+	}
+}
+
+func newNodes(parent string, objs []model.Obj) []model.SearchNode {
+	nodes := make([]model.SearchNode, len(objs))
+	for i := range objs {
+		nodes[i] = searcher.NewNode(parent, objs[i])
+	}
+	searcher.AttachMedia(nodes, objs)
+	return nodes
+}
+
+// QueueDepth returns the number of index jobs waiting to be applied.
+func QueueDepth() (int64, error) {
+	return db.CountIndexJobs()
+}
+
+// StartQueue applies the queued index jobs in the background, jobs left from
+// the last run are applied first. Jobs wait while the index is being built.
+func StartQueue() {
+	if !queueStarted.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(jobInterval)
+		defer ticker.Stop()
+		for {
+			for applyJobs(context.Background()) {
+			}
+			select {
+			case <-jobSignal:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// applyJobs applies a batch of queued jobs in order. It returns false when the
+// queue is drained or a job failed, the failed job is retried in the next
+// round and dropped after jobMaxAttempts.
+func applyJobs(ctx context.Context) bool {
+	if instance == nil || Running() {
+		return false
+	}
+	jobs, err := db.GetIndexJobs(jobBatchSize)
+	if err != nil {
+		log.Errorf("failed to load index jobs: %+v", err)
+		return false
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if err = applyJob(ctx, job); err != nil {
+			job.Attempts++
+			if job.Attempts < jobMaxAttempts {
+				log.Warnf("index job %s [%s] failed, attempt %d: %+v", job.Op, job.Path, job.Attempts, err)
+				if err = db.FailIndexJob(job.ID, job.Attempts, err.Error()); err != nil {
+					log.Errorf("failed to update index job: %+v", err)
+				}
+				return false
+			}
+			log.Errorf("drop index job %s [%s] after %d attempts: %+v", job.Op, job.Path, job.Attempts, err)
+		}
+		if err = db.DeleteIndexJob(job.ID); err != nil {
+			log.Errorf("failed to delete index job: %+v", err)
+			return false
+		}
+	}
+	return len(jobs) == jobBatchSize
+}
+
+func applyJob(ctx context.Context, job *model.IndexJob) error {
+	switch job.Op {
+	case model.IndexJobDelete:
+		if op.HasStorage(job.Path) {
+			return nil
+		}
+		return instance.Del(ctx, job.Path)
+	case model.IndexJobSync, model.IndexJobUpsert:
+		complete := job.Op == model.IndexJobSync
+		nodes := job.Nodes
+		if complete {
+			var err error
+			if nodes, err = listNodes(ctx, job.Path); err != nil {
+				// removed again before the job ran, its delete job is queued
+				if errs.IsObjectNotFound(err) {
+					return nil
+				}
+				return err
+			}
+		}
+		// Meilisearch indexes asynchronously, its own queue waits for the
+		// pending tasks of a directory before diffing it again
+		if q, ok := instance.(interface {
+			EnqueueUpdate(parent string, nodes []model.SearchNode)
+		}); ok && complete {
+			q.EnqueueUpdate(job.Path, nodes)
+			return nil
+		}
+		indexed, err := instance.Get(ctx, job.Path)
+		if err != nil {
+			return err
+		}
+		toDel, toAdd := searcher.DiffNodes(job.Path, indexed, nodes, complete)
+		for _, p := range toDel {
+			if op.HasStorage(p) {
+				continue
+			}
+			log.Debugf("delete index: %s", p)
+			if err = instance.Del(ctx, p); err != nil {
+				return err
+			}
+		}
+		if len(toAdd) == 0 {
+			return nil
+		}
+		return instance.BatchIndex(ctx, toAdd)
+	case model.IndexJobWalk:
+		return walkIndex(ctx, job.Path)
+	}
+	return errors.Errorf("unknown index job op: %s", job.Op)
+}
+
+// listNodes returns the nodes of the children of the directory p, mostly from
+// the list cache.
+func listNodes(ctx context.Context, p string) ([]model.SearchNode, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(p)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := op.List(withoutJobs(ctx), storage, actualPath, model.ListArgs{})
+	if err != nil {
+		return nil, err
+	}
+	return newNodes(p, objs), nil
+}
+
+// walkIndex indexes p and everything below it again, the listings of the
+// walk queue no jobs as they are indexed right away.
+func walkIndex(ctx context.Context, p string) error {
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return err
+	}
+	ctx = withoutJobs(context.WithValue(ctx, conf.UserKey, admin))
+	obj, err := fs.Get(ctx, p, &fs.GetArgs{})
+	if err != nil {
+		// removed again before the job ran, its delete job is queued
+		if errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err = instance.Del(ctx, p); err != nil {
+		return err
+	}
+	batch := make([]ObjWithParent, 0, walkBatchSize)
+	err = fs.WalkFS(ctx, -1, p, obj, func(reqPath string, info model.Obj) error {
+		if isIgnorePath(reqPath) {
+			return filepath.SkipDir
+		}
+		if storage, _, err := op.GetStorageAndActualPath(reqPath); err == nil && storage.GetStorage().DisableIndex {
+			return filepath.SkipDir
+		}
+		batch = append(batch, ObjWithParent{Parent: path.Dir(reqPath), Obj: info})
+		if len(batch) < walkBatchSize {
+			return nil
+		}
+		err := BatchIndex(ctx, batch)
+		batch = batch[:0]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return BatchIndex(ctx, batch)
+}
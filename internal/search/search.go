@@ -2,13 +2,18 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -48,19 +53,70 @@ func Init(mode string) error {
 }
 
 func Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	if instance == nil {
+		return nil, 0, errs.SearchNotAvailable
+	}
+	requested := len(req.Exts) + len(req.Types)
+	req.Exts, req.Types = searcher.ResolveExts(req.SearchFilter), nil
+	if len(req.Exts) == 0 && requested > 0 {
+		// the requested types have no extensions configured
+		return nil, 0, nil
+	}
 	return instance.Search(ctx, req)
 }
 
+// Facets counts the hits of req per top-level storage, storages mounted
+// inside another storage are counted with it. When req.Parent is inside a
+// storage only req.Parent is counted. A storage is skipped when allow returns
+// false for the path that would be counted.
+func Facets(ctx context.Context, req model.SearchReq, allow func(p string) bool) ([]model.SearchFacet, error) {
+	var mountPaths []string
+	for _, storage := range op.GetAllStorages() {
+		mountPaths = append(mountPaths, storage.GetStorage().MountPath)
+	}
+	facets := make([]model.SearchFacet, 0)
+	for _, mountPath := range topLevelPaths(mountPaths) {
+		countReq := req
+		countReq.Facets = false
+		countReq.PageReq = model.PageReq{Page: 1, PerPage: 1}
+		if utils.IsSubPath(req.Parent, mountPath) {
+			countReq.Parent = mountPath
+		} else if !utils.IsSubPath(mountPath, req.Parent) {
+			continue
+		}
+		if !allow(countReq.Parent) {
+			continue
+		}
+		_, count, err := Search(ctx, countReq)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			facets = append(facets, model.SearchFacet{Storage: mountPath, Count: count})
+		}
+	}
+	return facets, nil
+}
+
+// topLevelPaths drops the paths inside another path of paths.
+func topLevelPaths(paths []string) []string {
+	sort.Strings(paths)
+	var result []string
+	for _, p := range paths {
+		if !slices.ContainsFunc(result, func(top string) bool { return utils.IsSubPath(top, p) }) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
 func Index(ctx context.Context, parent string, obj model.Obj) error {
 	if instance == nil {
 		return errs.SearchNotAvailable
 	}
-	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
-	})
+	nodes := []model.SearchNode{searcher.NewNode(parent, obj)}
+	searcher.AttachMedia(nodes, []model.Obj{obj})
+	return instance.Index(ctx, nodes[0])
 }
 
 type ObjWithParent struct {
@@ -75,19 +131,35 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	if len(objs) == 0 {
 		return nil
 	}
-	var searchNodes []model.SearchNode
+	searchNodes := make([]model.SearchNode, len(objs))
+	rawObjs := make([]model.Obj, len(objs))
 	for i := range objs {
-		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
-		})
+		searchNodes[i] = searcher.NewNode(objs[i].Parent, objs[i].Obj)
+		rawObjs[i] = objs[i].Obj
 	}
+	searcher.AttachMedia(searchNodes, rawObjs)
 	return instance.BatchIndex(ctx, searchNodes)
 }
 
+// updateMedia refreshes the indexed metadata of a work after it changed.
+func updateMedia(workID uint) {
+	if instance == nil {
+		return
+	}
+	work, err := db.GetFilmWork(workID)
+	if err != nil {
+		log.Warnf("failed to load media work %d for search index: %+v", workID, err)
+		return
+	}
+	if err = instance.UpdateMedia(context.Background(), model.NewSearchMedia(work)); err != nil && !errors.Is(err, errs.NotSupport) {
+		log.Errorf("update media %d in search index error: %+v", workID, err)
+	}
+}
+
 func init() {
+	db.RegisterMediaWorkHook(func(workID uint) {
+		go updateMedia(workID)
+	})
 	op.RegisterSettingItemHook(conf.SearchIndex, func(item *model.SettingItem) error {
 		log.Debugf("searcher init, mode: %s", item.Value)
 		return Init(item.Value)
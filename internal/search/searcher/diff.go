@@ -0,0 +1,57 @@
+package searcher
+
+import (
+	"path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+// DiffNodes compares the indexed children of parent with listed ones and
+// returns the paths to delete and the nodes to add. Changed files are deleted
+// and added again, directories are kept as long as they are listed since
+// deleting them drops their subtree. Indexed children missing from listed are
+// only deleted when the listing is complete.
+func DiffNodes(parent string, indexed, listed []model.SearchNode, complete bool) (toDel []string, toAdd []model.SearchNode) {
+	old := make(map[string]model.SearchNode, len(indexed))
+	for _, node := range indexed {
+		old[node.Name] = node
+	}
+	seen := make(map[string]bool, len(listed))
+	for _, node := range listed {
+		seen[node.Name] = true
+		oldNode, ok := old[node.Name]
+		if ok && sameNode(oldNode, node) {
+			continue
+		}
+		if ok {
+			toDel = append(toDel, path.Join(parent, node.Name))
+		}
+		toAdd = append(toAdd, node)
+	}
+	if complete {
+		for _, node := range indexed {
+			if !seen[node.Name] {
+				seen[node.Name] = true
+				toDel = append(toDel, path.Join(parent, node.Name))
+			}
+		}
+	}
+	return toDel, toAdd
+}
+
+func sameNode(a, b model.SearchNode) bool {
+	if a.IsDir != b.IsDir {
+		return false
+	}
+	if a.IsDir {
+		return true
+	}
+	return a.Size == b.Size && nodeWorkID(a) == nodeWorkID(b)
+}
+
+func nodeWorkID(node model.SearchNode) uint {
+	if node.Media == nil {
+		return 0
+	}
+	return node.Media.WorkID
+}
@@ -0,0 +1,49 @@
+package searcher
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestDiffNodes(t *testing.T) {
+	indexed := []model.SearchNode{
+		{Parent: "/a", Name: "same.mp4", Size: 10},
+		{Parent: "/a", Name: "resized.mp4", Size: 10},
+		{Parent: "/a", Name: "matched.mp4", Size: 10},
+		{Parent: "/a", Name: "dir", IsDir: true},
+		{Parent: "/a", Name: "gone.mp4", Size: 1},
+	}
+	listed := []model.SearchNode{
+		{Parent: "/a", Name: "same.mp4", Size: 10},
+		{Parent: "/a", Name: "resized.mp4", Size: 20},
+		{Parent: "/a", Name: "matched.mp4", Size: 10, Media: &model.SearchMedia{WorkID: 7}},
+		{Parent: "/a", Name: "dir", IsDir: true, Size: 5},
+		{Parent: "/a", Name: "new.mp4", Size: 2},
+	}
+	names := func(nodes []model.SearchNode) []string {
+		var out []string
+		for _, node := range nodes {
+			out = append(out, node.Name)
+		}
+		return out
+	}
+
+	toDel, toAdd := DiffNodes("/a", indexed, listed, true)
+	if want := []string{"/a/resized.mp4", "/a/matched.mp4", "/a/gone.mp4"}; !slices.Equal(toDel, want) {
+		t.Fatalf("complete toDel = %v, want %v", toDel, want)
+	}
+	if want := []string{"resized.mp4", "matched.mp4", "new.mp4"}; !slices.Equal(names(toAdd), want) {
+		t.Fatalf("complete toAdd = %v, want %v", names(toAdd), want)
+	}
+
+	// a delta keeps the children it does not mention
+	toDel, toAdd = DiffNodes("/a", indexed, listed[1:2], false)
+	if want := []string{"/a/resized.mp4"}; !slices.Equal(toDel, want) {
+		t.Fatalf("delta toDel = %v, want %v", toDel, want)
+	}
+	if want := []string{"resized.mp4"}; !slices.Equal(names(toAdd), want) {
+		t.Fatalf("delta toAdd = %v, want %v", names(toAdd), want)
+	}
+}
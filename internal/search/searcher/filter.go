@@ -0,0 +1,46 @@
+package searcher
+
+import (
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+var typeSettings = map[string]string{
+	model.SearchTypeVideo: conf.VideoTypes,
+	model.SearchTypeAudio: conf.AudioTypes,
+	model.SearchTypeImage: conf.ImageTypes,
+	model.SearchTypeText:  conf.TextTypes,
+}
+
+// ResolveExts returns the lower case extensions matched by filter, with the
+// extensions of its Types added from the type settings.
+func ResolveExts(filter model.SearchFilter) []string {
+	exts := make([]string, 0, len(filter.Exts))
+	add := func(ext string) {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext != "" && !utils.SliceContains(exts, ext) {
+			exts = append(exts, ext)
+		}
+	}
+	for _, ext := range filter.Exts {
+		add(ext)
+	}
+	for _, t := range filter.Types {
+		for _, ext := range conf.SlicesMap[typeSettings[t]] {
+			add(ext)
+		}
+	}
+	return exts
+}
+
+// NodeExt is the extension of a node matched by SearchFilter.Exts,
+// directories have none.
+func NodeExt(node model.SearchNode) string {
+	if node.IsDir {
+		return ""
+	}
+	return utils.Ext(node.Name)
+}
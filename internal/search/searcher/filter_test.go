@@ -0,0 +1,23 @@
+package searcher
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+func TestResolveExts(t *testing.T) {
+	old := conf.SlicesMap[conf.VideoTypes]
+	conf.SlicesMap[conf.VideoTypes] = []string{"mp4", "mkv"}
+	t.Cleanup(func() { conf.SlicesMap[conf.VideoTypes] = old })
+
+	exts := ResolveExts(model.SearchFilter{
+		Exts:  []string{".MKV", " iso ", ""},
+		Types: []string{model.SearchTypeVideo},
+	})
+	if want := []string{"mkv", "iso", "mp4"}; !slices.Equal(exts, want) {
+		t.Fatalf("exts = %v, want %v", exts, want)
+	}
+}
@@ -0,0 +1,57 @@
+package searcher
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+func NewNode(parent string, obj model.Obj) model.SearchNode {
+	return model.SearchNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+	}
+}
+
+// AttachMedia fills the media fields of the nodes whose objs belong to a
+// FilmWork, nodes[i] must be built from objs[i].
+func AttachMedia(nodes []model.SearchNode, objs []model.Obj) {
+	workIDs := make([]uint, 0)
+	for _, obj := range objs {
+		if id := mediaWorkID(obj); id != 0 {
+			workIDs = append(workIDs, id)
+		}
+	}
+	if len(workIDs) == 0 {
+		return
+	}
+	works, err := db.GetFilmWorksByIDs(workIDs)
+	if err != nil {
+		log.Warnf("failed to load media works for search index: %+v", err)
+		return
+	}
+	media := make(map[uint]*model.SearchMedia, len(works))
+	for _, work := range works {
+		media[work.ID] = model.NewSearchMedia(work)
+	}
+	for i, obj := range objs {
+		if id := mediaWorkID(obj); id != 0 {
+			nodes[i].Media = media[id]
+		}
+	}
+}
+
+func mediaWorkID(obj model.Obj) uint {
+	switch o := model.UnwrapObj(obj).(type) {
+	case *model.EmbyFileObj:
+		return o.WorkID
+	case *model.EmbyFileDirWrapper:
+		if len(o.EmbyFiles) > 0 {
+			return o.EmbyFiles[0].WorkID
+		}
+	}
+	return 0
+}
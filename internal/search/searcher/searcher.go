@@ -24,6 +24,8 @@ type Searcher interface {
 	Get(ctx context.Context, parent string) ([]model.SearchNode, error)
 	// Del with prefix
 	Del(ctx context.Context, prefix string) error
+	// UpdateMedia replaces the media fields of the nodes of media.WorkID
+	UpdateMedia(ctx context.Context, media *model.SearchMedia) error
 	// Release resource
 	Release(ctx context.Context) error
 	// Clear all index
@@ -0,0 +1,125 @@
+// Package searchertest holds the search tests shared by the searcher
+// backends, each backend runs them against its own index.
+package searchertest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
+)
+
+const gb = int64(1) << 30
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 12, 0, 0, 0, time.UTC)
+}
+
+// Nodes is the fixture indexed by Run.
+var Nodes = []model.SearchNode{
+	{Parent: "/movies", Name: "a.mkv", Size: 12 * gb, Modified: day(time.October, 5)},
+	{Parent: "/movies", Name: "b.MKV", Size: gb, Modified: day(time.September, 1)},
+	{Parent: "/movies", Name: "c.mp4", Size: 20 * gb, Modified: day(time.October, 10)},
+	{Parent: "/movies", Name: "x.mkv", IsDir: true, Modified: day(time.October, 5)},
+	{Parent: "/movies", Name: "sub", IsDir: true, Modified: day(time.October, 5)},
+	{Parent: "/movies/sub", Name: "d.mkv", Size: 11 * gb, Modified: day(time.October, 12)},
+	{Parent: "/music", Name: "e.mp3", Size: 5 << 20, Modified: day(time.October, 1)},
+}
+
+// Run indexes Nodes into an empty searcher and checks the filters and sort
+// orders of SearchReq.
+func Run(t *testing.T, s searcher.Searcher) {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.BatchIndex(ctx, Nodes); err != nil {
+		t.Fatalf("batch index: %v", err)
+	}
+	from := day(time.October, 1)
+	to := day(time.October, 10)
+
+	tests := []struct {
+		name string
+		req  model.SearchReq
+		want []string
+	}{
+		{
+			name: "ext size and modified",
+			req: model.SearchReq{Parent: "/", SearchFilter: model.SearchFilter{
+				Exts: []string{"mkv"}, MinSize: 10 * gb, ModifiedFrom: &from,
+			}},
+			want: []string{"a.mkv", "d.mkv"},
+		},
+		{
+			name: "ext ignores case and directories",
+			req:  model.SearchReq{Parent: "/", SearchFilter: model.SearchFilter{Exts: []string{"mkv"}}},
+			want: []string{"a.mkv", "b.MKV", "d.mkv"},
+		},
+		{
+			name: "several exts",
+			req:  model.SearchReq{Parent: "/", SearchFilter: model.SearchFilter{Exts: []string{"mp3", "mp4"}}},
+			want: []string{"c.mp4", "e.mp3"},
+		},
+		{
+			name: "parent and max size",
+			req:  model.SearchReq{Parent: "/music", Scope: 2, SearchFilter: model.SearchFilter{MaxSize: 10 << 20}},
+			want: []string{"e.mp3"},
+		},
+		{
+			name: "modified range",
+			req: model.SearchReq{Parent: "/movies", Scope: 2, SearchFilter: model.SearchFilter{
+				ModifiedFrom: &from, ModifiedTo: &to,
+			}},
+			want: []string{"a.mkv", "c.mp4"},
+		},
+		{
+			name: "size desc",
+			req:  model.SearchReq{Parent: "/movies", Scope: 2, Sort: model.SearchSortSize, Order: "desc"},
+			want: []string{"c.mp4", "a.mkv", "d.mkv", "b.MKV"},
+		},
+		{
+			name: "modified asc",
+			req:  model.SearchReq{Parent: "/", Scope: 2, Sort: model.SearchSortModified},
+			want: []string{"b.MKV", "e.mp3", "a.mkv", "c.mp4", "d.mkv"},
+		},
+		{
+			name: "name desc",
+			req:  model.SearchReq{Parent: "/movies/sub", Sort: model.SearchSortName, Order: "desc"},
+			want: []string{"d.mkv"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.PageReq = model.PageReq{Page: 1, PerPage: 100}
+			nodes, total, err := s.Search(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			names := make([]string, len(nodes))
+			for i, node := range nodes {
+				names[i] = node.Name
+			}
+			if tt.req.Sort == "" {
+				slices.Sort(names)
+			}
+			if !slices.Equal(names, tt.want) || total != int64(len(tt.want)) {
+				t.Fatalf("found %v (total %d), want %v", names, total, tt.want)
+			}
+		})
+	}
+
+	t.Run("modified is kept", func(t *testing.T) {
+		nodes, _, err := s.Search(ctx, model.SearchReq{
+			Parent: "/music", SearchFilter: model.SearchFilter{Exts: []string{"mp3"}},
+			PageReq: model.PageReq{Page: 1, PerPage: 1},
+		})
+		if err != nil || len(nodes) != 1 {
+			t.Fatalf("search = %v, %v", nodes, err)
+		}
+		if !nodes[0].Modified.Equal(Nodes[6].Modified) || nodes[0].Size != Nodes[6].Size {
+			t.Fatalf("node = %+v, want %+v", nodes[0], Nodes[6])
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package sqlite_fts
+
+import (
+	"fmt"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
+	log "github.com/sirupsen/logrus"
+)
+
+var config = searcher.Config{
+	Name:       "sqlite_fts",
+	AutoUpdate: true,
+}
+
+func init() {
+	searcher.RegisterSearcher(config, func() (searcher.Searcher, error) {
+		if conf.Conf.Database.Type != "sqlite3" {
+			return nil, fmt.Errorf("sqlite_fts needs a sqlite3 database, current: %s", conf.Conf.Database.Type)
+		}
+		if !db.FTS5Available() {
+			return nil, errs.FTS5NotAvailable
+		}
+		if err := db.InitSearchNodeFTS(conf.Conf.SqliteFTS.Tokenizer); err != nil {
+			log.Errorf("failed to create fts index: %+v", err)
+			return nil, err
+		}
+		return &FTS{}, nil
+	})
+}
@@ -0,0 +1,51 @@
+package sqlite_fts
+
+import (
+	"context"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher"
+)
+
+// FTS keeps the nodes in the search node table like the database searcher
+// and matches names with the FTS5 index of the table.
+type FTS struct{}
+
+func (F FTS) Config() searcher.Config {
+	return config
+}
+
+func (F FTS) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	return db.SearchNodeFTS(req)
+}
+
+func (F FTS) Index(ctx context.Context, node model.SearchNode) error {
+	return db.CreateSearchNode(&node)
+}
+
+func (F FTS) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
+	return db.BatchCreateSearchNodes(&nodes)
+}
+
+func (F FTS) Get(ctx context.Context, parent string) ([]model.SearchNode, error) {
+	return db.GetSearchNodesByParent(parent)
+}
+
+func (F FTS) Del(ctx context.Context, prefix string) error {
+	return db.DeleteSearchNodesByParent(prefix)
+}
+
+func (F FTS) UpdateMedia(ctx context.Context, media *model.SearchMedia) error {
+	return db.UpdateSearchNodesMedia(media)
+}
+
+func (F FTS) Release(ctx context.Context) error {
+	return nil
+}
+
+func (F FTS) Clear(ctx context.Context) error {
+	return db.ClearSearchNodesFTS()
+}
+
+var _ searcher.Searcher = (*FTS)(nil)
@@ -0,0 +1,142 @@
+package sqlite_fts
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/search/searcher/searchertest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func initFTS(t *testing.T, tokenizer string) FTS {
+	t.Helper()
+	dataDir := t.TempDir()
+	conf.Conf = conf.DefaultConfig(dataDir)
+	database, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "fts-test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	if err = db.Init(database); err != nil {
+		t.Fatalf("init db: %v", err)
+	}
+	if err = db.InitSearchNodeFTS(tokenizer); err != nil {
+		if errors.Is(err, errs.FTS5NotAvailable) {
+			t.Skip(err)
+		}
+		t.Fatalf("init fts: %v", err)
+	}
+	return FTS{}
+}
+
+func names(t *testing.T, f FTS, parent, keywords string) []string {
+	t.Helper()
+	nodes, total, err := f.Search(context.Background(), model.SearchReq{
+		Parent: parent, Keywords: keywords, PageReq: model.PageReq{Page: 1, PerPage: 100},
+	})
+	if err != nil {
+		t.Fatalf("search %q: %v", keywords, err)
+	}
+	if int(total) != len(nodes) {
+		t.Fatalf("total = %d, found %d", total, len(nodes))
+	}
+	result := make([]string, len(nodes))
+	for i, node := range nodes {
+		result[i] = node.Name
+	}
+	return result
+}
+
+func TestSearch(t *testing.T) {
+	searchertest.Run(t, initFTS(t, db.FTSTokenizerTrigram))
+}
+
+func TestSearchKeywords(t *testing.T) {
+	ctx := context.Background()
+	f := initFTS(t, db.FTSTokenizerTrigram)
+	nodes := []model.SearchNode{
+		{Parent: "/anime", Name: "进击的巨人 第一季.mkv"},
+		{Parent: "/anime", Name: "巨人の星.mp4"},
+		{Parent: "/anime/old", Name: "Giant Robo.mkv"},
+		{Parent: "/anime/old", Name: "old", IsDir: true},
+		{Parent: "/other", Name: "giant.txt"},
+	}
+	if err := f.BatchIndex(ctx, nodes); err != nil {
+		t.Fatalf("batch index: %v", err)
+	}
+
+	tests := []struct {
+		parent, keywords string
+		want             []string
+	}{
+		{"/", "第一季", []string{"进击的巨人 第一季.mkv"}},
+		// shorter than a trigram
+		{"/", "巨人", []string{"巨人の星.mp4", "进击的巨人 第一季.mkv"}},
+		{"/", "GIANT", []string{"Giant Robo.mkv", "giant.txt"}},
+		{"/anime", "giant robo", []string{"Giant Robo.mkv"}},
+		{"/", `ant "R`, nil},
+	}
+	for _, tt := range tests {
+		if got := names(t, f, tt.parent, tt.keywords); !slices.Equal(got, tt.want) {
+			t.Fatalf("search %q in %s = %v, want %v", tt.keywords, tt.parent, got, tt.want)
+		}
+	}
+
+	// Del removes the path and everything below it from the index as well
+	if err := f.Del(ctx, "/anime/old"); err != nil {
+		t.Fatalf("del: %v", err)
+	}
+	if got := names(t, f, "/", "giant"); !slices.Equal(got, []string{"giant.txt"}) {
+		t.Fatalf("search after del = %v", got)
+	}
+	children, err := f.Get(ctx, "/anime")
+	if err != nil || len(children) != 2 {
+		t.Fatalf("get = %v, %v", children, err)
+	}
+
+	if err = f.Clear(ctx); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if got := names(t, f, "/", "巨人"); len(got) != 0 {
+		t.Fatalf("search after clear = %v", got)
+	}
+	if err = f.Index(ctx, model.SearchNode{Parent: "/", Name: "giant again"}); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if got := names(t, f, "/", "giant"); !slices.Equal(got, []string{"giant again"}) {
+		t.Fatalf("search after index = %v", got)
+	}
+}
+
+func TestTokenizerChangeRebuildsIndex(t *testing.T) {
+	ctx := context.Background()
+	f := initFTS(t, db.FTSTokenizerTrigram)
+	if err := f.Index(ctx, model.SearchNode{Parent: "/", Name: "Summer Holiday.mkv"}); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if got := names(t, f, "/", "oliday"); len(got) != 1 {
+		t.Fatalf("trigram substring search = %v", got)
+	}
+
+	if err := db.InitSearchNodeFTS(db.FTSTokenizerUnicode61); err != nil {
+		t.Fatalf("init unicode61: %v", err)
+	}
+	if got := names(t, f, "/", "oliday"); len(got) != 0 {
+		t.Fatalf("unicode61 matched a substring: %v", got)
+	}
+	if got := names(t, f, "/", "holi sum"); len(got) != 1 {
+		t.Fatalf("unicode61 prefix search = %v", got)
+	}
+}
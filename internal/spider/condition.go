@@ -0,0 +1,110 @@
+package spider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+const pollInterval = 200 * time.Millisecond
+
+// Condition reports whether a page reached the state a fetch waits for.
+type Condition = selenium.Condition
+
+// ElementFinder is the part of a web driver needed to look up elements.
+type ElementFinder interface {
+	FindElement(by, value string) (selenium.WebElement, error)
+}
+
+// Selector is met once an element matches the css selector.
+func Selector(css string) Condition {
+	return func(wd selenium.WebDriver) (bool, error) {
+		return hasElement(wd, css), nil
+	}
+}
+
+// DocumentReady is met once the document finished loading.
+func DocumentReady(wd selenium.WebDriver) (bool, error) {
+	state, err := wd.ExecuteScript("return document.readyState", nil)
+	if err != nil {
+		return false, nil
+	}
+	return state == "complete", nil
+}
+
+// TitleContains is met once the page title contains one of the markers,
+// compared case-insensitively.
+func TitleContains(markers ...string) Condition {
+	return func(wd selenium.WebDriver) (bool, error) {
+		title, err := wd.Title()
+		if err != nil {
+			return false, nil
+		}
+		return containsAny(title, markers), nil
+	}
+}
+
+// Any is met once one of the conditions is met.
+func Any(conditions ...Condition) Condition {
+	return func(wd selenium.WebDriver) (bool, error) {
+		for _, condition := range conditions {
+			if condition == nil {
+				continue
+			}
+			if ok, err := condition(wd); ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+}
+
+// WaitFor polls the condition until it is met, it fails with ErrTimeout
+// after the timeout.
+func WaitFor(wd selenium.WebDriver, condition Condition, timeout time.Duration) error {
+	return poll(timeout, func() (bool, error) {
+		return condition(wd)
+	})
+}
+
+// WaitForElement waits until an element matches the css selector, for
+// pages navigated to inside a fetch.
+func WaitForElement(finder ElementFinder, css string, timeout time.Duration) error {
+	return poll(timeout, func() (bool, error) {
+		return hasElement(finder, css), nil
+	})
+}
+
+func poll(timeout time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := check()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return &Error{Kind: ErrTimeout, Err: fmt.Errorf("condition not met after %s", timeout)}
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func hasElement(finder ElementFinder, css string) bool {
+	_, err := finder.FindElement(selenium.ByCSSSelector, css)
+	return err == nil
+}
+
+func containsAny(text string, markers []string) bool {
+	text = strings.ToLower(text)
+	for _, marker := range markers {
+		if strings.Contains(text, strings.ToLower(marker)) {
+			return true
+		}
+	}
+	return false
+}
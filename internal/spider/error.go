@@ -0,0 +1,43 @@
+package spider
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrBlocked means the site answered with a challenge or an access denied page.
+	ErrBlocked = errors.New("blocked")
+	// ErrTimeout means the page did not become ready in time.
+	ErrTimeout = errors.New("timeout")
+	// ErrNotFound means the site reported the page as missing.
+	ErrNotFound = errors.New("not found")
+)
+
+// Error is a classified page failure, errors.Is matches it against its Kind.
+type Error struct {
+	Kind error
+	URL  string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("spider %s: %s", e.URL, e.Kind)
+	}
+	return fmt.Sprintf("spider %s: %s: %s", e.URL, e.Kind, e.Err)
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether a failed fetch is worth trying again later,
+// a page the site reported as missing is not.
+func Retryable(err error) bool {
+	return err != nil && !errors.Is(err, ErrNotFound)
+}
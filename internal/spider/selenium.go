@@ -1,50 +1,232 @@
 package spider
 
 import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/tebeka/selenium"
 	"github.com/tebeka/selenium/chrome"
-	"time"
 )
 
-func Visit(spiderServer, url string, maxWaitTime time.Duration, pageFunc func(wd selenium.WebDriver)) error {
+const defaultTimeout = 30 * time.Second
 
-	caps := selenium.Capabilities{
-		"browserName": "chrome",
-	}
+var (
+	// PoolSize bounds the browser sessions opened per spider server.
+	PoolSize = 2
+	// IdleTimeout closes sessions which were not used for a while, remote
+	// servers usually reap idle sessions on their own.
+	IdleTimeout = 2 * time.Minute
 
-	chromeCaps := chrome.Capabilities{
-		Args: []string{
-			"--no-sandbox",
-			"--headless",
-			"--disable-dev-shm-usage",
-			"--disable-gpu",
-			"--start-maximized",
-		},
-		W3C: true,
+	blockedTitles  = []string{"just a moment", "attention required", "access denied", "verify you are human", "are you a robot"}
+	blockedSources = []string{"cf-challenge", "challenge-platform", "g-recaptcha", "h-captcha"}
+	notFoundTitles = []string{"page not found", "404 not found", "404 error"}
+
+	newSession = func(server string) (selenium.WebDriver, error) {
+		caps := selenium.Capabilities{
+			"browserName": "chrome",
+		}
+		caps.AddChrome(chrome.Capabilities{
+			Args: []string{
+				"--no-sandbox",
+				"--headless",
+				"--disable-dev-shm-usage",
+				"--disable-gpu",
+				"--start-maximized",
+			},
+			W3C: true,
+		})
+		return selenium.NewRemote(caps, server)
 	}
-	caps.AddChrome(chromeCaps)
 
-	wd, err := selenium.NewRemote(caps, spiderServer)
+	pools   = make(map[string]*pool)
+	poolsMu sync.Mutex
+)
+
+// Page describes a page to fetch and when it counts as loaded.
+type Page struct {
+	URL string
+	// Ready is met once the content the caller reads is present,
+	// DocumentReady when unset
+	Ready Condition
+	// NotFound is met when the site reports the page as missing, pages
+	// titled like a 404 page are always treated as missing
+	NotFound Condition
+	// Timeout bounds both waiting for a free session and for Ready
+	Timeout time.Duration
+}
+
+// Fetch loads the page in a pooled browser session and calls read once the
+// page is ready. Failures are classified as ErrBlocked, ErrTimeout or
+// ErrNotFound where possible.
+func Fetch(server string, page Page, read func(wd selenium.WebDriver) error) error {
+	if page.Timeout <= 0 {
+		page.Timeout = defaultTimeout
+	}
+	p := getPool(server)
+	s, err := p.acquire(page.Timeout)
 	if err != nil {
-		utils.Log.Warnf("Failed to start Selenium: %s", err.Error())
-		return err
+		return withURL(err, page.URL)
 	}
-	defer func(wd selenium.WebDriver) {
-		err1 := wd.Quit()
-		if err1 != nil {
-			utils.Log.Warnf("failed to close remote webdriver: %s", err1.Error())
+	healthy := false
+	defer func() {
+		p.release(s, healthy)
+	}()
+
+	if err = s.wd.Get(page.URL); err != nil {
+		utils.Log.Warnf("[spider] failed to load %s: %s", page.URL, err)
+		if strings.Contains(strings.ToLower(err.Error()), "timeout") {
+			return &Error{Kind: ErrTimeout, URL: page.URL, Err: err}
 		}
-	}(wd)
+		return err
+	}
+	healthy = true
+	if err = waitPage(s.wd, page); err != nil {
+		return withURL(err, page.URL)
+	}
+	if read == nil {
+		return nil
+	}
+	return read(s.wd)
+}
 
-	err = wd.Get(url)
+func waitPage(wd selenium.WebDriver, page Page) error {
+	ready := page.Ready
+	if ready == nil {
+		ready = DocumentReady
+	}
+	notFound := Any(page.NotFound, TitleContains(notFoundTitles...))
+	blocked := TitleContains(blockedTitles...)
+
+	err := WaitFor(wd, Any(ready, notFound, blocked), page.Timeout)
+	if err != nil && !errors.Is(err, ErrTimeout) {
+		return err
+	}
+	// challenge and 404 pages may match Ready as well, e.g. a body selector
+	if ok, _ := blocked(wd); ok {
+		return &Error{Kind: ErrBlocked}
+	}
+	if ok, _ := notFound(wd); ok {
+		return &Error{Kind: ErrNotFound}
+	}
 	if err != nil {
+		if source, sourceErr := wd.PageSource(); sourceErr == nil && containsAny(source, blockedSources) {
+			return &Error{Kind: ErrBlocked, Err: err}
+		}
 		return err
 	}
-	time.Sleep(maxWaitTime)
+	return nil
+}
+
+func withURL(err error, url string) error {
+	var spiderErr *Error
+	if errors.As(err, &spiderErr) && spiderErr.URL == "" {
+		spiderErr.URL = url
+	}
+	return err
+}
 
-	pageFunc(wd)
+type session struct {
+	wd       selenium.WebDriver
+	lastUsed time.Time
+}
 
-	return nil
+// pool keeps the browser sessions of one spider server, sessions are reused
+// between fetches instead of starting a browser per page.
+type pool struct {
+	server string
+	slots  chan struct{}
+	mu     sync.Mutex
+	idle   []*session
+}
+
+func getPool(server string) *pool {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	p, ok := pools[server]
+	if !ok {
+		p = &pool{server: server, slots: make(chan struct{}, max(PoolSize, 1))}
+		pools[server] = p
+	}
+	return p
+}
+
+func (p *pool) acquire(timeout time.Duration) (*session, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case p.slots <- struct{}{}:
+	case <-timer.C:
+		return nil, &Error{Kind: ErrTimeout, Err: errors.New("no free browser session")}
+	}
+
+	for s := p.popIdle(); s != nil; s = p.popIdle() {
+		if time.Since(s.lastUsed) < IdleTimeout && alive(s.wd) {
+			return s, nil
+		}
+		quit(s.wd)
+	}
+	wd, err := newSession(p.server)
+	if err != nil {
+		<-p.slots
+		utils.Log.Warnf("[spider] failed to start Selenium: %s", err)
+		return nil, err
+	}
+	return &session{wd: wd}, nil
+}
 
+func (p *pool) release(s *session, healthy bool) {
+	if healthy {
+		s.lastUsed = time.Now()
+		p.mu.Lock()
+		p.idle = append(p.idle, s)
+		p.mu.Unlock()
+		time.AfterFunc(IdleTimeout, p.reap)
+	} else {
+		quit(s.wd)
+	}
+	<-p.slots
+}
+
+func (p *pool) popIdle() *session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle) == 0 {
+		return nil
+	}
+	s := p.idle[len(p.idle)-1]
+	p.idle = p.idle[:len(p.idle)-1]
+	return s
+}
+
+// reap closes the sessions that stayed idle for longer than IdleTimeout.
+func (p *pool) reap() {
+	p.mu.Lock()
+	var expired []*session
+	kept := p.idle[:0]
+	for _, s := range p.idle {
+		if time.Since(s.lastUsed) >= IdleTimeout {
+			expired = append(expired, s)
+		} else {
+			kept = append(kept, s)
+		}
+	}
+	p.idle = kept
+	p.mu.Unlock()
+	for _, s := range expired {
+		quit(s.wd)
+	}
+}
+
+func alive(wd selenium.WebDriver) bool {
+	_, err := wd.CurrentURL()
+	return err == nil
+}
+
+func quit(wd selenium.WebDriver) {
+	if err := wd.Quit(); err != nil {
+		utils.Log.Warnf("failed to close remote webdriver: %s", err.Error())
+	}
 }
@@ -0,0 +1,192 @@
+package spider
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+type fakePage struct {
+	title    string
+	source   string
+	elements []string
+}
+
+type fakeDriver struct {
+	selenium.WebDriver
+	pages   map[string]fakePage
+	current fakePage
+	dead    bool
+	quits   *atomic.Int32
+}
+
+func (d *fakeDriver) Get(url string) error {
+	if d.dead {
+		return errors.New("invalid session id")
+	}
+	d.current = d.pages[url]
+	return nil
+}
+
+func (d *fakeDriver) CurrentURL() (string, error) {
+	if d.dead {
+		return "", errors.New("invalid session id")
+	}
+	return "", nil
+}
+
+func (d *fakeDriver) Title() (string, error) {
+	return d.current.title, nil
+}
+
+func (d *fakeDriver) PageSource() (string, error) {
+	return d.current.source, nil
+}
+
+func (d *fakeDriver) ExecuteScript(string, []interface{}) (interface{}, error) {
+	return "complete", nil
+}
+
+func (d *fakeDriver) FindElement(_, value string) (selenium.WebElement, error) {
+	for _, element := range d.current.elements {
+		if element == value {
+			return nil, nil
+		}
+	}
+	return nil, errors.New("no such element")
+}
+
+func (d *fakeDriver) Quit() error {
+	d.quits.Add(1)
+	return nil
+}
+
+func useFakeSessions(t *testing.T, pages map[string]fakePage) (*atomic.Int32, *atomic.Int32) {
+	t.Helper()
+	oldNewSession := newSession
+	var created, quits atomic.Int32
+	newSession = func(string) (selenium.WebDriver, error) {
+		created.Add(1)
+		return &fakeDriver{pages: pages, quits: &quits}, nil
+	}
+	t.Cleanup(func() {
+		newSession = oldNewSession
+		poolsMu.Lock()
+		pools = make(map[string]*pool)
+		poolsMu.Unlock()
+	})
+	return &created, &quits
+}
+
+func TestFetchClassifiesFailures(t *testing.T) {
+	useFakeSessions(t, map[string]fakePage{
+		"/ok":        {title: "Videos", elements: []string{".list"}},
+		"/challenge": {title: "Just a moment..."},
+		"/captcha":   {title: "Videos", source: `<div class="g-recaptcha"></div>`},
+		"/missing":   {title: "Page Not Found"},
+		"/removed":   {title: "Videos", elements: []string{".removed"}},
+		"/slow":      {title: "Videos"},
+	})
+
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{url: "/ok"},
+		{url: "/challenge", want: ErrBlocked},
+		{url: "/captcha", want: ErrBlocked},
+		{url: "/missing", want: ErrNotFound},
+		{url: "/removed", want: ErrNotFound},
+		{url: "/slow", want: ErrTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimPrefix(tt.url, "/"), func(t *testing.T) {
+			read := false
+			err := Fetch("fake", Page{
+				URL:      tt.url,
+				Ready:    Selector(".list"),
+				NotFound: Selector(".removed"),
+				Timeout:  50 * time.Millisecond,
+			}, func(selenium.WebDriver) error {
+				read = true
+				return nil
+			})
+			if tt.want == nil {
+				if err != nil || !read {
+					t.Fatalf("fetch error = %v, read = %v", err, read)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) || read {
+				t.Fatalf("fetch error = %v, read = %v, want %v", err, read, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.url) {
+				t.Fatalf("error %q does not name the page", err)
+			}
+			if Retryable(err) != (tt.want != ErrNotFound) {
+				t.Fatalf("retryable = %v for %v", Retryable(err), err)
+			}
+		})
+	}
+}
+
+func TestFetchReusesHealthySessions(t *testing.T) {
+	created, quits := useFakeSessions(t, map[string]fakePage{"/ok": {title: "Videos"}})
+
+	for i := 0; i < 3; i++ {
+		if err := Fetch("fake", Page{URL: "/ok"}, nil); err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+	}
+	if created.Load() != 1 {
+		t.Fatalf("sessions created = %d, want 1", created.Load())
+	}
+
+	// a session that died while idle is replaced
+	p := getPool("fake")
+	p.idle[0].wd.(*fakeDriver).dead = true
+	if err := Fetch("fake", Page{URL: "/ok"}, nil); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if created.Load() != 2 || quits.Load() != 1 {
+		t.Fatalf("sessions created = %d, quit = %d, want 2 and 1", created.Load(), quits.Load())
+	}
+}
+
+func TestFetchBoundsSessionsPerServer(t *testing.T) {
+	created, _ := useFakeSessions(t, map[string]fakePage{"/ok": {title: "Videos"}})
+
+	release := make(chan struct{})
+	started := make(chan struct{}, PoolSize)
+	done := make(chan error, PoolSize)
+	for i := 0; i < PoolSize; i++ {
+		go func() {
+			done <- Fetch("fake", Page{URL: "/ok"}, func(selenium.WebDriver) error {
+				started <- struct{}{}
+				<-release
+				return nil
+			})
+		}()
+	}
+	for i := 0; i < PoolSize; i++ {
+		<-started
+	}
+
+	err := Fetch("fake", Page{URL: "/ok", Timeout: 50 * time.Millisecond}, nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("fetch with a full pool = %v, want ErrTimeout", err)
+	}
+	close(release)
+	for i := 0; i < PoolSize; i++ {
+		if err = <-done; err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+	}
+	if created.Load() != int32(PoolSize) {
+		t.Fatalf("sessions created = %d, want %d", created.Load(), PoolSize)
+	}
+}
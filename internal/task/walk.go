@@ -0,0 +1,87 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+// WalkProgress is the progress of a task walking a tree, Walked returns the
+// dirs done and still queued the percentage is computed from.
+type WalkProgress interface {
+	Walked() (done, queued int)
+}
+
+// WalkTask is the base of the tasks walking a tree breadth first.
+type WalkTask[P WalkProgress] struct {
+	TaskExtension
+	mu       sync.Mutex
+	progress P
+}
+
+// NewWalkTask returns the base of a task created by the user in ctx.
+func NewWalkTask[P WalkProgress](ctx context.Context) WalkTask[P] {
+	creator, _ := ctx.Value(conf.UserKey).(*model.User)
+	apiUrl, _ := ctx.Value(conf.ApiUrlKey).(string)
+	return WalkTask[P]{
+		TaskExtension: TaskExtension{
+			Creator: creator,
+			ApiUrl:  apiUrl,
+		},
+	}
+}
+
+func (t *WalkTask[P]) Progress() P {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.progress
+}
+
+// Update changes the progress with f and sets the percentage from it.
+func (t *WalkTask[P]) Update(f func(p *P)) {
+	t.mu.Lock()
+	f(&t.progress)
+	done, queued := t.progress.Walked()
+	t.mu.Unlock()
+	if done+queued > 0 {
+		t.SetProgress(float64(done) * 100 / float64(done+queued))
+	}
+}
+
+// Walk runs walk with a fresh progress and records the times of the run.
+func (t *WalkTask[P]) Walk(walk func(ctx context.Context) error) error {
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	t.Update(func(p *P) {
+		var zero P
+		*p = zero
+	})
+	return walk(t.Ctx())
+}
+
+// RequireAdmin fails unless the user in ctx is an admin.
+func RequireAdmin(ctx context.Context) error {
+	user, _ := ctx.Value(conf.UserKey).(*model.User)
+	if user == nil || !user.IsAdmin() {
+		return errs.PermissionDenied
+	}
+	return nil
+}
+
+// AddByAdmin adds a task with add for the admin in ctx and returns its id,
+// the way the Other methods of drivers respond.
+func AddByAdmin[T interface{ GetID() string }](ctx context.Context, add func() (T, error)) (interface{}, error) {
+	if err := RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	t, err := add()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"task_id": t.GetID()}, nil
+}
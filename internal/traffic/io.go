@@ -0,0 +1,43 @@
+package traffic
+
+import (
+	"context"
+	"io"
+)
+
+// Reader counts and limits what is read through it against a Session.
+type Reader struct {
+	io.Reader
+	Session *Session
+	Ctx     context.Context
+}
+
+func (r *Reader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if e := r.Session.Wait(r.Ctx, n); e != nil {
+		return n, e
+	}
+	return n, err
+}
+
+func (r *Reader) Close() error {
+	if c, ok := r.Reader.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Writer counts and limits what is written through it against a Session.
+type Writer struct {
+	io.Writer
+	Session *Session
+	Ctx     context.Context
+}
+
+func (w *Writer) Write(p []byte) (n int, err error) {
+	n, err = w.Writer.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, w.Session.Wait(w.Ctx, n)
+}
@@ -0,0 +1,324 @@
+// Package traffic enforces the per-user transfer limits: rate limits, daily
+// quotas and concurrent streams. Counters live in memory and are flushed to
+// the traffic usage table periodically.
+package traffic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"golang.org/x/time/rate"
+)
+
+const flushInterval = 30 * time.Second
+
+type Direction int
+
+const (
+	Download Direction = iota
+	Upload
+)
+
+// accountKey picks an account, client is set for the guest only so each
+// anonymous client gets the guest's limits of its own.
+type accountKey struct {
+	userID uint
+	client string
+}
+
+type account struct {
+	mu     sync.Mutex
+	userID uint
+	client string
+	gen    int
+	limit  model.TrafficLimit
+	down   *rate.Limiter
+	up     *rate.Limiter
+
+	day                    string
+	downloaded, uploaded   int64
+	pendingDown, pendingUp int64
+	streams                int
+}
+
+var (
+	mu         sync.Mutex
+	accounts   = make(map[accountKey]*account)
+	generation int
+	flushOnce  sync.Once
+)
+
+var today = func() string {
+	return time.Now().Format("2006-01-02")
+}
+
+func newLimiter(kib int64) *rate.Limiter {
+	if kib <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(kib*1024), int(kib*1024))
+}
+
+// waitN waits for n bytes, in steps of the burst since rate.Limiter refuses
+// to wait for more than that at once.
+func waitN(ctx context.Context, l *rate.Limiter, n int) error {
+	if l == nil {
+		return nil
+	}
+	for n > 0 {
+		step := min(n, l.Burst())
+		if err := l.WaitN(ctx, step); err != nil {
+			return err
+		}
+		n -= step
+	}
+	return nil
+}
+
+func (a *account) load() error {
+	own, groups, err := db.GetUserTrafficLimits(a.userID)
+	if err != nil {
+		return err
+	}
+	a.limit = model.MergeTrafficLimits(own, groups)
+	a.down = newLimiter(a.limit.DownloadSpeed)
+	a.up = newLimiter(a.limit.UploadSpeed)
+	return nil
+}
+
+// rollover starts the counters of a new day, a.mu must be held.
+func (a *account) rollover() {
+	day := today()
+	if a.day == day {
+		return
+	}
+	if a.pendingDown != 0 || a.pendingUp != 0 {
+		if err := db.AddTrafficUsage(a.userID, a.day, a.pendingDown, a.pendingUp); err != nil {
+			utils.Log.Warnf("[traffic] failed save usage of user %d: %+v", a.userID, err)
+		}
+	}
+	a.day = day
+	a.downloaded, a.uploaded, a.pendingDown, a.pendingUp = 0, 0, 0, 0
+}
+
+func (a *account) flush() {
+	a.mu.Lock()
+	day, down, up := a.day, a.pendingDown, a.pendingUp
+	a.pendingDown, a.pendingUp = 0, 0
+	a.mu.Unlock()
+	if down == 0 && up == 0 {
+		return
+	}
+	if err := db.AddTrafficUsage(a.userID, day, down, up); err != nil {
+		utils.Log.Warnf("[traffic] failed save usage of user %d: %+v", a.userID, err)
+		a.mu.Lock()
+		if a.day == day {
+			a.pendingDown += down
+			a.pendingUp += up
+		}
+		a.mu.Unlock()
+	}
+}
+
+func get(userID uint, client string) (*account, error) {
+	flushOnce.Do(func() {
+		go func() {
+			for range time.Tick(flushInterval) {
+				Flush()
+			}
+		}()
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	key := accountKey{userID: userID, client: client}
+	a, ok := accounts[key]
+	if ok && a.gen == generation {
+		return a, nil
+	}
+	if !ok && client != "" {
+		// the usage table sums up all clients, a client starts the day at 0
+		a = &account{userID: userID, client: client, day: today()}
+	} else if !ok {
+		usage, err := db.GetTrafficUsage(userID, today())
+		if err != nil {
+			return nil, err
+		}
+		a = &account{userID: userID, day: usage.Day, downloaded: usage.Downloaded, uploaded: usage.Uploaded}
+	}
+	a.mu.Lock()
+	err := a.load()
+	a.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	a.gen = generation
+	accounts[key] = a
+	return a, nil
+}
+
+// Reset makes the limits be loaded again, call it whenever a limit or a
+// group membership changes. Counters are kept.
+func Reset() {
+	mu.Lock()
+	generation++
+	mu.Unlock()
+}
+
+// Flush saves the counters not yet written to the usage table, and drops
+// the idle client accounts of past days.
+func Flush() {
+	mu.Lock()
+	accs := make([]*account, 0, len(accounts))
+	for key, a := range accounts {
+		a.mu.Lock()
+		idle := a.client != "" && a.streams == 0 && a.day != today()
+		a.mu.Unlock()
+		if idle {
+			delete(accounts, key)
+		}
+		accs = append(accs, a)
+	}
+	mu.Unlock()
+	for _, a := range accs {
+		a.flush()
+	}
+}
+
+// Session is one transfer of a user in one direction, it takes a stream
+// slot until Close. A nil Session is unlimited.
+type Session struct {
+	acc  *account
+	dir  Direction
+	once sync.Once
+}
+
+// Begin starts a transfer for user, failing when the user already runs the
+// maximum of streams or used up the daily quota of dir. A nil user is not
+// limited.
+func Begin(user *model.User, dir Direction) (*Session, error) {
+	return BeginClient(user, "", dir)
+}
+
+// BeginClient is Begin, but a guest is limited per client, like a client IP,
+// instead of all anonymous transfers sharing the guest's streams and quota.
+func BeginClient(user *model.User, client string, dir Direction) (*Session, error) {
+	if user == nil {
+		return nil, nil
+	}
+	if !user.IsGuest() {
+		client = ""
+	}
+	a, err := get(user.ID, client)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rollover()
+	if a.limit.MaxStreams > 0 && a.streams >= a.limit.MaxStreams {
+		return nil, errs.TooManyStreams
+	}
+	if a.exhausted(dir) {
+		return nil, errs.TrafficQuotaExceeded
+	}
+	a.streams++
+	return &Session{acc: a, dir: dir}, nil
+}
+
+// exhausted tells whether the daily quota of dir is used up, a.mu must be
+// held.
+func (a *account) exhausted(dir Direction) bool {
+	if dir == Download {
+		return a.limit.DailyDownload > 0 && a.downloaded >= a.limit.DailyDownload
+	}
+	return a.limit.DailyUpload > 0 && a.uploaded >= a.limit.DailyUpload
+}
+
+// Wait counts n transferred bytes and waits for the user's rate limit. It
+// fails once the transfer goes past the daily quota.
+func (s *Session) Wait(ctx context.Context, n int) error {
+	if s == nil || n <= 0 {
+		return nil
+	}
+	a := s.acc
+	a.mu.Lock()
+	a.rollover()
+	limiter := a.down
+	if s.dir == Download {
+		a.downloaded += int64(n)
+		a.pendingDown += int64(n)
+	} else {
+		limiter = a.up
+		a.uploaded += int64(n)
+		a.pendingUp += int64(n)
+	}
+	over := a.exhausted(s.dir)
+	a.mu.Unlock()
+	if err := waitN(ctx, limiter, n); err != nil {
+		return err
+	}
+	if over {
+		return errs.TrafficQuotaExceeded
+	}
+	return nil
+}
+
+// Close frees the stream slot, it is safe to call more than once.
+func (s *Session) Close() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() {
+		s.acc.mu.Lock()
+		s.acc.streams--
+		s.acc.mu.Unlock()
+	})
+}
+
+// Stats is the limit a user transfers under and what the user transferred
+// today.
+type Stats struct {
+	Limit      model.TrafficLimit `json:"limit"`
+	Day        string             `json:"day"`
+	Downloaded int64              `json:"downloaded"`
+	Uploaded   int64              `json:"uploaded"`
+	Streams    int                `json:"streams"`
+}
+
+func GetStats(userID uint) (*Stats, error) {
+	a, err := get(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rollover()
+	return &Stats{
+		Limit:      a.limit,
+		Day:        a.day,
+		Downloaded: a.downloaded,
+		Uploaded:   a.uploaded,
+		Streams:    a.streams,
+	}, nil
+}
+
+// Streams returns the transfers each user is running now, the guest's
+// clients summed up.
+func Streams() map[uint]int {
+	mu.Lock()
+	defer mu.Unlock()
+	res := make(map[uint]int, len(accounts))
+	for key, a := range accounts {
+		a.mu.Lock()
+		if a.streams > 0 {
+			res[key.userID] += a.streams
+		}
+		a.mu.Unlock()
+	}
+	return res
+}
@@ -0,0 +1,145 @@
+package traffic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig("data")
+	db.Init(dB)
+}
+
+// limitUser gives a new user limit and sets the day to day, both are undone
+// when t ends.
+func limitUser(t *testing.T, id uint, limit model.TrafficLimit, day string) *model.User {
+	t.Helper()
+	limit.UserID = id
+	if err := db.CreateTrafficLimit(&limit); err != nil {
+		t.Fatalf("create limit: %v", err)
+	}
+	prev := today
+	today = func() string { return day }
+	t.Cleanup(func() {
+		today = prev
+		_ = db.DeleteTrafficLimitsByUserId(id)
+		mu.Lock()
+		for key := range accounts {
+			if key.userID == id {
+				delete(accounts, key)
+			}
+		}
+		mu.Unlock()
+	})
+	return &model.User{ID: id, Role: model.GENERAL}
+}
+
+func TestBeginLimitsStreams(t *testing.T) {
+	user := limitUser(t, 101, model.TrafficLimit{MaxStreams: 1}, "2026-10-18")
+	first, err := Begin(user, Download)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if _, err = Begin(user, Upload); !errors.Is(err, errs.TooManyStreams) {
+		t.Fatalf("second stream err = %v, want too many streams", err)
+	}
+	first.Close()
+	first.Close()
+	second, err := Begin(user, Upload)
+	if err != nil {
+		t.Fatalf("begin after close: %v", err)
+	}
+	second.Close()
+	if s, err := Begin(nil, Download); s != nil || err != nil {
+		t.Fatalf("nil user = %v, %v, want unlimited", s, err)
+	}
+}
+
+func TestWaitCountsQuota(t *testing.T) {
+	user := limitUser(t, 102, model.TrafficLimit{DailyDownload: 100}, "2026-10-18")
+	s, err := Begin(user, Download)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer s.Close()
+	if err = s.Wait(context.Background(), 60); err != nil {
+		t.Fatalf("wait below quota: %v", err)
+	}
+	if err = s.Wait(context.Background(), 60); !errors.Is(err, errs.TrafficQuotaExceeded) {
+		t.Fatalf("wait past quota err = %v", err)
+	}
+	if _, err = Begin(user, Download); !errors.Is(err, errs.TrafficQuotaExceeded) {
+		t.Fatalf("begin past quota err = %v", err)
+	}
+	// uploads have a quota of their own
+	up, err := Begin(user, Upload)
+	if err != nil {
+		t.Fatalf("begin upload: %v", err)
+	}
+	up.Close()
+	stats, err := GetStats(user.ID)
+	if err != nil || stats.Downloaded != 120 || stats.Streams != 1 {
+		t.Fatalf("stats = %+v, %v", stats, err)
+	}
+}
+
+func TestRolloverStartsNewDay(t *testing.T) {
+	user := limitUser(t, 103, model.TrafficLimit{DailyUpload: 100}, "2026-10-18")
+	s, err := Begin(user, Upload)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err = s.Wait(context.Background(), 150); !errors.Is(err, errs.TrafficQuotaExceeded) {
+		t.Fatalf("wait past quota err = %v", err)
+	}
+	s.Close()
+
+	today = func() string { return "2026-10-19" }
+	s, err = Begin(user, Upload)
+	if err != nil {
+		t.Fatalf("begin on the next day: %v", err)
+	}
+	s.Close()
+	// the usage of the past day was saved by the rollover
+	usage, err := db.GetTrafficUsage(user.ID, "2026-10-18")
+	if err != nil || usage.Uploaded != 150 {
+		t.Fatalf("usage = %+v, %v, want 150 uploaded", usage, err)
+	}
+	stats, err := GetStats(user.ID)
+	if err != nil || stats.Day != "2026-10-19" || stats.Uploaded != 0 {
+		t.Fatalf("stats = %+v, %v", stats, err)
+	}
+}
+
+func TestBeginClientSeparatesGuests(t *testing.T) {
+	limitUser(t, 104, model.TrafficLimit{MaxStreams: 1}, "2026-10-18")
+	guest := &model.User{ID: 104, Role: model.GUEST}
+	a, err := BeginClient(guest, "10.0.0.1", Download)
+	if err != nil {
+		t.Fatalf("begin first client: %v", err)
+	}
+	defer a.Close()
+	b, err := BeginClient(guest, "10.0.0.2", Download)
+	if err != nil {
+		t.Fatalf("begin second client: %v", err)
+	}
+	defer b.Close()
+	if _, err = BeginClient(guest, "10.0.0.1", Download); !errors.Is(err, errs.TooManyStreams) {
+		t.Fatalf("same client err = %v, want too many streams", err)
+	}
+	if n := Streams()[guest.ID]; n != 2 {
+		t.Fatalf("guest streams = %d, want 2", n)
+	}
+}
@@ -33,7 +33,7 @@ func IsApply(metaPath, reqPath string, applySub bool) bool {
 
 func CanAccess(user *model.User, meta *model.Meta, reqPath string, password string) bool {
 	// if the reqPath is in hide (only can check the nearest meta) and user can't see hides, can't access
-	if meta != nil && !user.CanSeeHides() && meta.Hide != "" &&
+	if meta != nil && !user.CanAt(model.PermSeeHides, reqPath) && meta.Hide != "" &&
 		IsApply(meta.Path, path.Dir(reqPath), meta.HSub) { // the meta should apply to the parent of current path
 		for _, hide := range strings.Split(meta.Hide, "\n") {
 			re := regexp2.MustCompile(hide, regexp2.None)
@@ -42,8 +42,8 @@ func CanAccess(user *model.User, meta *model.Meta, reqPath string, password stri
 			}
 		}
 	}
-	// if is not guest and can access without password
-	if user.CanAccessWithoutPassword() {
+	// if is not guest and can access without password, here or by a grant above reqPath
+	if user.CanAt(model.PermAccessWithoutPassword, reqPath) {
 		return true
 	}
 	// if meta is nil or password is empty, can access
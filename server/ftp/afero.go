@@ -77,8 +77,12 @@ func (a *AferoAdapter) Chown(_ string, _, _ int) error {
 	return errs.NotSupport
 }
 
-func (a *AferoAdapter) Chtimes(_ string, _ time.Time, _ time.Time) error {
-	return errs.NotSupport
+func (a *AferoAdapter) Chtimes(name string, _ time.Time, mtime time.Time) error {
+	return SetModTime(a.ctx, name, mtime)
+}
+
+func (a *AferoAdapter) ReadLink(name string) (string, error) {
+	return ReadLink(a.ctx, name)
 }
 
 func (a *AferoAdapter) ReadDir(name string) ([]os.FileInfo, error) {
@@ -99,6 +103,9 @@ func (a *AferoAdapter) GetHandle(name string, flags int, offset int64) (ftpserve
 	if err != nil {
 		return nil, err
 	}
+	if (flags&os.O_WRONLY) != 0 && offset != 0 {
+		return OpenResumeUpload(a.ctx, path, offset)
+	}
 	if f, err := Borrow(a.ctx, path); !errors.Is(err, errs.ObjectNotFound) {
 		if err != nil {
 			return nil, err
@@ -125,9 +132,6 @@ func (a *AferoAdapter) GetHandle(name string, flags int, offset int64) (ftpserve
 		return nil, errs.ObjectAlreadyExists
 	}
 	if (flags & os.O_WRONLY) != 0 {
-		if offset != 0 {
-			return nil, errs.NotSupport
-		}
 		trunc := (flags & os.O_TRUNC) != 0
 		if fileSize > 0 {
 			return OpenUploadWithLength(a.ctx, path, trunc, fileSize)
@@ -3,6 +3,7 @@ package ftp
 import (
 	"context"
 	stdpath "path"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -19,7 +20,7 @@ func Mkdir(ctx context.Context, path string) error {
 	if err != nil {
 		return err
 	}
-	if !user.CanWrite() || !user.CanFTPManage() {
+	if !user.CanAt(model.PermWrite|model.PermFTPManage, reqPath) {
 		meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
 		if err != nil {
 			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
@@ -35,13 +36,13 @@ func Mkdir(ctx context.Context, path string) error {
 
 func Remove(ctx context.Context, path string) error {
 	user := ctx.Value(conf.UserKey).(*model.User)
-	if !user.CanRemove() || !user.CanFTPManage() {
-		return errs.PermissionDenied
-	}
 	reqPath, err := user.JoinPath(path)
 	if err != nil {
 		return err
 	}
+	if !user.CanAt(model.PermRemove|model.PermFTPManage, reqPath) {
+		return errs.PermissionDenied
+	}
 	if err = RemoveStage(reqPath); !errors.Is(err, errs.ObjectNotFound) {
 		return err
 	}
@@ -61,7 +62,7 @@ func Rename(ctx context.Context, oldPath, newPath string) error {
 	srcDir, srcBase := stdpath.Split(srcPath)
 	dstDir, dstBase := stdpath.Split(dstPath)
 	if srcDir == dstDir {
-		if !user.CanRename() || !user.CanFTPManage() {
+		if !user.CanAt(model.PermRename|model.PermFTPManage, srcPath) {
 			return errs.PermissionDenied
 		}
 		if err = MoveStage(srcPath, dstPath); !errors.Is(err, errs.ObjectNotFound) {
@@ -69,7 +70,11 @@ func Rename(ctx context.Context, oldPath, newPath string) error {
 		}
 		return fs.Rename(ctx, srcPath, dstBase)
 	} else {
-		if !user.CanFTPManage() || !user.CanMove() || (srcBase != dstBase && !user.CanRename()) {
+		perm := model.PermFTPManage | model.PermMove
+		if srcBase != dstBase {
+			perm |= model.PermRename
+		}
+		if !user.CanAt(perm, srcPath) || !user.CanAt(perm, dstDir) {
 			return errs.PermissionDenied
 		}
 		if err = MoveStage(srcPath, dstPath); !errors.Is(err, errs.ObjectNotFound) {
@@ -85,3 +90,18 @@ func Rename(ctx context.Context, oldPath, newPath string) error {
 		return err
 	}
 }
+
+func SetModTime(ctx context.Context, path string, modified time.Time) error {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(path)
+	if err != nil {
+		return err
+	}
+	if err = uploadAuth(ctx, reqPath); err != nil {
+		return err
+	}
+	if err = TouchStage(reqPath, modified); !errors.Is(err, errs.ObjectNotFound) {
+		return err
+	}
+	return fs.SetModTime(ctx, reqPath, modified)
+}
@@ -6,6 +6,7 @@ import (
 	fs2 "io/fs"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
@@ -14,6 +15,8 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/pkg/errors"
 )
@@ -21,7 +24,8 @@ import (
 type FileDownloadProxy struct {
 	model.File
 	io.Closer
-	ctx context.Context
+	ctx     context.Context
+	session *traffic.Session
 }
 
 func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownloadProxy, error) {
@@ -33,7 +37,8 @@ func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownl
 		}
 	}
 	ctx = context.WithValue(ctx, conf.MetaKey, meta)
-	if !common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
+	if !user.CanAt(model.PermFTPAccess, reqPath) ||
+		!common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
 		return nil, errs.PermissionDenied
 	}
 
@@ -57,7 +62,12 @@ func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownl
 		_ = ss.Close()
 		return nil, err
 	}
-	return &FileDownloadProxy{File: reader, Closer: ss, ctx: ctx}, nil
+	session, err := traffic.Begin(user, traffic.Download)
+	if err != nil {
+		_ = ss.Close()
+		return nil, err
+	}
+	return &FileDownloadProxy{File: reader, Closer: ss, ctx: ctx, session: session}, nil
 }
 
 func (f *FileDownloadProxy) Read(p []byte) (n int, err error) {
@@ -65,7 +75,10 @@ func (f *FileDownloadProxy) Read(p []byte) (n int, err error) {
 	if err != nil {
 		return n, err
 	}
-	err = stream.ClientDownloadLimit.WaitN(f.ctx, n)
+	if err = stream.ClientDownloadLimit.WaitN(f.ctx, n); err != nil {
+		return n, err
+	}
+	err = f.session.Wait(f.ctx, n)
 	return n, err
 }
 
@@ -74,10 +87,18 @@ func (f *FileDownloadProxy) ReadAt(p []byte, off int64) (n int, err error) {
 	if err != nil {
 		return n, err
 	}
-	err = stream.ClientDownloadLimit.WaitN(f.ctx, n)
+	if err = stream.ClientDownloadLimit.WaitN(f.ctx, n); err != nil {
+		return n, err
+	}
+	err = f.session.Wait(f.ctx, n)
 	return n, err
 }
 
+func (f *FileDownloadProxy) Close() error {
+	f.session.Close()
+	return f.Closer.Close()
+}
+
 func (f *FileDownloadProxy) Write(p []byte) (n int, err error) {
 	return 0, errs.NotSupport
 }
@@ -127,7 +148,8 @@ func Stat(ctx context.Context, path string) (os.FileInfo, error) {
 		}
 	}
 	ctx = context.WithValue(ctx, conf.MetaKey, meta)
-	if !common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
+	if !user.CanReach(model.PermFTPAccess, reqPath) ||
+		!common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
 		return nil, errs.PermissionDenied
 	}
 	if ret, err := StatStage(reqPath); !errors.Is(err, errs.ObjectNotFound) {
@@ -153,7 +175,8 @@ func List(ctx context.Context, path string) ([]os.FileInfo, error) {
 		}
 	}
 	ctx = context.WithValue(ctx, conf.MetaKey, meta)
-	if !common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
+	if !user.CanReach(model.PermFTPAccess, reqPath) ||
+		!common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
 		return nil, errs.PermissionDenied
 	}
 	objs, err := fs.List(ctx, reqPath, &fs.ListArgs{})
@@ -173,3 +196,25 @@ func List(ctx context.Context, path string) ([]os.FileInfo, error) {
 	}
 	return ret, nil
 }
+
+// ReadLink returns where the storage mounted at path links to, relative to
+// the user's base path like any path the user sends.
+func ReadLink(ctx context.Context, path string) (string, error) {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(path)
+	if err != nil {
+		return "", err
+	}
+	if !user.CanReach(model.PermFTPAccess, reqPath) {
+		return "", errs.PermissionDenied
+	}
+	target, ok := fs.LinkTarget(reqPath)
+	if !ok {
+		return "", errs.NotSupport
+	}
+	base := utils.FixAndCleanPath(user.BasePath)
+	if !utils.IsSubPath(base, target) {
+		return "", errs.PermissionDenied
+	}
+	return utils.FixAndCleanPath(strings.TrimPrefix(target, base)), nil
+}
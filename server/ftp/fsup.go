@@ -17,6 +17,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	ftpserver "github.com/fclairamb/ftpserverlib"
@@ -25,10 +26,11 @@ import (
 
 type FileUploadProxy struct {
 	ftpserver.FileTransfer
-	buffer *os.File
-	path   string
-	ctx    context.Context
-	trunc  bool
+	buffer  *os.File
+	path    string
+	ctx     context.Context
+	trunc   bool
+	session *traffic.Session
 }
 
 func uploadAuth(ctx context.Context, path string) error {
@@ -40,7 +42,7 @@ func uploadAuth(ctx context.Context, path string) error {
 		}
 	}
 	if !(common.CanAccess(user, meta, path, ctx.Value(conf.MetaPassKey).(string)) &&
-		((user.CanFTPManage() && user.CanWrite()) || common.CanWrite(meta, stdpath.Dir(path)))) {
+		(user.CanAt(model.PermFTPManage|model.PermWrite, path) || common.CanWrite(meta, stdpath.Dir(path)))) {
 		return errs.PermissionDenied
 	}
 	return nil
@@ -56,11 +58,16 @@ func OpenUpload(ctx context.Context, path string, trunc bool) (*FileUploadProxy,
 	if setting.GetBool(conf.IgnoreSystemFiles) && utils.IsSystemFile(name) {
 		return nil, errs.IgnoredSystemFile
 	}
+	session, err := traffic.Begin(ctx.Value(conf.UserKey).(*model.User), traffic.Upload)
+	if err != nil {
+		return nil, err
+	}
 	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
 	if err != nil {
+		session.Close()
 		return nil, err
 	}
-	return &FileUploadProxy{buffer: tmpFile, path: path, ctx: ctx, trunc: trunc}, nil
+	return &FileUploadProxy{buffer: tmpFile, path: path, ctx: ctx, trunc: trunc, session: session}, nil
 }
 
 func (f *FileUploadProxy) Read(p []byte) (n int, err error) {
@@ -72,7 +79,10 @@ func (f *FileUploadProxy) Write(p []byte) (n int, err error) {
 	if err != nil {
 		return n, err
 	}
-	err = stream.ClientUploadLimit.WaitN(f.ctx, n)
+	if err = stream.ClientUploadLimit.WaitN(f.ctx, n); err != nil {
+		return n, err
+	}
+	err = f.session.Wait(f.ctx, n)
 	return n, err
 }
 
@@ -81,6 +91,7 @@ func (f *FileUploadProxy) Seek(offset int64, whence int) (int64, error) {
 }
 
 func (f *FileUploadProxy) Close() error {
+	defer f.session.Close()
 	dir, name := stdpath.Split(f.path)
 	size, err := f.buffer.Seek(0, io.SeekCurrent)
 	if err != nil {
@@ -138,9 +149,136 @@ func (f *FileUploadProxy) Close() error {
 	sf.SetRemoveCallback(func() {
 		fs.UploadTaskManager.Cancel(task.GetID())
 	})
+	sf.SetModTimeCallback(func(target string, modified time.Time) {
+		ctx := context.WithValue(context.Background(), conf.UserKey, user)
+		_ = fs.SetModTime(ctx, target, modified)
+	})
 	return nil
 }
 
+// FileResumeProxy continues an interrupted upload. The first offset bytes
+// are copied from the staged or stored file into a new buffer, the client
+// appends the rest and Close puts the whole file in place.
+type FileResumeProxy struct {
+	ftpserver.FileTransfer
+	buffer  *os.File
+	path    string
+	ctx     context.Context
+	session *traffic.Session
+}
+
+func OpenResumeUpload(ctx context.Context, path string, offset int64) (*FileResumeProxy, error) {
+	err := uploadAuth(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	_, name := stdpath.Split(path)
+	if setting.GetBool(conf.IgnoreSystemFiles) && utils.IsSystemFile(name) {
+		return nil, errs.IgnoredSystemFile
+	}
+	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	if err != nil {
+		return nil, err
+	}
+	if err = copyUploaded(ctx, tmpFile, path, offset); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return nil, err
+	}
+	session, err := traffic.Begin(ctx.Value(conf.UserKey).(*model.User), traffic.Upload)
+	if err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return nil, err
+	}
+	return &FileResumeProxy{buffer: tmpFile, path: path, ctx: ctx, session: session}, nil
+}
+
+// copyUploaded copies the first offset bytes of path into dst, from the
+// stage when the interrupted upload is still there.
+func copyUploaded(ctx context.Context, dst io.Writer, path string, offset int64) error {
+	var src io.ReadCloser
+	if f, err := Borrow(ctx, path); err == nil {
+		// cancel the task of the interrupted upload, or it would put the
+		// partial file over the resumed one
+		if err = RemoveStage(path); err != nil {
+			_ = f.Close()
+			return err
+		}
+		src = f
+	} else if errors.Is(err, errs.ObjectNotFound) {
+		f, err := OpenDownload(ctx, path, 0)
+		if err != nil {
+			return err
+		}
+		src = f
+	} else {
+		return err
+	}
+	defer src.Close()
+	n, err := io.CopyN(dst, src, offset)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("cannot resume [%s] at %d, only %d bytes uploaded", path, offset, n)
+	}
+	return err
+}
+
+func (f *FileResumeProxy) Read(p []byte) (n int, err error) {
+	return 0, errs.NotSupport
+}
+
+func (f *FileResumeProxy) Write(p []byte) (n int, err error) {
+	n, err = f.buffer.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if err = stream.ClientUploadLimit.WaitN(f.ctx, n); err != nil {
+		return n, err
+	}
+	err = f.session.Wait(f.ctx, n)
+	return n, err
+}
+
+func (f *FileResumeProxy) Seek(offset int64, whence int) (int64, error) {
+	return f.buffer.Seek(offset, whence)
+}
+
+func (f *FileResumeProxy) Close() error {
+	defer f.session.Close()
+	defer func() {
+		_ = f.buffer.Close()
+		_ = os.Remove(f.buffer.Name())
+	}()
+	dir, name := stdpath.Split(f.path)
+	size, err := f.buffer.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := f.buffer.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	arr := make([]byte, 512)
+	n, err := io.ReadFull(f.buffer, arr)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	contentType := http.DetectContentType(arr[:n])
+	if _, err := f.buffer.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     size,
+			Modified: time.Now(),
+		},
+		Mimetype:     contentType,
+		WebPutAsTask: false,
+		Reader:       io.LimitReader(f.buffer, size),
+	}
+	return fs.PutDirectly(f.ctx, dir, s)
+}
+
 type FileUploadWithLengthProxy struct {
 	ftpserver.FileTransfer
 	ctx           context.Context
@@ -150,6 +288,7 @@ type FileUploadWithLengthProxy struct {
 	pFirst        int
 	pipeWriter    io.WriteCloser
 	errChan       chan error
+	session       *traffic.Session
 }
 
 func OpenUploadWithLength(ctx context.Context, path string, trunc bool, length int64) (*FileUploadWithLengthProxy, error) {
@@ -162,10 +301,14 @@ func OpenUploadWithLength(ctx context.Context, path string, trunc bool, length i
 	if setting.GetBool(conf.IgnoreSystemFiles) && utils.IsSystemFile(name) {
 		return nil, errs.IgnoredSystemFile
 	}
+	session, err := traffic.Begin(ctx.Value(conf.UserKey).(*model.User), traffic.Upload)
+	if err != nil {
+		return nil, err
+	}
 	if trunc {
 		_ = fs.Remove(ctx, path)
 	}
-	return &FileUploadWithLengthProxy{ctx: ctx, path: path, length: length}, nil
+	return &FileUploadWithLengthProxy{ctx: ctx, path: path, length: length, session: session}, nil
 }
 
 func (f *FileUploadWithLengthProxy) Read(p []byte) (n int, err error) {
@@ -224,7 +367,10 @@ func (f *FileUploadWithLengthProxy) Write(p []byte) (n int, err error) {
 	if err != nil {
 		return n, err
 	}
-	err = stream.ClientUploadLimit.WaitN(f.ctx, n)
+	if err = stream.ClientUploadLimit.WaitN(f.ctx, n); err != nil {
+		return n, err
+	}
+	err = f.session.Wait(f.ctx, n)
 	return n, err
 }
 
@@ -233,6 +379,7 @@ func (f *FileUploadWithLengthProxy) Seek(offset int64, whence int) (int64, error
 }
 
 func (f *FileUploadWithLengthProxy) Close() error {
+	defer f.session.Close()
 	if f.pipeWriter != nil {
 		err := f.pipeWriter.Close()
 		if err != nil {
@@ -39,6 +39,8 @@ type UploadingFile struct {
 	softLinks   []patricia.Prefix
 	mvCallback  func(string)
 	rmCallback  func()
+	touched     bool
+	mtCallback  func(string, time.Time)
 }
 
 func (u *UploadingFile) SetRemoveCallback(rm func()) {
@@ -47,6 +49,12 @@ func (u *UploadingFile) SetRemoveCallback(rm func()) {
 	u.rmCallback = rm
 }
 
+func (u *UploadingFile) SetModTimeCallback(mt func(string, time.Time)) {
+	stageMutex.Lock()
+	defer stageMutex.Unlock()
+	u.mtCallback = mt
+}
+
 type softLink struct {
 	target *UploadingFile
 }
@@ -126,9 +134,21 @@ func drop(path patricia.Prefix) {
 			stage.Delete(sl)
 		}
 		stage.Delete(path)
-		if s.currentPath != string(path) {
-			if s.currentPath != "" {
-				go s.mvCallback(s.currentPath)
+		if s.currentPath != "" {
+			target, moved := s.currentPath, s.currentPath != string(path)
+			modified, mt := s.modTime, s.mtCallback
+			if !s.touched {
+				mt = nil
+			}
+			if moved || mt != nil {
+				go func() {
+					if moved {
+						s.mvCallback(target)
+					}
+					if mt != nil {
+						mt(target, modified)
+					}
+				}()
 			}
 		}
 	}
@@ -208,6 +228,28 @@ func MoveStage(from, to string) error {
 	return nil
 }
 
+// TouchStage sets the modification time of an uploading file, it is passed
+// on to the stored file once the upload ends.
+func TouchStage(path string, modified time.Time) error {
+	stageMutex.Lock()
+	defer stageMutex.Unlock()
+	prefix := patricia.Prefix(path)
+	v := stage.Get(prefix)
+	if v == nil {
+		return errs.ObjectNotFound
+	}
+	s, ok := v.(*UploadingFile)
+	if !ok {
+		s = v.(*softLink).target
+	}
+	if s.currentPath != path {
+		return ErrStageMoved
+	}
+	s.modTime = modified
+	s.touched = true
+	return nil
+}
+
 func RemoveStage(path string) error {
 	stageMutex.Lock()
 	defer stageMutex.Unlock()
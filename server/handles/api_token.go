@@ -0,0 +1,91 @@
+package handles
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type APITokenCreateReq struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	Paths     []string   `json:"paths"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APITokenCreateResp struct {
+	model.APIToken
+	// Token is only returned once, when the token is created
+	Token string `json:"token"`
+}
+
+func ListMyAPITokens(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	tokens, err := op.GetAPITokensByUserId(userObj.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tokens)
+}
+
+func CreateMyAPIToken(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req APITokenCreateReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		common.ErrorStrResp(c, "expires_at is in the past", 400)
+		return
+	}
+	if !userObj.IsAdmin() && utils.SliceContains(req.Scopes, model.TokenScopeAdminStorage) {
+		common.ErrorStrResp(c, "admin scopes need an admin user", 403)
+		return
+	}
+	t := &model.APIToken{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		Paths:     req.Paths,
+		ExpiresAt: req.ExpiresAt,
+	}
+	raw, err := op.CreateAPIToken(userObj, t)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, APITokenCreateResp{APIToken: *t, Token: raw})
+}
+
+func DeleteMyAPIToken(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	tokenId, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "id format invalid", 400)
+		return
+	}
+	if err = op.DeleteAPIToken(uint(tokenId), userObj.ID); err != nil {
+		common.ErrorStrResp(c, "failed to delete api token", 404)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,58 @@
+package handles
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type ListAuditLogsReq struct {
+	model.PageReq
+	Username string `json:"username" form:"username"`
+	Protocol string `json:"protocol" form:"protocol"`
+	Action   string `json:"action" form:"action"`
+	Path     string `json:"path" form:"path"`
+	Result   string `json:"result" form:"result"`
+	// Since and Until are unix seconds, zero leaves the range open
+	Since int64 `json:"since" form:"since"`
+	Until int64 `json:"until" form:"until"`
+}
+
+func ListAuditLogs(c *gin.Context) {
+	var req ListAuditLogsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	f := db.AuditLogFilter{
+		Username: req.Username,
+		Protocol: req.Protocol,
+		Action:   req.Action,
+		Result:   req.Result,
+	}
+	if req.Path != "" {
+		f.Path = utils.FixAndCleanPath(req.Path)
+	}
+	if req.Since > 0 {
+		since := time.Unix(req.Since, 0)
+		f.Since = &since
+	}
+	if req.Until > 0 {
+		until := time.Unix(req.Until, 0)
+		f.Until = &until
+	}
+	logs, total, err := db.GetAuditLogs(f, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
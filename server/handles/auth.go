@@ -8,6 +8,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pquerna/otp/totp"
@@ -82,7 +83,8 @@ func loginHash(c *gin.Context, req *LoginReq) {
 
 type UserResp struct {
 	model.User
-	Otp bool `json:"otp"`
+	Otp     bool           `json:"otp"`
+	Traffic *traffic.Stats `json:"traffic,omitempty"`
 }
 
 // CurrentUser get current user by token
@@ -93,9 +95,14 @@ func CurrentUser(c *gin.Context) {
 		User: *user,
 	}
 	userResp.Password = ""
+	// the bitmask is moved to grants by the migration, report what applies
+	userResp.Permission = user.PermissionAt(user.BasePath)
 	if userResp.OtpSecret != "" {
 		userResp.Otp = true
 	}
+	if stats, err := traffic.GetStats(user.ID); err == nil {
+		userResp.Traffic = stats
+	}
 	common.SuccessResp(c, userResp)
 }
 
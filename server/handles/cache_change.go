@@ -0,0 +1,37 @@
+package handles
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/cache"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListCacheChanges(c *gin.Context) {
+	var req struct {
+		model.PageReq
+		StorageID uint   `json:"storage_id" form:"storage_id" binding:"required"`
+		DirPath   string `json:"dir_path" form:"dir_path"`
+		Since     int64  `json:"since" form:"since"` // unix seconds, only newer changes are returned
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	var since time.Time
+	if req.Since > 0 {
+		since = time.Unix(req.Since, 0)
+	}
+	changes, total, err := cache.ListCacheChanges(req.StorageID, req.DirPath, since, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: changes,
+		Total:   total,
+	})
+}
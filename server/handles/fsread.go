@@ -1,7 +1,6 @@
 package handles
 
 import (
-	"context"
 	"fmt"
 	stdpath "path"
 	"strings"
@@ -15,12 +14,10 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
-	"github.com/OpenListTeam/OpenList/v4/pkg/generic"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 )
 
 type ListReq struct {
@@ -103,7 +100,7 @@ func FsList(c *gin.Context, req *ListReq, user *model.User) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
-	if !user.CanWrite() && !common.CanWrite(meta, reqPath) && req.Refresh {
+	if !user.CanAt(model.PermWrite, reqPath) && !common.CanWrite(meta, reqPath) && req.Refresh {
 		common.ErrorStrResp(c, "Refresh without permission", 403)
 		return
 	}
@@ -120,7 +117,7 @@ func FsList(c *gin.Context, req *ListReq, user *model.User) {
 	var directUploadTools []string
 	var mkdirConfig []driver.Item
 	var linkParse *driver.LinkParseConfig
-	if user.CanWrite() {
+	if user.CanAt(model.PermWrite, reqPath) {
 		if storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{}); err == nil {
 			directUploadTools = op.GetDirectUploadTools(storage)
 			provider = storage.Config().Name
@@ -135,7 +132,7 @@ func FsList(c *gin.Context, req *ListReq, user *model.User) {
 		Total:             int64(total),
 		Readme:            getReadme(meta, reqPath),
 		Header:            getHeader(meta, reqPath),
-		Write:             user.CanWrite() || common.CanWrite(meta, reqPath),
+		Write:             user.CanAt(model.PermWrite, reqPath) || common.CanWrite(meta, reqPath),
 		Provider:          provider,
 		DirectUploadTools: directUploadTools,
 		MkdirConfig:       mkdirConfig,
@@ -504,55 +501,19 @@ func FsRecurseList(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
-	if !user.CanWrite() && !common.CanWrite(meta, reqPath) && req.Refresh {
+	if !user.CanAt(model.PermWrite, reqPath) && !common.CanWrite(meta, reqPath) && req.Refresh {
 		common.ErrorStrResp(c, "Refresh without permission", 403)
 		return
 	}
 
-	go func() {
-		ctx := context.Background()
-		ctx = context.WithValue(ctx, conf.UserKey, user)
-		ctx = context.WithValue(ctx, conf.MetaKey, meta)
-
-		queue := generic.NewQueue[string]()
-		queue.Push(reqPath)
-
-		visited := make(map[string]bool)
-
-		for queue.Len() > 0 {
-			currentPath := queue.Pop()
-
-			if visited[currentPath] {
-				continue
-			}
-			visited[currentPath] = true
-
-			objs, err := fs.List(ctx, currentPath, &fs.ListArgs{
-				Refresh:            req.Refresh,
-				WithStorageDetails: false,
-				NoLog:              true,
-			})
-			if err != nil {
-				log.Warnf("FsRecurseList: failed to list %s: %+v", currentPath, err)
-				continue
-			}
-
-			for _, obj := range objs {
-				if obj.IsDir() {
-					subPath := stdpath.Join(currentPath, obj.GetName())
-					if !visited[subPath] {
-						queue.Push(subPath)
-					}
-				}
-			}
-
-			if req.IntervalSec > 0 && queue.Len() > 0 {
-				time.Sleep(time.Duration(req.IntervalSec) * time.Second)
-			}
-		}
-
-		log.Infof("FsRecurseList: completed recursion for path %s", reqPath)
-	}()
-
-	common.SuccessResp(c)
+	t := fs.ScanAsTask(c.Request.Context(), fs.ScanArgs{
+		Path:     reqPath,
+		Refresh:  req.Refresh,
+		Interval: time.Duration(req.IntervalSec) * time.Second,
+		Meta:     meta,
+		User:     user,
+	})
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
 }
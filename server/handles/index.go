@@ -105,5 +105,10 @@ func GetProgress(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
+	progress.QueueDepth, err = search.QueueDepth()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
 	common.SuccessResp(c, progress)
 }
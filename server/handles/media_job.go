@@ -0,0 +1,71 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/media_job"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListMediaJobs(c *gin.Context) {
+	var req struct {
+		StorageID uint `json:"storage_id" form:"storage_id"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	jobs, err := media_job.List(req.StorageID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, jobs)
+}
+
+func ListMediaJobRuns(c *gin.Context) {
+	var req struct {
+		ID    uint `json:"id" form:"id" binding:"required"`
+		Limit int  `json:"limit" form:"limit"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	runs, err := db.ListMediaJobRuns(req.ID, req.Limit)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, runs)
+}
+
+func RunMediaJob(c *gin.Context) {
+	mediaJobAction(c, media_job.RunNow)
+}
+
+func PauseMediaJob(c *gin.Context) {
+	mediaJobAction(c, media_job.Pause)
+}
+
+func ResumeMediaJob(c *gin.Context) {
+	mediaJobAction(c, media_job.Resume)
+}
+
+func mediaJobAction(c *gin.Context, action func(uint) error) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = action(uint(id)); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
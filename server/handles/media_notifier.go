@@ -0,0 +1,143 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/notifier"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListMediaNotifiers(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	notifiers, total, err := db.GetMediaNotifiers(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: notifiers,
+		Total:   total,
+	})
+}
+
+func ListMediaNotifierTypes(c *gin.Context) {
+	common.SuccessResp(c, notifier.TargetTypes())
+}
+
+func CreateMediaNotifier(c *gin.Context) {
+	var req model.MediaNotifier
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, err := notifier.NewTarget(req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ID = 0
+	if err := db.CreateMediaNotifier(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, req)
+}
+
+func UpdateMediaNotifier(c *gin.Context) {
+	var req model.MediaNotifier
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, err := notifier.NewTarget(req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, err := db.GetMediaNotifierById(req.ID); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.UpdateMediaNotifier(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteMediaNotifier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.DeleteMediaNotifierById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// TestMediaNotifier sends the given paths (or the notifier's PathPrefix)
+// to the notifier right away, without touching the delivery log.
+func TestMediaNotifier(c *gin.Context) {
+	var req struct {
+		ID    uint     `json:"id" form:"id"`
+		Paths []string `json:"paths" form:"paths"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	n, err := db.GetMediaNotifierById(req.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	paths := req.Paths
+	if len(paths) == 0 {
+		paths = []string{n.PathPrefix}
+	}
+	target, err := notifier.NewTarget(*n)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = target.Notify(c.Request.Context(), notifier.MapPaths(*n, paths)); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListMediaNotifyDeliveries(c *gin.Context) {
+	var req struct {
+		model.PageReq
+		NotifierID uint `json:"notifier_id" form:"notifier_id"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	deliveries, total, err := db.GetMediaNotifyDeliveries(req.NotifierID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: deliveries,
+		Total:   total,
+	})
+}
+
+func FlushMediaNotifiers(c *gin.Context) {
+	go notifier.Flush()
+	common.SuccessResp(c)
+}
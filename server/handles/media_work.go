@@ -0,0 +1,231 @@
+package handles
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/drivers/virtual_file"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListMediaWorks(c *gin.Context) {
+	var req struct {
+		model.PageReq
+		StorageID uint   `json:"storage_id" form:"storage_id"`
+		Source    string `json:"source" form:"source"`
+		Code      string `json:"code" form:"code"`
+		Actor     string `json:"actor" form:"actor"`
+		Tag       string `json:"tag" form:"tag"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	works, total, err := db.SearchFilmWorks(db.MediaWorkFilter{
+		StorageID: req.StorageID,
+		Source:    req.Source,
+		Code:      req.Code,
+		Actor:     req.Actor,
+		Tag:       req.Tag,
+	}, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: works,
+		Total:   total,
+	})
+}
+
+type MediaWorkResp struct {
+	Work    model.FilmWork       `json:"work"`
+	Files   []model.FilmFile     `json:"files"`
+	Magnets []model.SourceMagnet `json:"magnets"`
+}
+
+func GetMediaWork(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	work, err := db.GetFilmWork(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	files, err := db.ListFilmFiles(work.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	magnets, err := db.ListSourceMagnets(work.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, MediaWorkResp{Work: work, Files: files, Magnets: magnets})
+}
+
+type MagnetHealthResp struct {
+	model.SourceMagnet
+	Score       int  `json:"score"`
+	CoolingDown bool `json:"cooling_down"`
+}
+
+// ListMediaWorkMagnets lists the magnets of a work with their health, in
+// the order playback would try them.
+func ListMediaWorkMagnets(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	magnets, err := db.ListPlaybackSourceMagnets(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	now := time.Now()
+	ready, cooling := tool.SortMagnetsByHealth(magnets, now)
+	resp := make([]MagnetHealthResp, 0, len(ready)+len(cooling))
+	for _, magnet := range append(ready, cooling...) {
+		resp = append(resp, MagnetHealthResp{
+			SourceMagnet: magnet,
+			Score:        magnet.HealthScore(),
+			CoolingDown:  magnet.CoolingDown(now),
+		})
+	}
+	common.SuccessResp(c, resp)
+}
+
+type UpdateMediaWorkReq struct {
+	ID              uint    `json:"id" binding:"required"`
+	RawTitle        *string `json:"raw_title"`
+	TranslatedTitle *string `json:"translated_title"`
+	Synopsis        *string `json:"synopsis"`
+	ImageURL        *string `json:"image_url"`
+	// ReleaseDate is formatted as 2006-01-02, empty clears it
+	ReleaseDate *string            `json:"release_date"`
+	Actors      *model.StringArray `json:"actors"`
+	Tags        *model.StringArray `json:"tags"`
+	// LockedFields replaces the locks when set, otherwise every edited field gets locked
+	LockedFields *model.StringArray `json:"locked_fields"`
+	// WriteNfo rewrites the NFO right after the update
+	WriteNfo bool `json:"write_nfo"`
+}
+
+func UpdateMediaWork(c *gin.Context) {
+	var req UpdateMediaWorkReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	fields := make(map[string]interface{})
+	for field, value := range map[string]*string{
+		model.MediaFieldRawTitle:        req.RawTitle,
+		model.MediaFieldTranslatedTitle: req.TranslatedTitle,
+		model.MediaFieldSynopsis:        req.Synopsis,
+		model.MediaFieldImageURL:        req.ImageURL,
+	} {
+		if value != nil {
+			fields[field] = strings.TrimSpace(*value)
+		}
+	}
+	if req.ReleaseDate != nil {
+		var release time.Time
+		if date := strings.TrimSpace(*req.ReleaseDate); date != "" {
+			var err error
+			if release, err = time.Parse(time.DateOnly, date); err != nil {
+				common.ErrorResp(c, err, 400)
+				return
+			}
+		}
+		fields[model.MediaFieldReleaseDate] = release
+	}
+	if req.Actors != nil {
+		fields[model.MediaFieldActors] = compactStringArray(*req.Actors)
+	}
+	if req.Tags != nil {
+		fields[model.MediaFieldTags] = compactStringArray(*req.Tags)
+	}
+	if _, err := db.GetFilmWork(req.ID); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	work, err := db.EditMediaWork(req.ID, db.MediaWorkEdit{Fields: fields, Locks: req.LockedFields})
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.WriteNfo {
+		if err = virtual_file.RewriteMediaWorkNFO(work, mediaNFOIncludesCode(work)); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c, work)
+}
+
+func compactStringArray(values model.StringArray) model.StringArray {
+	result := make(model.StringArray, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" && !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func DeleteMediaWork(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := virtual_file.DeleteMediaWork(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// RewriteMediaWorkNFO writes the NFO of a work right away, the title
+// includes the code unless include_code says otherwise. Pornhub works
+// default to the plain title like the driver's NFO sync.
+func RewriteMediaWorkNFO(c *gin.Context) {
+	var req struct {
+		ID          uint  `json:"id" form:"id" binding:"required"`
+		IncludeCode *bool `json:"include_code" form:"include_code"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	work, err := db.GetFilmWork(req.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	includeCode := mediaNFOIncludesCode(work)
+	if req.IncludeCode != nil {
+		includeCode = *req.IncludeCode
+	}
+	if err = virtual_file.RewriteMediaWorkNFO(work, includeCode); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func mediaNFOIncludesCode(work model.FilmWork) bool {
+	return work.Source != "pornhub"
+}
@@ -438,7 +438,7 @@ type AddOfflineDownloadReq struct {
 
 func AddOfflineDownload(c *gin.Context) {
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	if !user.CanAddOfflineDownloadTasks() {
+	if !user.CanAnywhere(model.PermOfflineDownload) {
 		common.ErrorStrResp(c, "permission denied", 403)
 		return
 	}
@@ -453,6 +453,10 @@ func AddOfflineDownload(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.CanAt(model.PermOfflineDownload, reqPath) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
 	var tasks []task.TaskExtensionInfo
 	for _, url := range req.Urls {
 		// Filter out empty lines and whitespace-only strings
@@ -1,9 +1,12 @@
 package handles
 
 import (
+	"strings"
+
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/open_ai"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
 )
@@ -37,3 +40,45 @@ func SetOpenAi(c *gin.Context) {
 
 	common.SuccessResp(c, "ok")
 }
+
+type SetTranslatorReq struct {
+	TranslateProvider string `json:"translate_provider" form:"translate_provider"`
+	OllamaUrl         string `json:"ollama_url" form:"ollama_url"`
+	OllamaModel       string `json:"ollama_model" form:"ollama_model"`
+	DeepLUrl          string `json:"deepl_url" form:"deepl_url"`
+	DeepLApiKey       string `json:"deepl_api_key" form:"deepl_api_key"`
+	DeepLTargetLang   string `json:"deepl_target_lang" form:"deepl_target_lang"`
+}
+
+// SetTranslator selects the translation backend, the OpenAI compatible one
+// keeps its settings in SetOpenAi.
+func SetTranslator(c *gin.Context) {
+	var req SetTranslatorReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	switch req.TranslateProvider {
+	case "":
+		req.TranslateProvider = open_ai.ProviderOpenAI
+	case open_ai.ProviderOpenAI, open_ai.ProviderOllama, open_ai.ProviderDeepL:
+	default:
+		common.ErrorStrResp(c, "unknown translate provider: "+req.TranslateProvider, 400)
+		return
+	}
+
+	items := []model.SettingItem{
+		{Key: conf.TranslateProvider, Value: req.TranslateProvider, Type: conf.TypeSelect, Options: strings.Join([]string{open_ai.ProviderOpenAI, open_ai.ProviderOllama, open_ai.ProviderDeepL}, ","), Group: model.OpenAi, Flag: model.PRIVATE},
+		{Key: conf.OllamaUrl, Value: req.OllamaUrl, Type: conf.TypeString, Group: model.OpenAi, Flag: model.PRIVATE},
+		{Key: conf.OllamaModel, Value: req.OllamaModel, Type: conf.TypeString, Group: model.OpenAi, Flag: model.PRIVATE},
+		{Key: conf.DeepLUrl, Value: req.DeepLUrl, Type: conf.TypeString, Group: model.OpenAi, Flag: model.PRIVATE},
+		{Key: conf.DeepLApiKey, Value: req.DeepLApiKey, Type: conf.TypeString, Group: model.OpenAi, Flag: model.PRIVATE},
+		{Key: conf.DeepLTargetLang, Value: req.DeepLTargetLang, Type: conf.TypeString, Group: model.OpenAi, Flag: model.PRIVATE},
+	}
+	if err := op.SaveSettingItems(items); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+
+	common.SuccessResp(c, "ok")
+}
@@ -0,0 +1,227 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleResp struct {
+	model.Role
+	Actions []string `json:"actions"`
+}
+
+// RoleReq is a role, whose permission is the bitmask or'ed with the bits of
+// the given actions.
+type RoleReq struct {
+	model.Role
+	Actions []string `json:"actions"`
+}
+
+func (r *RoleReq) toRole() (*model.Role, error) {
+	perm, err := model.ActionsPermission(r.Actions)
+	if err != nil {
+		return nil, err
+	}
+	role := r.Role
+	role.Permission |= perm
+	return &role, nil
+}
+
+func ListRoles(c *gin.Context) {
+	roles, err := op.GetRoles()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := make([]RoleResp, 0, len(roles))
+	for _, r := range roles {
+		resp = append(resp, RoleResp{Role: r, Actions: model.PermissionActions(r.Permission)})
+	}
+	common.SuccessResp(c, resp)
+}
+
+func CreateRole(c *gin.Context) {
+	var req RoleReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	role, err := req.toRole()
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	role.ID = 0
+	if err := op.CreateRole(role); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, RoleResp{Role: *role, Actions: model.PermissionActions(role.Permission)})
+}
+
+func UpdateRole(c *gin.Context) {
+	var req RoleReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	role, err := req.toRole()
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.UpdateRole(role); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteRoleById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type UserGroupResp struct {
+	model.UserGroup
+	UserIds []uint `json:"user_ids"`
+}
+
+func ListUserGroups(c *gin.Context) {
+	groups, err := op.GetUserGroups()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := make([]UserGroupResp, 0, len(groups))
+	for _, g := range groups {
+		ids, err := op.GetUserGroupMemberIds(g.ID)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		resp = append(resp, UserGroupResp{UserGroup: g, UserIds: ids})
+	}
+	common.SuccessResp(c, resp)
+}
+
+func CreateUserGroup(c *gin.Context) {
+	var req model.UserGroup
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ID = 0
+	if err := op.CreateUserGroup(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, req)
+}
+
+func UpdateUserGroup(c *gin.Context) {
+	var req model.UserGroup
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.UpdateUserGroup(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteUserGroup(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteUserGroupById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type SetUserGroupMembersReq struct {
+	GroupId uint   `json:"group_id" binding:"required"`
+	UserIds []uint `json:"user_ids"`
+}
+
+func SetUserGroupMembers(c *gin.Context) {
+	var req SetUserGroupMembersReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.SetUserGroupMembers(req.GroupId, req.UserIds); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListPathGrants(c *gin.Context) {
+	userID, _ := strconv.Atoi(c.Query("user_id"))
+	groupID, _ := strconv.Atoi(c.Query("group_id"))
+	grants, err := op.GetPathGrants(uint(userID), uint(groupID))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, grants)
+}
+
+func CreatePathGrant(c *gin.Context) {
+	var req model.PathGrant
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ID = 0
+	if err := op.CreatePathGrant(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, req)
+}
+
+func UpdatePathGrant(c *gin.Context) {
+	var req model.PathGrant
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.UpdatePathGrant(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeletePathGrant(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeletePathGrantById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
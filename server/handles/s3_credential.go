@@ -0,0 +1,153 @@
+package handles
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/OpenListTeam/OpenList/v4/server/s3"
+	"github.com/gin-gonic/gin"
+)
+
+type S3CredentialCreateReq struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type S3CredentialCreateResp struct {
+	model.S3Credential
+	// SecretKey is only returned once, when the credential is created
+	SecretKey string `json:"secret_key"`
+}
+
+type S3PresignReq struct {
+	CredentialID uint   `json:"credential_id" binding:"required"`
+	Method       string `json:"method"`
+	Bucket       string `json:"bucket" binding:"required"`
+	Key          string `json:"key" binding:"required"`
+	// Expires is the validity in seconds, one hour by default
+	Expires int `json:"expires"`
+}
+
+func ListMyS3Credentials(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	creds, err := op.GetS3CredentialsByUserId(userObj.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, creds)
+}
+
+func CreateMyS3Credential(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req S3CredentialCreateReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	cred, err := op.CreateS3Credential(userObj, req.Name)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, S3CredentialCreateResp{S3Credential: *cred, SecretKey: cred.SecretKey})
+}
+
+func DeleteMyS3Credential(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	credId, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "id format invalid", 400)
+		return
+	}
+	if err = op.DeleteS3Credential(uint(credId), userObj.ID); err != nil {
+		common.ErrorStrResp(c, "failed to delete s3 credential", 404)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// PresignMyS3 returns a presigned GET or PUT URL of the S3 server, signed
+// with one of the user's credentials.
+func PresignMyS3(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	if !conf.Conf.S3.Enable {
+		common.ErrorStrResp(c, "S3 server is not enabled", 403)
+		return
+	}
+	var req S3PresignReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodPut {
+		common.ErrorStrResp(c, "method must be GET or PUT", 400)
+		return
+	}
+	if req.Expires == 0 {
+		req.Expires = 3600
+	}
+	if req.Expires < 0 || req.Expires > 7*24*3600 {
+		common.ErrorStrResp(c, "expires must be at most a week", 400)
+		return
+	}
+	cred, err := op.GetS3CredentialById(req.CredentialID)
+	if err != nil || cred.UserID != userObj.ID {
+		common.ErrorStrResp(c, "s3 credential not found", 404)
+		return
+	}
+	u, err := s3.Presign(req.Method, s3BaseURL(c), req.Bucket, strings.TrimPrefix(req.Key, "/"),
+		cred.AccessKey, cred.SecretKey, time.Duration(req.Expires)*time.Second)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"url":        u,
+		"expires_at": time.Now().Add(time.Duration(req.Expires) * time.Second),
+	})
+}
+
+// s3BaseURL is where clients reach the S3 server, under the api url or on
+// its own port of the requested host.
+func s3BaseURL(c *gin.Context) string {
+	if conf.Conf.S3.Port == -1 {
+		return common.GetApiUrl(c) + "/s3"
+	}
+	scheme := "http"
+	if conf.Conf.S3.SSL {
+		scheme = "https"
+	}
+	host := c.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(conf.Conf.S3.Port)))
+}
@@ -1,8 +1,9 @@
 package handles
 
 import (
-	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/OpenListTeam/tache"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,31 +18,83 @@ func StartManualScan(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.BeginManualScan(req.Path, req.Limit); err != nil {
-		common.ErrorResp(c, err, 400)
-		return
-	}
-	common.SuccessResp(c)
+	t := fs.ScanAsTask(c.Request.Context(), fs.ScanArgs{
+		Path:    req.Path,
+		Refresh: true,
+		Limit:   req.Limit,
+	})
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
 }
 
+func undoneScanTasks() []*fs.ScanTask {
+	return fs.ScanTaskManager.GetByState(tache.StatePending, tache.StateRunning, tache.StateWaitingRetry, tache.StateBeforeRetry)
+}
+
+// StopManualScan cancels the scan given by tid, or every running scan
 func StopManualScan(c *gin.Context) {
-	if !op.ManualScanRunning() {
+	if tid := c.Query("tid"); tid != "" {
+		if _, ok := fs.ScanTaskManager.GetByID(tid); !ok {
+			common.ErrorStrResp(c, "task not found", 404)
+			return
+		}
+		fs.ScanTaskManager.Cancel(tid)
+		common.SuccessResp(c)
+		return
+	}
+	tasks := undoneScanTasks()
+	if len(tasks) == 0 {
 		common.ErrorStrResp(c, "manual scan is not running", 400)
 		return
 	}
-	op.StopManualScan()
+	for _, t := range tasks {
+		fs.ScanTaskManager.Cancel(t.GetID())
+	}
 	common.SuccessResp(c)
 }
 
 type ManualScanResp struct {
+	fs.ScanProgress
+	TaskID   string `json:"task_id"`
 	ObjCount uint64 `json:"obj_count"`
 	IsDone   bool   `json:"is_done"`
 }
 
+// GetManualScanProgress reports the scan given by tid, or the latest scan
 func GetManualScanProgress(c *gin.Context) {
-	ret := ManualScanResp{
-		ObjCount: op.ScannedCount.Load(),
-		IsDone:   !op.ManualScanRunning(),
+	var t *fs.ScanTask
+	if tid := c.Query("tid"); tid != "" {
+		var ok bool
+		if t, ok = fs.ScanTaskManager.GetByID(tid); !ok {
+			common.ErrorStrResp(c, "task not found", 404)
+			return
+		}
+	} else {
+		for _, candidate := range fs.ScanTaskManager.GetAll() {
+			if t == nil || startedAfter(candidate, t) {
+				t = candidate
+			}
+		}
+	}
+	if t == nil {
+		common.SuccessResp(c, ManualScanResp{IsDone: true})
+		return
+	}
+	progress := t.Progress()
+	state := t.GetState()
+	common.SuccessResp(c, ManualScanResp{
+		ScanProgress: progress,
+		TaskID:       t.GetID(),
+		ObjCount:     progress.Objs,
+		IsDone:       argsContains(state, tache.StateSucceeded, tache.StateFailed, tache.StateCanceled),
+	})
+}
+
+func startedAfter(a, b *fs.ScanTask) bool {
+	if a.GetStartTime() == nil {
+		// pending tasks are newer than any started one
+		return b.GetStartTime() != nil
 	}
-	common.SuccessResp(c, ret)
+	return b.GetStartTime() != nil && a.GetStartTime().After(*b.GetStartTime())
 }
@@ -20,6 +20,11 @@ type SearchReq struct {
 	Password string `json:"password"`
 }
 
+type SearchPageResp struct {
+	common.PageResp
+	Facets []model.SearchFacet `json:"facets,omitempty"`
+}
+
 type SearchResp struct {
 	model.SearchNode
 	Type int `json:"type"`
@@ -63,10 +68,38 @@ func Search(c *gin.Context) {
 		}
 		filteredNodes = append(filteredNodes, node)
 	}
-	common.SuccessResp(c, common.PageResp{
+	resp := SearchPageResp{PageResp: common.PageResp{
 		Content: utils.MustSliceConvert(filteredNodes, nodeToSearchResp),
 		Total:   total,
-	})
+	}}
+	if req.Facets {
+		resp.Facets, err = search.Facets(c, req.SearchReq, func(p string) bool {
+			return canSearchIn(user, p, req.Password)
+		})
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c, resp)
+}
+
+// canSearchIn checks the directory p like the nodes of a search, and also
+// against its own meta, so a hidden or protected storage is not counted.
+func canSearchIn(user *model.User, p, password string) bool {
+	if !utils.IsSubPath(user.BasePath, p) {
+		return false
+	}
+	for _, metaPath := range []string{path.Dir(p), p} {
+		meta, err := op.GetNearestMeta(metaPath)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return false
+		}
+		if !common.CanAccess(user, meta, p, password) {
+			return false
+		}
+	}
+	return true
 }
 
 func nodeToSearchResp(node model.SearchNode) SearchResp {
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/audit"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -444,7 +445,7 @@ func UpdateSharing(c *gin.Context) {
 		}
 	} else {
 		user = reqUser
-		if !user.CanShare() {
+		if !user.CanAnywhere(model.PermShare) {
 			common.ErrorStrResp(c, "permission denied", 403)
 			return
 		}
@@ -452,7 +453,7 @@ func UpdateSharing(c *gin.Context) {
 	for i, s := range req.Files {
 		s = utils.FixAndCleanPath(s)
 		req.Files[i] = s
-		if !reqUser.IsAdmin() && !strings.HasPrefix(s, user.BasePath) {
+		if !reqUser.IsAdmin() && (!strings.HasPrefix(s, user.BasePath) || !user.CanAt(model.PermShare, s)) {
 			common.ErrorStrResp(c, fmt.Sprintf("permission denied to share path [%s]", s), 500)
 			return
 		}
@@ -476,7 +477,9 @@ func UpdateSharing(c *gin.Context) {
 	s.Readme = req.Readme
 	s.Remark = req.Remark
 	s.Creator = user
-	if err = op.UpdateSharing(s); err != nil {
+	err = op.UpdateSharing(s)
+	audit.Admin(c.Request.Context(), model.AuditShareUpdate, s.ID, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c, SharingResp{
@@ -508,7 +511,7 @@ func CreateSharing(c *gin.Context) {
 		}
 	} else {
 		user = reqUser
-		if !user.CanShare() || (!user.IsAdmin() && req.ID != "") {
+		if !user.CanAnywhere(model.PermShare) || (!user.IsAdmin() && req.ID != "") {
 			common.ErrorStrResp(c, "permission denied", 403)
 			return
 		}
@@ -516,7 +519,7 @@ func CreateSharing(c *gin.Context) {
 	for i, s := range req.Files {
 		s = utils.FixAndCleanPath(s)
 		req.Files[i] = s
-		if !reqUser.IsAdmin() && !strings.HasPrefix(s, user.BasePath) {
+		if !reqUser.IsAdmin() && (!strings.HasPrefix(s, user.BasePath) || !user.CanAt(model.PermShare, s)) {
 			common.ErrorStrResp(c, fmt.Sprintf("permission denied to share path [%s]", s), 500)
 			return
 		}
@@ -537,8 +540,9 @@ func CreateSharing(c *gin.Context) {
 		Files:   req.Files,
 		Creator: user,
 	}
-	var id string
-	if id, err = op.CreateSharing(s); err != nil {
+	id, err := op.CreateSharing(s)
+	audit.Admin(c.Request.Context(), model.AuditShareCreate, id, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		s.ID = id
@@ -558,7 +562,9 @@ func DeleteSharing(c *gin.Context) {
 		common.ErrorResp(c, err, 404)
 		return
 	}
-	if err = op.DeleteSharing(sid); err != nil {
+	err = op.DeleteSharing(sid)
+	audit.Admin(c.Request.Context(), model.AuditShareDelete, sid, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -575,7 +581,9 @@ func SetEnableSharing(disable bool) func(ctx *gin.Context) {
 			return
 		}
 		s.Disabled = disable
-		if err = op.UpdateSharing(s, true); err != nil {
+		err = op.UpdateSharing(s, true)
+		audit.Admin(c.Request.Context(), model.AuditShareUpdate, sid, err)
+		if err != nil {
 			common.ErrorResp(c, err, 500)
 		} else {
 			common.SuccessResp(c)
@@ -7,13 +7,15 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
 )
 
 type SSHKeyAddReq struct {
-	Title string `json:"title" binding:"required"`
-	Key   string `json:"key" binding:"required"`
+	Title string   `json:"title" binding:"required"`
+	Key   string   `json:"key" binding:"required"`
+	Paths []string `json:"paths"`
 }
 
 func AddMyPublicKey(c *gin.Context) {
@@ -31,10 +33,14 @@ func AddMyPublicKey(c *gin.Context) {
 		common.ErrorStrResp(c, "request invalid", 400)
 		return
 	}
+	for i, p := range req.Paths {
+		req.Paths[i] = utils.FixAndCleanPath(p)
+	}
 	key := &model.SSHPublicKey{
 		Title:  req.Title,
 		KeyStr: strings.TrimSpace(req.Key),
 		UserId: userObj.ID,
+		Paths:  req.Paths,
 	}
 	err, parsed := op.CreateSSHPublicKey(key)
 	if !parsed {
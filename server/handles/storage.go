@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/audit"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
@@ -96,7 +97,9 @@ func CreateStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if id, err := op.CreateStorage(c.Request.Context(), req); err != nil {
+	id, err := op.CreateStorage(c.Request.Context(), req)
+	audit.Admin(c.Request.Context(), model.AuditStorageCreate, req.MountPath, err)
+	if err != nil {
 		common.ErrorWithDataResp(c, err, 500, gin.H{
 			"id": id,
 		}, true)
@@ -113,7 +116,9 @@ func UpdateStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.UpdateStorage(c.Request.Context(), req); err != nil {
+	err := op.UpdateStorage(c.Request.Context(), req)
+	audit.Admin(c.Request.Context(), model.AuditStorageUpdate, req.MountPath, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -127,7 +132,10 @@ func DeleteStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DeleteStorageById(c.Request.Context(), uint(id)); err != nil {
+	target := storageTarget(uint(id))
+	err = op.DeleteStorageById(c.Request.Context(), uint(id))
+	audit.Admin(c.Request.Context(), model.AuditStorageDelete, target, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -141,7 +149,10 @@ func DisableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DisableStorage(c.Request.Context(), uint(id)); err != nil {
+	target := storageTarget(uint(id))
+	err = op.DisableStorage(c.Request.Context(), uint(id))
+	audit.Admin(c.Request.Context(), model.AuditStorageDisable, target, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -155,13 +166,24 @@ func EnableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.EnableStorage(c.Request.Context(), uint(id)); err != nil {
+	target := storageTarget(uint(id))
+	err = op.EnableStorage(c.Request.Context(), uint(id))
+	audit.Admin(c.Request.Context(), model.AuditStorageEnable, target, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
 	common.SuccessResp(c)
 }
 
+// storageTarget names a storage in the audit log by its mount path.
+func storageTarget(id uint) string {
+	if storage, err := db.GetStorageById(id); err == nil {
+		return storage.MountPath
+	}
+	return strconv.Itoa(int(id))
+}
+
 func GetStorage(c *gin.Context) {
 	idStr := c.Query("id")
 	id, err := strconv.Atoi(idStr)
@@ -4,6 +4,9 @@ import (
 	"math"
 	"time"
 
+	"github.com/OpenListTeam/OpenList/v4/drivers/chunk"
+	"github.com/OpenListTeam/OpenList/v4/drivers/crypt"
+	"github.com/OpenListTeam/OpenList/v4/drivers/strm"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
@@ -77,6 +80,13 @@ func getUserInfo(c *gin.Context) (bool, uint, bool) {
 	}
 }
 
+// isCreator tells whether the user uid created t, tasks run by the server
+// itself have no creator.
+func isCreator[T task.TaskExtensionInfo](t T, uid uint) bool {
+	creator := t.GetCreator()
+	return creator != nil && creator.ID == uid
+}
+
 func getTargetedHandler[T task.TaskExtensionInfo](manager task.Manager[T], callback func(c *gin.Context, task T)) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		isAdmin, uid, ok := getUserInfo(c)
@@ -90,7 +100,7 @@ func getTargetedHandler[T task.TaskExtensionInfo](manager task.Manager[T], callb
 			common.ErrorStrResp(c, "task not found", 404)
 			return
 		}
-		if !isAdmin && uid != t.GetCreator().ID {
+		if !isAdmin && !isCreator(t, uid) {
 			// to avoid an attacker using error messages to guess valid TID, return a 404 rather than a 403
 			common.ErrorStrResp(c, "task not found", 404)
 			return
@@ -114,7 +124,7 @@ func getBatchHandler[T task.TaskExtensionInfo](manager task.Manager[T], callback
 		retErrs := make(map[string]string)
 		for _, tid := range tids {
 			t, ok := manager.GetByID(tid)
-			if !ok || (!isAdmin && uid != t.GetCreator().ID) {
+			if !ok || (!isAdmin && !isCreator(t, uid)) {
 				retErrs[tid] = "task not found"
 				continue
 			}
@@ -134,7 +144,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 		}
 		common.SuccessResp(c, getTaskInfos(manager.GetByCondition(func(task T) bool {
 			// avoid directly passing the user object into the function to reduce closure size
-			return (isAdmin || uid == task.GetCreator().ID) &&
+			return (isAdmin || isCreator(task, uid)) &&
 				argsContains(task.GetState(), tache.StatePending, tache.StateRunning, tache.StateCanceling,
 					tache.StateErrored, tache.StateFailing, tache.StateWaitingRetry, tache.StateBeforeRetry)
 		})))
@@ -147,7 +157,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 			return
 		}
 		common.SuccessResp(c, getTaskInfos(manager.GetByCondition(func(task T) bool {
-			return (isAdmin || uid == task.GetCreator().ID) &&
+			return (isAdmin || isCreator(task, uid)) &&
 				argsContains(task.GetState(), tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)
 		})))
 	})
@@ -183,7 +193,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 			return
 		}
 		manager.RemoveByCondition(func(task T) bool {
-			return (isAdmin || uid == task.GetCreator().ID) &&
+			return (isAdmin || isCreator(task, uid)) &&
 				argsContains(task.GetState(), tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)
 		})
 		common.SuccessResp(c)
@@ -196,7 +206,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 			return
 		}
 		manager.RemoveByCondition(func(task T) bool {
-			return (isAdmin || uid == task.GetCreator().ID) && task.GetState() == tache.StateSucceeded
+			return (isAdmin || isCreator(task, uid)) && task.GetState() == tache.StateSucceeded
 		})
 		common.SuccessResp(c)
 	})
@@ -208,7 +218,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 			return
 		}
 		tasks := manager.GetByCondition(func(task T) bool {
-			return (isAdmin || uid == task.GetCreator().ID) && task.GetState() == tache.StateFailed
+			return (isAdmin || isCreator(task, uid)) && task.GetState() == tache.StateFailed
 		})
 		for _, t := range tasks {
 			manager.Retry(t.GetID())
@@ -217,6 +227,15 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 	})
 }
 
+// walkTaskRoute adds the routes of taskRoute and one at path responding
+// with the progress of a task.
+func walkTaskRoute[T task.TaskExtensionInfo, P any](g *gin.RouterGroup, manager task.Manager[T], path string, progress func(T) P) {
+	taskRoute(g, manager)
+	g.POST(path, getTargetedHandler(manager, func(c *gin.Context, task T) {
+		common.SuccessResp(c, progress(task))
+	}))
+}
+
 func SetupTaskRoute(g *gin.RouterGroup) {
 	taskRoute(g.Group("/upload"), fs.UploadTaskManager)
 	taskRoute(g.Group("/copy"), fs.CopyTaskManager)
@@ -225,4 +244,9 @@ func SetupTaskRoute(g *gin.RouterGroup) {
 	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
 	taskRoute(g.Group("/decompress"), fs.ArchiveDownloadTaskManager)
 	taskRoute(g.Group("/decompress_upload"), fs.ArchiveContentUploadTaskManager)
+	walkTaskRoute(g.Group("/scan"), fs.ScanTaskManager, "/progress", (*fs.ScanTask).Progress)
+	walkTaskRoute(g.Group("/verify"), fs.VerifyTaskManager, "/report", (*fs.VerifyTask).Report)
+	walkTaskRoute(g.Group("/crypt_rotate"), crypt.RotateTaskManager, "/progress", (*crypt.RotateTask).Progress)
+	walkTaskRoute(g.Group("/chunk_gc"), chunk.GCTaskManager, "/progress", (*chunk.GCTask).Progress)
+	walkTaskRoute(g.Group("/strm_export"), strm.ExportTaskManager, "/report", (*strm.ExportTask).Progress)
 }
@@ -0,0 +1,93 @@
+package handles
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListTrafficLimits(c *gin.Context) {
+	limits, err := op.GetTrafficLimits()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, limits)
+}
+
+func CreateTrafficLimit(c *gin.Context) {
+	var req model.TrafficLimit
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ID = 0
+	if err := op.CreateTrafficLimit(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, req)
+}
+
+func UpdateTrafficLimit(c *gin.Context) {
+	var req model.TrafficLimit
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.UpdateTrafficLimit(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteTrafficLimit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteTrafficLimitById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type TrafficUsageResp struct {
+	model.TrafficUsage
+	Username string `json:"username"`
+	Streams  int    `json:"streams"`
+}
+
+// ListTrafficUsage lists what every user transferred on a day, today by
+// default, with the streams running now.
+func ListTrafficUsage(c *gin.Context) {
+	day := c.Query("day")
+	if day == "" {
+		day = time.Now().Format("2006-01-02")
+	}
+	traffic.Flush()
+	usages, err := db.GetTrafficUsages(day)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	streams := traffic.Streams()
+	resp := make([]TrafficUsageResp, 0, len(usages))
+	for _, u := range usages {
+		r := TrafficUsageResp{TrafficUsage: u, Streams: streams[u.UserID]}
+		if user, err := op.GetUserById(u.UserID); err == nil {
+			r.Username = user.Username
+		}
+		resp = append(resp, r)
+	}
+	common.SuccessResp(c, resp)
+}
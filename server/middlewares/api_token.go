@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	stdpath "path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+var fsReadRoutes = []string{
+	"/fs/list", "/fs/get", "/fs/dirs", "/fs/search", "/fs/recurse_list",
+	"/fs/archive/meta", "/fs/archive/list",
+}
+
+// apiTokenScope returns the scope an API token needs for a route of the
+// api group, ok is false for routes no token may call.
+func apiTokenScope(fullPath string) (scope string, ok bool) {
+	route := strings.TrimPrefix(fullPath, stdpath.Join(conf.URL.Path, "/api"))
+	switch {
+	case route == "/me":
+		return "", true
+	case utils.SliceContains(fsReadRoutes, route):
+		return model.TokenScopeFsRead, true
+	case strings.HasPrefix(route, "/fs/"):
+		return model.TokenScopeFsWrite, true
+	case strings.HasPrefix(route, "/admin/storage/"), strings.HasPrefix(route, "/admin/driver/"):
+		return model.TokenScopeAdminStorage, true
+	case strings.HasPrefix(route, "/task/"), strings.HasPrefix(route, "/admin/task/"):
+		return model.TokenScopeTasks, true
+	}
+	return "", false
+}
+
+// apiTokenAuth authenticates a personal API token and checks the matched
+// route is within the token's scopes.
+func apiTokenAuth(c *gin.Context, token string) {
+	user, t, err := op.AuthByAPIToken(token)
+	if err != nil {
+		common.ErrorResp(c, err, 401)
+		c.Abort()
+		return
+	}
+	scope, ok := apiTokenScope(c.FullPath())
+	if !ok || (scope != "" && !t.HasScope(scope)) {
+		common.ErrorStrResp(c, "api token is not allowed to call this api", 403)
+		c.Abort()
+		return
+	}
+	common.GinWithValue(c, conf.UserKey, user)
+	log.Debugf("use api token %d of user: %s", t.ID, user.Username)
+	c.Next()
+}
@@ -0,0 +1,13 @@
+package middlewares
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/audit"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditSource tags API requests so the writes they make are audited.
+func AuditSource(c *gin.Context) {
+	c.Request = c.Request.WithContext(audit.WithSource(c.Request.Context(), model.AuditProtocolHTTP, c.ClientIP()))
+	c.Next()
+}
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// Traffic applies the limits of the request's user to what the request
+// uploads or downloads. Requests without a user are not limited, the guest
+// is limited per client IP.
+func Traffic(c *gin.Context) {
+	user, _ := c.Request.Context().Value(conf.UserKey).(*model.User)
+	trafficLimit(c, user)
+}
+
+// DownTraffic is Traffic for the download routes, which do not log in.
+// A login or API token sent along picks the user, anything else counts as
+// the guest, share viewers included, and is limited per client IP.
+func DownTraffic(c *gin.Context) {
+	trafficLimit(c, downUser(c))
+}
+
+func downUser(c *gin.Context) *model.User {
+	token := c.GetHeader("Authorization")
+	if token != "" {
+		if model.IsAPIToken(token) {
+			if user, _, err := op.AuthByAPIToken(token); err == nil {
+				return user
+			}
+		} else if claims, err := common.ParseToken(token); err == nil {
+			if user, err := op.GetUserByName(claims.Username); err == nil && user.PwdTS == claims.PwdTS {
+				return user
+			}
+		}
+	}
+	guest, _ := op.GetGuest()
+	return guest
+}
+
+func trafficLimit(c *gin.Context, user *model.User) {
+	var dir traffic.Direction
+	switch c.Request.Method {
+	case http.MethodGet:
+		dir = traffic.Download
+	case http.MethodPut, http.MethodPost:
+		dir = traffic.Upload
+	default:
+		c.Next()
+		return
+	}
+	session, err := traffic.BeginClient(user, c.ClientIP(), dir)
+	if err != nil {
+		code := 500
+		if errors.Is(err, errs.TrafficQuotaExceeded) || errors.Is(err, errs.TooManyStreams) {
+			code = 429
+		}
+		common.ErrorStrResp(c, err.Error(), code)
+		c.Abort()
+		return
+	}
+	defer session.Close()
+	if dir == traffic.Download {
+		c.Writer = &ResponseWriterWrapper{
+			ResponseWriter: c.Writer,
+			WrapWriter:     &traffic.Writer{Writer: c.Writer, Session: session, Ctx: c},
+		}
+	} else {
+		c.Request.Body = &traffic.Reader{Reader: c.Request.Body, Session: session, Ctx: c}
+	}
+	c.Next()
+}
@@ -44,28 +44,28 @@ func Init(e *gin.Engine) {
 
 	downloadLimiter := middlewares.DownloadRateLimiter(stream.ClientDownloadLimit)
 	signCheck := middlewares.Down(sign.Verify)
-	g.GET("/d/*path", middlewares.PathParse, signCheck, downloadLimiter, handles.Down)
-	g.GET("/p/*path", middlewares.PathParse, signCheck, downloadLimiter, handles.Proxy)
+	g.GET("/d/*path", middlewares.PathParse, signCheck, downloadLimiter, middlewares.DownTraffic, handles.Down)
+	g.GET("/p/*path", middlewares.PathParse, signCheck, downloadLimiter, middlewares.DownTraffic, handles.Proxy)
 	g.HEAD("/d/*path", middlewares.PathParse, signCheck, handles.Down)
 	g.HEAD("/p/*path", middlewares.PathParse, signCheck, handles.Proxy)
 	archiveSignCheck := middlewares.Down(sign.VerifyArchive)
-	g.GET("/ad/*path", middlewares.PathParse, archiveSignCheck, downloadLimiter, handles.ArchiveDown)
-	g.GET("/ap/*path", middlewares.PathParse, archiveSignCheck, downloadLimiter, handles.ArchiveProxy)
-	g.GET("/ae/*path", middlewares.PathParse, archiveSignCheck, downloadLimiter, handles.ArchiveInternalExtract)
+	g.GET("/ad/*path", middlewares.PathParse, archiveSignCheck, downloadLimiter, middlewares.DownTraffic, handles.ArchiveDown)
+	g.GET("/ap/*path", middlewares.PathParse, archiveSignCheck, downloadLimiter, middlewares.DownTraffic, handles.ArchiveProxy)
+	g.GET("/ae/*path", middlewares.PathParse, archiveSignCheck, downloadLimiter, middlewares.DownTraffic, handles.ArchiveInternalExtract)
 	g.HEAD("/ad/*path", middlewares.PathParse, archiveSignCheck, handles.ArchiveDown)
 	g.HEAD("/ap/*path", middlewares.PathParse, archiveSignCheck, handles.ArchiveProxy)
 	g.HEAD("/ae/*path", middlewares.PathParse, archiveSignCheck, handles.ArchiveInternalExtract)
 
-	g.GET("/sd/:sid", middlewares.EmptyPathParse, middlewares.SharingIdParse, downloadLimiter, handles.SharingDown)
-	g.GET("/sd/:sid/*path", middlewares.PathParse, middlewares.SharingIdParse, downloadLimiter, handles.SharingDown)
+	g.GET("/sd/:sid", middlewares.EmptyPathParse, middlewares.SharingIdParse, downloadLimiter, middlewares.DownTraffic, handles.SharingDown)
+	g.GET("/sd/:sid/*path", middlewares.PathParse, middlewares.SharingIdParse, downloadLimiter, middlewares.DownTraffic, handles.SharingDown)
 	g.HEAD("/sd/:sid", middlewares.EmptyPathParse, middlewares.SharingIdParse, handles.SharingDown)
 	g.HEAD("/sd/:sid/*path", middlewares.PathParse, middlewares.SharingIdParse, handles.SharingDown)
-	g.GET("/sad/:sid", middlewares.EmptyPathParse, middlewares.SharingIdParse, downloadLimiter, handles.SharingArchiveExtract)
-	g.GET("/sad/:sid/*path", middlewares.PathParse, middlewares.SharingIdParse, downloadLimiter, handles.SharingArchiveExtract)
+	g.GET("/sad/:sid", middlewares.EmptyPathParse, middlewares.SharingIdParse, downloadLimiter, middlewares.DownTraffic, handles.SharingArchiveExtract)
+	g.GET("/sad/:sid/*path", middlewares.PathParse, middlewares.SharingIdParse, downloadLimiter, middlewares.DownTraffic, handles.SharingArchiveExtract)
 	g.HEAD("/sad/:sid", middlewares.EmptyPathParse, middlewares.SharingIdParse, handles.SharingArchiveExtract)
 	g.HEAD("/sad/:sid/*path", middlewares.PathParse, middlewares.SharingIdParse, handles.SharingArchiveExtract)
 
-	api := g.Group("/api")
+	api := g.Group("/api", middlewares.AuditSource)
 	auth := api.Group("", middlewares.Auth(false))
 	webauthn := api.Group("/authn", middlewares.Authn)
 
@@ -77,6 +77,13 @@ func Init(e *gin.Engine) {
 	auth.GET("/me/sshkey/list", handles.ListMyPublicKey)
 	auth.POST("/me/sshkey/add", handles.AddMyPublicKey)
 	auth.POST("/me/sshkey/delete", handles.DeleteMyPublicKey)
+	auth.GET("/me/tokens/list", handles.ListMyAPITokens)
+	auth.POST("/me/tokens/create", handles.CreateMyAPIToken)
+	auth.POST("/me/tokens/delete", handles.DeleteMyAPIToken)
+	auth.GET("/me/s3/list", handles.ListMyS3Credentials)
+	auth.POST("/me/s3/create", handles.CreateMyS3Credential)
+	auth.POST("/me/s3/delete", handles.DeleteMyS3Credential)
+	auth.POST("/me/s3/presign", handles.PresignMyS3)
 	auth.POST("/auth/2fa/generate", handles.Generate2FA)
 	auth.POST("/auth/2fa/verify", handles.Verify2FA)
 	auth.GET("/auth/logout", handles.LogOut)
@@ -133,6 +140,25 @@ func admin(g *gin.RouterGroup) {
 	user.GET("/sshkey/list", handles.ListPublicKeys)
 	user.POST("/sshkey/delete", handles.DeletePublicKey)
 
+	role := g.Group("/role")
+	role.GET("/list", handles.ListRoles)
+	role.POST("/create", handles.CreateRole)
+	role.POST("/update", handles.UpdateRole)
+	role.POST("/delete", handles.DeleteRole)
+
+	group := g.Group("/group")
+	group.GET("/list", handles.ListUserGroups)
+	group.POST("/create", handles.CreateUserGroup)
+	group.POST("/update", handles.UpdateUserGroup)
+	group.POST("/delete", handles.DeleteUserGroup)
+	group.POST("/members", handles.SetUserGroupMembers)
+
+	grant := g.Group("/grant")
+	grant.GET("/list", handles.ListPathGrants)
+	grant.POST("/create", handles.CreatePathGrant)
+	grant.POST("/update", handles.UpdatePathGrant)
+	grant.POST("/delete", handles.DeletePathGrant)
+
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
 	storage.GET("/get", handles.GetStorage)
@@ -142,6 +168,7 @@ func admin(g *gin.RouterGroup) {
 	storage.POST("/enable", handles.EnableStorage)
 	storage.POST("/disable", handles.DisableStorage)
 	storage.POST("/load_all", handles.LoadAllStorages)
+	storage.GET("/cache_changes", handles.ListCacheChanges)
 
 	driver := g.Group("/driver")
 	driver.GET("/list", handles.ListDriverInfo)
@@ -164,6 +191,7 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/set_pikpak", handles.SetPikPak)
 	setting.POST("/set_thunder", handles.SetThunder)
 	setting.POST("/set_openai", handles.SetOpenAi)
+	setting.POST("/set_translator", handles.SetTranslator)
 	setting.POST("/set_thunderx", handles.SetThunderX)
 	setting.POST("/set_thunder_browser", handles.SetThunderBrowser)
 
@@ -181,6 +209,41 @@ func admin(g *gin.RouterGroup) {
 	index.POST("/clear", middlewares.SearchIndex, handles.ClearIndex)
 	index.GET("/progress", middlewares.SearchIndex, handles.GetProgress)
 
+	mediaNotifier := g.Group("/media/notifier")
+	mediaNotifier.GET("/list", handles.ListMediaNotifiers)
+	mediaNotifier.GET("/types", handles.ListMediaNotifierTypes)
+	mediaNotifier.POST("/create", handles.CreateMediaNotifier)
+	mediaNotifier.POST("/update", handles.UpdateMediaNotifier)
+	mediaNotifier.POST("/delete", handles.DeleteMediaNotifier)
+	mediaNotifier.POST("/test", handles.TestMediaNotifier)
+	mediaNotifier.POST("/flush", handles.FlushMediaNotifiers)
+	mediaNotifier.GET("/deliveries", handles.ListMediaNotifyDeliveries)
+
+	mediaWork := g.Group("/media/works")
+	mediaWork.GET("/list", handles.ListMediaWorks)
+	mediaWork.GET("/get", handles.GetMediaWork)
+	mediaWork.GET("/magnets", handles.ListMediaWorkMagnets)
+	mediaWork.POST("/update", handles.UpdateMediaWork)
+	mediaWork.POST("/delete", handles.DeleteMediaWork)
+	mediaWork.POST("/nfo", handles.RewriteMediaWorkNFO)
+
+	mediaJob := g.Group("/media/jobs")
+	mediaJob.GET("/list", handles.ListMediaJobs)
+	mediaJob.GET("/runs", handles.ListMediaJobRuns)
+	mediaJob.POST("/run", handles.RunMediaJob)
+	mediaJob.POST("/pause", handles.PauseMediaJob)
+	mediaJob.POST("/resume", handles.ResumeMediaJob)
+
+	trafficLimit := g.Group("/traffic/limit")
+	trafficLimit.GET("/list", handles.ListTrafficLimits)
+	trafficLimit.POST("/create", handles.CreateTrafficLimit)
+	trafficLimit.POST("/update", handles.UpdateTrafficLimit)
+	trafficLimit.POST("/delete", handles.DeleteTrafficLimit)
+	g.GET("/traffic/usage", handles.ListTrafficUsage)
+
+	auditLog := g.Group("/audit")
+	auditLog.GET("/list", handles.ListAuditLogs)
+
 	scan := g.Group("/scan")
 	scan.POST("/start", handles.StartManualScan)
 	scan.POST("/stop", handles.StopManualScan)
@@ -209,8 +272,8 @@ func _fs(g *gin.RouterGroup) {
 	g.POST("/remove", handles.FsRemove)
 	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
 	uploadLimiter := middlewares.UploadRateLimiter(stream.ClientUploadLimit)
-	g.PUT("/put", middlewares.FsUp, uploadLimiter, handles.FsStream)
-	g.PUT("/form", middlewares.FsUp, uploadLimiter, handles.FsForm)
+	g.PUT("/put", middlewares.FsUp, middlewares.Traffic, uploadLimiter, handles.FsStream)
+	g.PUT("/form", middlewares.FsUp, middlewares.Traffic, uploadLimiter, handles.FsForm)
 	g.POST("/link", middlewares.AuthAdmin, handles.Link)
 	// g.POST("/add_aria2", handles.AddOfflineDownload)
 	// g.POST("/add_qbit", handles.AddQbittorrent)
@@ -5,8 +5,11 @@ import (
 	"path"
 	"strings"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/audit"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/OpenListTeam/OpenList/v4/server/middlewares"
 	"github.com/OpenListTeam/OpenList/v4/server/s3"
 	"github.com/gin-gonic/gin"
 )
@@ -26,7 +29,7 @@ func S3(g *gin.RouterGroup) {
 	}
 	h, _ := s3.NewServer(context.Background())
 
-	g.Any("/*path", func(c *gin.Context) {
+	g.Any("/*path", s3Auth, middlewares.Traffic, func(c *gin.Context) {
 		adjustedPath := strings.TrimPrefix(c.Request.URL.Path, path.Join(conf.URL.Path, "/s3"))
 		c.Request.URL.Path = adjustedPath
 		gin.WrapH(h)(c)
@@ -35,5 +38,23 @@ func S3(g *gin.RouterGroup) {
 
 func S3Server(g *gin.RouterGroup) {
 	h, _ := s3.NewServer(context.Background())
-	g.Any("/*path", gin.WrapH(h))
+	g.Any("/*path", s3Auth, middlewares.Traffic, gin.WrapH(h))
+}
+
+// s3Auth tags S3 requests for the audit log and checks their signature,
+// acting as the user the access key belongs to. It runs before the path
+// is adjusted since the signature covers the original one.
+func s3Auth(c *gin.Context) {
+	ctx := audit.WithSource(c.Request.Context(), model.AuditProtocolS3, c.ClientIP())
+	c.Request = c.Request.WithContext(ctx)
+	user, err := s3.Authenticate(c.Request)
+	if err != nil {
+		s3.WriteError(c.Writer, c.Request, err)
+		c.Abort()
+		return
+	}
+	if user != nil {
+		c.Request = c.Request.WithContext(context.WithValue(ctx, conf.UserKey, user))
+	}
+	c.Next()
 }
@@ -0,0 +1,210 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/itsHenry35/gofakes3"
+	"github.com/itsHenry35/gofakes3/signature"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxPresignExpires is the longest validity S3 allows a presigned URL.
+const maxPresignExpires = 7 * 24 * 60 * 60
+
+// keyring maps every access key to the id of the user it acts as, 0 for
+// the global key of the settings, and to its secret key.
+var keyring struct {
+	sync.Mutex
+	users   map[string]uint
+	secrets map[string]string
+}
+
+func init() {
+	op.RegisterSettingChangingCallback(resetKeys)
+	op.RegisterS3CredentialChangingCallback(resetKeys)
+}
+
+func resetKeys() {
+	keyring.Lock()
+	keyring.users = nil
+	keyring.secrets = nil
+	keyring.Unlock()
+}
+
+// keys loads the access keys into the signature checker on first use after
+// a change.
+func keys() (map[string]uint, error) {
+	keyring.Lock()
+	defer keyring.Unlock()
+	if keyring.users != nil {
+		return keyring.users, nil
+	}
+	pairs := make(map[string]string)
+	users := make(map[string]uint)
+	if global := authlistResolver(); global != nil {
+		for ak, sk := range global {
+			pairs[ak] = sk
+			users[ak] = 0
+		}
+	}
+	creds, err := op.GetS3Credentials()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range creds {
+		pairs[c.AccessKey] = c.SecretKey
+		users[c.AccessKey] = c.UserID
+	}
+	signature.ReloadKeys(pairs)
+	keyring.users = users
+	keyring.secrets = pairs
+	return users, nil
+}
+
+// secretOf returns the secret key of an access key.
+func secretOf(ak string) (string, bool) {
+	if _, err := keys(); err != nil {
+		return "", false
+	}
+	keyring.Lock()
+	defer keyring.Unlock()
+	secret, ok := keyring.secrets[ak]
+	return secret, ok
+}
+
+// accessKeyOf reads the access key a request is signed with, from either
+// the Authorization header or the query of a presigned URL.
+func accessKeyOf(r *http.Request) string {
+	q := r.URL.Query()
+	if cred := q.Get("X-Amz-Credential"); cred != "" {
+		ak, _, _ := strings.Cut(cred, "/")
+		return ak
+	}
+	if ak := q.Get("AWSAccessKeyId"); ak != "" {
+		return ak
+	}
+	auth := r.Header.Get("Authorization")
+	if _, cred, ok := strings.Cut(auth, "Credential="); ok {
+		ak, _, _ := strings.Cut(cred, "/")
+		return strings.TrimSpace(ak)
+	}
+	if v2, ok := strings.CutPrefix(auth, "AWS "); ok {
+		ak, _, _ := strings.Cut(v2, ":")
+		return ak
+	}
+	return ""
+}
+
+// defaultUser is the user the global key acts as, nil when the s3 user
+// setting is empty, which leaves the key unrestricted.
+func defaultUser() (*model.User, error) {
+	name := setting.GetStr(conf.S3User)
+	if name == "" {
+		return nil, nil
+	}
+	return op.GetUserByName(name)
+}
+
+// Authenticate checks the signature of r, including presigned URLs, and
+// returns the user the access key belongs to. Without any key configured
+// the server is open and acts as the default user.
+func Authenticate(r *http.Request) (*model.User, error) {
+	users, err := keys()
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return defaultUser()
+	}
+	if expires := r.URL.Query().Get("X-Amz-Expires"); expires != "" {
+		if n, err := strconv.Atoi(expires); err == nil && n > maxPresignExpires {
+			return nil, signatureError{
+				Code:           "AuthorizationQueryParametersError",
+				Description:    "X-Amz-Expires must be less than a week",
+				HTTPStatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+	code := signature.V4SignVerify(r)
+	if code == signature.ErrUnsupportAlgorithm {
+		code = signature.V2SignVerify(r)
+	}
+	if code != signature.ErrNone {
+		log.Warnf("[s3] access denied: %s => %s", r.RemoteAddr, r.URL)
+		return nil, signatureError(signature.GetAPIError(code))
+	}
+	id, ok := users[accessKeyOf(r)]
+	if !ok {
+		return nil, signatureError{
+			Code:           "InvalidAccessKeyId",
+			Description:    "The access key ID you provided does not exist in our records.",
+			HTTPStatusCode: http.StatusForbidden,
+		}
+	}
+	if id == 0 {
+		return defaultUser()
+	}
+	user, err := op.GetUserById(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, errAccessDenied
+	}
+	return user, nil
+}
+
+type signatureError signature.APIError
+
+func (e signatureError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// errorResponse turns err into the S3 error document answering r.
+func errorResponse(r *http.Request, err error) *gofakes3.ErrorResponse {
+	if resp, ok := err.(*gofakes3.ErrorResponse); ok {
+		return resp
+	}
+	code := gofakes3.ErrInternal
+	if e, ok := err.(interface{ ErrorCode() gofakes3.ErrorCode }); ok {
+		code = e.ErrorCode()
+	}
+	if code == gofakes3.ErrInternal {
+		log.Errorf("[s3] %s %s: %+v", r.Method, r.URL.Path, err)
+	}
+	return &gofakes3.ErrorResponse{Code: code, Message: code.Message()}
+}
+
+// WriteError answers r with err as an S3 error document.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var sigErr signatureError
+	if errors.As(err, &sigErr) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(sigErr.HTTPStatusCode)
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(signature.EncodeAPIErrorToResponse(signature.APIError(sigErr)))
+		}
+		return
+	}
+	resp := errorResponse(r, err)
+	status := resp.Code.Status()
+	switch resp.Code {
+	case errAccessDenied:
+		status = http.StatusForbidden
+	case errOperationAborted:
+		status = http.StatusConflict
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		writeXML(w, resp)
+	}
+}
@@ -54,11 +54,17 @@ func (b *s3Backend) ListBuckets(ctx context.Context) ([]gofakes3.BucketInfo, err
 	}
 	var response []gofakes3.BucketInfo
 	for _, b := range buckets {
-		node, _ := fs.Get(ctx, b.Path, &fs.GetArgs{})
+		if checkRead(ctx, b.Path) != nil {
+			continue
+		}
+		var created time.Time
+		if node, err := fs.Get(ctx, b.Path, &fs.GetArgs{}); err == nil {
+			created = node.ModTime()
+		}
 		response = append(response, gofakes3.BucketInfo{
 			// Name:         gofakes3.URLEncode(b.Name),
 			Name:         b.Name,
-			CreationDate: gofakes3.NewContentTime(node.ModTime()),
+			CreationDate: gofakes3.NewContentTime(created),
 		})
 	}
 	return response, nil
@@ -86,6 +92,9 @@ func (b *s3Backend) ListBucket(ctx context.Context, bucketName string, prefix *g
 
 	response := gofakes3.NewObjectList()
 	path, remaining := prefixParser(prefix)
+	if err = checkRead(ctx, bucketPath); err != nil {
+		return nil, err
+	}
 
 	err = b.entryListR(bucketPath, path, remaining, prefix.HasDelimiter, response)
 	if err == gofakes3.ErrNoSuchKey {
@@ -109,6 +118,9 @@ func (b *s3Backend) HeadObject(ctx context.Context, bucketName, objectName strin
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if err = checkRead(ctx, fp); err != nil {
+		return nil, err
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, conf.MetaKey, fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -120,7 +132,6 @@ func (b *s3Backend) HeadObject(ctx context.Context, bucketName, objectName strin
 	}
 
 	size := node.GetSize()
-	// hash := getFileHashByte(fobj)
 
 	meta := map[string]string{
 		"Last-Modified": node.ModTime().Format(timeFormat),
@@ -135,8 +146,8 @@ func (b *s3Backend) HeadObject(ctx context.Context, bucketName, objectName strin
 	}
 
 	return &gofakes3.Object{
-		Name: objectName,
-		// Hash:     hash,
+		Name:     objectName,
+		Hash:     getFileHashByte(node),
 		Metadata: meta,
 		Size:     size,
 		Contents: noOpReadCloser{},
@@ -152,6 +163,9 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if err = checkRead(ctx, fp); err != nil {
+		return nil, err
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, conf.MetaKey, fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -211,8 +225,8 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 
 	return &gofakes3.Object{
 		// Name: gofakes3.URLEncode(objectName),
-		Name: objectName,
-		// Hash:     "",
+		Name:     objectName,
+		Hash:     getFileHashByte(node),
 		Metadata: meta,
 		Size:     size,
 		Range:    rnge,
@@ -251,6 +265,9 @@ func (b *s3Backend) PutObject(
 		reqPath = path.Dir(fp)
 	}
 	log.Debugf("reqPath: %s", reqPath)
+	if err = checkPermission(ctx, model.PermWrite, fp); err != nil {
+		return result, err
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	ctx = context.WithValue(ctx, conf.MetaKey, fmeta)
 
@@ -347,6 +364,9 @@ func (b *s3Backend) deleteObject(ctx context.Context, bucketName, objectName str
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if err = checkPermission(ctx, model.PermRemove, fp); err != nil {
+		return err
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	// S3 does not report an error when attemping to delete a key that does not exist, so
 	// we need to skip IsNotExist errors.
@@ -0,0 +1,683 @@
+package s3
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/itsHenry35/gofakes3"
+	xml "github.com/minio/xxml"
+)
+
+const (
+	// uploadTTL is how long an unfinished multipart upload is kept.
+	uploadTTL = 24 * time.Hour
+	maxParts  = 1000
+	// completeKeepAlive is how often a complete request that waits for the
+	// backend is sent whitespace, so the client does not time out.
+	completeKeepAlive = 10 * time.Second
+)
+
+var errUploadCompleting = gofakes3.ErrorMessage(errOperationAborted, "The upload is being completed.")
+
+type uploadPart struct {
+	file     string
+	etag     string
+	size     int64
+	modified time.Time
+}
+
+type upload struct {
+	mu        sync.Mutex
+	id        string
+	bucket    string
+	key       string
+	userID    uint
+	meta      map[string]string
+	initiated time.Time
+	parts     map[int]*uploadPart
+	gone      bool
+	// completing is set while the parts are put into the backend, they must
+	// not change meanwhile
+	completing bool
+}
+
+// discard removes the spooled parts, u.mu must be held.
+func (u *upload) discard() {
+	for _, p := range u.parts {
+		_ = os.Remove(p.file)
+	}
+	u.parts = nil
+	u.gone = true
+}
+
+// uploader runs the multipart uploads. Parts are spooled to temp files as
+// they arrive and streamed one after another into the backend once the
+// upload completes, so no part is held in memory.
+type uploader struct {
+	backend gofakes3.Backend
+	mu      sync.Mutex
+	uploads map[string]*upload
+}
+
+func newUploader(backend gofakes3.Backend) *uploader {
+	return &uploader{
+		backend: backend,
+		uploads: make(map[string]*upload),
+	}
+}
+
+func splitPath(p string) (bucket, key string) {
+	bucket, key, _ = strings.Cut(strings.TrimPrefix(p, "/"), "/")
+	return
+}
+
+func userIDOf(r *http.Request) uint {
+	if user, ok := r.Context().Value(conf.UserKey).(*model.User); ok {
+		return user.ID
+	}
+	return 0
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+// serve handles r if it is a multipart upload request and tells whether
+// it did.
+func (u *uploader) serve(w http.ResponseWriter, r *http.Request) bool {
+	query := r.URL.Query()
+	bucket, key := splitPath(r.URL.Path)
+	var err error
+	if id := query.Get("uploadId"); id != "" {
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("X-Amz-Copy-Source") != "" {
+				err = gofakes3.ErrNotImplemented
+			} else {
+				err = u.putPart(w, r, bucket, key, id)
+			}
+		case http.MethodPost:
+			err = u.complete(w, r, bucket, key, id)
+		case http.MethodDelete:
+			err = u.abort(w, r, bucket, key, id)
+		case http.MethodGet:
+			err = u.listParts(w, r, bucket, key, id)
+		default:
+			return false
+		}
+	} else if _, ok := query["uploads"]; ok {
+		switch {
+		case r.Method == http.MethodPost && key != "":
+			err = u.initiate(w, r, bucket, key)
+		case r.Method == http.MethodGet && key == "":
+			err = u.listUploads(w, r, bucket)
+		default:
+			return false
+		}
+	} else {
+		return false
+	}
+	if err != nil {
+		WriteError(w, r, err)
+	}
+	return true
+}
+
+func (u *uploader) get(r *http.Request, bucket, key, id string) (*upload, error) {
+	u.mu.Lock()
+	up, ok := u.uploads[id]
+	u.mu.Unlock()
+	if !ok || up.bucket != bucket || up.key != key || up.userID != userIDOf(r) {
+		return nil, gofakes3.ErrNoSuchUpload
+	}
+	return up, nil
+}
+
+func (u *uploader) forget(id string) {
+	u.mu.Lock()
+	delete(u.uploads, id)
+	u.mu.Unlock()
+}
+
+// sweep drops the uploads which were neither completed nor aborted in time.
+func (u *uploader) sweep() {
+	var expired []*upload
+	u.mu.Lock()
+	for id, up := range u.uploads {
+		if time.Since(up.initiated) <= uploadTTL {
+			continue
+		}
+		up.mu.Lock()
+		completing := up.completing
+		up.mu.Unlock()
+		if !completing {
+			expired = append(expired, up)
+			delete(u.uploads, id)
+		}
+	}
+	u.mu.Unlock()
+	for _, up := range expired {
+		up.mu.Lock()
+		up.discard()
+		up.mu.Unlock()
+	}
+}
+
+func (u *uploader) initiate(w http.ResponseWriter, r *http.Request, bucketName, key string) error {
+	bucket, err := getBucketByName(bucketName)
+	if err != nil {
+		return err
+	}
+	if err = checkPermission(r.Context(), model.PermWrite, path.Join(bucket.Path, key)); err != nil {
+		return err
+	}
+	meta := make(map[string]string)
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, "X-Amz-") || strings.HasPrefix(k, "Content-") || k == "Cache-Control" {
+			meta[k] = v[0]
+		}
+	}
+	// the headers of the initiating request do not describe the object body
+	delete(meta, "Content-Length")
+	delete(meta, "Content-Md5")
+	delete(meta, "X-Amz-Content-Sha256")
+	delete(meta, "X-Amz-Decoded-Content-Length")
+
+	u.sweep()
+	up := &upload{
+		id:        uuid.NewString(),
+		bucket:    bucketName,
+		key:       key,
+		userID:    userIDOf(r),
+		meta:      meta,
+		initiated: time.Now(),
+		parts:     make(map[int]*uploadPart),
+	}
+	u.mu.Lock()
+	u.uploads[up.id] = up
+	u.mu.Unlock()
+	writeXML(w, gofakes3.InitiateMultipartUpload{
+		Bucket:   bucketName,
+		Key:      key,
+		UploadID: gofakes3.UploadID(up.id),
+	})
+	return nil
+}
+
+func (u *uploader) putPart(w http.ResponseWriter, r *http.Request, bucket, key, id string) error {
+	number, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || number < 1 || number > gofakes3.MaxUploadPartNumber {
+		return gofakes3.ErrorInvalidArgument("partNumber", r.URL.Query().Get("partNumber"),
+			"Part number must be an integer between 1 and 10000, inclusive")
+	}
+	up, err := u.get(r, bucket, key, id)
+	if err != nil {
+		return err
+	}
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		signer, err := newChunkSigner(r)
+		if err != nil {
+			return err
+		}
+		body = &chunkReader{r: bufio.NewReader(r.Body), signer: signer}
+	}
+	part, err := spoolPart(body)
+	if err != nil {
+		return err
+	}
+	if want := r.Header.Get("Content-MD5"); want != "" {
+		if sum, _ := base64.StdEncoding.DecodeString(want); hex.EncodeToString(sum) != part.etag {
+			_ = os.Remove(part.file)
+			return gofakes3.ErrBadDigest
+		}
+	}
+
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	if up.gone || up.completing {
+		_ = os.Remove(part.file)
+		if up.completing {
+			return errUploadCompleting
+		}
+		return gofakes3.ErrNoSuchUpload
+	}
+	if old, ok := up.parts[number]; ok {
+		_ = os.Remove(old.file)
+	}
+	up.parts[number] = part
+	w.Header().Set("ETag", `"`+part.etag+`"`)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// spoolPart writes a part to a temp file, taking its MD5 on the way.
+func spoolPart(body io.Reader) (*uploadPart, error) {
+	f, err := os.CreateTemp(conf.Conf.TempDir, "s3-part-*")
+	if err != nil {
+		return nil, err
+	}
+	h := md5.New()
+	n, err := utils.CopyWithBuffer(io.MultiWriter(f, h), body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+	return &uploadPart{
+		file:     f.Name(),
+		etag:     hex.EncodeToString(h.Sum(nil)),
+		size:     n,
+		modified: time.Now(),
+	}, nil
+}
+
+func (u *uploader) complete(w http.ResponseWriter, r *http.Request, bucket, key, id string) error {
+	up, err := u.get(r, bucket, key, id)
+	if err != nil {
+		return err
+	}
+	var req gofakes3.CompleteMultipartUploadRequest
+	if err = xml.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		return gofakes3.ErrMalformedXML
+	}
+
+	files, size, sums, err := up.begin(req.Parts)
+	if err != nil {
+		return err
+	}
+
+	// the backend may take long to store a large object, so the response
+	// starts now and whitespace keeps the connection open until the result
+	// is written, like S3 does. The put goes on if the client hangs up.
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	done := make(chan error, 1)
+	go func() {
+		rd := &partsReader{files: files}
+		defer rd.Close()
+		_, err := u.backend.PutObject(context.WithoutCancel(r.Context()), bucket, key, up.meta, rd, size)
+		done <- err
+	}()
+	err = keepAlive(w, done)
+
+	up.mu.Lock()
+	up.completing = false
+	if err == nil {
+		up.discard()
+	}
+	up.mu.Unlock()
+	if err != nil {
+		// the parts are kept, the client may complete the upload again
+		_ = xml.NewEncoder(w).Encode(errorResponse(r, err))
+		return nil
+	}
+	u.forget(id)
+
+	etag := md5.Sum(sums)
+	_ = xml.NewEncoder(w).Encode(gofakes3.CompleteMultipartUploadResult{
+		Location: "/" + bucket + "/" + key,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     `"` + hex.EncodeToString(etag[:]) + "-" + strconv.Itoa(len(files)) + `"`,
+	})
+	return nil
+}
+
+// begin checks the parts a complete request lists and marks the upload as
+// completing, it returns the spooled files of the parts in order.
+func (up *upload) begin(parts []gofakes3.CompletedPart) (files []string, size int64, sums []byte, err error) {
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	if up.gone {
+		return nil, 0, nil, gofakes3.ErrNoSuchUpload
+	}
+	if up.completing {
+		return nil, 0, nil, errUploadCompleting
+	}
+	last := 0
+	for _, p := range parts {
+		if p.PartNumber <= last {
+			return nil, 0, nil, gofakes3.ErrInvalidPartOrder
+		}
+		last = p.PartNumber
+		part, ok := up.parts[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, `"`) != part.etag {
+			return nil, 0, nil, gofakes3.ErrInvalidPart
+		}
+		sum, _ := hex.DecodeString(part.etag)
+		sums = append(sums, sum...)
+		files = append(files, part.file)
+		size += part.size
+	}
+	up.completing = true
+	return files, size, sums, nil
+}
+
+// keepAlive writes whitespace to w until done yields the result of the put.
+func keepAlive(w http.ResponseWriter, done <-chan error) error {
+	ticker := time.NewTicker(completeKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			_, _ = w.Write([]byte(" "))
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+	}
+}
+
+func (u *uploader) abort(w http.ResponseWriter, r *http.Request, bucket, key, id string) error {
+	up, err := u.get(r, bucket, key, id)
+	if err != nil {
+		return err
+	}
+	up.mu.Lock()
+	if up.completing {
+		up.mu.Unlock()
+		return errUploadCompleting
+	}
+	up.discard()
+	up.mu.Unlock()
+	u.forget(id)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (u *uploader) listParts(w http.ResponseWriter, r *http.Request, bucket, key, id string) error {
+	up, err := u.get(r, bucket, key, id)
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	marker, _ := strconv.Atoi(query.Get("part-number-marker"))
+	limit, err := strconv.Atoi(query.Get("max-parts"))
+	if err != nil || limit <= 0 || limit > maxParts {
+		limit = maxParts
+	}
+
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	if up.gone {
+		return gofakes3.ErrNoSuchUpload
+	}
+	numbers := make([]int, 0, len(up.parts))
+	for n := range up.parts {
+		if n > marker {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	res := gofakes3.ListMultipartUploadPartsResult{
+		Bucket:           bucket,
+		Key:              key,
+		UploadID:         gofakes3.UploadID(id),
+		StorageClass:     gofakes3.StorageStandard,
+		PartNumberMarker: marker,
+		MaxParts:         int64(limit),
+	}
+	if len(numbers) > limit {
+		numbers = numbers[:limit]
+		res.IsTruncated = true
+	}
+	for _, n := range numbers {
+		p := up.parts[n]
+		res.Parts = append(res.Parts, gofakes3.ListMultipartUploadPartItem{
+			PartNumber:   n,
+			LastModified: gofakes3.NewContentTime(p.modified),
+			ETag:         `"` + p.etag + `"`,
+			Size:         p.size,
+		})
+		res.NextPartNumberMarker = n
+	}
+	writeXML(w, res)
+	return nil
+}
+
+func (u *uploader) listUploads(w http.ResponseWriter, r *http.Request, bucket string) error {
+	if _, err := getBucketByName(bucket); err != nil {
+		return err
+	}
+	prefix := r.URL.Query().Get("prefix")
+	userID := userIDOf(r)
+	var items []gofakes3.ListMultipartUploadItem
+	u.mu.Lock()
+	for _, up := range u.uploads {
+		if up.bucket == bucket && up.userID == userID && strings.HasPrefix(up.key, prefix) {
+			items = append(items, gofakes3.ListMultipartUploadItem{
+				Key:          up.key,
+				UploadID:     gofakes3.UploadID(up.id),
+				StorageClass: gofakes3.StorageStandard,
+				Initiated:    gofakes3.NewContentTime(up.initiated),
+			})
+		}
+	}
+	u.mu.Unlock()
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Key != items[j].Key {
+			return items[i].Key < items[j].Key
+		}
+		return items[i].Initiated.Before(items[j].Initiated.Time)
+	})
+	res := gofakes3.ListMultipartUploadsResult{
+		Bucket:     bucket,
+		Prefix:     prefix,
+		MaxUploads: maxParts,
+	}
+	if len(items) > maxParts {
+		items = items[:maxParts]
+		res.IsTruncated = true
+		res.NextKeyMarker = items[maxParts-1].Key
+		res.NextUploadIDMarker = items[maxParts-1].UploadID
+	}
+	res.Uploads = items
+	writeXML(w, res)
+	return nil
+}
+
+// partsReader reads the spooled parts one after another, opening each file
+// only when it is reached.
+type partsReader struct {
+	files []string
+	cur   *os.File
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.cur == nil {
+			if len(p.files) == 0 {
+				return 0, io.EOF
+			}
+			f, err := os.Open(p.files[0])
+			if err != nil {
+				return 0, err
+			}
+			p.cur, p.files = f, p.files[1:]
+		}
+		n, err := p.cur.Read(b)
+		if err == io.EOF {
+			_ = p.cur.Close()
+			p.cur = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (p *partsReader) Close() error {
+	if p.cur == nil {
+		return nil
+	}
+	return p.cur.Close()
+}
+
+// streamingPayload marks a body whose chunks are signed, a -TRAILER suffix
+// adds trailing headers.
+const streamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// emptySHA256 is the hex SHA256 of no data.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var errChunkSignature = signatureError{
+	Code:           "SignatureDoesNotMatch",
+	Description:    "The chunk signature we calculated does not match the signature you provided.",
+	HTTPStatusCode: http.StatusForbidden,
+}
+
+// chunkSigner checks the chunk signatures of an aws-chunked body. Each chunk
+// signs its data and the signature of the chunk before it, the first one
+// chains on the seed signature of the request.
+type chunkSigner struct {
+	key   []byte
+	date  string
+	scope string
+	prev  string
+}
+
+// newChunkSigner returns the signer of the chunks of r, nil when they are
+// not signed or the server is open.
+func newChunkSigner(r *http.Request) (*chunkSigner, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), streamingPayload) {
+		return nil, nil
+	}
+	users, err := keys()
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return nil, errChunkSignature
+	}
+	var cred, seed string
+	for _, field := range strings.Split(auth, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch k {
+		case "Credential":
+			cred = v
+		case "Signature":
+			seed = v
+		}
+	}
+	ak, scope, _ := strings.Cut(cred, "/")
+	secret, ok := secretOf(ak)
+	date := r.Header.Get("X-Amz-Date")
+	// the scope is date/region/service/aws4_request, the signing key is
+	// derived from each in turn
+	fields := strings.Split(scope, "/")
+	if !ok || len(fields) != 4 || seed == "" || date == "" {
+		return nil, errChunkSignature
+	}
+	key := []byte("AWS4" + secret)
+	for _, field := range fields {
+		key = hmacSHA256(key, field)
+	}
+	return &chunkSigner{key: key, date: date, scope: scope, prev: seed}, nil
+}
+
+// verify checks sig against the SHA256 sum of a chunk's data.
+func (s *chunkSigner) verify(sig string, sum []byte) bool {
+	stringToSign := "AWS4-HMAC-SHA256-PAYLOAD\n" + s.date + "\n" + s.scope + "\n" +
+		s.prev + "\n" + emptySHA256 + "\n" + hex.EncodeToString(sum)
+	want := hex.EncodeToString(hmacSHA256(s.key, stringToSign))
+	if !hmac.Equal([]byte(want), []byte(sig)) {
+		return false
+	}
+	s.prev = sig
+	return true
+}
+
+// chunkReader decodes an aws-chunked body, checking the chunk signatures
+// when signer is set and dropping the trailers.
+type chunkReader struct {
+	r      *bufio.Reader
+	signer *chunkSigner
+	hash   hash.Hash
+	sig    string
+	remain int64
+	done   bool
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for c.remain == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+		sizeStr, ext, _ := strings.Cut(strings.TrimRight(line, "\r\n"), ";")
+		size, err := strconv.ParseInt(strings.TrimSpace(sizeStr), 16, 64)
+		if err != nil || size < 0 {
+			return 0, gofakes3.ErrIncompleteBody
+		}
+		_, c.sig, _ = strings.Cut(ext, "chunk-signature=")
+		c.hash = sha256.New()
+		if size == 0 {
+			c.done = true
+			if err = c.check(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+		c.remain = size
+	}
+	if int64(len(p)) > c.remain {
+		p = p[:c.remain]
+	}
+	n, err := c.r.Read(p)
+	c.hash.Write(p[:n])
+	c.remain -= int64(n)
+	if err == nil && c.remain == 0 {
+		// the data of every chunk ends with CRLF
+		if _, err = c.r.Discard(2); err == nil {
+			err = c.check()
+		}
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// check verifies the signature of the chunk just read.
+func (c *chunkReader) check() error {
+	if c.signer != nil && !c.signer.verify(c.sig, c.hash.Sum(nil)) {
+		return errChunkSignature
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	presignRegion = "us-east-1"
+	iso8601Format = "20060102T150405Z"
+	yyyymmdd      = "20060102"
+)
+
+// Presign returns a SigV4 query-signed URL letting anyone holding it run
+// method on key of bucket until it expires. base is the URL the S3 server
+// is reached at, path-style buckets are used.
+func Presign(method, base, bucket, key, accessKey, secretKey string, expires time.Duration) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(base, "/"))
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	scope := strings.Join([]string{now.Format(yyyymmdd), presignRegion, "s3", "aws4_request"}, "/")
+	canonicalPath := uriEncode(u.Path + "/" + bucket + "/" + key)
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {accessKey + "/" + scope},
+		"X-Amz-Date":          {now.Format(iso8601Format)},
+		"X-Amz-Expires":       {strconv.Itoa(int(expires.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	rawQuery := strings.ReplaceAll(query.Encode(), "+", "%20")
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalPath,
+		rawQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format(iso8601Format) + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	signingKey := []byte("AWS4" + secretKey)
+	for _, s := range []string{now.Format(yyyymmdd), presignRegion, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, s)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	return u.Scheme + "://" + u.Host + canonicalPath + "?" + rawQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// uriEncode escapes p the way SigV4 canonical URIs are, keeping slashes.
+func uriEncode(p string) string {
+	var sb strings.Builder
+	for _, b := range []byte(p) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/':
+			sb.WriteByte(b)
+		default:
+			sb.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{b})))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,52 @@
+package s3
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itsHenry35/gofakes3/signature"
+)
+
+func TestPresign(t *testing.T) {
+	signature.StoreKeys(map[string]string{"OLTESTKEY": "secret"})
+	tests := []struct {
+		method, base, key string
+	}{
+		{http.MethodGet, "http://127.0.0.1:5246", "dir/file.txt"},
+		{http.MethodPut, "https://example.com/s3/", "dir/with space/文件.bin"},
+	}
+	for _, tt := range tests {
+		u, err := Presign(tt.method, tt.base, "bucket", tt.key, "OLTESTKEY", "secret", time.Hour)
+		if err != nil {
+			t.Fatalf("Presign(%s): %v", tt.key, err)
+		}
+		r := httptest.NewRequest(tt.method, u, nil)
+		if code := signature.V4SignVerify(r); code != signature.ErrNone {
+			t.Errorf("%s %s: verify failed: %v", tt.method, u, signature.GetAPIError(code))
+		}
+		r = httptest.NewRequest(http.MethodDelete, u, nil)
+		if code := signature.V4SignVerify(r); code == signature.ErrNone {
+			t.Errorf("%s: verified for another method", u)
+		}
+		if got := accessKeyOf(r); got != "OLTESTKEY" {
+			t.Errorf("accessKeyOf = %q", got)
+		}
+	}
+}
+
+func TestChunkReader(t *testing.T) {
+	body := "5;chunk-signature=abc\r\nhello\r\n6;chunk-signature=def\r\n world\r\n0;chunk-signature=ghi\r\n\r\n"
+	got, err := io.ReadAll(&chunkReader{r: bufio.NewReader(strings.NewReader(body))})
+	if err != nil || string(got) != "hello world" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	_, err = io.ReadAll(&chunkReader{r: bufio.NewReader(strings.NewReader(body[:30]))})
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated body: got %v", err)
+	}
+}
@@ -5,23 +5,64 @@ package s3
 import (
 	"context"
 	"math/rand"
+	"net"
 	"net/http"
+	"strings"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/itsHenry35/gofakes3"
 )
 
+type server struct {
+	faker   http.Handler
+	uploads *uploader
+}
+
 // Make a new S3 Server to serve the remote
+//
+// Requests must be authenticated with Authenticate before they reach the
+// returned handler.
 func NewServer(ctx context.Context) (h http.Handler, err error) {
 	var newLogger logger
+	backend := newBackend()
 	faker := gofakes3.New(
-		newBackend(),
-		// gofakes3.WithHostBucket(!opt.pathBucketMode),
+		backend,
 		gofakes3.WithLogger(newLogger),
 		gofakes3.WithRequestID(rand.Uint64()),
 		gofakes3.WithoutVersioning(),
-		gofakes3.WithV4Auth(authlistResolver()),
 		gofakes3.WithIntegrityCheck(true), // Check Content-MD5 if supplied
 	)
 
-	return faker.Server(), nil
+	return &server{
+		faker:   faker.Server(),
+		uploads: newUploader(backend),
+	}, nil
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if bucket := hostBucket(r.Host); bucket != "" {
+		r.URL.Path = "/" + bucket + r.URL.Path
+	}
+	if s.uploads.serve(w, r) {
+		return
+	}
+	s.faker.ServeHTTP(w, r)
+}
+
+// hostBucket returns the bucket of a virtual-host style request, that is
+// the label in front of the s3 virtual host domain.
+func hostBucket(host string) string {
+	domain := strings.Trim(setting.GetStr(conf.S3VirtualHostDomain), ".")
+	if domain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	bucket, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(domain))
+	if !ok || bucket == "" {
+		return ""
+	}
+	return bucket
 }
@@ -4,6 +4,8 @@ package s3
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
 	"strings"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/itsHenry35/gofakes3"
 )
 
@@ -64,49 +68,25 @@ func getDirEntries(path string) ([]model.Obj, error) {
 	return dirEntries, nil
 }
 
-// func getFileHashByte(node interface{}) []byte {
-// 	b, err := hex.DecodeString(getFileHash(node))
-// 	if err != nil {
-// 		return nil
-// 	}
-// 	return b
-// }
+// getFileHashByte returns the MD5 of obj, nil when the driver does not
+// know it.
+func getFileHashByte(obj model.Obj) []byte {
+	if obj == nil {
+		return nil
+	}
+	b, err := hex.DecodeString(obj.GetHash().GetHash(utils.MD5))
+	if err != nil || len(b) != md5.Size {
+		return nil
+	}
+	return b
+}
 
-func getFileHash(node interface{}) string {
-	// var o fs.Object
-
-	// switch b := node.(type) {
-	// case vfs.Node:
-	// 	fsObj, ok := b.DirEntry().(fs.Object)
-	// 	if !ok {
-	// 		fs.Debugf("serve s3", "File uploading - reading hash from VFS cache")
-	// 		in, err := b.Open(os.O_RDONLY)
-	// 		if err != nil {
-	// 			return ""
-	// 		}
-	// 		defer func() {
-	// 			_ = in.Close()
-	// 		}()
-	// 		h, err := hash.NewMultiHasherTypes(hash.NewHashSet(hash.MD5))
-	// 		if err != nil {
-	// 			return ""
-	// 		}
-	// 		_, err = io.Copy(h, in)
-	// 		if err != nil {
-	// 			return ""
-	// 		}
-	// 		return h.Sums()[hash.MD5]
-	// 	}
-	// 	o = fsObj
-	// case fs.Object:
-	// 	o = b
-	// }
-
-	// hash, err := o.Hash(context.Background(), hash.MD5)
-	// if err != nil {
-	// 	return ""
-	// }
-	// return hash
+// getFileHash returns the MD5 of obj as an ETag, empty when the driver
+// does not know it.
+func getFileHash(obj model.Obj) string {
+	if b := getFileHashByte(obj); b != nil {
+		return `"` + hex.EncodeToString(b) + `"`
+	}
 	return ""
 }
 
@@ -160,3 +140,38 @@ func authlistResolver() map[string]string {
 	authList[s3accesskeyid] = s3secretaccesskey
 	return authList
 }
+
+var (
+	errAccessDenied     = gofakes3.ErrorCode("AccessDenied")
+	errOperationAborted = gofakes3.ErrorCode("OperationAborted")
+)
+
+// checkPermission checks perm on fp for the user the request authenticated
+// as, a request without one is unrestricted.
+func checkPermission(ctx context.Context, perm int32, fp string) error {
+	user, _ := ctx.Value(conf.UserKey).(*model.User)
+	if user == nil {
+		return nil
+	}
+	if user.Disabled || !utils.IsSubPath(user.BasePath, fp) || !user.CanAt(perm, fp) {
+		return errAccessDenied
+	}
+	return nil
+}
+
+// checkRead tells whether the user of the request may read fp, that is fp
+// is below the user's base path and not hidden or password protected.
+func checkRead(ctx context.Context, fp string) error {
+	user, _ := ctx.Value(conf.UserKey).(*model.User)
+	if user == nil {
+		return nil
+	}
+	if user.Disabled || !utils.IsSubPath(user.BasePath, fp) {
+		return errAccessDenied
+	}
+	meta, _ := op.GetNearestMeta(fp)
+	if !common.CanAccess(user, meta, fp, "") {
+		return errAccessDenied
+	}
+	return nil
+}
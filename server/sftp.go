@@ -3,9 +3,11 @@ package server
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/drivers/base"
+	"github.com/OpenListTeam/OpenList/v4/internal/audit"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
@@ -18,6 +20,10 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
+// sshKeyIDExtension carries the id of the public key a connection logged in
+// with, so its path limits apply to the session.
+const sshKeyIDExtension = "openlist-key-id"
+
 type SftpDriver struct {
 	proxyHeader http.Header
 	config      *sftpd.Config
@@ -66,10 +72,23 @@ func (d *SftpDriver) GetFileSystem(sc *ssh.ServerConn) (sftpd.FileSystem, error)
 	if err != nil {
 		return nil, err
 	}
+	if sc.Permissions != nil {
+		if id, ok := sc.Permissions.Extensions[sshKeyIDExtension]; ok {
+			keyId, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			key, err := op.GetSSHPublicKeyByIdAndUserId(uint(keyId), userObj.ID)
+			if err != nil {
+				return nil, err
+			}
+			userObj = userObj.WithPathLimits(key.Paths)
+		}
+	}
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, conf.UserKey, userObj)
 	ctx = context.WithValue(ctx, conf.MetaPassKey, "")
-	ctx = context.WithValue(ctx, conf.ClientIPKey, sc.RemoteAddr().String())
+	ctx = audit.WithSource(ctx, model.AuditProtocolSFTP, sc.RemoteAddr().String())
 	ctx = context.WithValue(ctx, conf.ProxyHeaderKey, d.proxyHeader)
 	return &sftp.DriverAdapter{FtpDriver: ftp.NewAferoAdapter(ctx)}, nil
 }
@@ -85,7 +104,7 @@ func (d *SftpDriver) NoClientAuth(conn ssh.ConnMetadata) (*ssh.Permissions, erro
 	if err != nil {
 		return nil, err
 	}
-	if guest.Disabled || !guest.CanFTPAccess() {
+	if guest.Disabled || !guest.CanAnywhere(model.PermFTPAccess) {
 		return nil, errors.New("user is not allowed to access via SFTP")
 	}
 	return nil, nil
@@ -96,9 +115,12 @@ func (d *SftpDriver) PasswordAuth(conn ssh.ConnMetadata, password []byte) (*ssh.
 	if err != nil {
 		return nil, err
 	}
-	if userObj.Disabled || !userObj.CanFTPAccess() {
+	if userObj.Disabled || !userObj.CanAnywhere(model.PermFTPAccess) {
 		return nil, errors.New("user is not allowed to access via SFTP")
 	}
+	if userObj.SSHKeyOnly {
+		return nil, errors.New("user is only allowed to log in with a public key")
+	}
 	passHash := model.StaticHash(string(password))
 	if err = userObj.ValidatePwdStaticHash(passHash); err != nil {
 		return nil, err
@@ -111,7 +133,7 @@ func (d *SftpDriver) PublicKeyAuth(conn ssh.ConnMetadata, key ssh.PublicKey) (*s
 	if err != nil {
 		return nil, err
 	}
-	if userObj.Disabled || !userObj.CanFTPAccess() {
+	if userObj.Disabled || !userObj.CanAnywhere(model.PermFTPAccess) {
 		return nil, errors.New("user is not allowed to access via SFTP")
 	}
 	keys, _, err := op.GetSSHPublicKeyByUserId(userObj.ID, 1, -1)
@@ -128,7 +150,9 @@ func (d *SftpDriver) PublicKeyAuth(conn ssh.ConnMetadata, key ssh.PublicKey) (*s
 		}
 		sk.LastUsedTime = time.Now()
 		_ = op.UpdateSSHPublicKey(&sk)
-		return nil, nil
+		return &ssh.Permissions{
+			Extensions: map[string]string{sshKeyIDExtension: strconv.FormatUint(uint64(sk.ID), 10)},
+		}, nil
 	}
 	return nil, errors.New("public key refused")
 }
@@ -2,6 +2,7 @@ package sftp
 
 import (
 	"os"
+	"path"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -40,20 +41,29 @@ func (s *DriverAdapter) Rmdir(name string) error {
 	return s.Remove(name)
 }
 
-func (s *DriverAdapter) Stat(name string, _ bool) (*sftpd.Attr, error) {
+func (s *DriverAdapter) Stat(name string, isLstat bool) (*sftpd.Attr, error) {
 	stat, err := s.FtpDriver.Stat(name)
 	if err != nil {
 		return nil, err
 	}
-	return fileInfoToSftpAttr(stat), nil
+	attr := fileInfoToSftpAttr(stat)
+	if isLstat && s.isLink(name, stat) {
+		attr.Mode = attr.Mode&^os.ModeDir | os.ModeSymlink
+	}
+	return attr, nil
 }
 
-func (s *DriverAdapter) SetStat(_ string, _ *sftpd.Attr) error {
-	return errs.NotSupport
+func (s *DriverAdapter) SetStat(name string, attr *sftpd.Attr) error {
+	// modes and owners mean nothing to the storages, they are accepted so
+	// that clients preserving attributes carry on
+	if (attr.Flags & sftpd.ATTR_TIME) == 0 {
+		return nil
+	}
+	return s.FtpDriver.Chtimes(name, attr.ATime, attr.MTime)
 }
 
-func (s *DriverAdapter) ReadLink(_ string) (string, error) {
-	return "", errs.NotSupport
+func (s *DriverAdapter) ReadLink(name string) (string, error) {
+	return s.FtpDriver.ReadLink(name)
 }
 
 func (s *DriverAdapter) CreateLink(_, _ string, _ uint32) error {
@@ -76,10 +86,23 @@ func (s *DriverAdapter) ReadDir(name string) ([]sftpd.NamedAttr, error) {
 	ret := make([]sftpd.NamedAttr, len(dir))
 	for i, d := range dir {
 		ret[i] = *fileInfoToSftpNamedAttr(d)
+		if s.isLink(path.Join(name, d.Name()), d) {
+			ret[i].Attr.Mode = ret[i].Attr.Mode&^os.ModeDir | os.ModeSymlink
+		}
 	}
 	return ret, nil
 }
 
+// isLink tells whether name is the mount point of a storage standing for
+// another path, such as an alias of a single directory.
+func (s *DriverAdapter) isLink(name string, stat os.FileInfo) bool {
+	if !stat.IsDir() {
+		return false
+	}
+	_, err := s.FtpDriver.ReadLink(name)
+	return err == nil
+}
+
 // From leffss/sftpd
 func sftpFlagToOpenMode(flags uint32) int {
 	mode := 0
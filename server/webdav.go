@@ -3,9 +3,12 @@ package server
 import (
 	"crypto/subtle"
 	"net/http"
+	"net/url"
 	"path"
 	"strings"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/audit"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
@@ -29,7 +32,7 @@ func WebDav(dav *gin.RouterGroup) {
 			log.Errorf("%s %s %+v", request.Method, request.URL.Path, err)
 		},
 	}
-	dav.Use(WebDAVAuth)
+	dav.Use(WebDAVAuth, middlewares.Traffic)
 	uploadLimiter := middlewares.UploadRateLimiter(stream.ClientUploadLimit)
 	downloadLimiter := middlewares.DownloadRateLimiter(stream.ClientDownloadLimit)
 	dav.Any("/*path", uploadLimiter, downloadLimiter, ServeWebDAV)
@@ -51,6 +54,7 @@ func ServeWebDAV(c *gin.Context) {
 func WebDAVAuth(c *gin.Context) {
 	// check count of login
 	ip := c.ClientIP()
+	c.Request = c.Request.WithContext(audit.WithSource(c.Request.Context(), model.AuditProtocolWebDAV, ip))
 	guest, _ := op.GetGuest()
 	count, cok := model.LoginCache.Get(ip)
 	if cok && count >= model.DefaultMaxAuthRetries {
@@ -83,6 +87,12 @@ func WebDAVAuth(c *gin.Context) {
 				c.Next()
 				return
 			}
+			if model.IsAPIToken(bt) {
+				if user, err := webdavAPITokenUser(bt); err == nil {
+					webdavAuthorize(c, user, guest)
+					return
+				}
+			}
 		}
 		if c.Request.Method == "OPTIONS" {
 			common.GinWithValue(c, conf.UserKey, guest)
@@ -94,8 +104,21 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	user, err := op.GetUserByName(username)
-	if err != nil || user.ValidateRawPassword(password) != nil {
+	var user *model.User
+	var err error
+	if model.IsAPIToken(password) {
+		// a personal api token works in place of the password
+		user, err = webdavAPITokenUser(password)
+		if err == nil && user.Username != username {
+			err = errs.PermissionDenied
+		}
+	} else {
+		user, err = op.GetUserByName(username)
+		if err == nil {
+			err = user.ValidateRawPassword(password)
+		}
+	}
+	if err != nil {
 		if c.Request.Method == "OPTIONS" {
 			common.GinWithValue(c, conf.UserKey, guest)
 			c.Next()
@@ -108,7 +131,11 @@ func WebDAVAuth(c *gin.Context) {
 	}
 	// at least auth is successful till here
 	model.LoginCache.Del(ip)
-	if user.Disabled || !user.CanWebdavRead() {
+	webdavAuthorize(c, user, guest)
+}
+
+func webdavAuthorize(c *gin.Context, user, guest *model.User) {
+	if user.Disabled || !user.CanAnywhere(model.PermWebdavRead) {
 		if c.Request.Method == "OPTIONS" {
 			common.GinWithValue(c, conf.UserKey, guest)
 			c.Next()
@@ -118,31 +145,71 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	if (c.Request.Method == "PUT" || c.Request.Method == "MKCOL") && (!user.CanWebdavManage() || !user.CanWrite()) {
+	if !webdavPermitted(c, user) {
 		c.Status(http.StatusForbidden)
 		c.Abort()
 		return
 	}
-	if c.Request.Method == "MOVE" && (!user.CanWebdavManage() || (!user.CanMove() && !user.CanRename())) {
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
+	common.GinWithValue(c, conf.UserKey, user)
+	c.Next()
+}
+
+// webdavAPITokenUser authenticates a personal api token, which needs the
+// fs:read scope to be used over webdav.
+func webdavAPITokenUser(raw string) (*model.User, error) {
+	user, t, err := op.AuthByAPIToken(raw)
+	if err != nil {
+		return nil, err
 	}
-	if c.Request.Method == "COPY" && (!user.CanWebdavManage() || !user.CanCopy()) {
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
+	if !t.HasScope(model.TokenScopeFsRead) {
+		return nil, errs.PermissionDenied
 	}
-	if c.Request.Method == "DELETE" && (!user.CanWebdavManage() || !user.CanRemove()) {
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
+	return user, nil
+}
+
+// webdavPermitted checks the user's permissions on the paths a request
+// touches, grants may allow a method below some directory only.
+func webdavPermitted(c *gin.Context, user *model.User) bool {
+	reqPath, err := webdavPath(user, c.Request.URL.Path)
+	if err != nil {
+		return false
 	}
-	if c.Request.Method == "PROPPATCH" && !user.CanWebdavManage() {
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
+	if c.Request.Method != "OPTIONS" && !user.CanReach(model.PermWebdavRead, reqPath) {
+		return false
 	}
-	common.GinWithValue(c, conf.UserKey, user)
-	c.Next()
+	switch c.Request.Method {
+	case "PUT", "MKCOL":
+		return user.CanAt(model.PermWebdavManage|model.PermWrite, reqPath)
+	case "MOVE":
+		dstPath, err := webdavDestination(c, user)
+		if err != nil {
+			return false
+		}
+		return user.CanAt(model.PermWebdavManage, reqPath) && user.CanAt(model.PermWebdavManage, dstPath) &&
+			(user.CanAt(model.PermMove, reqPath) || user.CanAt(model.PermRename, reqPath))
+	case "COPY":
+		dstPath, err := webdavDestination(c, user)
+		if err != nil {
+			return false
+		}
+		return user.CanAt(model.PermWebdavManage|model.PermCopy, dstPath)
+	case "DELETE":
+		return user.CanAt(model.PermWebdavManage|model.PermRemove, reqPath)
+	case "PROPPATCH":
+		return user.CanAt(model.PermWebdavManage, reqPath)
+	}
+	return true
+}
+
+// webdavPath maps a request URL path to the path the handler will serve.
+func webdavPath(user *model.User, urlPath string) (string, error) {
+	return user.JoinPath(strings.TrimPrefix(urlPath, handler.Prefix))
+}
+
+func webdavDestination(c *gin.Context, user *model.User) (string, error) {
+	u, err := url.Parse(c.GetHeader("Destination"))
+	if err != nil {
+		return "", err
+	}
+	return webdavPath(user, u.Path)
 }
@@ -34,10 +34,10 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 	srcName := path.Base(src)
 	dstName := path.Base(dst)
 	user := ctx.Value(conf.UserKey).(*model.User)
-	if srcDir != dstDir && !user.CanMove() {
+	if srcDir != dstDir && (!user.CanAt(model.PermMove, src) || !user.CanAt(model.PermMove, dstDir)) {
 		return http.StatusForbidden, nil
 	}
-	if srcName != dstName && !user.CanRename() {
+	if srcName != dstName && !user.CanAt(model.PermRename, src) {
 		return http.StatusForbidden, nil
 	}
 	if srcDir == dstDir {